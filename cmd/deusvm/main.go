@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -10,9 +11,12 @@ import (
 	"time"
 
 	"github.com/riccardotacconi/deusvm/internal/api"
+	"github.com/riccardotacconi/deusvm/internal/backup"
 	"github.com/riccardotacconi/deusvm/internal/config"
+	"github.com/riccardotacconi/deusvm/internal/host"
 	"github.com/riccardotacconi/deusvm/internal/kvm"
 	"github.com/riccardotacconi/deusvm/internal/logging"
+	"github.com/riccardotacconi/deusvm/internal/network"
 	"github.com/riccardotacconi/deusvm/internal/storage"
 	deusvmproto "github.com/riccardotacconi/deusvm/pkg/proto/gen/github.com/riccardotacconi/deusvm/pkg/proto"
 	"google.golang.org/grpc"
@@ -31,6 +35,12 @@ func main() {
 		logger.Fatal("failed to load config", logging.FieldError(err))
 	}
 
+	if cfg.Network.Bridge != "" {
+		if err := host.CheckBridge(cfg.Network.Bridge, cfg.Network.BridgeType); err != nil && !errors.Is(err, host.ErrUnsupported) {
+			logger.Warn("network.bridge is not usable, VMs with default NICs will fail to start", logging.FieldError(err))
+		}
+	}
+
 	var manager kvm.Manager
 	// For now, use in-memory manager unless LIBVIRT_ADDR is set or config.Libvirt.Address present
 	libvirtAddr := cfg.Libvirt.Address
@@ -38,7 +48,7 @@ func main() {
 		libvirtAddr = env
 	}
 	if libvirtAddr != "" {
-		lm, lerr := kvm.NewLibvirtManager(ctx, libvirtAddr, cfg.Network.Bridge)
+		lm, lerr := kvm.NewLibvirtManager(ctx, libvirtAddr, cfg.Network.Bridge, cfg.Network.BridgeType)
 		if lerr != nil {
 			logger.Warn("failed to connect to libvirt, falling back to in-memory manager", logging.FieldError(lerr))
 			manager = kvm.NewInMemoryManager()
@@ -49,12 +59,94 @@ func main() {
 		manager = kvm.NewInMemoryManager()
 	}
 
-	store, err := storage.NewLocalManager(cfg.Storage.ImagesPath)
+	store, err := storage.NewLocalManager(cfg.Storage.ImagesPath, cfg.Storage.StatePath)
 	if err != nil {
 		logger.Fatal("failed to init storage", logging.FieldError(err))
 	}
+	for _, pc := range cfg.Storage.Pools {
+		backend, err := storage.NewBackend(pc, storage.ExecRunner{})
+		if err != nil {
+			logger.Fatal("failed to init storage pool", logging.FieldError(err))
+		}
+		store.AddPool(pc.Name, backend, pc.Name == cfg.Storage.DefaultPool)
+		store.SetPoolLimits(pc.Name, storage.Limits{HighWaterMark: *pc.HighWaterMark, MaxOvercommit: *pc.MaxOvercommit})
+	}
+	store.SetImageLimits(storage.Limits{HighWaterMark: cfg.Storage.HighWaterMark})
+	store.RegisterSource("file", storage.NewFileSource(cfg.Storage.ImportDirs))
+	store.RegisterSource("oci", storage.NewOCISource(cfg.Storage.OCI, http.DefaultClient))
+	s3Source, err := storage.NewS3Source(cfg.Storage.S3)
+	if err != nil {
+		logger.Fatal("failed to init s3 image source", logging.FieldError(err))
+	}
+	store.RegisterSource("s3", s3Source)
+	// the in-memory manager knows no VMs, so every reference would be dropped
+	// and everything would look orphaned to garbage collection
+	_, inMemory := manager.(*kvm.InMemoryManager)
+	if !inMemory {
+		if err := api.SyncImageRefs(ctx, manager, store); err != nil {
+			logger.Warn("failed to record images used by existing VMs", logging.FieldError(err))
+		}
+	}
+	gc := cfg.Storage.GC
+	if err := store.SetGCPolicy(storage.GCPolicy{QuarantineDir: gc.QuarantinePath, MinAge: gc.MinAge, Retention: gc.Retention}); err != nil {
+		logger.Fatal("failed to init garbage collection", logging.FieldError(err))
+	}
+	if gc.Interval > 0 {
+		if inMemory {
+			logger.Warn("scheduled garbage collection disabled without libvirt")
+		} else {
+			go api.RunGC(ctx, logger, manager, store, gc.Interval)
+		}
+	}
+
+	backups, err := backup.NewService(cfg.Backup, manager, store, storage.ExecRunner{})
+	if err != nil {
+		logger.Fatal("failed to init backups", logging.FieldError(err))
+	}
+	backups.Schedule(ctx, logger)
+
+	// the in-memory manager knows no VMs, so every address would be released
+	// and there are no tap devices to filter
+	var applier network.Applier = network.NFT{}
+	if inMemory {
+		applier = nil
+	}
+	networks, err := network.NewService(manager, cfg.Storage.StatePath, applier, []string{cfg.API.ListenAddress, cfg.GRPC.ListenAddress})
+	if err != nil {
+		logger.Fatal("failed to init networks", logging.FieldError(err))
+	}
+	if !inMemory {
+		if err := networks.Prune(ctx); err != nil {
+			logger.Warn("failed to release addresses of removed VMs", logging.FieldError(err))
+		}
+		if cfg.Network.FirewallInterval > 0 {
+			go networks.Enforce(ctx, logger, cfg.Network.FirewallInterval)
+		}
+	}
+	if cfg.Network.DNS.Enabled {
+		if inMemory {
+			logger.Warn("DNS server disabled without libvirt")
+		} else {
+			dns, err := network.NewDNSServer(cfg.Network.DNS, manager, networks)
+			if err != nil {
+				logger.Fatal("failed to init DNS server", logging.FieldError(err))
+			}
+			go dns.Serve(ctx, logger)
+		}
+	}
+	if cfg.Network.PXE.Enabled {
+		if inMemory {
+			logger.Warn("boot server disabled without libvirt")
+		} else {
+			boot, err := network.NewBootServer(cfg.Network.PXE, manager, networks)
+			if err != nil {
+				logger.Fatal("failed to init boot server", logging.FieldError(err))
+			}
+			go boot.Serve(ctx, logger)
+		}
+	}
 
-	apiServer := api.NewServer(logger, manager, store, cfg)
+	apiServer := api.NewServer(logger, manager, store, backups, networks, cfg)
 
 	server := &http.Server{
 		Addr:              cfg.API.ListenAddress,
@@ -81,8 +173,16 @@ func main() {
 			opts = append(opts, grpc.Creds(creds))
 		}
 		grpcServer := grpc.NewServer(opts...)
-		deusvmproto.RegisterVMServiceServer(grpcServer, api.NewVMServiceServer(manager))
-		deusvmproto.RegisterImageServiceServer(grpcServer, api.NewImageServiceServer(store))
+		deusvmproto.RegisterVMServiceServer(grpcServer, api.NewVMServiceServer(manager, store, backups, networks))
+		deusvmproto.RegisterImageServiceServer(grpcServer, api.NewImageServiceServer(manager, store))
+		deusvmproto.RegisterVolumeServiceServer(grpcServer, api.NewVolumeServiceServer(manager, store))
+		deusvmproto.RegisterStorageServiceServer(grpcServer, api.NewStorageServiceServer(manager, store))
+		deusvmproto.RegisterBackupServiceServer(grpcServer, api.NewBackupServiceServer(manager, store, backups, networks))
+		deusvmproto.RegisterNetworkServiceServer(grpcServer, api.NewNetworkServiceServer(manager, store, networks))
+		deusvmproto.RegisterSecurityGroupServiceServer(grpcServer, api.NewSecurityGroupServiceServer(manager, store, networks))
+		deusvmproto.RegisterPortForwardServiceServer(grpcServer, api.NewPortForwardServiceServer(manager, store, networks))
+		deusvmproto.RegisterBootServiceServer(grpcServer, api.NewBootServiceServer(manager, store, networks))
+		deusvmproto.RegisterHostServiceServer(grpcServer, api.NewHostServiceServer())
 		ln, err := netListen("tcp", lisAddr)
 		if err != nil {
 			logger.Fatal("gRPC listen error", logging.FieldError(err))
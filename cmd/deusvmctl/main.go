@@ -2,10 +2,17 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	deusvmproto "github.com/riccardotacconi/deusvm/pkg/proto/gen/github.com/riccardotacconi/deusvm/pkg/proto"
@@ -23,6 +30,24 @@ func main() {
 		vmCmd(os.Args[2:])
 	case "image":
 		imageCmd(os.Args[2:])
+	case "volume":
+		volumeCmd(os.Args[2:])
+	case "storage":
+		storageCmd(os.Args[2:])
+	case "job":
+		jobCmd(os.Args[2:])
+	case "backup":
+		backupCmd(os.Args[2:])
+	case "network":
+		networkCmd(os.Args[2:])
+	case "security-group", "sg":
+		securityGroupCmd(os.Args[2:])
+	case "forward":
+		forwardCmd(os.Args[2:])
+	case "boot-template":
+		bootTemplateCmd(os.Args[2:])
+	case "host":
+		hostCmd(os.Args[2:])
 	case "help", "-h", "--help":
 		usage()
 	default:
@@ -42,6 +67,22 @@ func dials(endpoint string) (*grpc.ClientConn, deusvmproto.VMServiceClient, deus
 	return conn, deusvmproto.NewVMServiceClient(conn), deusvmproto.NewImageServiceClient(conn), nil
 }
 
+func dialVolumes(endpoint string) (*grpc.ClientConn, deusvmproto.VolumeServiceClient, error) {
+	conn, _, _, err := dials(endpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, deusvmproto.NewVolumeServiceClient(conn), nil
+}
+
+func dialStorage(endpoint string) (*grpc.ClientConn, deusvmproto.StorageServiceClient, error) {
+	conn, _, _, err := dials(endpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, deusvmproto.NewStorageServiceClient(conn), nil
+}
+
 func vmCmd(args []string) {
 	if len(args) == 0 {
 		vmUsage()
@@ -50,19 +91,72 @@ func vmCmd(args []string) {
 	switch args[0] {
 	case "create":
 		fs := flag.NewFlagSet("vm create", flag.ExitOnError)
-		var endpoint, name, image, memory, disk string
-		var cpu int
+		var endpoint, name, image, memory, disk, pool, iso, boot, networks, bridges, groups, bridgeType, trunk, inbound, outbound, bootTemplate string
+		var cpu, vlan int
+		var netboot bool
 		fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
 		fs.StringVar(&name, "name", "", "VM name")
 		fs.StringVar(&image, "image", "", "base image path or name")
 		fs.IntVar(&cpu, "cpu", 1, "vCPU count")
 		fs.StringVar(&memory, "memory", "1GB", "memory (e.g. 4GB)")
 		fs.StringVar(&disk, "disk", "10GB", "disk size (e.g. 20GB)")
+		fs.StringVar(&pool, "pool", "", "storage pool for the root disk (default pool if empty)")
+		fs.StringVar(&iso, "iso", "", "ISO image to install from; the root disk starts blank")
+		fs.StringVar(&boot, "boot", "", "boot order, comma separated (hd,cdrom,network)")
+		fs.BoolVar(&netboot, "netboot", false, "boot from the daemon's boot server before the disk; the root disk starts blank without --image")
+		fs.StringVar(&bootTemplate, "boot-template", "", "boot template to serve the VM (the default template if empty)")
+		fs.StringVar(&networks, "network", "", "managed networks to add a NIC on, comma separated, each optionally with fixed addresses (lab=10.10.0.5=fd00:10::5)")
+		fs.StringVar(&bridges, "bridge", "", "host bridges to add a NIC on, comma separated (the configured bridge without --network)")
+		fs.StringVar(&bridgeType, "bridge-type", "", "type of the --bridge bridges: linux or openvswitch (network.bridge_type for the configured bridge)")
+		fs.IntVar(&vlan, "vlan", 0, "access VLAN of the --bridge NICs (openvswitch only)")
+		fs.StringVar(&trunk, "trunk", "", "VLANs trunked to the --bridge NICs, comma separated, ranges allowed (openvswitch only)")
+		fs.StringVar(&groups, "security-groups", "", "security groups of every NIC given with --network or --bridge, comma separated")
+		fs.StringVar(&inbound, "inbound", "", "traffic limit to every NIC given with --network or --bridge: average[,peak[,burst]] in KiB/s and KiB")
+		fs.StringVar(&outbound, "outbound", "", "traffic limit from every NIC given with --network or --bridge, like --inbound")
 		_ = fs.Parse(args[1:])
-		if name == "" || image == "" {
-			fmt.Fprintln(os.Stderr, "name and image required")
+		if name == "" || (image == "" && iso == "" && !netboot) {
+			fmt.Fprintln(os.Stderr, "name and image, iso or netboot required")
 			os.Exit(1)
 		}
+		var bootMode string
+		if netboot {
+			bootMode = "network"
+		}
+		var bootOrder []string
+		if boot != "" {
+			bootOrder = strings.Split(boot, ",")
+		}
+		var nics []*deusvmproto.NIC
+		for _, n := range splitList(networks) {
+			parts := strings.Split(n, "=")
+			nic := &deusvmproto.NIC{Network: parts[0], SecurityGroups: splitList(groups)}
+			for _, ip := range parts[1:] {
+				if strings.Contains(ip, ":") {
+					nic.Ipv6 = ip
+				} else {
+					nic.Ipv4 = ip
+				}
+			}
+			nics = append(nics, nic)
+		}
+		trunkIDs, err := vlanList(trunk)
+		if err != nil {
+			fatal(err)
+		}
+		for _, b := range splitList(bridges) {
+			nics = append(nics, &deusvmproto.NIC{Bridge: b, BridgeType: bridgeType, Vlan: int32(vlan), Trunk: trunkIDs, SecurityGroups: splitList(groups)})
+		}
+		in, err := bandwidthFlag(inbound)
+		if err != nil {
+			fatal(err)
+		}
+		out, err := bandwidthFlag(outbound)
+		if err != nil {
+			fatal(err)
+		}
+		for _, nic := range nics {
+			nic.Inbound, nic.Outbound = in, out
+		}
 		memBytes, err := parseSize(memory)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, "invalid memory")
@@ -78,9 +172,11 @@ func vmCmd(args []string) {
 			fatal(err)
 		}
 		defer conn.Close()
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		// cloning into a block device pool copies the whole image
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
 		defer cancel()
-		vm, err := vmc.Create(ctx, &deusvmproto.CreateVMRequest{Name: name, Image: image, Cpu: int32(cpu), MemoryBytes: memBytes, DiskBytes: diskBytes})
+		vm, err := vmc.Create(ctx, &deusvmproto.CreateVMRequest{Name: name, Image: image, Cpu: int32(cpu), MemoryBytes: memBytes, DiskBytes: diskBytes, Pool: pool, Iso: iso, BootOrder: bootOrder, Nics: nics,
+			Boot: bootMode, BootTemplate: bootTemplate})
 		if err != nil {
 			fatal(err)
 		}
@@ -126,11 +222,268 @@ func vmCmd(args []string) {
 			fatal(err)
 		}
 		fmt.Printf("%s\t%s\t%d CPU\t%d MB\t%s\n", v.GetId(), v.GetName(), v.GetCpu(), v.GetMemoryBytes()/1024/1024, v.GetStatus())
+		if v.GetCdrom() != "" {
+			fmt.Printf("cdrom\t%s\n", v.GetCdrom())
+		}
+		if v.GetBootTemplate() != "" {
+			fmt.Printf("boot template\t%s\n", v.GetBootTemplate())
+		}
+		for _, nic := range v.GetNics() {
+			link := ""
+			if nic.GetLinkState() == "down" {
+				link = "\tlink down"
+			}
+			link += bandwidthString("in", nic.GetInbound()) + bandwidthString("out", nic.GetOutbound())
+			if nic.GetNetwork() != "" {
+				addrs := ""
+				for _, ip := range []string{nic.GetIpv4(), nic.GetIpv6()} {
+					if ip != "" {
+						addrs += "\t" + ip
+					}
+				}
+				if len(nic.GetSecurityGroups()) > 0 {
+					addrs += "\tgroups " + strings.Join(nic.GetSecurityGroups(), ",")
+				}
+				fmt.Printf("nic\t%s\tnetwork %s%s%s\n", nic.GetMac(), nic.GetNetwork(), addrs, link)
+			} else {
+				tags := ""
+				switch {
+				case nic.GetVlan() != 0:
+					tags = fmt.Sprintf("\tvlan %d", nic.GetVlan())
+				case len(nic.GetTrunk()) > 0:
+					tags = fmt.Sprintf("\ttrunk %v", nic.GetTrunk())
+				}
+				fmt.Printf("nic\t%s\tbridge %s (%s)%s%s\n", nic.GetMac(), nic.GetBridge(), nic.GetBridgeType(), tags, link)
+			}
+		}
+	case "security-groups":
+		fs := flag.NewFlagSet("vm security-groups", flag.ExitOnError)
+		var endpoint, id, mac, groups string
+		fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+		fs.StringVar(&id, "id", "", "VM id or name")
+		fs.StringVar(&mac, "mac", "", "MAC address of the NIC")
+		fs.StringVar(&groups, "groups", "", "security groups, comma separated (none leaves the NIC unfiltered)")
+		_ = fs.Parse(args[1:])
+		if id == "" || mac == "" {
+			fmt.Fprintln(os.Stderr, "id and mac required")
+			os.Exit(1)
+		}
+		conn, _, _, err := dials(endpoint)
+		if err != nil {
+			fatal(err)
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		sgc := deusvmproto.NewSecurityGroupServiceClient(conn)
+		if _, err := sgc.SetNICGroups(ctx, &deusvmproto.SetNICSecurityGroupsRequest{VmId: id, Mac: mac, Groups: splitList(groups)}); err != nil {
+			fatal(err)
+		}
+		fmt.Println("ok")
+	case "boot-template":
+		fs := flag.NewFlagSet("vm boot-template", flag.ExitOnError)
+		var endpoint, id, template string
+		fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+		fs.StringVar(&id, "id", "", "VM id or name")
+		fs.StringVar(&template, "template", "", "boot template (none goes back to the default template)")
+		_ = fs.Parse(args[1:])
+		if id == "" {
+			fmt.Fprintln(os.Stderr, "id required")
+			os.Exit(1)
+		}
+		conn, _, _, err := dials(endpoint)
+		if err != nil {
+			fatal(err)
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		bc := deusvmproto.NewBootServiceClient(conn)
+		if _, err := bc.SetVMTemplate(ctx, &deusvmproto.SetVMBootTemplateRequest{Id: id, Template: template}); err != nil {
+			fatal(err)
+		}
+		fmt.Println("ok")
+	case "attach-nic", "update-nic":
+		fs := flag.NewFlagSet("vm "+args[0], flag.ExitOnError)
+		var endpoint, id, mac, netName, bridge, bridgeType, trunk, ipv4, ipv6, link, groups, inbound, outbound string
+		var vlan int
+		fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+		fs.StringVar(&id, "id", "", "VM id or name")
+		fs.StringVar(&mac, "mac", "", "MAC address of the NIC (generated on attach if empty)")
+		fs.StringVar(&netName, "network", "", "managed network to connect the NIC to")
+		fs.StringVar(&bridge, "bridge", "", "host bridge to connect the NIC to")
+		fs.StringVar(&bridgeType, "bridge-type", "", "type of the bridge: linux or openvswitch")
+		fs.IntVar(&vlan, "vlan", 0, "access VLAN (openvswitch only)")
+		fs.StringVar(&trunk, "trunk", "", "VLANs trunked to the NIC, comma separated, ranges allowed (openvswitch only)")
+		fs.StringVar(&ipv4, "ipv4", "", "fixed IPv4 address on the network")
+		fs.StringVar(&ipv6, "ipv6", "", "fixed IPv6 address on the network")
+		fs.StringVar(&link, "link", "", "link state: up or down")
+		fs.StringVar(&inbound, "inbound", "", "traffic limit to the NIC: average[,peak[,burst]] in KiB/s and KiB (0 lifts it)")
+		fs.StringVar(&outbound, "outbound", "", "traffic limit from the NIC, like --inbound")
+		if args[0] == "attach-nic" {
+			fs.StringVar(&groups, "security-groups", "", "security groups of the NIC, comma separated")
+		}
+		_ = fs.Parse(args[1:])
+		if id == "" || (args[0] == "update-nic" && mac == "") {
+			fmt.Fprintln(os.Stderr, "id required, and mac to update a nic")
+			os.Exit(1)
+		}
+		trunkIDs, err := vlanList(trunk)
+		if err != nil {
+			fatal(err)
+		}
+		nic := &deusvmproto.NIC{
+			Network: netName, Bridge: bridge, BridgeType: bridgeType, Vlan: int32(vlan), Trunk: trunkIDs,
+			Mac: mac, Ipv4: ipv4, Ipv6: ipv6, LinkState: link, SecurityGroups: splitList(groups),
+		}
+		if nic.Inbound, err = bandwidthFlag(inbound); err != nil {
+			fatal(err)
+		}
+		if nic.Outbound, err = bandwidthFlag(outbound); err != nil {
+			fatal(err)
+		}
+		conn, vmc, _, err := dials(endpoint)
+		if err != nil {
+			fatal(err)
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		var v *deusvmproto.VM
+		if args[0] == "attach-nic" {
+			v, err = vmc.AttachInterface(ctx, &deusvmproto.AttachInterfaceRequest{Id: id, Nic: nic})
+		} else {
+			v, err = vmc.UpdateInterface(ctx, &deusvmproto.UpdateInterfaceRequest{Id: id, Nic: nic})
+		}
+		if err != nil {
+			fatal(err)
+		}
+		// a NIC attached without a MAC address is the last one
+		nics := v.GetNics()
+		for i, n := range nics {
+			if !strings.EqualFold(n.GetMac(), mac) && (mac != "" || i != len(nics)-1) {
+				continue
+			}
+			addrs := ""
+			for _, ip := range []string{n.GetIpv4(), n.GetIpv6()} {
+				if ip != "" {
+					addrs += "\t" + ip
+				}
+			}
+			fmt.Printf("nic\t%s\t%s%s\tlink %s%s%s%s\n", n.GetMac(), n.GetNetwork(), n.GetBridge(), n.GetLinkState(), addrs,
+				bandwidthString("in", n.GetInbound()), bandwidthString("out", n.GetOutbound()))
+		}
+	case "detach-nic":
+		fs := flag.NewFlagSet("vm detach-nic", flag.ExitOnError)
+		var endpoint, id, mac string
+		fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+		fs.StringVar(&id, "id", "", "VM id or name")
+		fs.StringVar(&mac, "mac", "", "MAC address of the NIC")
+		_ = fs.Parse(args[1:])
+		if id == "" || mac == "" {
+			fmt.Fprintln(os.Stderr, "id and mac required")
+			os.Exit(1)
+		}
+		conn, vmc, _, err := dials(endpoint)
+		if err != nil {
+			fatal(err)
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if _, err := vmc.DetachInterface(ctx, &deusvmproto.DetachInterfaceRequest{Id: id, Mac: mac}); err != nil {
+			fatal(err)
+		}
+		fmt.Println("detached")
 	case "delete":
 		vmAction(args[1:], "vm delete", func(ctx context.Context, vmc deusvmproto.VMServiceClient, id string) error {
 			_, err := vmc.Delete(ctx, &deusvmproto.VMIDRequest{Id: id})
 			return err
 		})
+	case "insert-media":
+		fs := flag.NewFlagSet("vm insert-media", flag.ExitOnError)
+		var endpoint, id, image string
+		fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+		fs.StringVar(&id, "id", "", "VM id or name")
+		fs.StringVar(&image, "image", "", "ISO image name")
+		_ = fs.Parse(args[1:])
+		if id == "" || image == "" {
+			fmt.Fprintln(os.Stderr, "id and image required")
+			os.Exit(1)
+		}
+		conn, vmc, _, err := dials(endpoint)
+		if err != nil {
+			fatal(err)
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if _, err := vmc.InsertMedia(ctx, &deusvmproto.InsertMediaRequest{Id: id, Image: image}); err != nil {
+			fatal(err)
+		}
+		fmt.Println("ok")
+	case "export":
+		fs := flag.NewFlagSet("vm export", flag.ExitOnError)
+		var endpoint, id, out string
+		fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+		fs.StringVar(&id, "id", "", "VM id or name")
+		fs.StringVar(&out, "o", "", "archive file to write (<name>.tar.zst if empty, - for stdout)")
+		_ = fs.Parse(args[1:])
+		if id == "" {
+			fmt.Fprintln(os.Stderr, "id required")
+			os.Exit(1)
+		}
+		if out == "" {
+			out = id + ".tar.zst"
+		}
+		conn, vmc, _, err := dials(endpoint)
+		if err != nil {
+			fatal(err)
+		}
+		defer conn.Close()
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		if err := exportVM(ctx, vmc, id, out); err != nil {
+			fatal(err)
+		}
+	case "import":
+		fs := flag.NewFlagSet("vm import", flag.ExitOnError)
+		var endpoint, file, name, pool string
+		fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+		fs.StringVar(&file, "file", "", "archive written by vm export")
+		fs.StringVar(&name, "name", "", "name of the new VM (the archived name if empty)")
+		fs.StringVar(&pool, "pool", "", "storage pool for its disks (default pool if empty)")
+		_ = fs.Parse(args[1:])
+		if file == "" {
+			fmt.Fprintln(os.Stderr, "file required")
+			os.Exit(1)
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			fatal(err)
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			fatal(err)
+		}
+		conn, vmc, _, err := dials(endpoint)
+		if err != nil {
+			fatal(err)
+		}
+		defer conn.Close()
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		v, err := importVM(ctx, vmc, &deusvmproto.ImportVMInfo{Name: name, Pool: pool}, f, info.Size())
+		if err != nil {
+			fatal(err)
+		}
+		fmt.Println(v.GetId())
+	case "eject-media":
+		vmAction(args[1:], "vm eject-media", func(ctx context.Context, vmc deusvmproto.VMServiceClient, id string) error {
+			_, err := vmc.EjectMedia(ctx, &deusvmproto.VMIDRequest{Id: id})
+			return err
+		})
 	case "start":
 		vmAction(args[1:], "vm start", func(ctx context.Context, vmc deusvmproto.VMServiceClient, id string) error {
 			_, err := vmc.Start(ctx, &deusvmproto.VMIDRequest{Id: id})
@@ -191,12 +544,111 @@ func imageCmd(args []string) {
 			fatal(err)
 		}
 		defer conn.Close()
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		// large downloads can take a long time; stop on Ctrl-C instead of a deadline
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		stream, err := imgc.CreateStream(ctx, &deusvmproto.CreateImageRequest{Name: name, Source: source})
+		if err != nil {
+			fatal(err)
+		}
+		var bar progressBar
+		for {
+			msg, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				bar.finish()
+				fatal(err)
+			}
+			bar.update(msg.GetBytesDone(), msg.GetBytesTotal())
+			if im := msg.GetImage(); im != nil {
+				bar.finish()
+				fmt.Printf("%s\t%s\t%d\t%s\n", im.GetName(), im.GetFormat(), im.GetSizeBytes(), im.GetSha256())
+			}
+		}
+	case "upload":
+		fs := flag.NewFlagSet("image upload", flag.ExitOnError)
+		var endpoint, name, file string
+		fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+		fs.StringVar(&name, "name", "", "image name (defaults to the file name)")
+		fs.StringVar(&file, "file", "", "local image file")
+		_ = fs.Parse(args[1:])
+		if file == "" {
+			fmt.Fprintln(os.Stderr, "file required")
+			os.Exit(1)
+		}
+		if name == "" {
+			name = filepath.Base(file)
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			fatal(err)
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			fatal(err)
+		}
+		conn, _, imgc, err := dials(endpoint)
+		if err != nil {
+			fatal(err)
+		}
+		defer conn.Close()
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		im, err := uploadImage(ctx, imgc, name, f, info.Size())
+		if err != nil {
+			fatal(err)
+		}
+		fmt.Printf("%s\t%s\t%d\t%s\n", im.GetName(), im.GetFormat(), im.GetSizeBytes(), im.GetSha256())
+	case "tag", "alias":
+		fs := flag.NewFlagSet("image "+args[0], flag.ExitOnError)
+		var endpoint, source, name string
+		fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+		fs.StringVar(&source, "source", "", "existing image name")
+		fs.StringVar(&name, "name", "", "additional name")
+		_ = fs.Parse(args[1:])
+		if source == "" || name == "" {
+			fmt.Fprintln(os.Stderr, "source and name required")
+			os.Exit(1)
+		}
+		conn, _, imgc, err := dials(endpoint)
+		if err != nil {
+			fatal(err)
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
-		if _, err := imgc.Create(ctx, &deusvmproto.CreateImageRequest{Name: name, Source: source}); err != nil {
+		if _, err := imgc.Tag(ctx, &deusvmproto.TagImageRequest{Source: source, Name: name}); err != nil {
 			fatal(err)
 		}
 		fmt.Println("ok")
+	case "capture":
+		fs := flag.NewFlagSet("image capture", flag.ExitOnError)
+		var endpoint, vm, name string
+		var sparsify bool
+		fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+		fs.StringVar(&vm, "vm", "", "VM id or name")
+		fs.StringVar(&name, "name", "", "name of the new image")
+		fs.BoolVar(&sparsify, "sparsify", false, "drop blocks unused by the guest filesystems")
+		_ = fs.Parse(args[1:])
+		if vm == "" || name == "" {
+			fmt.Fprintln(os.Stderr, "vm and name required")
+			os.Exit(1)
+		}
+		conn, _, imgc, err := dials(endpoint)
+		if err != nil {
+			fatal(err)
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Minute)
+		defer cancel()
+		im, err := imgc.CaptureFromVM(ctx, &deusvmproto.CaptureImageRequest{VmId: vm, Name: name, Sparsify: sparsify})
+		if err != nil {
+			fatal(err)
+		}
+		fmt.Printf("%s\t%s\t%d\t%s\n", im.GetName(), im.GetFormat(), im.GetSizeBytes(), im.GetSha256())
 	case "list":
 		fs := flag.NewFlagSet("image list", flag.ExitOnError)
 		var endpoint string
@@ -214,13 +666,19 @@ func imageCmd(args []string) {
 			fatal(err)
 		}
 		for _, im := range resp.GetImages() {
-			fmt.Printf("%s\t%s\t%d\n", im.GetName(), im.GetFormat(), im.GetSizeBytes())
+			digest := im.GetSha256()
+			if len(digest) > 12 {
+				digest = digest[:12]
+			}
+			fmt.Printf("%s\t%s\t%d\t%s\t%d\n", im.GetName(), im.GetFormat(), im.GetSizeBytes(), digest, im.GetUsedBy())
 		}
 	case "delete":
 		fs := flag.NewFlagSet("image delete", flag.ExitOnError)
 		var endpoint, name string
+		var force bool
 		fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
 		fs.StringVar(&name, "name", "", "image name")
+		fs.BoolVar(&force, "force", false, "delete even if VMs or disks use the image")
 		_ = fs.Parse(args[1:])
 		if name == "" {
 			fmt.Fprintln(os.Stderr, "name required")
@@ -233,7 +691,7 @@ func imageCmd(args []string) {
 		defer conn.Close()
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
-		if _, err := imgc.Delete(ctx, &deusvmproto.ImageNameRequest{Name: name}); err != nil {
+		if _, err := imgc.Delete(ctx, &deusvmproto.ImageNameRequest{Name: name, Force: force}); err != nil {
 			fatal(err)
 		}
 		fmt.Println("ok")
@@ -243,27 +701,1116 @@ func imageCmd(args []string) {
 	}
 }
 
-func usage() {
-	fmt.Println("deusvmctl <vm|image> [subcommand] [flags]")
-	fmt.Println("Use --help under each subcommand")
+func volumeCmd(args []string) {
+	if len(args) == 0 {
+		volumeUsage()
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("volume "+args[0], flag.ExitOnError)
+	var endpoint, name, pool, size, format, image, vm, source string
+	fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+	switch args[0] {
+	case "create":
+		fs.StringVar(&name, "name", "", "volume name")
+		fs.StringVar(&pool, "pool", "", "storage pool (default pool if empty)")
+		fs.StringVar(&size, "size", "", "size (e.g. 20GB); optional with --image")
+		fs.StringVar(&format, "format", "", "qcow2 or raw (directory pools)")
+		fs.StringVar(&image, "image", "", "image to start from")
+	case "get", "delete", "detach":
+		fs.StringVar(&name, "name", "", "volume name")
+	case "attach":
+		fs.StringVar(&name, "name", "", "volume name")
+		fs.StringVar(&vm, "vm", "", "VM id or name")
+	case "resize":
+		fs.StringVar(&name, "name", "", "volume name")
+		fs.StringVar(&size, "size", "", "new size (e.g. 40GB)")
+	case "clone":
+		fs.StringVar(&source, "source", "", "volume to copy")
+		fs.StringVar(&name, "name", "", "name of the copy")
+		fs.StringVar(&pool, "pool", "", "storage pool (source pool if empty)")
+		fs.StringVar(&size, "size", "", "optional larger size for the copy")
+	case "list":
+	default:
+		volumeUsage()
+		os.Exit(1)
+	}
+	_ = fs.Parse(args[1:])
+	if args[0] != "list" && args[0] != "allocations" && name == "" {
+		fmt.Fprintln(os.Stderr, "name required")
+		os.Exit(1)
+	}
+	var sizeBytes int64
+	if size != "" {
+		var err error
+		if sizeBytes, err = parseSize(size); err != nil {
+			fmt.Fprintln(os.Stderr, "invalid size")
+			os.Exit(1)
+		}
+	}
+	conn, volc, err := dialVolumes(endpoint)
+	if err != nil {
+		fatal(err)
+	}
+	defer conn.Close()
+	// creating, cloning or growing volumes on block device pools copies data
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	var vol *deusvmproto.Volume
+	switch args[0] {
+	case "create":
+		vol, err = volc.Create(ctx, &deusvmproto.CreateVolumeRequest{Name: name, Pool: pool, SizeBytes: sizeBytes, Format: format, Image: image})
+	case "get":
+		vol, err = volc.Get(ctx, &deusvmproto.VolumeNameRequest{Name: name})
+	case "delete":
+		if _, err := volc.Delete(ctx, &deusvmproto.VolumeNameRequest{Name: name}); err != nil {
+			fatal(err)
+		}
+		fmt.Println("ok")
+		return
+	case "attach":
+		if vm == "" {
+			fmt.Fprintln(os.Stderr, "vm required")
+			os.Exit(1)
+		}
+		vol, err = volc.Attach(ctx, &deusvmproto.AttachVolumeRequest{Name: name, VmId: vm})
+	case "detach":
+		vol, err = volc.Detach(ctx, &deusvmproto.VolumeNameRequest{Name: name})
+	case "resize":
+		if sizeBytes == 0 {
+			fmt.Fprintln(os.Stderr, "size required")
+			os.Exit(1)
+		}
+		vol, err = volc.Resize(ctx, &deusvmproto.ResizeVolumeRequest{Name: name, SizeBytes: sizeBytes})
+	case "clone":
+		if source == "" {
+			fmt.Fprintln(os.Stderr, "source required")
+			os.Exit(1)
+		}
+		vol, err = volc.Clone(ctx, &deusvmproto.CloneVolumeRequest{Source: source, Name: name, Pool: pool, SizeBytes: sizeBytes})
+	case "list":
+		resp, err := volc.List(ctx, &deusvmproto.Empty{})
+		if err != nil {
+			fatal(err)
+		}
+		for _, v := range resp.GetVolumes() {
+			printVolume(v)
+		}
+		return
+	}
+	if err != nil {
+		fatal(err)
+	}
+	printVolume(vol)
 }
 
-func vmUsage()    { fmt.Println("vm subcommands: create|list|get|delete|start|stop") }
-func imageUsage() { fmt.Println("image subcommands: create|list|delete") }
+func printVolume(v *deusvmproto.Volume) {
+	fmt.Printf("%s\t%s\t%s\t%d\t%s\n", v.GetName(), v.GetPool(), v.GetFormat(), v.GetSizeBytes(), v.GetVmId())
+}
 
-func parseSize(s string) (int64, error) {
-	s = strings.TrimSpace(s)
-	if len(s) < 3 {
-		return 0, fmt.Errorf("invalid size")
+func storageCmd(args []string) {
+	if len(args) == 0 {
+		storageUsage()
+		os.Exit(1)
 	}
-	unit := strings.ToUpper(s[len(s)-2:])
-	numStr := s[:len(s)-2]
-	var v int64
-	for i := 0; i < len(numStr); i++ {
-		if numStr[i] < '0' || numStr[i] > '9' {
-			return 0, fmt.Errorf("invalid size")
-		}
-		v = v*10 + int64(numStr[i]-'0')
+	switch args[0] {
+	case "convert":
+		convertCmd(args[1:])
+	case "usage":
+		usageCmd(args[1:])
+	case "gc":
+		gcCmd(args[1:])
+	default:
+		storageUsage()
+		os.Exit(1)
+	}
+}
+
+func usageCmd(args []string) {
+	fs := flag.NewFlagSet("storage usage", flag.ExitOnError)
+	var endpoint string
+	fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+	_ = fs.Parse(args)
+	conn, stc, err := dialStorage(endpoint)
+	if err != nil {
+		fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	u, err := stc.Usage(ctx, &deusvmproto.Empty{})
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Println("POOL\tTYPE\tUSED\tFREE\tCAPACITY\tPROVISIONED\tOVERCOMMIT")
+	for _, p := range append([]*deusvmproto.PoolUsage{u.GetImageStore()}, u.GetPools()...) {
+		if p.GetError() != "" {
+			fmt.Printf("%s\t%s\terror: %s\n", p.GetName(), p.GetType(), p.GetError())
+			continue
+		}
+		fmt.Printf("%s\t%s\t%s\t%s\t%s\t%s\t%.2fx\n", p.GetName(), p.GetType(), humanBytes(p.GetUsedBytes()), humanBytes(p.GetFreeBytes()),
+			humanBytes(p.GetCapacityBytes()), humanBytes(p.GetProvisionedBytes()), p.GetOvercommit())
+	}
+	fmt.Println("\nIMAGE\tALLOCATED\tVIRTUAL")
+	for _, im := range u.GetImages() {
+		fmt.Printf("%s\t%s\t%s\n", im.GetName(), humanBytes(im.GetAllocatedBytes()), humanBytes(im.GetVirtualBytes()))
+	}
+	fmt.Println("\nVM\tALLOCATED\tVIRTUAL\tVOLUMES")
+	for _, vm := range u.GetVms() {
+		name := vm.GetName()
+		if name == "" {
+			name = vm.GetVmId()
+		}
+		fmt.Printf("%s\t%s\t%s\t%s\n", name, humanBytes(vm.GetAllocatedBytes()), humanBytes(vm.GetVirtualBytes()), strings.Join(vm.GetVolumes(), ","))
+	}
+}
+
+func gcCmd(args []string) {
+	fs := flag.NewFlagSet("storage gc", flag.ExitOnError)
+	var endpoint string
+	var dryRun bool
+	var minAge time.Duration
+	fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+	fs.BoolVar(&dryRun, "dry-run", false, "only report what would be collected")
+	fs.DurationVar(&minAge, "min-age", 0, "only collect files older than this (configured minimum age if 0)")
+	_ = fs.Parse(args)
+	conn, stc, err := dialStorage(endpoint)
+	if err != nil {
+		fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	r, err := stc.GarbageCollect(ctx, &deusvmproto.GCRequest{DryRun: dryRun, MinAgeSeconds: int64(minAge / time.Second)})
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Println("KIND\tSIZE\tPATH\tREASON\tRESULT")
+	for _, it := range r.GetItems() {
+		where := it.GetPath()
+		if where == "" {
+			where = it.GetName()
+		}
+		result := it.GetQuarantine()
+		switch {
+		case it.GetError() != "":
+			result = "error: " + it.GetError()
+		case it.GetReportOnly():
+			result = "left in place"
+		case r.GetDryRun():
+			result = "dry run"
+		case result == "":
+			result = "released"
+		}
+		fmt.Printf("%s\t%s\t%s\t%s\t%s\n", it.GetKind(), humanBytes(it.GetSizeBytes()), where, it.GetReason(), result)
+	}
+	fmt.Printf("\n%d items, %s reclaimable\n", len(r.GetItems()), humanBytes(r.GetReclaimableBytes()))
+	for _, p := range r.GetPurged() {
+		fmt.Printf("purged %s\n", p)
+	}
+}
+
+func convertCmd(args []string) {
+	fs := flag.NewFlagSet("storage convert", flag.ExitOnError)
+	var endpoint, image, volume, name, format string
+	var compress, wait bool
+	fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+	fs.StringVar(&image, "image", "", "image to convert")
+	fs.StringVar(&volume, "volume", "", "detached volume to convert in place")
+	fs.StringVar(&name, "name", "", "store the converted image under a new name")
+	fs.StringVar(&format, "format", "", "raw, qcow2 or vmdk (current format if empty)")
+	fs.BoolVar(&compress, "compress", false, "write compressed qcow2")
+	fs.BoolVar(&wait, "wait", false, "wait for the job and show its progress")
+	_ = fs.Parse(args)
+	if (image == "") == (volume == "") {
+		fmt.Fprintln(os.Stderr, "either image or volume required")
+		os.Exit(1)
+	}
+	conn, stc, err := dialStorage(endpoint)
+	if err != nil {
+		fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	job, err := stc.Convert(ctx, &deusvmproto.ConvertRequest{Image: image, Volume: volume, Name: name, Format: format, Compress: compress})
+	if err != nil {
+		fatal(err)
+	}
+	if wait {
+		job = waitJob(ctx, stc, job)
+	}
+	printJob(job)
+}
+
+// waitJob shows the progress of a job until it finishes, exiting if it fails.
+// Ctrl-C stops waiting; the job keeps running on the daemon.
+func waitJob(ctx context.Context, stc deusvmproto.StorageServiceClient, job *deusvmproto.Job) *deusvmproto.Job {
+	var bar progressBar
+	var err error
+	for job.GetState() == "running" {
+		bar.update(int64(job.GetProgress()), 100)
+		select {
+		case <-ctx.Done():
+			bar.finish()
+			fatal(ctx.Err())
+		case <-time.After(time.Second):
+		}
+		if job, err = stc.GetJob(ctx, &deusvmproto.JobIDRequest{Id: job.GetId()}); err != nil {
+			bar.finish()
+			fatal(err)
+		}
+	}
+	bar.finish()
+	if job.GetState() == "failed" {
+		fatal(errors.New(job.GetError()))
+	}
+	return job
+}
+
+func networkCmd(args []string) {
+	if len(args) == 0 {
+		networkUsage()
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("network "+args[0], flag.ExitOnError)
+	var endpoint, name, mode, bridge, uplink, ipv4, dhcp, ipv6, dhcp6, inbound, outbound string
+	var externalDNS, pxe bool
+	fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+	switch args[0] {
+	case "create":
+		fs.StringVar(&name, "name", "", "network name")
+		fs.StringVar(&mode, "mode", "nat", "nat, isolated or routed")
+		fs.StringVar(&bridge, "bridge", "", "bridge to create (picked by libvirt if empty)")
+		fs.StringVar(&uplink, "uplink", "", "host interface for nat and routed traffic")
+		fs.StringVar(&ipv4, "ipv4", "", "IPv4 subnet (e.g. 10.10.0.0/24)")
+		fs.StringVar(&dhcp, "dhcp", "", "IPv4 DHCP range (e.g. 10.10.0.100-10.10.0.200)")
+		fs.StringVar(&ipv6, "ipv6", "", "IPv6 subnet (e.g. fd00:10::/64)")
+		fs.StringVar(&dhcp6, "dhcp6", "", "IPv6 DHCP range")
+		fs.BoolVar(&externalDNS, "external-dns", false, "answer DNS on the gateway with the daemon's DNS server")
+		fs.BoolVar(&pxe, "pxe", false, "point VMs booting from the network to the daemon's boot server (needs --ipv4 and --dhcp)")
+		fs.StringVar(&inbound, "inbound", "", "default traffic limit to NICs: average[,peak[,burst]] in KiB/s and KiB")
+		fs.StringVar(&outbound, "outbound", "", "default traffic limit from NICs, like --inbound")
+	case "get", "delete":
+		fs.StringVar(&name, "name", "", "network name")
+	case "allocations":
+		fs.StringVar(&name, "name", "", "network name (every network if empty)")
+	case "list":
+	default:
+		networkUsage()
+		os.Exit(1)
+	}
+	_ = fs.Parse(args[1:])
+	if args[0] != "list" && args[0] != "allocations" && name == "" {
+		fmt.Fprintln(os.Stderr, "name required")
+		os.Exit(1)
+	}
+	conn, _, _, err := dials(endpoint)
+	if err != nil {
+		fatal(err)
+	}
+	defer conn.Close()
+	nc := deusvmproto.NewNetworkServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	var n *deusvmproto.Network
+	switch args[0] {
+	case "create":
+		req := &deusvmproto.CreateNetworkRequest{Name: name, Mode: mode, Bridge: bridge, Uplink: uplink, ExternalDns: externalDNS, Pxe: pxe}
+		if req.Ipv4, err = subnetFlag(ipv4, dhcp); err != nil {
+			fatal(err)
+		}
+		if req.Ipv6, err = subnetFlag(ipv6, dhcp6); err != nil {
+			fatal(err)
+		}
+		if req.Inbound, err = bandwidthFlag(inbound); err != nil {
+			fatal(err)
+		}
+		if req.Outbound, err = bandwidthFlag(outbound); err != nil {
+			fatal(err)
+		}
+		n, err = nc.Create(ctx, req)
+	case "get":
+		n, err = nc.Get(ctx, &deusvmproto.NetworkNameRequest{Name: name})
+	case "delete":
+		if _, err := nc.Delete(ctx, &deusvmproto.NetworkNameRequest{Name: name}); err != nil {
+			fatal(err)
+		}
+		fmt.Println("deleted")
+		return
+	case "list":
+		resp, err := nc.List(ctx, &deusvmproto.Empty{})
+		if err != nil {
+			fatal(err)
+		}
+		for _, n := range resp.GetNetworks() {
+			printNetwork(n)
+		}
+		return
+	case "allocations":
+		resp, err := nc.ListAllocations(ctx, &deusvmproto.ListAllocationsRequest{Network: name})
+		if err != nil {
+			fatal(err)
+		}
+		for _, a := range resp.GetAllocations() {
+			kind := "auto"
+			if a.GetStatic() {
+				kind = "static"
+			}
+			fmt.Printf("%s\t%s\t%s\t%s\t%s\n", a.GetNetwork(), a.GetIp(), a.GetMac(), a.GetVmName(), kind)
+		}
+		return
+	}
+	if err != nil {
+		fatal(err)
+	}
+	printNetwork(n)
+}
+
+// subnetFlag builds a subnet from a CIDR and an optional start-end range.
+func subnetFlag(cidr, dhcp string) (*deusvmproto.Subnet, error) {
+	if cidr == "" {
+		if dhcp != "" {
+			return nil, errors.New("dhcp range without subnet")
+		}
+		return nil, nil
+	}
+	s := &deusvmproto.Subnet{Cidr: cidr}
+	if dhcp != "" {
+		start, end, ok := strings.Cut(dhcp, "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid dhcp range %q, want start-end", dhcp)
+		}
+		s.DhcpStart, s.DhcpEnd = start, end
+	}
+	return s, nil
+}
+
+func printNetwork(n *deusvmproto.Network) {
+	state := "inactive"
+	if n.GetActive() {
+		state = "active"
+	}
+	var subnets []string
+	for _, s := range []*deusvmproto.Subnet{n.GetIpv4(), n.GetIpv6()} {
+		if s != nil {
+			subnets = append(subnets, s.GetCidr())
+		}
+	}
+	fmt.Printf("%s\t%s\t%s\t%s\t%s\n", n.GetName(), n.GetMode(), n.GetBridge(), strings.Join(subnets, ","), state)
+}
+
+// bandwidthFlag parses a traffic limit given as average[,peak[,burst]].
+func bandwidthFlag(s string) (*deusvmproto.Bandwidth, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("invalid bandwidth %q (want average[,peak[,burst]])", s)
+	}
+	var vals [3]uint32
+	for i, p := range parts {
+		n, err := strconv.ParseUint(strings.TrimSpace(p), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bandwidth %q (want average[,peak[,burst]])", s)
+		}
+		vals[i] = uint32(n)
+	}
+	return &deusvmproto.Bandwidth{Average: vals[0], Peak: vals[1], Burst: vals[2]}, nil
+}
+
+// bandwidthString formats a traffic limit for a tab separated line.
+func bandwidthString(dir string, b *deusvmproto.Bandwidth) string {
+	if b == nil {
+		return ""
+	}
+	out := fmt.Sprintf("\t%s %d KiB/s", dir, b.GetAverage())
+	if b.GetPeak() != 0 {
+		out += fmt.Sprintf(" peak %d", b.GetPeak())
+	}
+	if b.GetBurst() != 0 {
+		out += fmt.Sprintf(" burst %d KiB", b.GetBurst())
+	}
+	return out
+}
+
+// vlanList parses a comma separated list of VLAN IDs and ranges such as
+// "10,20-22".
+func vlanList(s string) ([]int32, error) {
+	var out []int32
+	for _, item := range splitList(s) {
+		from, to, isRange := strings.Cut(item, "-")
+		start, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vlan %q", item)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(to); err != nil || end < start {
+				return nil, fmt.Errorf("invalid vlan range %q", item)
+			}
+		}
+		for id := start; id <= end; id++ {
+			out = append(out, int32(id))
+		}
+	}
+	return out, nil
+}
+
+// splitList splits a comma separated flag value, ignoring empty items.
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func securityGroupCmd(args []string) {
+	if len(args) == 0 {
+		securityGroupUsage()
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("security-group "+args[0], flag.ExitOnError)
+	var endpoint, name, description string
+	var rules ruleFlags
+	fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+	switch args[0] {
+	case "create", "update":
+		fs.StringVar(&name, "name", "", "security group name")
+		fs.StringVar(&description, "description", "", "description")
+		fs.Var(&rules, "rule", `rule as "direction protocol [ports] [peers]", repeatable (e.g. "ingress tcp 22 10.0.0.0/8,group:web")`)
+	case "get", "delete":
+		fs.StringVar(&name, "name", "", "security group name")
+	case "list":
+	default:
+		securityGroupUsage()
+		os.Exit(1)
+	}
+	_ = fs.Parse(args[1:])
+	if args[0] != "list" && name == "" {
+		fmt.Fprintln(os.Stderr, "name required")
+		os.Exit(1)
+	}
+	conn, _, _, err := dials(endpoint)
+	if err != nil {
+		fatal(err)
+	}
+	defer conn.Close()
+	sgc := deusvmproto.NewSecurityGroupServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	var g *deusvmproto.SecurityGroup
+	switch args[0] {
+	case "create":
+		g, err = sgc.Create(ctx, &deusvmproto.SecurityGroup{Name: name, Description: description, Rules: rules})
+	case "update":
+		g, err = sgc.Update(ctx, &deusvmproto.SecurityGroup{Name: name, Description: description, Rules: rules})
+	case "get":
+		g, err = sgc.Get(ctx, &deusvmproto.SecurityGroupNameRequest{Name: name})
+	case "delete":
+		if _, err := sgc.Delete(ctx, &deusvmproto.SecurityGroupNameRequest{Name: name}); err != nil {
+			fatal(err)
+		}
+		fmt.Println("deleted")
+		return
+	case "list":
+		resp, err := sgc.List(ctx, &deusvmproto.Empty{})
+		if err != nil {
+			fatal(err)
+		}
+		for _, g := range resp.GetGroups() {
+			fmt.Printf("%s\t%d rules\t%s\n", g.GetName(), len(g.GetRules()), g.GetDescription())
+		}
+		return
+	}
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Printf("%s\t%s\n", g.GetName(), g.GetDescription())
+	for _, r := range g.GetRules() {
+		ports := "-"
+		switch {
+		case r.GetPortFrom() == 0:
+		case r.GetPortFrom() == r.GetPortTo():
+			ports = strconv.Itoa(int(r.GetPortFrom()))
+		default:
+			ports = fmt.Sprintf("%d-%d", r.GetPortFrom(), r.GetPortTo())
+		}
+		peers := r.GetCidrs()
+		for _, ref := range r.GetGroups() {
+			peers = append(peers, "group:"+ref)
+		}
+		if len(peers) == 0 {
+			peers = []string{"any"}
+		}
+		fmt.Printf("rule\t%s\t%s\t%s\t%s\n", r.GetDirection(), r.GetProtocol(), ports, strings.Join(peers, ","))
+	}
+}
+
+// ruleFlags collects repeated --rule flags. A rule is a direction, a
+// protocol, optionally a port or port range, and optionally a comma
+// separated list of CIDRs and group:NAME peers.
+type ruleFlags []*deusvmproto.SecurityRule
+
+func (r *ruleFlags) String() string { return "" }
+
+func (r *ruleFlags) Set(value string) error {
+	fields := strings.Fields(value)
+	if len(fields) < 2 || len(fields) > 4 {
+		return fmt.Errorf("invalid rule %q, want direction protocol [ports] [peers]", value)
+	}
+	rule := &deusvmproto.SecurityRule{Direction: fields[0], Protocol: fields[1]}
+	rest := fields[2:]
+	if len(rest) > 0 && rest[0] != "" && rest[0][0] >= '0' && rest[0][0] <= '9' && !strings.ContainsAny(rest[0], "./:") {
+		from, to, _ := strings.Cut(rest[0], "-")
+		pf, err := strconv.Atoi(from)
+		if err != nil {
+			return fmt.Errorf("invalid port %q", from)
+		}
+		rule.PortFrom, rule.PortTo = int32(pf), int32(pf)
+		if to != "" {
+			pt, err := strconv.Atoi(to)
+			if err != nil {
+				return fmt.Errorf("invalid port %q", to)
+			}
+			rule.PortTo = int32(pt)
+		}
+		rest = rest[1:]
+	}
+	if len(rest) > 1 {
+		return fmt.Errorf("invalid rule %q, want direction protocol [ports] [peers]", value)
+	}
+	if len(rest) == 1 && rest[0] != "any" {
+		for _, peer := range splitList(rest[0]) {
+			if ref, ok := strings.CutPrefix(peer, "group:"); ok {
+				rule.Groups = append(rule.Groups, ref)
+			} else {
+				rule.Cidrs = append(rule.Cidrs, peer)
+			}
+		}
+	}
+	*r = append(*r, rule)
+	return nil
+}
+
+func forwardCmd(args []string) {
+	if len(args) == 0 {
+		forwardUsage()
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("forward "+args[0], flag.ExitOnError)
+	var endpoint, name, host, protocol, vm, network string
+	var port int
+	fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+	switch args[0] {
+	case "create":
+		fs.StringVar(&name, "name", "", "port forward name")
+		fs.StringVar(&host, "host", "", "host port, optionally with an address (2222 or 192.0.2.10:2222)")
+		fs.StringVar(&protocol, "protocol", "tcp", "tcp or udp")
+		fs.StringVar(&vm, "vm", "", "target VM id or name")
+		fs.StringVar(&network, "network", "", "nat network of the target NIC (the first one if empty)")
+		fs.IntVar(&port, "port", 0, "target port on the VM")
+	case "get", "delete":
+		fs.StringVar(&name, "name", "", "port forward name")
+	case "list":
+		fs.StringVar(&vm, "vm", "", "VM id or name (every VM if empty)")
+	default:
+		forwardUsage()
+		os.Exit(1)
+	}
+	_ = fs.Parse(args[1:])
+	if args[0] != "list" && name == "" {
+		fmt.Fprintln(os.Stderr, "name required")
+		os.Exit(1)
+	}
+	conn, _, _, err := dials(endpoint)
+	if err != nil {
+		fatal(err)
+	}
+	defer conn.Close()
+	pfc := deusvmproto.NewPortForwardServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	var f *deusvmproto.PortForward
+	switch args[0] {
+	case "create":
+		if host == "" || vm == "" || port == 0 {
+			fmt.Fprintln(os.Stderr, "host, vm and port required")
+			os.Exit(1)
+		}
+		hostIP, hostPort := "", host
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			hostIP, hostPort = host[:i], host[i+1:]
+		}
+		hp, err := strconv.Atoi(hostPort)
+		if err != nil {
+			fatal(fmt.Errorf("invalid host port %q", hostPort))
+		}
+		f, err = pfc.Create(ctx, &deusvmproto.PortForward{
+			Name: name, HostIp: hostIP, HostPort: int32(hp), Protocol: protocol, Vm: vm, Network: network, Port: int32(port),
+		})
+		if err != nil {
+			fatal(err)
+		}
+	case "get":
+		f, err = pfc.Get(ctx, &deusvmproto.PortForwardNameRequest{Name: name})
+	case "delete":
+		if _, err := pfc.Delete(ctx, &deusvmproto.PortForwardNameRequest{Name: name}); err != nil {
+			fatal(err)
+		}
+		fmt.Println("deleted")
+		return
+	case "list":
+		resp, err := pfc.List(ctx, &deusvmproto.ListPortForwardsRequest{Vm: vm})
+		if err != nil {
+			fatal(err)
+		}
+		for _, f := range resp.GetForwards() {
+			printForward(f)
+		}
+		return
+	}
+	if err != nil {
+		fatal(err)
+	}
+	printForward(f)
+}
+
+func printForward(f *deusvmproto.PortForward) {
+	host := f.GetHostIp()
+	if host == "" {
+		host = "*"
+	}
+	state := "inactive"
+	if f.GetActive() {
+		state = "active"
+	}
+	target := f.GetTarget()
+	if target == "" {
+		target = "-"
+	}
+	fmt.Printf("%s\t%s/%s:%d\t%s %s:%d\t%s\n", f.GetName(), f.GetProtocol(), host, f.GetHostPort(), f.GetVmName(), target, f.GetPort(), state)
+}
+
+func bootTemplateCmd(args []string) {
+	if len(args) == 0 {
+		bootTemplateUsage()
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("boot-template "+args[0], flag.ExitOnError)
+	var endpoint, name, description, file string
+	fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+	switch args[0] {
+	case "create", "update":
+		fs.StringVar(&name, "name", "", "boot template name")
+		fs.StringVar(&description, "description", "", "description")
+		fs.StringVar(&file, "file", "", "iPXE script template to read")
+	case "get", "delete":
+		fs.StringVar(&name, "name", "", "boot template name")
+	case "list":
+	default:
+		bootTemplateUsage()
+		os.Exit(1)
+	}
+	_ = fs.Parse(args[1:])
+	if args[0] != "list" && name == "" {
+		fmt.Fprintln(os.Stderr, "name required")
+		os.Exit(1)
+	}
+	var script []byte
+	if args[0] == "create" || args[0] == "update" {
+		if file == "" {
+			fmt.Fprintln(os.Stderr, "file required")
+			os.Exit(1)
+		}
+		var err error
+		if script, err = os.ReadFile(file); err != nil {
+			fatal(err)
+		}
+	}
+	conn, _, _, err := dials(endpoint)
+	if err != nil {
+		fatal(err)
+	}
+	defer conn.Close()
+	bc := deusvmproto.NewBootServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	var t *deusvmproto.BootTemplate
+	switch args[0] {
+	case "create":
+		t, err = bc.CreateTemplate(ctx, &deusvmproto.BootTemplate{Name: name, Description: description, Script: string(script)})
+	case "update":
+		t, err = bc.UpdateTemplate(ctx, &deusvmproto.BootTemplate{Name: name, Description: description, Script: string(script)})
+	case "get":
+		t, err = bc.GetTemplate(ctx, &deusvmproto.BootTemplateNameRequest{Name: name})
+	case "delete":
+		if _, err := bc.DeleteTemplate(ctx, &deusvmproto.BootTemplateNameRequest{Name: name}); err != nil {
+			fatal(err)
+		}
+		fmt.Println("deleted")
+		return
+	case "list":
+		resp, err := bc.ListTemplates(ctx, &deusvmproto.Empty{})
+		if err != nil {
+			fatal(err)
+		}
+		for _, t := range resp.GetTemplates() {
+			fmt.Printf("%s\t%s\n", t.GetName(), t.GetDescription())
+		}
+		return
+	}
+	if err != nil {
+		fatal(err)
+	}
+	if args[0] != "get" {
+		fmt.Println(t.GetName())
+		return
+	}
+	fmt.Printf("%s\t%s\n", t.GetName(), t.GetDescription())
+	fmt.Print(t.GetScript())
+}
+
+func hostCmd(args []string) {
+	if len(args) == 0 || args[0] != "interfaces" {
+		hostUsage()
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("host "+args[0], flag.ExitOnError)
+	var endpoint string
+	fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+	_ = fs.Parse(args[1:])
+	conn, _, _, err := dials(endpoint)
+	if err != nil {
+		fatal(err)
+	}
+	defer conn.Close()
+	hc := deusvmproto.NewHostServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	resp, err := hc.ListNetworkInterfaces(ctx, &deusvmproto.Empty{})
+	if err != nil {
+		fatal(err)
+	}
+	for _, i := range resp.GetInterfaces() {
+		printHostInterface(i)
+	}
+}
+
+func printHostInterface(i *deusvmproto.HostInterface) {
+	var extra string
+	switch {
+	case i.GetVlan() != 0:
+		extra = fmt.Sprintf("\tvlan %d on %s", i.GetVlan(), i.GetParent())
+	case i.GetBondMode() != "":
+		extra = "\tmode " + i.GetBondMode()
+	}
+	if len(i.GetPorts()) > 0 {
+		extra += "\tports " + strings.Join(i.GetPorts(), ",")
+	}
+	if i.GetMaster() != "" {
+		extra += "\tmaster " + i.GetMaster()
+	}
+	if len(i.GetAddresses()) > 0 {
+		extra += "\t" + strings.Join(i.GetAddresses(), ",")
+	}
+	fmt.Printf("%s\t%s\t%s/%s\tmtu %d%s\n", i.GetName(), i.GetKind(), i.GetState(), i.GetOperState(), i.GetMtu(), extra)
+}
+
+func backupCmd(args []string) {
+	if len(args) == 0 {
+		backupUsage()
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("backup "+args[0], flag.ExitOnError)
+	var endpoint, vm, policy, target, id, name, pool string
+	var wait bool
+	fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+	switch args[0] {
+	case "create":
+		fs.StringVar(&vm, "vm", "", "VM id or name")
+		fs.StringVar(&policy, "policy", "", "backup policy (optional when only one is configured)")
+		fs.BoolVar(&wait, "wait", false, "wait for the job and show its progress")
+	case "list":
+		fs.StringVar(&target, "target", "", "only backups on this target")
+		fs.StringVar(&vm, "vm", "", "only backups of this VM name")
+	case "get", "delete":
+		fs.StringVar(&id, "id", "", "backup id (target/vm/timestamp)")
+	case "restore":
+		fs.StringVar(&id, "id", "", "backup id (target/vm/timestamp)")
+		fs.StringVar(&name, "name", "", "name of the new VM (<vm>-restored if empty)")
+		fs.StringVar(&pool, "pool", "", "storage pool for the restored disks")
+		fs.BoolVar(&wait, "wait", false, "wait for the job and show its progress")
+	default:
+		backupUsage()
+		os.Exit(1)
+	}
+	_ = fs.Parse(args[1:])
+	if (args[0] == "create" && vm == "") || (args[0] != "create" && args[0] != "list" && id == "") {
+		fmt.Fprintln(os.Stderr, "vm or id required")
+		os.Exit(1)
+	}
+	conn, _, _, err := dials(endpoint)
+	if err != nil {
+		fatal(err)
+	}
+	defer conn.Close()
+	bc := deusvmproto.NewBackupServiceClient(conn)
+	stc := deusvmproto.NewStorageServiceClient(conn)
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	switch args[0] {
+	case "create", "restore":
+		var job *deusvmproto.Job
+		if args[0] == "create" {
+			job, err = bc.Create(ctx, &deusvmproto.CreateBackupRequest{VmId: vm, Policy: policy})
+		} else {
+			job, err = bc.Restore(ctx, &deusvmproto.RestoreBackupRequest{Id: id, Name: name, Pool: pool})
+		}
+		if err != nil {
+			fatal(err)
+		}
+		if wait {
+			job = waitJob(ctx, stc, job)
+		}
+		printJob(job)
+	case "list":
+		resp, err := bc.List(ctx, &deusvmproto.ListBackupsRequest{Target: target, VmName: vm})
+		if err != nil {
+			fatal(err)
+		}
+		for _, b := range resp.GetBackups() {
+			printBackup(b)
+		}
+	case "get":
+		b, err := bc.Get(ctx, &deusvmproto.BackupIDRequest{Id: id})
+		if err != nil {
+			fatal(err)
+		}
+		printBackup(b)
+		for _, f := range b.GetFiles() {
+			fmt.Printf("  %s\t%s\t%s\t%s\n", f.GetName(), f.GetKind(), humanBytes(f.GetSizeBytes()), f.GetSha256())
+		}
+	case "delete":
+		if _, err := bc.Delete(ctx, &deusvmproto.BackupIDRequest{Id: id}); err != nil {
+			fatal(err)
+		}
+		fmt.Println("deleted")
+	}
+}
+
+func printBackup(b *deusvmproto.Backup) {
+	consistency := b.GetConsistency()
+	if b.GetQuiesced() {
+		consistency += " (quiesced)"
+	}
+	fmt.Printf("%s\t%s\t%s\t%s\t%s\n", b.GetId(), b.GetCreatedAt(), b.GetPolicy(), consistency, humanBytes(b.GetSizeBytes()))
+}
+
+func jobCmd(args []string) {
+	if len(args) == 0 {
+		jobUsage()
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("job "+args[0], flag.ExitOnError)
+	var endpoint, id string
+	fs.StringVar(&endpoint, "endpoint", "127.0.0.1:9090", "gRPC endpoint host:port")
+	switch args[0] {
+	case "get":
+		fs.StringVar(&id, "id", "", "job id")
+	case "list":
+	default:
+		jobUsage()
+		os.Exit(1)
+	}
+	_ = fs.Parse(args[1:])
+	if args[0] == "get" && id == "" {
+		fmt.Fprintln(os.Stderr, "id required")
+		os.Exit(1)
+	}
+	conn, stc, err := dialStorage(endpoint)
+	if err != nil {
+		fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if args[0] == "list" {
+		resp, err := stc.ListJobs(ctx, &deusvmproto.Empty{})
+		if err != nil {
+			fatal(err)
+		}
+		for _, j := range resp.GetJobs() {
+			printJob(j)
+		}
+		return
+	}
+	job, err := stc.GetJob(ctx, &deusvmproto.JobIDRequest{Id: id})
+	if err != nil {
+		fatal(err)
+	}
+	printJob(job)
+}
+
+func printJob(j *deusvmproto.Job) {
+	fmt.Printf("%s\t%s\t%s\t%s\t%.1f%%\t%s\n", j.GetId(), j.GetKind(), j.GetTarget(), j.GetState(), j.GetProgress(), j.GetError())
+}
+
+// uploadImage streams r to the daemon in chunks, followed by its sha256.
+func uploadImage(ctx context.Context, imgc deusvmproto.ImageServiceClient, name string, r io.Reader, size int64) (*deusvmproto.Image, error) {
+	stream, err := imgc.Upload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info := &deusvmproto.UploadImageInfo{Name: name, SizeBytes: size}
+	if err := stream.Send(&deusvmproto.UploadImageRequest{Payload: &deusvmproto.UploadImageRequest_Info{Info: info}}); err != nil {
+		return nil, err
+	}
+	hasher := sha256.New()
+	buf := make([]byte, 1<<20)
+	var bar progressBar
+	defer bar.finish()
+	var sent int64
+	for {
+		n, rerr := r.Read(buf)
+		if n > 0 {
+			hasher.Write(buf[:n])
+			if err := stream.Send(&deusvmproto.UploadImageRequest{Payload: &deusvmproto.UploadImageRequest_Chunk{Chunk: buf[:n]}}); err != nil {
+				// the server ends the stream on error; CloseAndRecv reports why
+				break
+			}
+			sent += int64(n)
+			bar.update(sent, size)
+		}
+		if rerr == io.EOF {
+			sum := fmt.Sprintf("%x", hasher.Sum(nil))
+			_ = stream.Send(&deusvmproto.UploadImageRequest{Payload: &deusvmproto.UploadImageRequest_Sha256{Sha256: sum}})
+			break
+		}
+		if rerr != nil {
+			return nil, rerr
+		}
+	}
+	return stream.CloseAndRecv()
+}
+
+// exportVM writes the archive streamed by the daemon to out, or to stdout
+// when out is "-". A partial file is removed on failure.
+func exportVM(ctx context.Context, vmc deusvmproto.VMServiceClient, id, out string) error {
+	stream, err := vmc.Export(ctx, &deusvmproto.VMIDRequest{Id: id})
+	if err != nil {
+		return err
+	}
+	w := io.Writer(os.Stdout)
+	var f *os.File
+	if out != "-" {
+		if f, err = os.Create(out + ".part"); err != nil {
+			return err
+		}
+		defer os.Remove(f.Name())
+		defer f.Close()
+		w = f
+	}
+	var bar progressBar
+	defer bar.finish()
+	var received int64
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(msg.GetChunk()); err != nil {
+			return err
+		}
+		received += int64(len(msg.GetChunk()))
+		bar.update(received, 0)
+	}
+	if f == nil {
+		return nil
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), out)
+}
+
+// importVM streams an archive to the daemon in chunks.
+func importVM(ctx context.Context, vmc deusvmproto.VMServiceClient, info *deusvmproto.ImportVMInfo, r io.Reader, size int64) (*deusvmproto.VM, error) {
+	stream, err := vmc.Import(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(&deusvmproto.ImportVMRequest{Payload: &deusvmproto.ImportVMRequest_Info{Info: info}}); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 1<<20)
+	var bar progressBar
+	defer bar.finish()
+	var sent int64
+	for {
+		n, rerr := r.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&deusvmproto.ImportVMRequest{Payload: &deusvmproto.ImportVMRequest_Chunk{Chunk: buf[:n]}}); err != nil {
+				// the server ends the stream on error; CloseAndRecv reports why
+				break
+			}
+			sent += int64(n)
+			bar.update(sent, size)
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return nil, rerr
+		}
+	}
+	return stream.CloseAndRecv()
+}
+
+func usage() {
+	fmt.Println("deusvmctl <vm|image|volume|storage|job|backup|network|security-group|forward|boot-template|host> [subcommand] [flags]")
+	fmt.Println("Use --help under each subcommand")
+}
+
+func vmUsage() {
+	fmt.Println("vm subcommands: create|list|get|delete|start|stop|insert-media|eject-media|export|import|security-groups|attach-nic|detach-nic|update-nic|boot-template")
+}
+func imageUsage() { fmt.Println("image subcommands: create|upload|tag|alias|capture|list|delete") }
+func volumeUsage() {
+	fmt.Println("volume subcommands: create|list|get|delete|attach|detach|resize|clone")
+}
+func storageUsage() { fmt.Println("storage subcommands: convert|usage|gc") }
+func jobUsage()     { fmt.Println("job subcommands: list|get") }
+func backupUsage()  { fmt.Println("backup subcommands: create|list|get|restore|delete") }
+func networkUsage() { fmt.Println("network subcommands: create|list|get|delete|allocations") }
+func forwardUsage() { fmt.Println("forward subcommands: create|list|get|delete") }
+func hostUsage()    { fmt.Println("host subcommands: interfaces") }
+func bootTemplateUsage() {
+	fmt.Println("boot-template subcommands: create|list|get|update|delete")
+}
+func securityGroupUsage() {
+	fmt.Println("security-group (sg) subcommands: create|list|get|update|delete")
+}
+
+func parseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 3 {
+		return 0, fmt.Errorf("invalid size")
+	}
+	unit := strings.ToUpper(s[len(s)-2:])
+	numStr := s[:len(s)-2]
+	var v int64
+	for i := 0; i < len(numStr); i++ {
+		if numStr[i] < '0' || numStr[i] > '9' {
+			return 0, fmt.Errorf("invalid size")
+		}
+		v = v*10 + int64(numStr[i]-'0')
 	}
 	switch unit {
 	case "GB":
@@ -275,4 +1822,41 @@ func parseSize(s string) (int64, error) {
 	}
 }
 
+// progressBar renders a single-line transfer progress indicator on stderr.
+type progressBar struct{ drawn bool }
+
+func (b *progressBar) update(done, total int64) {
+	const width = 30
+	if total > 0 {
+		filled := int(done * width / total)
+		if filled > width {
+			filled = width
+		}
+		fmt.Fprintf(os.Stderr, "\r[%s%s] %3d%% %s / %s", strings.Repeat("=", filled), strings.Repeat(" ", width-filled), done*100/total, humanBytes(done), humanBytes(total))
+	} else {
+		fmt.Fprintf(os.Stderr, "\r%s", humanBytes(done))
+	}
+	b.drawn = true
+}
+
+func (b *progressBar) finish() {
+	if b.drawn {
+		fmt.Fprintln(os.Stderr)
+		b.drawn = false
+	}
+}
+
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 func fatal(err error) { fmt.Fprintln(os.Stderr, err.Error()); os.Exit(1) }
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/riccardotacconi/deusvm/internal/backup"
+	"github.com/riccardotacconi/deusvm/internal/kvm"
+	"github.com/riccardotacconi/deusvm/internal/storage"
+)
+
+// startRestore creates a new VM from a backup as a background job. The VM is
+// named name, or <vm>-restored when empty, and its disks become volumes in
+// pool.
+func (v vmService) startRestore(ctx context.Context, backups *backup.Service, id, name, pool string) (storage.Job, error) {
+	b, err := backups.Get(ctx, id)
+	if err != nil {
+		return storage.Job{}, err
+	}
+	if name == "" {
+		name = b.VM.Name + "-restored"
+	}
+	if _, err := v.manager.GetVM(ctx, name); err == nil {
+		return storage.Job{}, fmt.Errorf("vm %s: %w", name, errVMExists)
+	}
+	return v.store.StartJob("restore", "vm/"+name, func(ctx context.Context, progress func(float64)) error {
+		_, err := v.restore(ctx, backups, id, name, pool, progress)
+		return err
+	}), nil
+}
+
+// restore downloads and verifies the disks of a backup and defines a VM on
+// them.
+func (v vmService) restore(ctx context.Context, backups *backup.Service, id, name, pool string, progress func(float64)) (kvm.VM, error) {
+	dir, b, err := backups.Fetch(ctx, id, progress)
+	if err != nil {
+		return kvm.VM{}, err
+	}
+	defer os.RemoveAll(dir)
+	// the backed up VM may still be around, so its MAC addresses are not reused
+	m := b.Manifest
+	m.VM.NICs = slices.Clone(m.VM.NICs)
+	for i := range m.VM.NICs {
+		m.VM.NICs[i].MAC = ""
+	}
+	return v.define(ctx, dir, m, name, pool, func(p float64) { progress(50 + p/2) })
+}
+
+// importVM unpacks a VM archive written by backup.Service.Export and defines
+// a VM on its disks, named name or as in the archive.
+func (v vmService) importVM(ctx context.Context, backups *backup.Service, r io.Reader, name, pool string) (kvm.VM, error) {
+	if name != "" {
+		if _, err := v.manager.GetVM(ctx, name); err == nil {
+			return kvm.VM{}, fmt.Errorf("vm %s: %w", name, errVMExists)
+		}
+	}
+	dir, m, err := backups.Import(r)
+	if err != nil {
+		return kvm.VM{}, err
+	}
+	defer os.RemoveAll(dir)
+	if name == "" {
+		name = m.VM.Name
+		if _, err := v.manager.GetVM(ctx, name); err == nil {
+			return kvm.VM{}, fmt.Errorf("vm %s: %w", name, errVMExists)
+		}
+	}
+	return v.define(ctx, dir, m, name, pool, func(float64) {})
+}
+
+// define imports the disk files of m from dir as volumes that go away with
+// the VM, and defines a stopped VM on them with the CPU, memory, boot order
+// and NICs recorded in m. The CD-ROM drive starts empty. The disks are full
+// copies, so the VM does not record the image it was first cloned from and
+// holds no reference on it.
+func (v vmService) define(ctx context.Context, dir string, m backup.Manifest, name, pool string, progress func(float64)) (kvm.VM, error) {
+	var vols []storage.Volume
+	undo := func() {
+		for _, vol := range vols {
+			_ = v.store.SetVolumeAttachment(ctx, vol.Name, "")
+			_ = v.store.DeleteVolume(ctx, vol.Name)
+		}
+	}
+	req := kvm.CreateVMRequest{
+		Name:        name,
+		CPU:         m.VM.CPU,
+		MemoryBytes: m.VM.MemoryBytes,
+		DiskBytes:   m.VM.DiskBytes,
+		BootOrder:   m.VM.BootOrder,
+		NICs:        m.VM.NICs,
+	}
+	disks := m.Disks()
+	for i, f := range disks {
+		volName := fmt.Sprintf("%s-disk%d", name, i)
+		if i == 0 {
+			volName = name + "-root"
+		}
+		vol, err := v.store.ImportVolume(ctx, storage.VolumeSpec{Name: volName, Pool: pool, DeleteWithVM: true}, filepath.Join(dir, f.Name), "qcow2")
+		if err != nil {
+			undo()
+			return kvm.VM{}, fmt.Errorf("import disk %d: %w", i, err)
+		}
+		vols = append(vols, vol)
+		req.Disks = append(req.Disks, volumeDisk(vol))
+		progress(float64(i+1) * 100 / float64(len(disks)))
+	}
+	vm, err := v.create(ctx, req, pool)
+	if err != nil {
+		undo()
+		return kvm.VM{}, err
+	}
+	for _, vol := range vols {
+		if err := v.store.SetVolumeAttachment(ctx, vol.Name, vm.ID); err != nil {
+			_ = v.delete(ctx, vm.ID)
+			undo()
+			return kvm.VM{}, fmt.Errorf("attach %s: %w", vol.Name, err)
+		}
+	}
+	return vm, nil
+}
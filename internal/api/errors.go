@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/riccardotacconi/deusvm/internal/backup"
+	"github.com/riccardotacconi/deusvm/internal/host"
+	"github.com/riccardotacconi/deusvm/internal/kvm"
+	"github.com/riccardotacconi/deusvm/internal/network"
+	"github.com/riccardotacconi/deusvm/internal/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcError maps well-known errors onto gRPC status codes.
+func grpcError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, storage.ErrImageInUse), errors.Is(err, storage.ErrVolumeInUse), errors.Is(err, errVolumeNotAttached), errors.Is(err, errVMRunning),
+		errors.Is(err, backup.ErrBackupInProgress), errors.Is(err, errNetworkInUse), errors.Is(err, network.ErrGroupInUse),
+		errors.Is(err, network.ErrTemplateInUse):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, storage.ErrImageExists), errors.Is(err, storage.ErrVolumeExists), errors.Is(err, errVMExists),
+		errors.Is(err, kvm.ErrNetworkExists), errors.Is(err, network.ErrAddressInUse), errors.Is(err, network.ErrGroupExists),
+		errors.Is(err, network.ErrForwardExists), errors.Is(err, network.ErrPortInUse), errors.Is(err, kvm.ErrNICExists), errors.Is(err, network.ErrTemplateExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, os.ErrNotExist), errors.Is(err, storage.ErrVolumeNotFound), errors.Is(err, storage.ErrJobNotFound),
+		errors.Is(err, backup.ErrBackupNotFound), errors.Is(err, kvm.ErrNetworkNotFound), errors.Is(err, network.ErrGroupNotFound),
+		errors.Is(err, network.ErrForwardNotFound), errors.Is(err, kvm.ErrNICNotFound), errors.Is(err, network.ErrTemplateNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, storage.ErrSourceNotAllowed):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, storage.ErrInsufficientStorage), errors.Is(err, network.ErrExhausted):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, backup.ErrChecksumMismatch):
+		return status.Error(codes.DataLoss, err.Error())
+	case errors.Is(err, backup.ErrInvalidArchive), errors.Is(err, storage.ErrChecksumRequired), errors.Is(err, storage.ErrChecksumMismatch):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, host.ErrUnsupported):
+		return status.Error(codes.Unimplemented, err.Error())
+	default:
+		return err
+	}
+}
+
+// httpStatus picks the REST status for err, or fallback when it is not a well-known error.
+func httpStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, storage.ErrImageInUse), errors.Is(err, storage.ErrImageExists),
+		errors.Is(err, storage.ErrVolumeInUse), errors.Is(err, storage.ErrVolumeExists), errors.Is(err, errVolumeNotAttached),
+		errors.Is(err, errVMRunning), errors.Is(err, errVMExists), errors.Is(err, backup.ErrBackupInProgress),
+		errors.Is(err, kvm.ErrNetworkExists), errors.Is(err, errNetworkInUse), errors.Is(err, network.ErrAddressInUse),
+		errors.Is(err, network.ErrExhausted), errors.Is(err, network.ErrGroupExists), errors.Is(err, network.ErrGroupInUse),
+		errors.Is(err, network.ErrForwardExists), errors.Is(err, network.ErrPortInUse), errors.Is(err, kvm.ErrNICExists),
+		errors.Is(err, network.ErrTemplateExists), errors.Is(err, network.ErrTemplateInUse):
+		return http.StatusConflict
+	case errors.Is(err, os.ErrNotExist), errors.Is(err, storage.ErrVolumeNotFound), errors.Is(err, storage.ErrJobNotFound),
+		errors.Is(err, backup.ErrBackupNotFound), errors.Is(err, kvm.ErrNetworkNotFound), errors.Is(err, network.ErrGroupNotFound),
+		errors.Is(err, network.ErrForwardNotFound), errors.Is(err, kvm.ErrNICNotFound), errors.Is(err, network.ErrTemplateNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, storage.ErrSourceNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, storage.ErrInsufficientStorage):
+		return http.StatusInsufficientStorage
+	case errors.Is(err, backup.ErrInvalidArchive), errors.Is(err, backup.ErrChecksumMismatch),
+		errors.Is(err, storage.ErrChecksumRequired), errors.Is(err, storage.ErrChecksumMismatch):
+		return http.StatusBadRequest
+	case errors.Is(err, host.ErrUnsupported):
+		return http.StatusNotImplemented
+	default:
+		return fallback
+	}
+}
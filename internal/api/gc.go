@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/riccardotacconi/deusvm/internal/kvm"
+	"github.com/riccardotacconi/deusvm/internal/logging"
+	"github.com/riccardotacconi/deusvm/internal/storage"
+	"go.uber.org/zap"
+)
+
+// gc collects storage garbage against the VMs the hypervisor knows about.
+// minAge overrides the configured minimum age when not zero.
+func (v vmService) gc(ctx context.Context, dryRun bool, minAge time.Duration) (storage.GCReport, error) {
+	vms, err := v.manager.ListVMs(ctx)
+	if err != nil {
+		return storage.GCReport{}, err
+	}
+	spec := storage.GCSpec{VMs: make(map[string]bool), InUse: make(map[string]bool), MinAge: minAge, DryRun: dryRun}
+	for _, vm := range vms {
+		spec.VMs[vm.ID] = true
+		for _, d := range vm.Disks {
+			spec.InUse[d.Path] = true
+		}
+		if vm.CDROM != "" {
+			spec.InUse[vm.CDROM] = true
+		}
+	}
+	return v.store.CollectGarbage(ctx, spec)
+}
+
+// RunGC collects storage garbage every interval until ctx is done, logging
+// what each run quarantined and purged.
+func RunGC(ctx context.Context, logger *zap.Logger, manager kvm.Manager, store storage.Manager, interval time.Duration) {
+	v := vmService{manager: manager, store: store}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		report, err := v.gc(ctx, false, 0)
+		if err != nil {
+			logger.Warn("garbage collection failed", logging.FieldError(err))
+			continue
+		}
+		for _, it := range report.Items {
+			if it.Error != "" {
+				logger.Warn("failed to collect", logging.Field("kind", it.Kind), logging.Field("path", it.Path), logging.Field("name", it.Name), logging.Field("error", it.Error))
+				continue
+			}
+			logger.Info("collected", logging.Field("kind", it.Kind), logging.Field("path", it.Path), logging.Field("name", it.Name),
+				logging.Field("reason", it.Reason), logging.Field("quarantine", it.Quarantine))
+		}
+		for _, path := range report.Purged {
+			logger.Info("purged from quarantine", logging.Field("path", path))
+		}
+	}
+}
@@ -1,49 +1,61 @@
 package api
 
 import (
+	"bufio"
 	"context"
+	"io"
+	"time"
 
+	"github.com/riccardotacconi/deusvm/internal/backup"
+	"github.com/riccardotacconi/deusvm/internal/host"
 	"github.com/riccardotacconi/deusvm/internal/kvm"
+	"github.com/riccardotacconi/deusvm/internal/network"
 	"github.com/riccardotacconi/deusvm/internal/storage"
 	deusvmproto "github.com/riccardotacconi/deusvm/pkg/proto/gen/github.com/riccardotacconi/deusvm/pkg/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type VMServiceServer struct {
 	deusvmproto.UnimplementedVMServiceServer
 	manager kvm.Manager
+	vms     vmService
+	backups *backup.Service
 }
 
-func NewVMServiceServer(manager kvm.Manager) *VMServiceServer {
-	return &VMServiceServer{manager: manager}
+func NewVMServiceServer(manager kvm.Manager, store storage.Manager, backups *backup.Service, networks *network.Service) *VMServiceServer {
+	return &VMServiceServer{manager: manager, vms: vmService{manager: manager, store: store, networks: networks}, backups: backups}
 }
 
 func (s *VMServiceServer) Create(ctx context.Context, req *deusvmproto.CreateVMRequest) (*deusvmproto.VM, error) {
-	vm, err := s.manager.CreateVM(ctx, kvm.CreateVMRequest{
+	vm, err := s.vms.create(ctx, kvm.CreateVMRequest{
 		Name: req.GetName(), Image: req.GetImage(), CPU: int(req.GetCpu()), MemoryBytes: req.GetMemoryBytes(), DiskBytes: req.GetDiskBytes(),
-	})
+		CDROM: req.GetIso(), BootOrder: req.GetBootOrder(), NICs: nicsFromProto(req.GetNics()),
+		Boot: req.GetBoot(), BootTemplate: req.GetBootTemplate(),
+	}, req.GetPool())
 	if err != nil {
-		return nil, err
+		return nil, grpcError(err)
 	}
 	return vmToProto(vm), nil
 }
 
 func (s *VMServiceServer) Delete(ctx context.Context, req *deusvmproto.VMIDRequest) (*deusvmproto.Empty, error) {
-	if err := s.manager.DeleteVM(ctx, req.GetId()); err != nil {
-		return nil, err
+	if err := s.vms.delete(ctx, req.GetId()); err != nil {
+		return nil, grpcError(err)
 	}
 	return &deusvmproto.Empty{}, nil
 }
 
 func (s *VMServiceServer) Start(ctx context.Context, req *deusvmproto.VMIDRequest) (*deusvmproto.Empty, error) {
-	if err := s.manager.StartVM(ctx, req.GetId()); err != nil {
-		return nil, err
+	if err := s.vms.start(ctx, req.GetId()); err != nil {
+		return nil, grpcError(err)
 	}
 	return &deusvmproto.Empty{}, nil
 }
 
 func (s *VMServiceServer) Stop(ctx context.Context, req *deusvmproto.VMIDRequest) (*deusvmproto.Empty, error) {
-	if err := s.manager.StopVM(ctx, req.GetId()); err != nil {
-		return nil, err
+	if err := s.vms.stop(ctx, req.GetId()); err != nil {
+		return nil, grpcError(err)
 	}
 	return &deusvmproto.Empty{}, nil
 }
@@ -51,7 +63,7 @@ func (s *VMServiceServer) Stop(ctx context.Context, req *deusvmproto.VMIDRequest
 func (s *VMServiceServer) Get(ctx context.Context, req *deusvmproto.VMIDRequest) (*deusvmproto.VM, error) {
 	vm, err := s.manager.GetVM(ctx, req.GetId())
 	if err != nil {
-		return nil, err
+		return nil, grpcError(err)
 	}
 	return vmToProto(vm), nil
 }
@@ -59,7 +71,7 @@ func (s *VMServiceServer) Get(ctx context.Context, req *deusvmproto.VMIDRequest)
 func (s *VMServiceServer) List(ctx context.Context, req *deusvmproto.Empty) (*deusvmproto.ListVMsResponse, error) {
 	vms, err := s.manager.ListVMs(ctx)
 	if err != nil {
-		return nil, err
+		return nil, grpcError(err)
 	}
 	out := &deusvmproto.ListVMsResponse{}
 	for _, vm := range vms {
@@ -68,38 +80,286 @@ func (s *VMServiceServer) List(ctx context.Context, req *deusvmproto.Empty) (*de
 	return out, nil
 }
 
+func (s *VMServiceServer) InsertMedia(ctx context.Context, req *deusvmproto.InsertMediaRequest) (*deusvmproto.Empty, error) {
+	if err := s.vms.insertMedia(ctx, req.GetId(), req.GetImage()); err != nil {
+		return nil, grpcError(err)
+	}
+	return &deusvmproto.Empty{}, nil
+}
+
+func (s *VMServiceServer) EjectMedia(ctx context.Context, req *deusvmproto.VMIDRequest) (*deusvmproto.Empty, error) {
+	if err := s.vms.ejectMedia(ctx, req.GetId()); err != nil {
+		return nil, grpcError(err)
+	}
+	return &deusvmproto.Empty{}, nil
+}
+
+// AttachInterface plugs a NIC into a VM, live when it is running.
+func (s *VMServiceServer) AttachInterface(ctx context.Context, req *deusvmproto.AttachInterfaceRequest) (*deusvmproto.VM, error) {
+	nics := nicsFromProto([]*deusvmproto.NIC{req.GetNic()})
+	vm, err := s.vms.attachNIC(ctx, req.GetId(), nics[0])
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return vmToProto(vm), nil
+}
+
+func (s *VMServiceServer) DetachInterface(ctx context.Context, req *deusvmproto.DetachInterfaceRequest) (*deusvmproto.VM, error) {
+	vm, err := s.vms.detachNIC(ctx, req.GetId(), req.GetMac())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return vmToProto(vm), nil
+}
+
+// UpdateInterface moves a NIC to another network or bridge and sets its
+// link state, live when the VM is running.
+func (s *VMServiceServer) UpdateInterface(ctx context.Context, req *deusvmproto.UpdateInterfaceRequest) (*deusvmproto.VM, error) {
+	nics := nicsFromProto([]*deusvmproto.NIC{req.GetNic()})
+	vm, err := s.vms.updateNIC(ctx, req.GetId(), nics[0])
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return vmToProto(vm), nil
+}
+
+// Export streams a VM archive in chunks of up to a megabyte.
+func (s *VMServiceServer) Export(req *deusvmproto.VMIDRequest, stream deusvmproto.VMService_ExportServer) error {
+	w := bufio.NewWriterSize(chunkWriter{stream}, 1<<20)
+	if _, err := s.backups.Export(stream.Context(), req.GetId(), w); err != nil {
+		return grpcError(err)
+	}
+	return w.Flush()
+}
+
+// chunkWriter sends everything written to it as archive chunks.
+type chunkWriter struct {
+	stream deusvmproto.VMService_ExportServer
+}
+
+func (w chunkWriter) Write(p []byte) (int, error) {
+	if err := w.stream.Send(&deusvmproto.VMArchiveChunk{Chunk: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Import defines a VM from an archive streamed by the client. The first
+// message must carry the import info.
+func (s *VMServiceServer) Import(stream deusvmproto.VMService_ImportServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	info := first.GetInfo()
+	if info == nil {
+		return status.Error(codes.InvalidArgument, "first message must carry import info")
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		for {
+			msg, err := stream.Recv()
+			if err == io.EOF {
+				pw.Close()
+				return
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			chunk, ok := msg.GetPayload().(*deusvmproto.ImportVMRequest_Chunk)
+			if !ok {
+				pw.CloseWithError(status.Error(codes.InvalidArgument, "unexpected message"))
+				return
+			}
+			if _, err := pw.Write(chunk.Chunk); err != nil {
+				return
+			}
+		}
+	}()
+	vm, err := s.vms.importVM(stream.Context(), s.backups, pr, info.GetName(), info.GetPool())
+	pr.Close()
+	if err != nil {
+		return grpcError(err)
+	}
+	return stream.SendAndClose(vmToProto(vm))
+}
+
 func vmToProto(vm kvm.VM) *deusvmproto.VM {
 	return &deusvmproto.VM{
-		Id:          vm.ID,
-		Name:        vm.Name,
-		Cpu:         int32(vm.CPU),
-		MemoryBytes: vm.MemoryBytes,
-		DiskBytes:   vm.DiskBytes,
-		Image:       vm.Image,
-		Status:      string(vm.Status),
+		Id:           vm.ID,
+		Name:         vm.Name,
+		Cpu:          int32(vm.CPU),
+		MemoryBytes:  vm.MemoryBytes,
+		DiskBytes:    vm.DiskBytes,
+		Image:        vm.Image,
+		Status:       string(vm.Status),
+		Cdrom:        vm.CDROM,
+		BootOrder:    vm.BootOrder,
+		Nics:         nicsToProto(vm.NICs),
+		BootTemplate: vm.BootTemplate,
+	}
+}
+
+func nicsToProto(nics []kvm.NIC) []*deusvmproto.NIC {
+	var out []*deusvmproto.NIC
+	for _, n := range nics {
+		nic := &deusvmproto.NIC{
+			Network: n.Network, Bridge: n.Bridge, Mac: n.MAC, Model: n.Model, Ipv4: n.IPv4, Ipv6: n.IPv6,
+			SecurityGroups: n.SecurityGroups, Device: n.Device, BridgeType: n.BridgeType, Vlan: int32(n.VLAN),
+			LinkState: n.LinkState, Inbound: bandwidthToProto(n.Inbound), Outbound: bandwidthToProto(n.Outbound),
+		}
+		for _, id := range n.Trunk {
+			nic.Trunk = append(nic.Trunk, int32(id))
+		}
+		out = append(out, nic)
+	}
+	return out
+}
+
+func nicsFromProto(nics []*deusvmproto.NIC) []kvm.NIC {
+	var out []kvm.NIC
+	for _, n := range nics {
+		nic := kvm.NIC{
+			Network: n.GetNetwork(), Bridge: n.GetBridge(), MAC: n.GetMac(), Model: n.GetModel(), IPv4: n.GetIpv4(), IPv6: n.GetIpv6(),
+			SecurityGroups: n.GetSecurityGroups(), BridgeType: n.GetBridgeType(), VLAN: int(n.GetVlan()),
+			LinkState: n.GetLinkState(), Inbound: bandwidthFromProto(n.GetInbound()), Outbound: bandwidthFromProto(n.GetOutbound()),
+		}
+		for _, id := range n.GetTrunk() {
+			nic.Trunk = append(nic.Trunk, int(id))
+		}
+		out = append(out, nic)
+	}
+	return out
+}
+
+func bandwidthToProto(b *kvm.Bandwidth) *deusvmproto.Bandwidth {
+	if b == nil {
+		return nil
+	}
+	return &deusvmproto.Bandwidth{Average: uint32(b.Average), Peak: uint32(b.Peak), Burst: uint32(b.Burst)}
+}
+
+func bandwidthFromProto(b *deusvmproto.Bandwidth) *kvm.Bandwidth {
+	if b == nil {
+		return nil
 	}
+	return &kvm.Bandwidth{Average: int(b.GetAverage()), Peak: int(b.GetPeak()), Burst: int(b.GetBurst())}
 }
 
 type ImageServiceServer struct {
 	deusvmproto.UnimplementedImageServiceServer
 	storage storage.Manager
+	vms     vmService
 }
 
-func NewImageServiceServer(store storage.Manager) *ImageServiceServer {
-	return &ImageServiceServer{storage: store}
+func NewImageServiceServer(manager kvm.Manager, store storage.Manager) *ImageServiceServer {
+	return &ImageServiceServer{storage: store, vms: vmService{manager: manager, store: store}}
 }
 
 func (s *ImageServiceServer) Create(ctx context.Context, req *deusvmproto.CreateImageRequest) (*deusvmproto.Image, error) {
-	img, err := s.storage.SaveImageFromURL(ctx, req.GetName(), req.GetSource())
+	img, err := s.storage.SaveImageFromURL(ctx, req.GetName(), req.GetSource(), nil)
 	if err != nil {
-		return nil, err
+		return nil, grpcError(err)
 	}
-	return &deusvmproto.Image{Name: img.Name, Path: img.Path, SizeBytes: img.Size, Format: img.Format, Sha256: img.SHA256}, nil
+	return imageToProto(img), nil
+}
+
+// CreateStream downloads an image like Create but streams transfer progress,
+// finishing with a message that carries the stored image.
+func (s *ImageServiceServer) CreateStream(req *deusvmproto.CreateImageRequest, stream deusvmproto.ImageService_CreateStreamServer) error {
+	// a client that went away cancels the download, leaving the part file
+	// for the next attempt
+	ctx, cancel := context.WithCancelCause(stream.Context())
+	defer cancel(nil)
+	var last storage.Progress
+	img, err := s.storage.SaveImageFromURL(ctx, req.GetName(), req.GetSource(), func(p storage.Progress) {
+		last = p
+		if err := stream.Send(&deusvmproto.ImageProgress{BytesDone: p.BytesDone, BytesTotal: p.BytesTotal}); err != nil {
+			cancel(err)
+		}
+	})
+	if err != nil {
+		if cause := context.Cause(ctx); cause != nil {
+			return cause
+		}
+		return grpcError(err)
+	}
+	// progress counts transferred bytes, which differ from the image size
+	// when the source was compressed
+	return stream.Send(&deusvmproto.ImageProgress{BytesDone: last.BytesDone, BytesTotal: last.BytesDone, Image: imageToProto(img)})
+}
+
+// Upload stores an image streamed by the client. The first message must
+// carry the image info and the last one the checksum of the data.
+func (s *ImageServiceServer) Upload(stream deusvmproto.ImageService_UploadServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	info := first.GetInfo()
+	if info == nil {
+		return status.Error(codes.InvalidArgument, "first message must carry image info")
+	}
+	up, err := s.storage.BeginUpload(info.GetName())
+	if err != nil {
+		return grpcError(err)
+	}
+	var checksum string
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			up.Abort()
+			return err
+		}
+		switch p := msg.GetPayload().(type) {
+		case *deusvmproto.UploadImageRequest_Chunk:
+			if checksum != "" {
+				up.Abort()
+				return status.Error(codes.InvalidArgument, "data after checksum")
+			}
+			if _, err := up.Write(p.Chunk); err != nil {
+				up.Abort()
+				return grpcError(err)
+			}
+		case *deusvmproto.UploadImageRequest_Sha256:
+			checksum = p.Sha256
+		default:
+			up.Abort()
+			return status.Error(codes.InvalidArgument, "unexpected message")
+		}
+	}
+	img, err := up.Commit(checksum)
+	if err != nil {
+		return grpcError(err)
+	}
+	return stream.SendAndClose(imageToProto(img))
+}
+
+// Tag gives an existing image another name without copying its content.
+func (s *ImageServiceServer) Tag(ctx context.Context, req *deusvmproto.TagImageRequest) (*deusvmproto.Image, error) {
+	img, err := s.storage.TagImage(ctx, req.GetSource(), req.GetName())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return imageToProto(img), nil
+}
+
+// CaptureFromVM stores the boot disk of a VM as a new standalone image.
+func (s *ImageServiceServer) CaptureFromVM(ctx context.Context, req *deusvmproto.CaptureImageRequest) (*deusvmproto.Image, error) {
+	img, err := s.vms.capture(ctx, req.GetVmId(), req.GetName(), req.GetSparsify())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return imageToProto(img), nil
 }
 
 func (s *ImageServiceServer) Delete(ctx context.Context, req *deusvmproto.ImageNameRequest) (*deusvmproto.Empty, error) {
-	if err := s.storage.DeleteImage(ctx, req.GetName()); err != nil {
-		return nil, err
+	if err := s.storage.DeleteImage(ctx, req.GetName(), req.GetForce()); err != nil {
+		return nil, grpcError(err)
 	}
 	return &deusvmproto.Empty{}, nil
 }
@@ -111,7 +371,635 @@ func (s *ImageServiceServer) List(ctx context.Context, req *deusvmproto.Empty) (
 	}
 	out := &deusvmproto.ListImagesResponse{}
 	for _, im := range imgs {
-		out.Images = append(out.Images, &deusvmproto.Image{Name: im.Name, Path: im.Path, SizeBytes: im.Size, Format: im.Format, Sha256: im.SHA256})
+		out.Images = append(out.Images, imageToProto(im))
+	}
+	return out, nil
+}
+
+func imageToProto(img storage.Image) *deusvmproto.Image {
+	return &deusvmproto.Image{
+		Name:         img.Name,
+		Path:         img.Path,
+		SizeBytes:    img.Size,
+		Format:       img.Format,
+		Sha256:       img.SHA256,
+		Compression:  img.Compression,
+		SourceSha256: img.SourceSHA256,
+		UsedBy:       int32(img.UsedBy),
+		Lineage:      lineageToProto(img.Lineage),
+	}
+}
+
+func lineageToProto(l *storage.Lineage) *deusvmproto.Lineage {
+	if l == nil {
+		return nil
+	}
+	return &deusvmproto.Lineage{
+		SourceVm:   l.SourceVM,
+		SourceDisk: l.SourceDisk,
+		Parent:     l.Parent,
+		CapturedAt: l.CapturedAt.Format(time.RFC3339),
+	}
+}
+
+type VolumeServiceServer struct {
+	deusvmproto.UnimplementedVolumeServiceServer
+	storage storage.Manager
+	volumes volumeService
+}
+
+func NewVolumeServiceServer(manager kvm.Manager, store storage.Manager) *VolumeServiceServer {
+	return &VolumeServiceServer{storage: store, volumes: volumeService{manager: manager, store: store}}
+}
+
+func (s *VolumeServiceServer) Create(ctx context.Context, req *deusvmproto.CreateVolumeRequest) (*deusvmproto.Volume, error) {
+	vol, err := s.storage.CreateVolume(ctx, storage.VolumeSpec{
+		Name: req.GetName(), Pool: req.GetPool(), SizeBytes: req.GetSizeBytes(), Format: req.GetFormat(), Image: req.GetImage(),
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return volumeToProto(vol), nil
+}
+
+func (s *VolumeServiceServer) Get(ctx context.Context, req *deusvmproto.VolumeNameRequest) (*deusvmproto.Volume, error) {
+	vol, err := s.storage.GetVolume(ctx, req.GetName())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return volumeToProto(vol), nil
+}
+
+func (s *VolumeServiceServer) List(ctx context.Context, req *deusvmproto.Empty) (*deusvmproto.ListVolumesResponse, error) {
+	vols, err := s.storage.ListVolumes(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	out := &deusvmproto.ListVolumesResponse{}
+	for _, vol := range vols {
+		out.Volumes = append(out.Volumes, volumeToProto(vol))
+	}
+	return out, nil
+}
+
+func (s *VolumeServiceServer) Delete(ctx context.Context, req *deusvmproto.VolumeNameRequest) (*deusvmproto.Empty, error) {
+	if err := s.storage.DeleteVolume(ctx, req.GetName()); err != nil {
+		return nil, grpcError(err)
+	}
+	return &deusvmproto.Empty{}, nil
+}
+
+func (s *VolumeServiceServer) Attach(ctx context.Context, req *deusvmproto.AttachVolumeRequest) (*deusvmproto.Volume, error) {
+	vol, err := s.volumes.attach(ctx, req.GetName(), req.GetVmId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return volumeToProto(vol), nil
+}
+
+func (s *VolumeServiceServer) Detach(ctx context.Context, req *deusvmproto.VolumeNameRequest) (*deusvmproto.Volume, error) {
+	vol, err := s.volumes.detach(ctx, req.GetName())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return volumeToProto(vol), nil
+}
+
+func (s *VolumeServiceServer) Resize(ctx context.Context, req *deusvmproto.ResizeVolumeRequest) (*deusvmproto.Volume, error) {
+	vol, err := s.volumes.resize(ctx, req.GetName(), req.GetSizeBytes())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return volumeToProto(vol), nil
+}
+
+func (s *VolumeServiceServer) Clone(ctx context.Context, req *deusvmproto.CloneVolumeRequest) (*deusvmproto.Volume, error) {
+	vol, err := s.volumes.clone(ctx, req.GetSource(), storage.VolumeSpec{
+		Name: req.GetName(), Pool: req.GetPool(), SizeBytes: req.GetSizeBytes(),
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return volumeToProto(vol), nil
+}
+
+func volumeToProto(vol storage.Volume) *deusvmproto.Volume {
+	return &deusvmproto.Volume{
+		Name:         vol.Name,
+		Pool:         vol.Pool,
+		Path:         vol.Path,
+		Format:       vol.Format,
+		SizeBytes:    vol.SizeBytes,
+		VmId:         vol.VM,
+		Image:        vol.Image,
+		DeleteWithVm: vol.DeleteWithVM,
+	}
+}
+
+type StorageServiceServer struct {
+	deusvmproto.UnimplementedStorageServiceServer
+	storage storage.Manager
+	vms     vmService
+}
+
+func NewStorageServiceServer(manager kvm.Manager, store storage.Manager) *StorageServiceServer {
+	return &StorageServiceServer{storage: store, vms: vmService{manager: manager, store: store}}
+}
+
+// Convert starts a format conversion job and returns it right away.
+func (s *StorageServiceServer) Convert(ctx context.Context, req *deusvmproto.ConvertRequest) (*deusvmproto.Job, error) {
+	job, err := s.storage.Convert(ctx, storage.ConvertSpec{
+		Image: req.GetImage(), Volume: req.GetVolume(), Name: req.GetName(), Format: req.GetFormat(), Compress: req.GetCompress(),
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return jobToProto(job), nil
+}
+
+func (s *StorageServiceServer) GetJob(ctx context.Context, req *deusvmproto.JobIDRequest) (*deusvmproto.Job, error) {
+	job, err := s.storage.GetJob(ctx, req.GetId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return jobToProto(job), nil
+}
+
+func (s *StorageServiceServer) ListJobs(ctx context.Context, req *deusvmproto.Empty) (*deusvmproto.ListJobsResponse, error) {
+	jobs, err := s.storage.ListJobs(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	out := &deusvmproto.ListJobsResponse{}
+	for _, j := range jobs {
+		out.Jobs = append(out.Jobs, jobToProto(j))
+	}
+	return out, nil
+}
+
+func jobToProto(j storage.Job) *deusvmproto.Job {
+	out := &deusvmproto.Job{
+		Id:        j.ID,
+		Kind:      j.Kind,
+		Target:    j.Target,
+		State:     string(j.State),
+		Progress:  j.Progress,
+		Error:     j.Error,
+		CreatedAt: j.CreatedAt.Format(time.RFC3339),
+	}
+	if j.FinishedAt != nil {
+		out.FinishedAt = j.FinishedAt.Format(time.RFC3339)
+	}
+	return out
+}
+
+// Usage reports capacity and consumption of the image store and pools, and
+// allocated versus virtual size per image and per VM.
+func (s *StorageServiceServer) Usage(ctx context.Context, req *deusvmproto.Empty) (*deusvmproto.StorageUsage, error) {
+	u, err := s.vms.usage(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	out := &deusvmproto.StorageUsage{ImageStore: poolReportToProto(u.ImageStore)}
+	for _, p := range u.Pools {
+		out.Pools = append(out.Pools, poolReportToProto(p))
+	}
+	for _, im := range u.Images {
+		out.Images = append(out.Images, &deusvmproto.ImageUsage{
+			Name: im.Name, Sha256: im.SHA256, AllocatedBytes: im.AllocatedBytes, VirtualBytes: im.VirtualBytes,
+		})
+	}
+	for _, vm := range u.VMs {
+		out.Vms = append(out.Vms, &deusvmproto.VMUsage{
+			VmId: vm.VM, Name: vm.Name, Volumes: vm.Volumes, AllocatedBytes: vm.AllocatedBytes, VirtualBytes: vm.VirtualBytes,
+		})
+	}
+	return out, nil
+}
+
+// GarbageCollect reports orphaned disks, stale transfers and unused images,
+// and moves them to quarantine unless it is a dry run.
+func (s *StorageServiceServer) GarbageCollect(ctx context.Context, req *deusvmproto.GCRequest) (*deusvmproto.GCReport, error) {
+	if req.GetMinAgeSeconds() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "min_age_seconds must not be negative")
+	}
+	report, err := s.vms.gc(ctx, req.GetDryRun(), time.Duration(req.GetMinAgeSeconds())*time.Second)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	out := &deusvmproto.GCReport{DryRun: report.DryRun, ReclaimableBytes: report.ReclaimableBytes, Purged: report.Purged}
+	for _, it := range report.Items {
+		item := &deusvmproto.GCItem{
+			Kind: it.Kind, Path: it.Path, Pool: it.Pool, Name: it.Name, SizeBytes: it.SizeBytes, Reason: it.Reason,
+			ReportOnly: it.ReportOnly, Quarantine: it.Quarantine, Error: it.Error,
+		}
+		if !it.ModTime.IsZero() {
+			item.ModTime = it.ModTime.Format(time.RFC3339)
+		}
+		out.Items = append(out.Items, item)
+	}
+	return out, nil
+}
+
+func poolReportToProto(p storage.PoolReport) *deusvmproto.PoolUsage {
+	return &deusvmproto.PoolUsage{
+		Name:             p.Name,
+		Type:             p.Type,
+		CapacityBytes:    p.CapacityBytes,
+		UsedBytes:        p.UsedBytes,
+		FreeBytes:        p.FreeBytes,
+		ProvisionedBytes: p.ProvisionedBytes,
+		Overcommit:       p.Overcommit,
+		HighWaterMark:    p.HighWaterMark,
+		MaxOvercommit:    p.MaxOvercommit,
+		Error:            p.Error,
+	}
+}
+
+type BackupServiceServer struct {
+	deusvmproto.UnimplementedBackupServiceServer
+	backups *backup.Service
+	vms     vmService
+}
+
+func NewBackupServiceServer(manager kvm.Manager, store storage.Manager, backups *backup.Service, networks *network.Service) *BackupServiceServer {
+	return &BackupServiceServer{backups: backups, vms: vmService{manager: manager, store: store, networks: networks}}
+}
+
+// Create starts a backup job for one VM.
+func (s *BackupServiceServer) Create(ctx context.Context, req *deusvmproto.CreateBackupRequest) (*deusvmproto.Job, error) {
+	job, err := s.backups.Start(ctx, req.GetVmId(), req.GetPolicy())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return jobToProto(job), nil
+}
+
+func (s *BackupServiceServer) List(ctx context.Context, req *deusvmproto.ListBackupsRequest) (*deusvmproto.ListBackupsResponse, error) {
+	bs, err := s.backups.List(ctx, req.GetTarget(), req.GetVmName())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	out := &deusvmproto.ListBackupsResponse{}
+	for _, b := range bs {
+		out.Backups = append(out.Backups, backupToProto(b))
+	}
+	return out, nil
+}
+
+func (s *BackupServiceServer) Get(ctx context.Context, req *deusvmproto.BackupIDRequest) (*deusvmproto.Backup, error) {
+	b, err := s.backups.Get(ctx, req.GetId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return backupToProto(b), nil
+}
+
+// Restore starts a job creating a new VM from a backup.
+func (s *BackupServiceServer) Restore(ctx context.Context, req *deusvmproto.RestoreBackupRequest) (*deusvmproto.Job, error) {
+	job, err := s.vms.startRestore(ctx, s.backups, req.GetId(), req.GetName(), req.GetPool())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return jobToProto(job), nil
+}
+
+func (s *BackupServiceServer) Delete(ctx context.Context, req *deusvmproto.BackupIDRequest) (*deusvmproto.Empty, error) {
+	if err := s.backups.Delete(ctx, req.GetId()); err != nil {
+		return nil, grpcError(err)
+	}
+	return &deusvmproto.Empty{}, nil
+}
+
+func backupToProto(b backup.Backup) *deusvmproto.Backup {
+	out := &deusvmproto.Backup{
+		Id:          b.ID,
+		Target:      b.Target,
+		VmId:        b.VM.ID,
+		VmName:      b.VM.Name,
+		Policy:      b.Policy,
+		Consistency: b.Consistency,
+		Quiesced:    b.Quiesced,
+		CreatedAt:   b.CreatedAt.Format(time.RFC3339),
+		SizeBytes:   b.SizeBytes,
+	}
+	for _, f := range b.Files {
+		out.Files = append(out.Files, &deusvmproto.BackupFile{
+			Name: f.Name, Kind: f.Kind, SizeBytes: f.SizeBytes, Sha256: f.SHA256, Volume: f.Volume, VirtualBytes: f.VirtualBytes,
+		})
+	}
+	return out
+}
+
+type NetworkServiceServer struct {
+	deusvmproto.UnimplementedNetworkServiceServer
+	manager kvm.Manager
+	vms     vmService
+}
+
+func NewNetworkServiceServer(manager kvm.Manager, store storage.Manager, networks *network.Service) *NetworkServiceServer {
+	return &NetworkServiceServer{manager: manager, vms: vmService{manager: manager, store: store, networks: networks}}
+}
+
+func (s *NetworkServiceServer) Create(ctx context.Context, req *deusvmproto.CreateNetworkRequest) (*deusvmproto.Network, error) {
+	n, err := s.manager.CreateNetwork(ctx, kvm.NetworkSpec{
+		Name: req.GetName(), Mode: req.GetMode(), Bridge: req.GetBridge(), Uplink: req.GetUplink(),
+		IPv4: subnetFromProto(req.GetIpv4()), IPv6: subnetFromProto(req.GetIpv6()),
+		ExternalDNS: req.GetExternalDns(), Inbound: bandwidthFromProto(req.GetInbound()), Outbound: bandwidthFromProto(req.GetOutbound()),
+		PXE: req.GetPxe(),
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return networkToProto(n), nil
+}
+
+func (s *NetworkServiceServer) Get(ctx context.Context, req *deusvmproto.NetworkNameRequest) (*deusvmproto.Network, error) {
+	n, err := s.manager.GetNetwork(ctx, req.GetName())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return networkToProto(n), nil
+}
+
+func (s *NetworkServiceServer) List(ctx context.Context, _ *deusvmproto.Empty) (*deusvmproto.ListNetworksResponse, error) {
+	nets, err := s.manager.ListNetworks(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	out := &deusvmproto.ListNetworksResponse{}
+	for _, n := range nets {
+		out.Networks = append(out.Networks, networkToProto(n))
+	}
+	return out, nil
+}
+
+// Delete removes a network no VM is connected to.
+func (s *NetworkServiceServer) Delete(ctx context.Context, req *deusvmproto.NetworkNameRequest) (*deusvmproto.Empty, error) {
+	if err := s.vms.deleteNetwork(ctx, req.GetName()); err != nil {
+		return nil, grpcError(err)
+	}
+	return &deusvmproto.Empty{}, nil
+}
+
+// ListAllocations reports the addresses reserved for VM NICs.
+func (s *NetworkServiceServer) ListAllocations(ctx context.Context, req *deusvmproto.ListAllocationsRequest) (*deusvmproto.ListAllocationsResponse, error) {
+	allocs, err := s.vms.allocations(ctx, req.GetNetwork())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	out := &deusvmproto.ListAllocationsResponse{}
+	for _, a := range allocs {
+		out.Allocations = append(out.Allocations, &deusvmproto.Allocation{
+			Network: a.Network, Ip: a.IP, Mac: a.MAC, VmId: a.VM, VmName: a.VMName, Static: a.Static,
+			CreatedAt: a.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return out, nil
+}
+
+func networkToProto(n kvm.Network) *deusvmproto.Network {
+	return &deusvmproto.Network{
+		Name: n.Name, Mode: n.Mode, Bridge: n.Bridge, Uplink: n.Uplink,
+		Ipv4: subnetToProto(n.IPv4), Ipv6: subnetToProto(n.IPv6),
+		Uuid: n.UUID, Active: n.Active, ExternalDns: n.ExternalDNS, Pxe: n.PXE,
+		Inbound: bandwidthToProto(n.Inbound), Outbound: bandwidthToProto(n.Outbound),
+	}
+}
+
+func subnetToProto(s *kvm.Subnet) *deusvmproto.Subnet {
+	if s == nil {
+		return nil
+	}
+	return &deusvmproto.Subnet{Cidr: s.CIDR, Gateway: s.Gateway, DhcpStart: s.DHCPStart, DhcpEnd: s.DHCPEnd}
+}
+
+func subnetFromProto(s *deusvmproto.Subnet) *kvm.Subnet {
+	if s == nil || s.GetCidr() == "" {
+		return nil
+	}
+	return &kvm.Subnet{CIDR: s.GetCidr(), Gateway: s.GetGateway(), DHCPStart: s.GetDhcpStart(), DHCPEnd: s.GetDhcpEnd()}
+}
+
+type SecurityGroupServiceServer struct {
+	deusvmproto.UnimplementedSecurityGroupServiceServer
+	networks *network.Service
+	vms      vmService
+}
+
+func NewSecurityGroupServiceServer(manager kvm.Manager, store storage.Manager, networks *network.Service) *SecurityGroupServiceServer {
+	return &SecurityGroupServiceServer{networks: networks, vms: vmService{manager: manager, store: store, networks: networks}}
+}
+
+func (s *SecurityGroupServiceServer) Create(ctx context.Context, req *deusvmproto.SecurityGroup) (*deusvmproto.SecurityGroup, error) {
+	g, err := s.networks.CreateSecurityGroup(ctx, groupFromProto(req))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return groupToProto(g), nil
+}
+
+func (s *SecurityGroupServiceServer) Get(ctx context.Context, req *deusvmproto.SecurityGroupNameRequest) (*deusvmproto.SecurityGroup, error) {
+	g, err := s.networks.GetSecurityGroup(req.GetName())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return groupToProto(g), nil
+}
+
+func (s *SecurityGroupServiceServer) List(ctx context.Context, _ *deusvmproto.Empty) (*deusvmproto.ListSecurityGroupsResponse, error) {
+	out := &deusvmproto.ListSecurityGroupsResponse{}
+	for _, g := range s.networks.ListSecurityGroups() {
+		out.Groups = append(out.Groups, groupToProto(g))
+	}
+	return out, nil
+}
+
+// Update replaces the rules of a group and applies them to its NICs.
+func (s *SecurityGroupServiceServer) Update(ctx context.Context, req *deusvmproto.SecurityGroup) (*deusvmproto.SecurityGroup, error) {
+	g, err := s.networks.UpdateSecurityGroup(ctx, groupFromProto(req))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return groupToProto(g), nil
+}
+
+// Delete removes a group no NIC is in and no rule refers to.
+func (s *SecurityGroupServiceServer) Delete(ctx context.Context, req *deusvmproto.SecurityGroupNameRequest) (*deusvmproto.Empty, error) {
+	if err := s.networks.DeleteSecurityGroup(ctx, req.GetName()); err != nil {
+		return nil, grpcError(err)
+	}
+	return &deusvmproto.Empty{}, nil
+}
+
+func (s *SecurityGroupServiceServer) SetNICGroups(ctx context.Context, req *deusvmproto.SetNICSecurityGroupsRequest) (*deusvmproto.VM, error) {
+	vm, err := s.vms.setNICGroups(ctx, req.GetVmId(), req.GetMac(), req.GetGroups())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return vmToProto(vm), nil
+}
+
+func groupToProto(g network.SecurityGroup) *deusvmproto.SecurityGroup {
+	out := &deusvmproto.SecurityGroup{Name: g.Name, Description: g.Description}
+	for _, r := range g.Rules {
+		out.Rules = append(out.Rules, &deusvmproto.SecurityRule{
+			Direction: r.Direction, Protocol: r.Protocol, PortFrom: int32(r.PortFrom), PortTo: int32(r.PortTo), Cidrs: r.CIDRs, Groups: r.Groups,
+		})
+	}
+	return out
+}
+
+func groupFromProto(g *deusvmproto.SecurityGroup) network.SecurityGroup {
+	out := network.SecurityGroup{Name: g.GetName(), Description: g.GetDescription()}
+	for _, r := range g.GetRules() {
+		out.Rules = append(out.Rules, network.Rule{
+			Direction: r.GetDirection(), Protocol: r.GetProtocol(), PortFrom: int(r.GetPortFrom()), PortTo: int(r.GetPortTo()), CIDRs: r.GetCidrs(), Groups: r.GetGroups(),
+		})
+	}
+	return out
+}
+
+type PortForwardServiceServer struct {
+	deusvmproto.UnimplementedPortForwardServiceServer
+	networks *network.Service
+	vms      vmService
+}
+
+func NewPortForwardServiceServer(manager kvm.Manager, store storage.Manager, networks *network.Service) *PortForwardServiceServer {
+	return &PortForwardServiceServer{networks: networks, vms: vmService{manager: manager, store: store, networks: networks}}
+}
+
+func (s *PortForwardServiceServer) Create(ctx context.Context, req *deusvmproto.PortForward) (*deusvmproto.PortForward, error) {
+	f, err := s.networks.CreateForward(ctx, network.Forward{
+		Name: req.GetName(), HostIP: req.GetHostIp(), HostPort: int(req.GetHostPort()), Protocol: req.GetProtocol(),
+		VM: req.GetVm(), Network: req.GetNetwork(), Port: int(req.GetPort()),
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return forwardToProto(f), nil
+}
+
+func (s *PortForwardServiceServer) Get(ctx context.Context, req *deusvmproto.PortForwardNameRequest) (*deusvmproto.PortForward, error) {
+	f, err := s.networks.GetForward(ctx, req.GetName())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return forwardToProto(f), nil
+}
+
+func (s *PortForwardServiceServer) List(ctx context.Context, req *deusvmproto.ListPortForwardsRequest) (*deusvmproto.ListPortForwardsResponse, error) {
+	forwards, err := s.vms.listForwards(ctx, req.GetVm())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	out := &deusvmproto.ListPortForwardsResponse{}
+	for _, f := range forwards {
+		out.Forwards = append(out.Forwards, forwardToProto(f))
+	}
+	return out, nil
+}
+
+func (s *PortForwardServiceServer) Delete(ctx context.Context, req *deusvmproto.PortForwardNameRequest) (*deusvmproto.Empty, error) {
+	if err := s.networks.DeleteForward(ctx, req.GetName()); err != nil {
+		return nil, grpcError(err)
+	}
+	return &deusvmproto.Empty{}, nil
+}
+
+func forwardToProto(f network.Forward) *deusvmproto.PortForward {
+	return &deusvmproto.PortForward{
+		Name: f.Name, HostIp: f.HostIP, HostPort: int32(f.HostPort), Protocol: f.Protocol,
+		Vm: f.VM, VmName: f.VMName, Network: f.Network, Port: int32(f.Port), Target: f.Target, Active: f.Active,
+	}
+}
+
+type BootServiceServer struct {
+	deusvmproto.UnimplementedBootServiceServer
+	networks *network.Service
+	vms      vmService
+}
+
+func NewBootServiceServer(manager kvm.Manager, store storage.Manager, networks *network.Service) *BootServiceServer {
+	return &BootServiceServer{networks: networks, vms: vmService{manager: manager, store: store, networks: networks}}
+}
+
+func (s *BootServiceServer) CreateTemplate(ctx context.Context, req *deusvmproto.BootTemplate) (*deusvmproto.BootTemplate, error) {
+	t, err := s.networks.CreateBootTemplate(templateFromProto(req))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return templateToProto(t), nil
+}
+
+func (s *BootServiceServer) GetTemplate(ctx context.Context, req *deusvmproto.BootTemplateNameRequest) (*deusvmproto.BootTemplate, error) {
+	t, err := s.networks.GetBootTemplate(req.GetName())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return templateToProto(t), nil
+}
+
+func (s *BootServiceServer) ListTemplates(ctx context.Context, _ *deusvmproto.Empty) (*deusvmproto.ListBootTemplatesResponse, error) {
+	out := &deusvmproto.ListBootTemplatesResponse{}
+	for _, t := range s.networks.ListBootTemplates() {
+		out.Templates = append(out.Templates, templateToProto(t))
+	}
+	return out, nil
+}
+
+func (s *BootServiceServer) UpdateTemplate(ctx context.Context, req *deusvmproto.BootTemplate) (*deusvmproto.BootTemplate, error) {
+	t, err := s.networks.UpdateBootTemplate(templateFromProto(req))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return templateToProto(t), nil
+}
+
+// DeleteTemplate removes a template no VM boots from.
+func (s *BootServiceServer) DeleteTemplate(ctx context.Context, req *deusvmproto.BootTemplateNameRequest) (*deusvmproto.Empty, error) {
+	if err := s.networks.DeleteBootTemplate(ctx, req.GetName()); err != nil {
+		return nil, grpcError(err)
+	}
+	return &deusvmproto.Empty{}, nil
+}
+
+// SetVMTemplate sets the template a VM boots from over the network, or the
+// default template when it is empty.
+func (s *BootServiceServer) SetVMTemplate(ctx context.Context, req *deusvmproto.SetVMBootTemplateRequest) (*deusvmproto.VM, error) {
+	vm, err := s.vms.setBootTemplate(ctx, req.GetId(), req.GetTemplate())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return vmToProto(vm), nil
+}
+
+func templateToProto(t network.BootTemplate) *deusvmproto.BootTemplate {
+	return &deusvmproto.BootTemplate{Name: t.Name, Description: t.Description, Script: t.Script}
+}
+
+func templateFromProto(t *deusvmproto.BootTemplate) network.BootTemplate {
+	return network.BootTemplate{Name: t.GetName(), Description: t.GetDescription(), Script: t.GetScript()}
+}
+
+type HostServiceServer struct {
+	deusvmproto.UnimplementedHostServiceServer
+}
+
+func NewHostServiceServer() *HostServiceServer { return &HostServiceServer{} }
+
+func (s *HostServiceServer) ListNetworkInterfaces(ctx context.Context, _ *deusvmproto.Empty) (*deusvmproto.ListNetworkInterfacesResponse, error) {
+	ifaces, err := host.ListInterfaces()
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	out := &deusvmproto.ListNetworkInterfacesResponse{}
+	for _, i := range ifaces {
+		out.Interfaces = append(out.Interfaces, &deusvmproto.HostInterface{
+			Name: i.Name, Index: int32(i.Index), Kind: i.Kind, Mac: i.MAC, Mtu: int32(i.MTU), State: i.State, OperState: i.OperState,
+			Master: i.Master, Ports: i.Ports, Parent: i.Parent, Vlan: int32(i.VLAN), BondMode: i.BondMode, Addresses: i.Addresses,
+		})
 	}
 	return out, nil
 }
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/riccardotacconi/deusvm/internal/kvm"
+	"github.com/riccardotacconi/deusvm/internal/network"
+)
+
+// errNetworkInUse is returned when deleting a network VMs are connected to.
+var errNetworkInUse = errors.New("network is in use")
+
+// deleteNetwork removes a managed network once no VM has a NIC on it.
+func (v vmService) deleteNetwork(ctx context.Context, name string) error {
+	if _, err := v.manager.GetNetwork(ctx, name); err != nil {
+		return err
+	}
+	vms, err := v.manager.ListVMs(ctx)
+	if err != nil {
+		return err
+	}
+	var users []string
+	for _, vm := range vms {
+		for _, nic := range vm.NICs {
+			if nic.Network == name {
+				users = append(users, vm.Name)
+				break
+			}
+		}
+	}
+	if len(users) > 0 {
+		return fmt.Errorf("%s: %w by %s", name, errNetworkInUse, strings.Join(users, ", "))
+	}
+	return v.manager.DeleteNetwork(ctx, name)
+}
+
+// allocations lists the addresses reserved on the named network, or on every
+// network when name is empty.
+func (v vmService) allocations(ctx context.Context, name string) ([]network.Allocation, error) {
+	if name != "" {
+		if _, err := v.manager.GetNetwork(ctx, name); err != nil {
+			return nil, err
+		}
+	}
+	return v.networks.Allocations(name), nil
+}
+
+// setNICGroups replaces the security groups of the NIC of a VM with the MAC
+// address mac.
+func (v vmService) setNICGroups(ctx context.Context, id, mac string, groups []string) (kvm.VM, error) {
+	vm, err := v.manager.GetVM(ctx, id)
+	if err != nil {
+		return kvm.VM{}, err
+	}
+	if hw, err := net.ParseMAC(mac); err == nil {
+		mac = hw.String()
+	}
+	if err := v.networks.SetSecurityGroups(ctx, vm, mac, groups); err != nil {
+		return kvm.VM{}, err
+	}
+	return v.get(ctx, id)
+}
+
+// setBootTemplate sets the boot template of the VM with ID or name id; an
+// empty template leaves it to the default one.
+func (v vmService) setBootTemplate(ctx context.Context, id, template string) (kvm.VM, error) {
+	vm, err := v.manager.GetVM(ctx, id)
+	if err != nil {
+		return kvm.VM{}, err
+	}
+	if err := v.networks.SetBootTemplate(vm.ID, template); err != nil {
+		return kvm.VM{}, err
+	}
+	return v.get(ctx, id)
+}
+
+// listForwards lists the port forwards to the VM with ID or name vm, or all
+// of them when it is empty.
+func (v vmService) listForwards(ctx context.Context, vm string) ([]network.Forward, error) {
+	if vm != "" {
+		found, err := v.manager.GetVM(ctx, vm)
+		if err != nil {
+			return nil, err
+		}
+		vm = found.ID
+	}
+	return v.networks.ListForwards(ctx, vm)
+}
+
+// attachNIC plugs a NIC into a VM, reserving its addresses and putting it in
+// its security groups. The NIC is plugged in with its link down and only
+// brought up once its DHCP leases and firewall rules are in place.
+func (v vmService) attachNIC(ctx context.Context, id string, nic kvm.NIC) (kvm.VM, error) {
+	vm, err := v.manager.GetVM(ctx, id)
+	if err != nil {
+		return kvm.VM{}, err
+	}
+	up := nic.LinkState != kvm.LinkDown
+	plug := nic
+	plug.LinkState = kvm.LinkDown
+	added, err := v.manager.AttachInterface(ctx, vm.ID, plug)
+	if err != nil {
+		return kvm.VM{}, err
+	}
+	if _, err := v.networks.Assign(ctx, kvm.VM{ID: vm.ID, Name: vm.Name, NICs: []kvm.NIC{added}}, []kvm.NIC{nic}); err != nil {
+		_ = v.manager.DetachInterface(ctx, vm.ID, added.MAC)
+		return kvm.VM{}, fmt.Errorf("assign addresses: %w", err)
+	}
+	if err := v.networks.SyncFirewall(ctx); err != nil {
+		return kvm.VM{}, fmt.Errorf("nic %s attached with its link down, without its security groups: %w", added.MAC, err)
+	}
+	if up {
+		if _, err := v.manager.UpdateInterface(ctx, vm.ID, kvm.NIC{MAC: added.MAC, LinkState: kvm.LinkUp}); err != nil {
+			return kvm.VM{}, fmt.Errorf("nic %s attached with its link down: %w", added.MAC, err)
+		}
+	}
+	return v.get(ctx, vm.ID)
+}
+
+// detachNIC unplugs the NIC of a VM with the MAC address mac and releases
+// its addresses and security groups.
+func (v vmService) detachNIC(ctx context.Context, id, mac string) (kvm.VM, error) {
+	vm, err := v.manager.GetVM(ctx, id)
+	if err != nil {
+		return kvm.VM{}, err
+	}
+	if hw, err := net.ParseMAC(mac); err == nil {
+		mac = hw.String()
+	}
+	if err := v.manager.DetachInterface(ctx, vm.ID, mac); err != nil {
+		return kvm.VM{}, err
+	}
+	if err := v.networks.ReleaseNIC(ctx, vm.ID, mac); err != nil {
+		return kvm.VM{}, fmt.Errorf("release addresses: %w", err)
+	}
+	_ = v.networks.SyncFirewall(ctx)
+	return v.get(ctx, vm.ID)
+}
+
+// updateNIC moves the NIC of a VM with the MAC address nic.MAC to another
+// network or bridge, if nic names one, and sets its link state, if given.
+// The NIC keeps its security groups. Addresses are reserved on the new
+// network before the move and the old ones released after it. A NIC that
+// moves has its link taken down until its firewall rules are updated, which
+// also makes most guests ask for a new DHCP lease.
+func (v vmService) updateNIC(ctx context.Context, id string, nic kvm.NIC) (kvm.VM, error) {
+	vm, err := v.get(ctx, id)
+	if err != nil {
+		return kvm.VM{}, err
+	}
+	if hw, err := net.ParseMAC(nic.MAC); err == nil {
+		nic.MAC = hw.String()
+	}
+	i := slices.IndexFunc(vm.NICs, func(n kvm.NIC) bool { return n.MAC == nic.MAC })
+	if i < 0 {
+		return kvm.VM{}, fmt.Errorf("%s: %w", nic.MAC, kvm.ErrNICNotFound)
+	}
+	cur := vm.NICs[i]
+	moving := (nic.Network != "" || nic.Bridge != "") && (nic.Network != cur.Network || nic.Bridge != cur.Bridge)
+	if !moving {
+		if nic.IPv4 != "" || nic.IPv6 != "" {
+			return kvm.VM{}, errors.New("fixed addresses can only be requested when moving a nic to a network")
+		}
+		if _, err := v.manager.UpdateInterface(ctx, vm.ID, nic); err != nil {
+			return kvm.VM{}, err
+		}
+		return v.get(ctx, vm.ID)
+	}
+	if nic.Network != "" {
+		moved := cur
+		moved.Network, moved.Bridge = nic.Network, ""
+		if _, err := v.networks.Reserve(ctx, kvm.VM{ID: vm.ID, Name: vm.Name, NICs: []kvm.NIC{moved}}, []kvm.NIC{nic}); err != nil {
+			return kvm.VM{}, fmt.Errorf("assign addresses: %w", err)
+		}
+	}
+	up := nic.LinkState != kvm.LinkDown && (nic.LinkState == kvm.LinkUp || cur.LinkState != kvm.LinkDown)
+	change := nic
+	change.LinkState = kvm.LinkDown
+	if _, err := v.manager.UpdateInterface(ctx, vm.ID, change); err != nil {
+		if nic.Network != "" {
+			_ = v.networks.ReleaseAddresses(ctx, vm.ID, nic.MAC, nic.Network)
+		}
+		return kvm.VM{}, err
+	}
+	if cur.Network != "" {
+		if err := v.networks.ReleaseAddresses(ctx, vm.ID, nic.MAC, cur.Network); err != nil {
+			return kvm.VM{}, fmt.Errorf("release addresses: %w", err)
+		}
+	}
+	if err := v.networks.SyncFirewall(ctx); err != nil {
+		return kvm.VM{}, fmt.Errorf("nic %s moved with its link down, without its security groups: %w", nic.MAC, err)
+	}
+	if up {
+		if _, err := v.manager.UpdateInterface(ctx, vm.ID, kvm.NIC{MAC: nic.MAC, LinkState: kvm.LinkUp}); err != nil {
+			return kvm.VM{}, fmt.Errorf("nic %s moved with its link down: %w", nic.MAC, err)
+		}
+	}
+	return v.get(ctx, vm.ID)
+}
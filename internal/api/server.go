@@ -2,12 +2,20 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/riccardotacconi/deusvm/internal/backup"
 	"github.com/riccardotacconi/deusvm/internal/config"
+	"github.com/riccardotacconi/deusvm/internal/host"
 	"github.com/riccardotacconi/deusvm/internal/kvm"
+	"github.com/riccardotacconi/deusvm/internal/network"
 	"github.com/riccardotacconi/deusvm/internal/storage"
 	"go.uber.org/zap"
 )
@@ -18,10 +26,14 @@ type Server struct {
 	cfg     config.Config
 	router  *chi.Mux
 	store   storage.Manager
+	vms     vmService
+	volumes volumeService
+	backups *backup.Service
 }
 
-func NewServer(logger *zap.Logger, manager kvm.Manager, store storage.Manager, cfg config.Config) *Server {
-	s := &Server{logger: logger, manager: manager, store: store, cfg: cfg}
+func NewServer(logger *zap.Logger, manager kvm.Manager, store storage.Manager, backups *backup.Service, networks *network.Service, cfg config.Config) *Server {
+	s := &Server{logger: logger, manager: manager, store: store, backups: backups, cfg: cfg, vms: vmService{manager: manager, store: store, networks: networks}}
+	s.volumes = volumeService{manager: manager, store: store}
 	s.router = chi.NewRouter()
 	s.router.Use(middleware.RequestID, middleware.RealIP, middleware.Recoverer)
 	if cfg.API.AuthToken != "" {
@@ -34,14 +46,82 @@ func NewServer(logger *zap.Logger, manager kvm.Manager, store storage.Manager, c
 			r.Get("/{id}", s.getVM)
 			r.Put("/{id}/start", s.startVM)
 			r.Put("/{id}/stop", s.stopVM)
+			r.Put("/{id}/media", s.insertMedia)
+			r.Post("/{id}/nics", s.attachNIC)
+			r.Put("/{id}/nics/{mac}", s.updateNIC)
+			r.Delete("/{id}/nics/{mac}", s.detachNIC)
+			r.Put("/{id}/nics/{mac}/security-groups", s.setNICGroups)
+			r.Put("/{id}/boot-template", s.setBootTemplate)
+			r.Delete("/{id}/media", s.ejectMedia)
+			r.Get("/{id}/export", s.exportVM)
+			r.Post("/import", s.importVM)
 			r.Delete("/{id}", s.deleteVM)
 		})
 
 		r.Route("/images", func(r chi.Router) {
 			r.Post("/", s.createImage)
+			r.Post("/stream", s.createImageStream)
+			r.Post("/capture", s.captureImage)
 			r.Get("/", s.listImages)
+			r.Put("/{name}", s.uploadImage)
+			r.Post("/{name}/tags", s.tagImage)
 			r.Delete("/{name}", s.deleteImage)
 		})
+
+		r.Route("/volumes", func(r chi.Router) {
+			r.Post("/", s.createVolume)
+			r.Get("/", s.listVolumes)
+			r.Get("/{name}", s.getVolume)
+			r.Delete("/{name}", s.deleteVolume)
+			r.Post("/{name}/attach", s.attachVolume)
+			r.Post("/{name}/detach", s.detachVolume)
+			r.Post("/{name}/resize", s.resizeVolume)
+			r.Post("/{name}/clone", s.cloneVolume)
+		})
+
+		r.Post("/storage/convert", s.convert)
+		r.Get("/storage/usage", s.storageUsage)
+		r.Get("/storage/gc", s.storageGC)
+		r.Post("/storage/gc", s.storageGC)
+		r.Route("/backups", func(r chi.Router) {
+			r.Post("/", s.createBackup)
+			r.Get("/", s.listBackups)
+			r.Get("/{target}/{vm}/{stamp}", s.getBackup)
+			r.Delete("/{target}/{vm}/{stamp}", s.deleteBackup)
+			r.Post("/{target}/{vm}/{stamp}/restore", s.restoreBackup)
+		})
+		r.Route("/networks", func(r chi.Router) {
+			r.Post("/", s.createNetwork)
+			r.Get("/", s.listNetworks)
+			r.Get("/{name}", s.getNetwork)
+			r.Get("/{name}/allocations", s.listAllocations)
+			r.Delete("/{name}", s.deleteNetwork)
+		})
+		r.Route("/security-groups", func(r chi.Router) {
+			r.Post("/", s.createSecurityGroup)
+			r.Get("/", s.listSecurityGroups)
+			r.Get("/{name}", s.getSecurityGroup)
+			r.Put("/{name}", s.updateSecurityGroup)
+			r.Delete("/{name}", s.deleteSecurityGroup)
+		})
+		r.Route("/boot-templates", func(r chi.Router) {
+			r.Post("/", s.createBootTemplate)
+			r.Get("/", s.listBootTemplates)
+			r.Get("/{name}", s.getBootTemplate)
+			r.Put("/{name}", s.updateBootTemplate)
+			r.Delete("/{name}", s.deleteBootTemplate)
+		})
+		r.Route("/port-forwards", func(r chi.Router) {
+			r.Post("/", s.createForward)
+			r.Get("/", s.listForwards)
+			r.Get("/{name}", s.getForward)
+			r.Delete("/{name}", s.deleteForward)
+		})
+		r.Get("/host/interfaces", s.listHostInterfaces)
+		r.Route("/jobs", func(r chi.Router) {
+			r.Get("/", s.listJobs)
+			r.Get("/{id}", s.getJob)
+		})
 	})
 	return s
 }
@@ -54,6 +134,16 @@ type createVMRequest struct {
 	CPU    int    `json:"cpu"`
 	Memory string `json:"memory"` // human string like 4GB
 	Disk   string `json:"disk"`   // human string like 20GB
+	Pool   string `json:"pool"`   // storage pool, default pool when empty
+	// ISO boots an installer with a blank disk of size Disk instead of Image.
+	ISO       string   `json:"iso"`
+	BootOrder []string `json:"boot_order"` // hd|cdrom|network
+	// Boot "network" boots from the first NIC before the disk, over the boot
+	// server with the BootTemplate script, or the default one when empty.
+	Boot         string `json:"boot"`
+	BootTemplate string `json:"boot_template"`
+	// NICs default to one on the configured bridge.
+	NICs []kvm.NIC `json:"nics"`
 }
 
 type vmResponse struct{ kvm.VM }
@@ -74,18 +164,20 @@ func (s *Server) createVM(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid disk")
 		return
 	}
-	vm, err := s.manager.CreateVM(r.Context(), kvm.CreateVMRequest{
+	vm, err := s.vms.create(r.Context(), kvm.CreateVMRequest{
 		Name: req.Name, CPU: req.CPU, MemoryBytes: mem, DiskBytes: disk, Image: req.Image,
-	})
+		CDROM: req.ISO, BootOrder: req.BootOrder, NICs: req.NICs,
+		Boot: req.Boot, BootTemplate: req.BootTemplate,
+	}, req.Pool)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 	writeJSON(w, http.StatusCreated, vmResponse{vm})
 }
 
 func (s *Server) listVMs(w http.ResponseWriter, r *http.Request) {
-	vms, err := s.manager.ListVMs(r.Context())
+	vms, err := s.vms.list(r.Context())
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -95,7 +187,7 @@ func (s *Server) listVMs(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) getVM(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	vm, err := s.manager.GetVM(r.Context(), id)
+	vm, err := s.vms.get(r.Context(), id)
 	if err != nil {
 		writeError(w, http.StatusNotFound, err.Error())
 		return
@@ -105,7 +197,7 @@ func (s *Server) getVM(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) startVM(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	if err := s.manager.StartVM(r.Context(), id); err != nil {
+	if err := s.vms.start(r.Context(), id); err != nil {
 		writeError(w, http.StatusNotFound, err.Error())
 		return
 	}
@@ -114,16 +206,90 @@ func (s *Server) startVM(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) stopVM(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	if err := s.manager.StopVM(r.Context(), id); err != nil {
+	if err := s.vms.stop(r.Context(), id); err != nil {
 		writeError(w, http.StatusNotFound, err.Error())
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]string{"status": "stopped"})
 }
 
+// exportVM streams the VM as a zstd-compressed tar archive. Once the archive
+// has started, a failure aborts the connection so the download is visibly
+// truncated.
+func (s *Server) exportVM(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	vm, err := s.manager.GetVM(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	cw := &countingWriter{w: w}
+	w.Header().Set("Content-Type", "application/zstd")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", vm.Name+".tar.zst"))
+	if _, err := s.backups.Export(r.Context(), vm.ID, cw); err != nil {
+		if cw.n > 0 {
+			panic(http.ErrAbortHandler)
+		}
+		w.Header().Del("Content-Disposition")
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+	}
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// importVM defines a VM from an archive in the request body, named after
+// the name query parameter or as in the archive, with its disks in pool.
+func (s *Server) importVM(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	vm, err := s.vms.importVM(r.Context(), s.backups, r.Body, q.Get("name"), q.Get("pool"))
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, vmResponse{vm})
+}
+
+type insertMediaRequest struct {
+	Image string `json:"image"` // ISO image name
+}
+
+func (s *Server) insertMedia(w http.ResponseWriter, r *http.Request) {
+	var req insertMediaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.Image == "" {
+		writeError(w, http.StatusBadRequest, "image required")
+		return
+	}
+	if err := s.vms.insertMedia(r.Context(), chi.URLParam(r, "id"), req.Image); err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "inserted"})
+}
+
+func (s *Server) ejectMedia(w http.ResponseWriter, r *http.Request) {
+	if err := s.vms.ejectMedia(r.Context(), chi.URLParam(r, "id")); err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ejected"})
+}
+
 func (s *Server) deleteVM(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	if err := s.manager.DeleteVM(r.Context(), id); err != nil {
+	if err := s.vms.delete(r.Context(), id); err != nil {
 		writeError(w, http.StatusNotFound, err.Error())
 		return
 	}
@@ -209,9 +375,145 @@ func (s *Server) createImage(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "name and source required")
 		return
 	}
-	img, err := s.store.SaveImageFromURL(r.Context(), req.Name, req.Source)
+	img, err := s.store.SaveImageFromURL(r.Context(), req.Name, req.Source, nil)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, img)
+}
+
+// createImageStream is createImage reporting download progress as
+// server-sent events: "progress" while transferring, then "done" with the
+// image or "error".
+func (s *Server) createImageStream(w http.ResponseWriter, r *http.Request) {
+	var req createImageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.Name == "" || req.Source == "" {
+		writeError(w, http.StatusBadRequest, "name and source required")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	send := func(event string, v any) {
+		b, _ := json.Marshal(v)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+		flusher.Flush()
+	}
+	img, err := s.store.SaveImageFromURL(r.Context(), req.Name, req.Source, func(p storage.Progress) {
+		send("progress", p)
+	})
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		send("error", map[string]string{"error": err.Error()})
+		return
+	}
+	send("done", img)
+}
+
+// uploadImage stores the request body as an image. The body is either the raw
+// image or multipart/form-data with the image in a "file" part. The expected
+// sha256 is required, in the X-Checksum-Sha256 header, the sha256 query
+// parameter or a "sha256" form field placed before the file.
+func (s *Server) uploadImage(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	checksum := r.Header.Get("X-Checksum-Sha256")
+	if checksum == "" {
+		checksum = r.URL.Query().Get("sha256")
+	}
+	body := io.Reader(r.Body)
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "multipart/form-data" {
+		mr, err := r.MultipartReader()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		body = nil
+		for body == nil {
+			part, err := mr.NextPart()
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "multipart body has no file part")
+				return
+			}
+			switch part.FormName() {
+			case "file":
+				body = part
+			case "sha256":
+				b, _ := io.ReadAll(io.LimitReader(part, 128))
+				if checksum == "" {
+					checksum = strings.TrimSpace(string(b))
+				}
+			}
+		}
+	}
+	up, err := s.store.BeginUpload(name)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	if _, err := io.Copy(up, body); err != nil {
+		up.Abort()
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	img, err := up.Commit(checksum)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, img)
+}
+
+type tagImageRequest struct {
+	Name string `json:"name"`
+}
+
+func (s *Server) tagImage(w http.ResponseWriter, r *http.Request) {
+	var req tagImageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "name required")
+		return
+	}
+	img, err := s.store.TagImage(r.Context(), chi.URLParam(r, "name"), req.Name)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, img)
+}
+
+type captureImageRequest struct {
+	VM       string `json:"vm"` // id or name
+	Name     string `json:"name"`
+	Sparsify bool   `json:"sparsify"`
+}
+
+// captureImage stores the boot disk of a VM as a new image.
+func (s *Server) captureImage(w http.ResponseWriter, r *http.Request) {
+	var req captureImageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.VM == "" || req.Name == "" {
+		writeError(w, http.StatusBadRequest, "vm and name required")
+		return
+	}
+	img, err := s.vms.capture(r.Context(), req.VM, req.Name, req.Sparsify)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 	writeJSON(w, http.StatusCreated, img)
@@ -232,9 +534,565 @@ func (s *Server) deleteImage(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "name required")
 		return
 	}
-	if err := s.store.DeleteImage(r.Context(), name); err != nil {
-		writeError(w, http.StatusNotFound, err.Error())
+	force := r.URL.Query().Get("force") == "true"
+	if err := s.store.DeleteImage(r.Context(), name, force); err != nil {
+		writeError(w, httpStatus(err, http.StatusNotFound), err.Error())
 		return
 	}
 	writeJSON(w, http.StatusNoContent, nil)
 }
+
+type createVolumeRequest struct {
+	Name   string `json:"name"`
+	Pool   string `json:"pool"`
+	Size   string `json:"size"` // human string like 20GB; optional with image
+	Format string `json:"format"`
+	Image  string `json:"image"`
+}
+
+func (s *Server) createVolume(w http.ResponseWriter, r *http.Request) {
+	var req createVolumeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	var size int64
+	if req.Size != "" {
+		var err error
+		if size, err = parseSize(req.Size); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid size")
+			return
+		}
+	}
+	vol, err := s.store.CreateVolume(r.Context(), storage.VolumeSpec{
+		Name: req.Name, Pool: req.Pool, SizeBytes: size, Format: req.Format, Image: req.Image,
+	})
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, vol)
+}
+
+func (s *Server) listVolumes(w http.ResponseWriter, r *http.Request) {
+	vols, err := s.store.ListVolumes(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, vols)
+}
+
+func (s *Server) getVolume(w http.ResponseWriter, r *http.Request) {
+	vol, err := s.store.GetVolume(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, vol)
+}
+
+func (s *Server) deleteVolume(w http.ResponseWriter, r *http.Request) {
+	if err := s.store.DeleteVolume(r.Context(), chi.URLParam(r, "name")); err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+type attachVolumeRequest struct {
+	VM string `json:"vm"` // VM id or name
+}
+
+func (s *Server) attachVolume(w http.ResponseWriter, r *http.Request) {
+	var req attachVolumeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.VM == "" {
+		writeError(w, http.StatusBadRequest, "vm required")
+		return
+	}
+	vol, err := s.volumes.attach(r.Context(), chi.URLParam(r, "name"), req.VM)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, vol)
+}
+
+func (s *Server) detachVolume(w http.ResponseWriter, r *http.Request) {
+	vol, err := s.volumes.detach(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, vol)
+}
+
+type resizeVolumeRequest struct {
+	Size string `json:"size"` // human string like 40GB
+}
+
+func (s *Server) resizeVolume(w http.ResponseWriter, r *http.Request) {
+	var req resizeVolumeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	size, err := parseSize(req.Size)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid size")
+		return
+	}
+	vol, err := s.volumes.resize(r.Context(), chi.URLParam(r, "name"), size)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, vol)
+}
+
+type cloneVolumeRequest struct {
+	Name string `json:"name"`
+	Pool string `json:"pool"`
+	Size string `json:"size"` // optional, to grow the clone
+}
+
+func (s *Server) cloneVolume(w http.ResponseWriter, r *http.Request) {
+	var req cloneVolumeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	var size int64
+	if req.Size != "" {
+		var err error
+		if size, err = parseSize(req.Size); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid size")
+			return
+		}
+	}
+	vol, err := s.volumes.clone(r.Context(), chi.URLParam(r, "name"), storage.VolumeSpec{Name: req.Name, Pool: req.Pool, SizeBytes: size})
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, vol)
+}
+
+type convertRequest struct {
+	Image    string `json:"image"`
+	Volume   string `json:"volume"`
+	Name     string `json:"name"`
+	Format   string `json:"format"`
+	Compress bool   `json:"compress"`
+}
+
+// convert starts a format conversion job; poll /jobs/{id} for its progress.
+func (s *Server) convert(w http.ResponseWriter, r *http.Request) {
+	var req convertRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	job, err := s.store.Convert(r.Context(), storage.ConvertSpec{
+		Image: req.Image, Volume: req.Volume, Name: req.Name, Format: req.Format, Compress: req.Compress,
+	})
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// storageUsage reports pool capacity, overcommit and per-image and per-VM
+// allocation.
+func (s *Server) storageUsage(w http.ResponseWriter, r *http.Request) {
+	u, err := s.vms.usage(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+// storageGC reports what garbage collection would remove on GET and
+// collects it into quarantine on POST. min_age overrides the configured
+// minimum age, e.g. ?min_age=1h.
+func (s *Server) storageGC(w http.ResponseWriter, r *http.Request) {
+	var minAge time.Duration
+	if v := r.URL.Query().Get("min_age"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid min_age")
+			return
+		}
+		minAge = d
+	}
+	report, err := s.vms.gc(r.Context(), r.Method == http.MethodGet, minAge)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+type createBackupRequest struct {
+	VM     string `json:"vm"` // id or name
+	Policy string `json:"policy"`
+}
+
+// createBackup starts a backup job for one VM and answers 202 with the job.
+func (s *Server) createBackup(w http.ResponseWriter, r *http.Request) {
+	var req createBackupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	job, err := s.backups.Start(r.Context(), req.VM, req.Policy)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// listBackups lists complete backups, optionally filtered by ?target= and ?vm=.
+func (s *Server) listBackups(w http.ResponseWriter, r *http.Request) {
+	bs, err := s.backups.List(r.Context(), r.URL.Query().Get("target"), r.URL.Query().Get("vm"))
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, bs)
+}
+
+func backupID(r *http.Request) string {
+	return chi.URLParam(r, "target") + "/" + chi.URLParam(r, "vm") + "/" + chi.URLParam(r, "stamp")
+}
+
+func (s *Server) getBackup(w http.ResponseWriter, r *http.Request) {
+	b, err := s.backups.Get(r.Context(), backupID(r))
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (s *Server) deleteBackup(w http.ResponseWriter, r *http.Request) {
+	if err := s.backups.Delete(r.Context(), backupID(r)); err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+type restoreBackupRequest struct {
+	Name string `json:"name"` // <vm>-restored when empty
+	Pool string `json:"pool"`
+}
+
+// restoreBackup starts a job creating a new VM from a backup.
+func (s *Server) restoreBackup(w http.ResponseWriter, r *http.Request) {
+	var req restoreBackupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	job, err := s.vms.startRestore(r.Context(), s.backups, backupID(r), req.Name, req.Pool)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func (s *Server) listJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := s.store.ListJobs(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+func (s *Server) getJob(w http.ResponseWriter, r *http.Request) {
+	job, err := s.store.GetJob(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+func (s *Server) createNetwork(w http.ResponseWriter, r *http.Request) {
+	var spec kvm.NetworkSpec
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	n, err := s.manager.CreateNetwork(r.Context(), spec)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, n)
+}
+
+func (s *Server) listNetworks(w http.ResponseWriter, r *http.Request) {
+	nets, err := s.manager.ListNetworks(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, nets)
+}
+
+func (s *Server) getNetwork(w http.ResponseWriter, r *http.Request) {
+	n, err := s.manager.GetNetwork(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, n)
+}
+
+func (s *Server) listAllocations(w http.ResponseWriter, r *http.Request) {
+	allocs, err := s.vms.allocations(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, allocs)
+}
+
+func (s *Server) deleteNetwork(w http.ResponseWriter, r *http.Request) {
+	if err := s.vms.deleteNetwork(r.Context(), chi.URLParam(r, "name")); err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+func (s *Server) createSecurityGroup(w http.ResponseWriter, r *http.Request) {
+	var g network.SecurityGroup
+	if err := json.NewDecoder(r.Body).Decode(&g); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	g, err := s.vms.networks.CreateSecurityGroup(r.Context(), g)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, g)
+}
+
+func (s *Server) listSecurityGroups(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.vms.networks.ListSecurityGroups())
+}
+
+func (s *Server) getSecurityGroup(w http.ResponseWriter, r *http.Request) {
+	g, err := s.vms.networks.GetSecurityGroup(chi.URLParam(r, "name"))
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, g)
+}
+
+func (s *Server) updateSecurityGroup(w http.ResponseWriter, r *http.Request) {
+	var g network.SecurityGroup
+	if err := json.NewDecoder(r.Body).Decode(&g); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	g.Name = chi.URLParam(r, "name")
+	g, err := s.vms.networks.UpdateSecurityGroup(r.Context(), g)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, g)
+}
+
+func (s *Server) deleteSecurityGroup(w http.ResponseWriter, r *http.Request) {
+	if err := s.vms.networks.DeleteSecurityGroup(r.Context(), chi.URLParam(r, "name")); err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+func (s *Server) setNICGroups(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Groups []string `json:"groups"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	vm, err := s.vms.setNICGroups(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "mac"), req.Groups)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, vm)
+}
+
+func (s *Server) createBootTemplate(w http.ResponseWriter, r *http.Request) {
+	var t network.BootTemplate
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	t, err := s.vms.networks.CreateBootTemplate(t)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, t)
+}
+
+func (s *Server) listBootTemplates(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.vms.networks.ListBootTemplates())
+}
+
+func (s *Server) getBootTemplate(w http.ResponseWriter, r *http.Request) {
+	t, err := s.vms.networks.GetBootTemplate(chi.URLParam(r, "name"))
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) updateBootTemplate(w http.ResponseWriter, r *http.Request) {
+	var t network.BootTemplate
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	t.Name = chi.URLParam(r, "name")
+	t, err := s.vms.networks.UpdateBootTemplate(t)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) deleteBootTemplate(w http.ResponseWriter, r *http.Request) {
+	if err := s.vms.networks.DeleteBootTemplate(r.Context(), chi.URLParam(r, "name")); err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+func (s *Server) setBootTemplate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Template string `json:"template"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	vm, err := s.vms.setBootTemplate(r.Context(), chi.URLParam(r, "id"), req.Template)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, vm)
+}
+
+func (s *Server) attachNIC(w http.ResponseWriter, r *http.Request) {
+	var nic kvm.NIC
+	if err := json.NewDecoder(r.Body).Decode(&nic); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	vm, err := s.vms.attachNIC(r.Context(), chi.URLParam(r, "id"), nic)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, vmResponse{vm})
+}
+
+func (s *Server) updateNIC(w http.ResponseWriter, r *http.Request) {
+	var nic kvm.NIC
+	if err := json.NewDecoder(r.Body).Decode(&nic); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	nic.MAC = chi.URLParam(r, "mac")
+	vm, err := s.vms.updateNIC(r.Context(), chi.URLParam(r, "id"), nic)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, vmResponse{vm})
+}
+
+func (s *Server) detachNIC(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.vms.detachNIC(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "mac")); err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+func (s *Server) createForward(w http.ResponseWriter, r *http.Request) {
+	var f network.Forward
+	if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	f, err := s.vms.networks.CreateForward(r.Context(), f)
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, f)
+}
+
+func (s *Server) listForwards(w http.ResponseWriter, r *http.Request) {
+	forwards, err := s.vms.listForwards(r.Context(), r.URL.Query().Get("vm"))
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, forwards)
+}
+
+func (s *Server) getForward(w http.ResponseWriter, r *http.Request) {
+	f, err := s.vms.networks.GetForward(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, f)
+}
+
+func (s *Server) deleteForward(w http.ResponseWriter, r *http.Request) {
+	if err := s.vms.networks.DeleteForward(r.Context(), chi.URLParam(r, "name")); err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// listHostInterfaces reports the host's NICs, bridges, bonds and VLAN
+// subinterfaces with their addresses.
+func (s *Server) listHostInterfaces(w http.ResponseWriter, r *http.Request) {
+	ifaces, err := host.ListInterfaces()
+	if err != nil {
+		writeError(w, httpStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, ifaces)
+}
@@ -0,0 +1,373 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/riccardotacconi/deusvm/internal/kvm"
+	"github.com/riccardotacconi/deusvm/internal/network"
+	"github.com/riccardotacconi/deusvm/internal/storage"
+)
+
+// errVMRunning is returned for operations that need a stopped VM.
+var errVMRunning = errors.New("vm is running")
+
+// errVMExists is returned when restoring or importing onto a VM name in use.
+var errVMExists = errors.New("vm already exists")
+
+// vmService coordinates the VM manager with storage so that the REST and
+// gRPC front ends behave the same.
+type vmService struct {
+	manager  kvm.Manager
+	store    storage.Manager
+	networks *network.Service
+}
+
+func vmRef(vm kvm.VM) storage.Ref { return storage.Ref{Kind: "vm", ID: vm.ID, Name: vm.Name} }
+
+// cdromRef is the reference a VM holds on the ISO in its CD-ROM drive.
+func cdromRef(vm kvm.VM) storage.Ref { return storage.Ref{Kind: "cdrom", ID: vm.ID, Name: vm.Name} }
+
+// create clones the image into a root volume in pool (the default pool when
+// empty) and defines the VM on it. VMs booting an installer ISO from req.CDROM
+// or from the network without an image get a blank root volume of
+// req.DiskBytes instead. NICs on managed networks get addresses reserved, the
+// ones in req.NICs when set, which a cloud-init seed in the CD-ROM drive
+// passes to the guest unless req.CDROM takes the drive.
+func (v vmService) create(ctx context.Context, req kvm.CreateVMRequest, pool string) (kvm.VM, error) {
+	if req.BootTemplate != "" {
+		if _, err := v.networks.GetBootTemplate(req.BootTemplate); err != nil {
+			return kvm.VM{}, err
+		}
+	}
+	if req.CDROM != "" {
+		path, err := v.isoPath(ctx, req.CDROM)
+		if err != nil {
+			return kvm.VM{}, err
+		}
+		req.CDROM = path
+	}
+	var root *storage.Volume
+	if (req.Image != "" || req.CDROM != "" || req.Boot == kvm.BootNetwork) && len(req.Disks) == 0 {
+		vol, err := v.store.CreateVolume(ctx, storage.VolumeSpec{
+			Name:         req.Name + "-root",
+			Pool:         pool,
+			SizeBytes:    req.DiskBytes,
+			Image:        req.Image,
+			DeleteWithVM: true,
+		})
+		if err != nil {
+			return kvm.VM{}, fmt.Errorf("create root volume: %w", err)
+		}
+		root = &vol
+		req.Disks = []kvm.Disk{volumeDisk(vol)}
+	}
+	vm, err := v.manager.CreateVM(ctx, req)
+	if err != nil {
+		if root != nil {
+			_ = v.store.DeleteVolume(ctx, root.Name)
+		}
+		return kvm.VM{}, err
+	}
+	undo := func() {
+		_ = v.manager.DeleteVM(ctx, vm.ID)
+		if root != nil {
+			_ = v.store.SetVolumeAttachment(ctx, root.Name, "")
+			_ = v.store.DeleteVolume(ctx, root.Name)
+		}
+	}
+	if root != nil {
+		if err := v.store.SetVolumeAttachment(ctx, root.Name, vm.ID); err != nil {
+			undo()
+			return kvm.VM{}, fmt.Errorf("attach root volume: %w", err)
+		}
+	}
+	if err := v.store.AddImageRef(ctx, req.Image, vmRef(vm)); err != nil {
+		undo()
+		return kvm.VM{}, fmt.Errorf("record image use: %w", err)
+	}
+	if err := v.store.AddImageRef(ctx, req.CDROM, cdromRef(vm)); err != nil {
+		undo()
+		_ = v.store.ReleaseImageRefs(ctx, vmRef(vm))
+		return kvm.VM{}, fmt.Errorf("record image use: %w", err)
+	}
+	if _, err := v.networks.Assign(ctx, vm, req.NICs); err != nil {
+		undo()
+		_ = v.store.ReleaseImageRefs(ctx, vmRef(vm))
+		_ = v.store.ReleaseImageRefs(ctx, cdromRef(vm))
+		return kvm.VM{}, fmt.Errorf("assign addresses: %w", err)
+	}
+	if err := v.networks.SetBootTemplate(vm.ID, req.BootTemplate); err != nil {
+		undo()
+		_ = v.networks.Release(ctx, vm.ID)
+		_ = v.store.ReleaseImageRefs(ctx, vmRef(vm))
+		_ = v.store.ReleaseImageRefs(ctx, cdromRef(vm))
+		return kvm.VM{}, fmt.Errorf("set boot template: %w", err)
+	}
+	if req.CDROM == "" {
+		if err := v.attachSeed(ctx, &vm, pool); err != nil {
+			undo()
+			_ = v.networks.Release(ctx, vm.ID)
+			_ = v.store.ReleaseImageRefs(ctx, vmRef(vm))
+			return kvm.VM{}, fmt.Errorf("attach cloud-init seed: %w", err)
+		}
+	}
+	v.networks.Annotate(&vm)
+	// the periodic sync retries and logs failures; the VM is not running yet
+	_ = v.networks.SyncFirewall(ctx)
+	return vm, nil
+}
+
+// attachSeed puts a cloud-init NoCloud seed into the empty CD-ROM drive of
+// vm, with the addresses reserved for its NICs as its network-config. VMs
+// without reserved addresses get none, and neither do VMs on hosts without
+// genisoimage.
+func (v vmService) attachSeed(ctx context.Context, vm *kvm.VM, pool string) error {
+	netcfg, err := v.networks.NetworkConfig(ctx, *vm)
+	if err != nil || netcfg == nil {
+		return err
+	}
+	path, err := v.store.CreateSeed(ctx, storage.SeedSpec{
+		Name: vm.Name,
+		Pool: pool,
+		Files: map[string][]byte{
+			"meta-data":      fmt.Appendf(nil, "instance-id: %s\nlocal-hostname: %s\n", vm.ID, vm.Name),
+			"user-data":      []byte("#cloud-config\n"),
+			"network-config": netcfg,
+		},
+	})
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := v.manager.ChangeMedia(ctx, vm.ID, path); err != nil {
+		_ = v.store.DeleteSeed(ctx, path)
+		return err
+	}
+	vm.CDROM = path
+	return nil
+}
+
+// start boots a VM and applies the security groups of its NICs to the tap
+// devices it got.
+func (v vmService) start(ctx context.Context, id string) error {
+	if err := v.manager.StartVM(ctx, id); err != nil {
+		return err
+	}
+	if err := v.networks.SyncFirewall(ctx); err != nil {
+		return fmt.Errorf("vm started without its security groups: %w", err)
+	}
+	return nil
+}
+
+func (v vmService) stop(ctx context.Context, id string) error {
+	if err := v.manager.StopVM(ctx, id); err != nil {
+		return err
+	}
+	_ = v.networks.SyncFirewall(ctx)
+	return nil
+}
+
+// get returns a VM with the addresses reserved for its NICs.
+func (v vmService) get(ctx context.Context, id string) (kvm.VM, error) {
+	vm, err := v.manager.GetVM(ctx, id)
+	if err != nil {
+		return kvm.VM{}, err
+	}
+	v.networks.Annotate(&vm)
+	return vm, nil
+}
+
+func (v vmService) list(ctx context.Context) ([]kvm.VM, error) {
+	vms, err := v.manager.ListVMs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range vms {
+		v.networks.Annotate(&vms[i])
+	}
+	return vms, nil
+}
+
+// isoPath resolves an ISO image by name, or any existing absolute path, to
+// the file to put in a CD-ROM drive.
+func (v vmService) isoPath(ctx context.Context, image string) (string, error) {
+	img, err := v.store.GetImage(ctx, image)
+	if err != nil {
+		if filepath.IsAbs(image) {
+			if _, serr := os.Stat(image); serr == nil {
+				return image, nil
+			}
+		}
+		return "", err
+	}
+	if img.Format != "iso" {
+		return "", fmt.Errorf("image %s is %s, not an ISO", img.Name, img.Format)
+	}
+	return img.Path, nil
+}
+
+// insertMedia puts an ISO image into the CD-ROM drive of a VM, replacing any
+// media in it.
+func (v vmService) insertMedia(ctx context.Context, id, image string) error {
+	vm, err := v.manager.GetVM(ctx, id)
+	if err != nil {
+		return err
+	}
+	path, err := v.isoPath(ctx, image)
+	if err != nil {
+		return err
+	}
+	if err := v.manager.ChangeMedia(ctx, vm.ID, path); err != nil {
+		return err
+	}
+	if err := v.store.ReleaseImageRefs(ctx, cdromRef(vm)); err != nil {
+		return err
+	}
+	return v.store.AddImageRef(ctx, path, cdromRef(vm))
+}
+
+func (v vmService) ejectMedia(ctx context.Context, id string) error {
+	vm, err := v.manager.GetVM(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := v.manager.ChangeMedia(ctx, vm.ID, ""); err != nil {
+		return err
+	}
+	return v.store.ReleaseImageRefs(ctx, cdromRef(vm))
+}
+
+// delete removes the VM with the volumes created for it and its cloud-init
+// seed, detaches the rest, and releases its addresses.
+func (v vmService) delete(ctx context.Context, id string) error {
+	vm, err := v.manager.GetVM(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := v.manager.DeleteVM(ctx, id); err != nil {
+		return err
+	}
+	if err := v.networks.Release(ctx, vm.ID); err != nil {
+		return fmt.Errorf("release addresses: %w", err)
+	}
+	_ = v.networks.SyncFirewall(ctx)
+	vols, err := v.store.ListVolumes(ctx)
+	if err != nil {
+		return err
+	}
+	for _, vol := range vols {
+		if vol.VM != vm.ID {
+			continue
+		}
+		if err := v.store.SetVolumeAttachment(ctx, vol.Name, ""); err != nil {
+			return err
+		}
+		if vol.DeleteWithVM {
+			if err := v.store.DeleteVolume(ctx, vol.Name); err != nil {
+				return fmt.Errorf("delete volume %s: %w", vol.Name, err)
+			}
+		}
+	}
+	if err := v.store.DeleteSeed(ctx, vm.CDROM); err != nil {
+		return err
+	}
+	if err := v.store.ReleaseImageRefs(ctx, cdromRef(vm)); err != nil {
+		return err
+	}
+	return v.store.ReleaseImageRefs(ctx, vmRef(vm))
+}
+
+// capture stores the boot disk of a VM as a new image. A running VM must have
+// its filesystems frozen by the guest agent, which keeps them frozen while
+// the disk is copied; without an agent the VM has to be stopped first.
+func (v vmService) capture(ctx context.Context, id, name string, sparsify bool) (storage.Image, error) {
+	vm, err := v.manager.GetVM(ctx, id)
+	if err != nil {
+		return storage.Image{}, err
+	}
+	if len(vm.Disks) == 0 {
+		return storage.Image{}, fmt.Errorf("vm %s has no disk to capture", vm.Name)
+	}
+	disk := vm.Disks[0]
+	spec := storage.CaptureSpec{
+		Name:     name,
+		Path:     disk.Path,
+		Format:   disk.Format,
+		Sparsify: sparsify,
+		Lineage:  storage.Lineage{SourceVM: vm.Name, SourceDisk: disk.Path, Parent: vm.Image},
+	}
+	vols, err := v.store.ListVolumes(ctx)
+	if err != nil {
+		return storage.Image{}, err
+	}
+	for _, vol := range vols {
+		if vol.Path == disk.Path {
+			spec.Lineage.SourceDisk, spec.Lineage.Parent = vol.Name, vol.Image
+			break
+		}
+	}
+	if vm.Status == kvm.VMStatusRunning {
+		if err := v.manager.FreezeFilesystems(ctx, vm.ID); err != nil {
+			return storage.Image{}, fmt.Errorf("%w and its filesystems could not be frozen, stop it or run the guest agent: %v", errVMRunning, err)
+		}
+		defer func() { _ = v.manager.ThawFilesystems(context.WithoutCancel(ctx), vm.ID) }()
+		spec.Shared = true
+	}
+	return v.store.CaptureImage(ctx, spec)
+}
+
+// usage reports storage consumption with the names of the VMs using it.
+func (v vmService) usage(ctx context.Context) (storage.Usage, error) {
+	u, err := v.store.Usage(ctx)
+	if err != nil {
+		return storage.Usage{}, err
+	}
+	for i := range u.VMs {
+		if vm, err := v.manager.GetVM(ctx, u.VMs[i].VM); err == nil {
+			u.VMs[i].Name = vm.Name
+		}
+	}
+	return u, nil
+}
+
+func volumeDisk(vol storage.Volume) kvm.Disk {
+	return kvm.Disk{Path: vol.Path, Format: vol.Format, Block: vol.Block}
+}
+
+// SyncImageRefs records the images used by existing VMs, including ISOs in
+// their CD-ROM drives and domains defined before references were tracked,
+// and drops the references of VMs that are gone, such as those deleted with
+// virsh or while the daemon was down.
+func SyncImageRefs(ctx context.Context, manager kvm.Manager, store storage.Manager) error {
+	vms, err := manager.ListVMs(ctx)
+	if err != nil {
+		return err
+	}
+	ids := make(map[string]bool, len(vms))
+	for _, vm := range vms {
+		ids[vm.ID] = true
+	}
+	if err := store.ReleaseStaleImageRefs(ctx, ids); err != nil {
+		return err
+	}
+	for _, vm := range vms {
+		if vm.Image != "" {
+			if err := store.AddImageRef(ctx, vm.Image, vmRef(vm)); err != nil {
+				return err
+			}
+		}
+		if vm.CDROM != "" {
+			if err := store.AddImageRef(ctx, vm.CDROM, cdromRef(vm)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
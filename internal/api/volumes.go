@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/riccardotacconi/deusvm/internal/kvm"
+	"github.com/riccardotacconi/deusvm/internal/storage"
+)
+
+// errVolumeNotAttached is returned when detaching a volume that is not attached.
+var errVolumeNotAttached = errors.New("volume is not attached")
+
+// volumeService keeps volume attachments in storage and the VM definitions
+// in sync for the REST and gRPC front ends.
+type volumeService struct {
+	manager kvm.Manager
+	store   storage.Manager
+}
+
+// attach attaches a volume to the VM given by id or name.
+func (v volumeService) attach(ctx context.Context, name, vmID string) (storage.Volume, error) {
+	vol, err := v.store.GetVolume(ctx, name)
+	if err != nil {
+		return storage.Volume{}, err
+	}
+	vm, err := v.manager.GetVM(ctx, vmID)
+	if err != nil {
+		return storage.Volume{}, err
+	}
+	if vol.VM == vm.ID {
+		return vol, nil
+	}
+	if err := v.store.SetVolumeAttachment(ctx, name, vm.ID); err != nil {
+		return storage.Volume{}, err
+	}
+	if err := v.manager.AttachDisk(ctx, vm.ID, volumeDisk(vol)); err != nil {
+		_ = v.store.SetVolumeAttachment(ctx, name, "")
+		return storage.Volume{}, err
+	}
+	vol.VM = vm.ID
+	return vol, nil
+}
+
+func (v volumeService) detach(ctx context.Context, name string) (storage.Volume, error) {
+	vol, err := v.store.GetVolume(ctx, name)
+	if err != nil {
+		return storage.Volume{}, err
+	}
+	if vol.VM == "" {
+		return storage.Volume{}, fmt.Errorf("%s: %w", name, errVolumeNotAttached)
+	}
+	if err := v.manager.DetachDisk(ctx, vol.VM, vol.Path); err != nil {
+		return storage.Volume{}, err
+	}
+	if err := v.store.SetVolumeAttachment(ctx, name, ""); err != nil {
+		return storage.Volume{}, err
+	}
+	vol.VM = ""
+	return vol, nil
+}
+
+// resize grows a volume. Block volumes can grow under a running VM, which is
+// then told about the new size; file volumes are locked by QEMU while in use.
+func (v volumeService) resize(ctx context.Context, name string, sizeBytes int64) (storage.Volume, error) {
+	vol, err := v.store.GetVolume(ctx, name)
+	if err != nil {
+		return storage.Volume{}, err
+	}
+	running, err := v.running(ctx, vol)
+	if err != nil {
+		return storage.Volume{}, err
+	}
+	if running && !vol.Block {
+		return storage.Volume{}, fmt.Errorf("stop vm %s to grow %s: %w", vol.VM, name, storage.ErrVolumeInUse)
+	}
+	vol, err = v.store.ResizeVolume(ctx, name, sizeBytes)
+	if err != nil {
+		return storage.Volume{}, err
+	}
+	if running {
+		if err := v.manager.ResizeDisk(ctx, vol.VM, vol.Path, vol.SizeBytes); err != nil {
+			return storage.Volume{}, fmt.Errorf("notify vm: %w", err)
+		}
+	}
+	return vol, nil
+}
+
+// clone copies a volume. The source must not be in use by a running VM, or
+// the copy would not be consistent.
+func (v volumeService) clone(ctx context.Context, source string, spec storage.VolumeSpec) (storage.Volume, error) {
+	vol, err := v.store.GetVolume(ctx, source)
+	if err != nil {
+		return storage.Volume{}, err
+	}
+	running, err := v.running(ctx, vol)
+	if err != nil {
+		return storage.Volume{}, err
+	}
+	if running {
+		return storage.Volume{}, fmt.Errorf("stop vm %s to clone %s: %w", vol.VM, source, storage.ErrVolumeInUse)
+	}
+	return v.store.CloneVolume(ctx, source, spec)
+}
+
+// running reports whether the volume is attached to a running VM.
+func (v volumeService) running(ctx context.Context, vol storage.Volume) (bool, error) {
+	if vol.VM == "" {
+		return false, nil
+	}
+	vm, err := v.manager.GetVM(ctx, vol.VM)
+	if err != nil {
+		return false, err
+	}
+	return vm.Status == kvm.VMStatusRunning, nil
+}
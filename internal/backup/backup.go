@@ -0,0 +1,669 @@
+// Package backup copies the disks and definitions of VMs to backup targets
+// on a schedule, keeps them according to retention rules and fetches them
+// back for restores. It also packs single VMs into portable archives for
+// moving them between hosts.
+//
+// A target holds one directory per backup, <vm>/<timestamp>/, with the
+// domain definition (domain.xml), one qcow2 file per disk (disk0.qcow2, ...)
+// and manifest.json, which lists every file with its SHA-256 checksum. The
+// manifest is written last, so a backup without one is incomplete.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/riccardotacconi/deusvm/internal/config"
+	"github.com/riccardotacconi/deusvm/internal/kvm"
+	"github.com/riccardotacconi/deusvm/internal/logging"
+	"github.com/riccardotacconi/deusvm/internal/storage"
+	"go.uber.org/zap"
+)
+
+// FormatVersion is the version of the archive layout written by this build.
+// Newer backups are refused on restore.
+const FormatVersion = 1
+
+const (
+	ConsistencyNone     = "none"
+	ConsistencyFreeze   = "freeze"
+	ConsistencySnapshot = "snapshot"
+)
+
+const (
+	manifestName = "manifest.json"
+	domainName   = "domain.xml"
+	stampLayout  = "20060102T150405Z"
+)
+
+var (
+	ErrBackupNotFound   = errors.New("backup not found")
+	ErrBackupInProgress = errors.New("backup already in progress")
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+)
+
+// File is one file of a backup.
+type File struct {
+	Name string `json:"name"`
+	// Kind is disk or domain.
+	Kind      string `json:"kind"`
+	SizeBytes int64  `json:"size_bytes"`
+	SHA256    string `json:"sha256"`
+	// Source, Volume and VirtualBytes describe the disk a disk file was
+	// copied from.
+	Source       string `json:"source,omitempty"`
+	Volume       string `json:"volume,omitempty"`
+	VirtualBytes int64  `json:"virtual_bytes,omitempty"`
+}
+
+// Manifest describes a complete backup.
+type Manifest struct {
+	Format      int    `json:"format"`
+	Policy      string `json:"policy,omitempty"`
+	Consistency string `json:"consistency"`
+	// Quiesced is set when the guest agent flushed the filesystems.
+	Quiesced  bool      `json:"quiesced"`
+	CreatedAt time.Time `json:"created_at"`
+	VM        kvm.VM    `json:"vm"`
+	Files     []File    `json:"files"`
+}
+
+// Backup is a manifest as found on a target. ID is <target>/<vm>/<timestamp>.
+type Backup struct {
+	ID     string `json:"id"`
+	Target string `json:"target"`
+	Manifest
+	SizeBytes int64 `json:"size_bytes"`
+}
+
+// Disks lists the disk files in attachment order.
+func (m Manifest) Disks() []File {
+	var out []File
+	for _, f := range m.Files {
+		if f.Kind == "disk" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+type policy struct {
+	config.BackupPolicyConfig
+	schedule schedule
+}
+
+func (p policy) matches(vm string) bool {
+	if len(p.VMs) == 0 {
+		return true
+	}
+	for _, pattern := range p.VMs {
+		if ok, _ := path.Match(pattern, vm); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Service takes, lists, fetches and deletes backups.
+type Service struct {
+	manager  kvm.Manager
+	store    storage.Manager
+	run      storage.CommandRunner
+	staging  string
+	targets  map[string]Target
+	policies map[string]policy
+
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+// NewService sets up the targets and policies of cfg.
+func NewService(cfg config.BackupConfig, manager kvm.Manager, store storage.Manager, run storage.CommandRunner) (*Service, error) {
+	if cfg.StagingPath == "" {
+		return nil, errors.New("backup staging path required")
+	}
+	if err := os.MkdirAll(cfg.StagingPath, 0o700); err != nil {
+		return nil, fmt.Errorf("mkdir backup staging: %w", err)
+	}
+	s := &Service{
+		manager:  manager,
+		store:    store,
+		run:      run,
+		staging:  cfg.StagingPath,
+		targets:  make(map[string]Target),
+		policies: make(map[string]policy),
+		running:  make(map[string]bool),
+	}
+	for _, tc := range cfg.Targets {
+		if tc.Name == "" || strings.Contains(tc.Name, "/") {
+			return nil, fmt.Errorf("invalid backup target name %q", tc.Name)
+		}
+		t, err := NewTarget(tc)
+		if err != nil {
+			return nil, err
+		}
+		s.targets[tc.Name] = t
+	}
+	for _, pc := range cfg.Policies {
+		if pc.Name == "" {
+			return nil, errors.New("backup policy name required")
+		}
+		if _, ok := s.targets[pc.Target]; !ok {
+			return nil, fmt.Errorf("backup policy %s: unknown target %q", pc.Name, pc.Target)
+		}
+		switch pc.Consistency {
+		case "":
+			pc.Consistency = ConsistencySnapshot
+		case ConsistencyNone, ConsistencyFreeze, ConsistencySnapshot:
+		default:
+			return nil, fmt.Errorf("backup policy %s: unknown consistency %q (want none, freeze or snapshot)", pc.Name, pc.Consistency)
+		}
+		sched, err := parseSchedule(pc.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("backup policy %s: %w", pc.Name, err)
+		}
+		s.policies[pc.Name] = policy{BackupPolicyConfig: pc, schedule: sched}
+	}
+	return s, nil
+}
+
+// Schedule runs every policy on its schedule until ctx is done, logging
+// each backup and what retention removed.
+func (s *Service) Schedule(ctx context.Context, logger *zap.Logger) {
+	for _, p := range s.policies {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Until(p.schedule.next(time.Now()))):
+				}
+				s.runPolicy(ctx, logger, p)
+			}
+		}()
+	}
+}
+
+func (s *Service) runPolicy(ctx context.Context, logger *zap.Logger, p policy) {
+	vms, err := s.manager.ListVMs(ctx)
+	if err != nil {
+		logger.Warn("backup policy failed to list vms", logging.Field("policy", p.Name), logging.FieldError(err))
+		return
+	}
+	for _, vm := range vms {
+		if !p.matches(vm.Name) {
+			continue
+		}
+		b, err := s.backup(ctx, p, vm, func(float64) {})
+		if err != nil {
+			logger.Warn("backup failed", logging.Field("policy", p.Name), logging.Field("vm", vm.Name), logging.FieldError(err))
+			continue
+		}
+		logger.Info("backup taken", logging.Field("policy", p.Name), logging.Field("id", b.ID), logging.Field("size_bytes", b.SizeBytes))
+		pruned, err := s.prune(ctx, p, vm.Name)
+		for _, id := range pruned {
+			logger.Info("backup expired", logging.Field("policy", p.Name), logging.Field("id", id))
+		}
+		if err != nil {
+			logger.Warn("backup retention failed", logging.Field("policy", p.Name), logging.Field("vm", vm.Name), logging.FieldError(err))
+		}
+	}
+}
+
+// Start backs up one VM under a policy as a background job, then applies
+// the policy's retention. The policy may be omitted when only one exists.
+func (s *Service) Start(ctx context.Context, vmID, policyName string) (storage.Job, error) {
+	p, err := s.policy(policyName)
+	if err != nil {
+		return storage.Job{}, err
+	}
+	vm, err := s.manager.GetVM(ctx, vmID)
+	if err != nil {
+		return storage.Job{}, err
+	}
+	if s.busy(vm.ID) {
+		return storage.Job{}, fmt.Errorf("vm %s: %w", vm.Name, ErrBackupInProgress)
+	}
+	return s.store.StartJob("backup", "vm/"+vm.Name, func(ctx context.Context, progress func(float64)) error {
+		if _, err := s.backup(ctx, p, vm, progress); err != nil {
+			return err
+		}
+		if _, err := s.prune(ctx, p, vm.Name); err != nil {
+			return fmt.Errorf("retention: %w", err)
+		}
+		return nil
+	}), nil
+}
+
+func (s *Service) policy(name string) (policy, error) {
+	if name == "" && len(s.policies) == 1 {
+		for _, p := range s.policies {
+			return p, nil
+		}
+	}
+	p, ok := s.policies[name]
+	if !ok {
+		return policy{}, fmt.Errorf("unknown backup policy %q", name)
+	}
+	return p, nil
+}
+
+func (s *Service) busy(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running[id]
+}
+
+// claim marks vm as being copied until release is called, so that a VM is
+// never backed up or exported twice at once.
+func (s *Service) claim(vm kvm.VM) (release func(), err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running[vm.ID] {
+		return nil, fmt.Errorf("vm %s: %w", vm.Name, ErrBackupInProgress)
+	}
+	s.running[vm.ID] = true
+	return func() {
+		s.mu.Lock()
+		delete(s.running, vm.ID)
+		s.mu.Unlock()
+	}, nil
+}
+
+// backup copies the disks of vm into the staging directory while the
+// policy's consistency mode holds them still, then uploads them with the
+// domain definition and finally the manifest.
+func (s *Service) backup(ctx context.Context, p policy, vm kvm.VM, progress func(float64)) (Backup, error) {
+	release, err := s.claim(vm)
+	if err != nil {
+		return Backup{}, err
+	}
+	defer release()
+
+	work, err := os.MkdirTemp(s.staging, "backup-")
+	if err != nil {
+		return Backup{}, err
+	}
+	m := Manifest{Format: FormatVersion, Policy: p.Name, Consistency: p.Consistency, CreatedAt: time.Now().UTC(), VM: vm}
+	domainXML, copied, err := s.snapshotCopy(ctx, &m, work, p.Compress, progress)
+	if err != nil {
+		if !errors.Is(err, errOverlaysLeft) {
+			_ = os.RemoveAll(work)
+		}
+		return Backup{}, err
+	}
+	defer os.RemoveAll(work)
+
+	t := s.targets[p.Target]
+	prefix := vm.Name + "/" + m.CreatedAt.Format(stampLayout) + "/"
+	b, err := s.upload(ctx, t, prefix, work, domainXML, copied, m)
+	if err != nil {
+		_ = t.DeleteAll(context.WithoutCancel(ctx), prefix)
+		return Backup{}, err
+	}
+	b.Target = p.Target
+	b.ID = p.Target + "/" + strings.TrimSuffix(prefix, "/")
+	progress(100)
+	return b, nil
+}
+
+// errOverlaysLeft means snapshot overlays could not be merged back; the VM
+// may still be writing to them, so their directory must be kept.
+var errOverlaysLeft = errors.New("snapshot overlays left behind")
+
+// snapshotCopy reads the domain definition of m.VM and copies its disks
+// into work while m.Consistency holds them still, recording in m whether
+// the guest was quiesced.
+func (s *Service) snapshotCopy(ctx context.Context, m *Manifest, work string, compress bool, progress func(float64)) (string, []File, error) {
+	vm := m.VM
+	domainXML, err := s.manager.DomainXML(ctx, vm.ID)
+	if err != nil {
+		return "", nil, fmt.Errorf("domain definition: %w", err)
+	}
+	running := vm.Status == kvm.VMStatusRunning
+	release := func() error { return nil }
+	if running {
+		release, m.Quiesced, err = s.hold(ctx, m.Consistency, vm.ID, work)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	copied, err := s.copyDisks(ctx, vm, work, running, compress, progress)
+	if rerr := release(); rerr != nil {
+		return "", nil, errors.Join(err, fmt.Errorf("%w in %s: %w", errOverlaysLeft, work, rerr))
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return domainXML, copied, nil
+}
+
+// hold keeps the disks of a running VM consistent until release is called:
+// freeze keeps the guest filesystems frozen throughout, snapshot moves the
+// VM onto overlays in dir, quiesced by the guest agent when it answers.
+func (s *Service) hold(ctx context.Context, consistency, id, dir string) (release func() error, quiesced bool, err error) {
+	switch consistency {
+	case ConsistencyFreeze:
+		if err := s.manager.FreezeFilesystems(ctx, id); err != nil {
+			return nil, false, fmt.Errorf("freeze filesystems, run the guest agent or use snapshot consistency: %w", err)
+		}
+		return func() error { return s.manager.ThawFilesystems(context.WithoutCancel(ctx), id) }, true, nil
+	case ConsistencySnapshot:
+		quiesced = true
+		if err := s.manager.SnapshotDisks(ctx, id, dir, true); err != nil {
+			// without a guest agent the snapshot is crash consistent
+			quiesced = false
+			if err := s.manager.SnapshotDisks(ctx, id, dir, false); err != nil {
+				return nil, false, err
+			}
+		}
+		return func() error { return s.manager.MergeSnapshots(context.WithoutCancel(ctx), id, dir) }, quiesced, nil
+	default:
+		return func() error { return nil }, false, nil
+	}
+}
+
+// copyDisks converts every disk of vm into a qcow2 file in dir.
+func (s *Service) copyDisks(ctx context.Context, vm kvm.VM, dir string, shared, compress bool, progress func(float64)) ([]File, error) {
+	vols, err := s.store.ListVolumes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byPath := make(map[string]string)
+	for _, vol := range vols {
+		byPath[vol.Path] = vol.Name
+	}
+	var out []File
+	for i, d := range vm.Disks {
+		name := fmt.Sprintf("disk%d.qcow2", i)
+		format := d.Format
+		if format == "" {
+			format = "raw"
+		}
+		args := []string{"convert", "-f", format, "-O", "qcow2"}
+		if compress {
+			args = append(args, "-c")
+		}
+		if shared {
+			args = append(args, "-U")
+		}
+		dst := filepath.Join(dir, name)
+		if _, err := s.run.Run(ctx, "qemu-img", append(args, d.Path, dst)...); err != nil {
+			return nil, fmt.Errorf("copy disk %s: %w", d.Path, err)
+		}
+		out = append(out, File{Name: name, Kind: "disk", Source: d.Path, Volume: byPath[d.Path], VirtualBytes: qcow2VirtualSize(dst)})
+		// copying is the first half of the work, uploading the second
+		progress(float64(i+1) * 50 / float64(len(vm.Disks)))
+	}
+	return out, nil
+}
+
+// upload writes the domain definition, the disk copies and then the
+// manifest to t under prefix, checksumming each file on the way.
+func (s *Service) upload(ctx context.Context, t Target, prefix, dir, domainXML string, disks []File, m Manifest) (Backup, error) {
+	if domainXML != "" {
+		f, err := put(ctx, t, prefix+domainName, bytes.NewReader([]byte(domainXML)), int64(len(domainXML)))
+		if err != nil {
+			return Backup{}, err
+		}
+		f.Name, f.Kind = domainName, "domain"
+		m.Files = append(m.Files, f)
+	}
+	for _, d := range disks {
+		local := filepath.Join(dir, d.Name)
+		file, err := os.Open(local)
+		if err != nil {
+			return Backup{}, err
+		}
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return Backup{}, err
+		}
+		f, err := put(ctx, t, prefix+d.Name, file, info.Size())
+		file.Close()
+		if err != nil {
+			return Backup{}, err
+		}
+		_ = os.Remove(local)
+		d.SizeBytes, d.SHA256 = f.SizeBytes, f.SHA256
+		m.Files = append(m.Files, d)
+	}
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return Backup{}, err
+	}
+	if err := t.Put(ctx, prefix+manifestName, bytes.NewReader(b), int64(len(b))); err != nil {
+		return Backup{}, err
+	}
+	return backupOf(m), nil
+}
+
+// put stores r under key and reports its size and checksum.
+func put(ctx context.Context, t Target, key string, r io.Reader, size int64) (File, error) {
+	h := sha256.New()
+	if err := t.Put(ctx, key, io.TeeReader(r, h), size); err != nil {
+		return File{}, fmt.Errorf("upload %s: %w", key, err)
+	}
+	return File{SizeBytes: size, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+func backupOf(m Manifest) Backup {
+	b := Backup{Manifest: m}
+	for _, f := range m.Files {
+		b.SizeBytes += f.SizeBytes
+	}
+	return b
+}
+
+// prune deletes the backups of vm taken under p that its retention no
+// longer keeps, returning their ids.
+func (s *Service) prune(ctx context.Context, p policy, vm string) ([]string, error) {
+	all, err := s.list(ctx, p.Target, vm)
+	if err != nil {
+		return nil, err
+	}
+	var mine []Backup
+	for _, b := range all {
+		if b.Policy == p.Name {
+			mine = append(mine, b)
+		}
+	}
+	var deleted []string
+	var errs []error
+	for _, b := range expired(mine, p.KeepDaily, p.KeepWeekly) {
+		if err := s.Delete(ctx, b.ID); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		deleted = append(deleted, b.ID)
+	}
+	return deleted, errors.Join(errs...)
+}
+
+// List returns complete backups, oldest first, optionally only those on one
+// target or of one VM (by name).
+func (s *Service) List(ctx context.Context, target, vm string) ([]Backup, error) {
+	var names []string
+	if target != "" {
+		if _, ok := s.targets[target]; !ok {
+			return nil, fmt.Errorf("unknown backup target %q", target)
+		}
+		names = []string{target}
+	} else {
+		for name := range s.targets {
+			names = append(names, name)
+		}
+	}
+	var out []Backup
+	for _, name := range names {
+		bs, err := s.list(ctx, name, vm)
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %w", name, err)
+		}
+		out = append(out, bs...)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (s *Service) list(ctx context.Context, target, vm string) ([]Backup, error) {
+	prefix := ""
+	if vm != "" {
+		prefix = vm + "/"
+	}
+	keys, err := s.targets[target].List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	var out []Backup
+	for _, key := range keys {
+		parts := strings.Split(key, "/")
+		if len(parts) != 3 || parts[2] != manifestName {
+			continue
+		}
+		b, err := s.Get(ctx, target+"/"+parts[0]+"/"+parts[1])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+// Get reads the manifest of a backup.
+func (s *Service) Get(ctx context.Context, id string) (Backup, error) {
+	t, prefix, err := s.resolve(id)
+	if err != nil {
+		return Backup{}, err
+	}
+	r, err := t.Get(ctx, prefix+manifestName)
+	if errors.Is(err, os.ErrNotExist) {
+		return Backup{}, fmt.Errorf("%s: %w", id, ErrBackupNotFound)
+	}
+	if err != nil {
+		return Backup{}, err
+	}
+	defer r.Close()
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return Backup{}, fmt.Errorf("backup %s: parse manifest: %w", id, err)
+	}
+	b := backupOf(m)
+	b.ID = id
+	b.Target, _, _ = strings.Cut(id, "/")
+	return b, nil
+}
+
+// Delete removes a backup, starting with its manifest so that a backup
+// deleted halfway is no longer listed.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	t, prefix, err := s.resolve(id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	if err := t.DeleteAll(ctx, prefix+manifestName); err != nil {
+		return fmt.Errorf("delete backup %s: %w", id, err)
+	}
+	if err := t.DeleteAll(ctx, prefix); err != nil {
+		return fmt.Errorf("delete backup %s: %w", id, err)
+	}
+	return nil
+}
+
+// Fetch downloads the disks of a backup into a new directory under the
+// staging path and verifies their checksums. The caller removes dir.
+func (s *Service) Fetch(ctx context.Context, id string, progress func(float64)) (dir string, b Backup, err error) {
+	b, err = s.Get(ctx, id)
+	if err != nil {
+		return "", Backup{}, err
+	}
+	if b.Format > FormatVersion {
+		return "", Backup{}, fmt.Errorf("backup %s has format %d, newer than the supported %d", id, b.Format, FormatVersion)
+	}
+	t, prefix, _ := s.resolve(id)
+	dir, err = os.MkdirTemp(s.staging, "restore-")
+	if err != nil {
+		return "", Backup{}, err
+	}
+	disks := b.Disks()
+	for i, f := range disks {
+		if err := fetch(ctx, t, prefix+f.Name, filepath.Join(dir, f.Name), f.SHA256); err != nil {
+			_ = os.RemoveAll(dir)
+			return "", Backup{}, err
+		}
+		progress(float64(i+1) * 50 / float64(len(disks)))
+	}
+	return dir, b, nil
+}
+
+func fetch(ctx context.Context, t Target, key, dst, sum string) error {
+	r, err := t.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("download %s: %w", key, err)
+	}
+	defer r.Close()
+	f, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(f, io.TeeReader(r, h)); err != nil {
+		f.Close()
+		return fmt.Errorf("download %s: %w", key, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != sum {
+		return fmt.Errorf("%s: got sha256 %s, want %s: %w", key, got, sum, ErrChecksumMismatch)
+	}
+	return nil
+}
+
+// resolve splits a backup id into its target and key prefix.
+func (s *Service) resolve(id string) (Target, string, error) {
+	parts := strings.Split(id, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil, "", fmt.Errorf("invalid backup id %q, want target/vm/timestamp", id)
+	}
+	if _, err := time.Parse(stampLayout, parts[2]); err != nil {
+		return nil, "", fmt.Errorf("invalid backup id %q, want target/vm/timestamp", id)
+	}
+	t, ok := s.targets[parts[0]]
+	if !ok {
+		return nil, "", fmt.Errorf("%s: %w", id, ErrBackupNotFound)
+	}
+	return t, parts[1] + "/" + parts[2] + "/", nil
+}
+
+// qcow2VirtualSize reads the disk size from a qcow2 header, or 0.
+func qcow2VirtualSize(path string) int64 {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	var hdr [32]byte
+	if _, err := io.ReadFull(f, hdr[:]); err != nil || string(hdr[:4]) != "QFI\xfb" {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(hdr[24:32]))
+}
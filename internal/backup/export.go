@@ -0,0 +1,224 @@
+package backup
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/riccardotacconi/deusvm/internal/kvm"
+	"github.com/riccardotacconi/deusvm/internal/storage"
+)
+
+// ErrInvalidArchive is returned for VM archives that cannot be imported.
+var ErrInvalidArchive = errors.New("invalid vm archive")
+
+// maxManifestBytes bounds the manifest read from an archive.
+const maxManifestBytes = 1 << 20
+
+// Export writes vm to w as a zstd-compressed tar archive holding the same
+// files as a backup: domain.xml, one qcow2 file per disk and manifest.json,
+// which comes last. A running VM keeps running; its disks are copied from a
+// snapshot, quiesced when the guest agent answers.
+func (s *Service) Export(ctx context.Context, vmID string, w io.Writer) (Manifest, error) {
+	vm, err := s.manager.GetVM(ctx, vmID)
+	if err != nil {
+		return Manifest{}, err
+	}
+	release, err := s.claim(vm)
+	if err != nil {
+		return Manifest{}, err
+	}
+	defer release()
+
+	work, err := os.MkdirTemp(s.staging, "export-")
+	if err != nil {
+		return Manifest{}, err
+	}
+	m := Manifest{Format: FormatVersion, Consistency: ConsistencyNone, CreatedAt: time.Now().UTC(), VM: vm}
+	if vm.Status == kvm.VMStatusRunning {
+		m.Consistency = ConsistencySnapshot
+	}
+	domainXML, disks, err := s.snapshotCopy(ctx, &m, work, false, func(float64) {})
+	if err != nil {
+		if !errors.Is(err, errOverlaysLeft) {
+			_ = os.RemoveAll(work)
+		}
+		return Manifest{}, err
+	}
+	defer os.RemoveAll(work)
+
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return Manifest{}, err
+	}
+	defer zw.Close()
+	tw := tar.NewWriter(zw)
+	if domainXML != "" {
+		f, err := writeEntry(tw, domainName, strings.NewReader(domainXML), int64(len(domainXML)))
+		if err != nil {
+			return Manifest{}, err
+		}
+		f.Name, f.Kind = domainName, "domain"
+		m.Files = append(m.Files, f)
+	}
+	for _, d := range disks {
+		file, err := os.Open(filepath.Join(work, d.Name))
+		if err != nil {
+			return Manifest{}, err
+		}
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return Manifest{}, err
+		}
+		f, err := writeEntry(tw, d.Name, file, info.Size())
+		file.Close()
+		if err != nil {
+			return Manifest{}, err
+		}
+		d.SizeBytes, d.SHA256 = f.SizeBytes, f.SHA256
+		m.Files = append(m.Files, d)
+	}
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return Manifest{}, err
+	}
+	if _, err := writeEntry(tw, manifestName, strings.NewReader(string(b)), int64(len(b))); err != nil {
+		return Manifest{}, err
+	}
+	if err := tw.Close(); err != nil {
+		return Manifest{}, err
+	}
+	return m, zw.Close()
+}
+
+// writeEntry adds size bytes from r to tw as the file name and reports its
+// size and checksum.
+func writeEntry(tw *tar.Writer, name string, r io.Reader, size int64) (File, error) {
+	hdr := &tar.Header{Name: name, Mode: 0o644, Size: size, ModTime: time.Now(), Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return File{}, fmt.Errorf("archive %s: %w", name, err)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(tw, io.TeeReader(r, h)); err != nil {
+		return File{}, fmt.Errorf("archive %s: %w", name, err)
+	}
+	return File{SizeBytes: size, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// Import unpacks an archive written by Export into a new directory under the
+// staging path and checks every file against the manifest. Each file must
+// fit in the space left in the staging path before it is written. The
+// caller removes dir.
+func (s *Service) Import(r io.Reader) (dir string, m Manifest, err error) {
+	zr, err := zstd.NewReader(r)
+	if err != nil {
+		return "", Manifest{}, err
+	}
+	defer zr.Close()
+	dir, err = os.MkdirTemp(s.staging, "import-")
+	if err != nil {
+		return "", Manifest{}, err
+	}
+	m, err = unpack(tar.NewReader(zr), dir, s.stagingRoom)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return "", Manifest{}, err
+	}
+	return dir, m, nil
+}
+
+// stagingRoom refuses to stage size more bytes than the staging filesystem
+// has free. The disks are checked against the limits of their pool when
+// they are imported from there.
+func (s *Service) stagingRoom(size int64) error {
+	u, err := storage.DirUsage(s.staging)
+	if err != nil {
+		return fmt.Errorf("backup staging usage: %w", err)
+	}
+	if size > u.FreeBytes {
+		return fmt.Errorf("backup staging has %d bytes free, %d needed: %w", u.FreeBytes, size, storage.ErrInsufficientStorage)
+	}
+	return nil
+}
+
+// unpack writes the entries of tr to dir, calling room with the size each
+// file declares before writing it; tar stops every entry at that size.
+func unpack(tr *tar.Reader, dir string, room func(size int64) error) (Manifest, error) {
+	sums := make(map[string]string)
+	var m Manifest
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Manifest{}, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+		if m.Format != 0 {
+			return Manifest{}, fmt.Errorf("%w: %s after the manifest", ErrInvalidArchive, hdr.Name)
+		}
+		name := hdr.Name
+		if hdr.Typeflag != tar.TypeReg || name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+			return Manifest{}, fmt.Errorf("%w: unexpected entry %q", ErrInvalidArchive, name)
+		}
+		if _, ok := sums[name]; ok {
+			return Manifest{}, fmt.Errorf("%w: duplicate entry %q", ErrInvalidArchive, name)
+		}
+		if name == manifestName {
+			if err := json.NewDecoder(io.LimitReader(tr, maxManifestBytes)).Decode(&m); err != nil {
+				return Manifest{}, fmt.Errorf("%w: parse manifest: %w", ErrInvalidArchive, err)
+			}
+			if m.Format == 0 {
+				return Manifest{}, fmt.Errorf("%w: manifest without format", ErrInvalidArchive)
+			}
+			continue
+		}
+		if err := room(hdr.Size); err != nil {
+			return Manifest{}, fmt.Errorf("unpack %s: %w", name, err)
+		}
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err != nil {
+			return Manifest{}, err
+		}
+		h := sha256.New()
+		_, err = io.Copy(f, io.TeeReader(tr, h))
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return Manifest{}, fmt.Errorf("unpack %s: %w", name, err)
+		}
+		sums[name] = hex.EncodeToString(h.Sum(nil))
+	}
+	if m.Format == 0 {
+		return Manifest{}, fmt.Errorf("%w: no manifest", ErrInvalidArchive)
+	}
+	if m.Format > FormatVersion {
+		return Manifest{}, fmt.Errorf("%w: format %d is newer than the supported %d", ErrInvalidArchive, m.Format, FormatVersion)
+	}
+	for _, f := range m.Files {
+		got, ok := sums[f.Name]
+		if !ok {
+			return Manifest{}, fmt.Errorf("%w: %s missing", ErrInvalidArchive, f.Name)
+		}
+		if got != f.SHA256 {
+			return Manifest{}, fmt.Errorf("%s: got sha256 %s, want %s: %w", f.Name, got, f.SHA256, ErrChecksumMismatch)
+		}
+		delete(sums, f.Name)
+	}
+	for name := range sums {
+		return Manifest{}, fmt.Errorf("%w: %s not in the manifest", ErrInvalidArchive, name)
+	}
+	return m, nil
+}
@@ -0,0 +1,70 @@
+package backup
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// schedule is either a fixed interval or a time of day, in local time.
+type schedule struct {
+	every time.Duration
+	at    time.Duration // since midnight
+}
+
+// parseSchedule reads "6h"-style intervals and "02:30"-style daily times.
+func parseSchedule(s string) (schedule, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < time.Minute {
+			return schedule{}, fmt.Errorf("schedule %q: interval must be at least a minute", s)
+		}
+		return schedule{every: d}, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return schedule{}, fmt.Errorf("schedule %q: want a time such as 02:30 or an interval such as 6h", s)
+	}
+	return schedule{at: time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute}, nil
+}
+
+// next returns the first run after now.
+func (s schedule) next(now time.Time) time.Time {
+	if s.every > 0 {
+		return now.Add(s.every)
+	}
+	y, m, d := now.Date()
+	run := time.Date(y, m, d, 0, 0, 0, 0, now.Location()).Add(s.at)
+	if !run.After(now) {
+		run = time.Date(y, m, d+1, 0, 0, 0, 0, now.Location()).Add(s.at)
+	}
+	return run
+}
+
+// expired picks the backups retention no longer keeps: the newest backup of
+// each of the last daily days and of the last weekly ISO weeks survive, and
+// so does the newest backup overall. Nothing expires without retention.
+func expired(backups []Backup, daily, weekly int) []Backup {
+	if daily <= 0 && weekly <= 0 {
+		return nil
+	}
+	sorted := append([]Backup(nil), backups...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].CreatedAt.After(sorted[j].CreatedAt) })
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+	var out []Backup
+	for i, b := range sorted {
+		keep := i == 0
+		t := b.CreatedAt.Local()
+		if day := t.Format("2006-01-02"); !days[day] && len(days) < daily {
+			days[day], keep = true, true
+		}
+		y, w := t.ISOWeek()
+		if week := fmt.Sprintf("%d-%02d", y, w); !weeks[week] && len(weeks) < weekly {
+			weeks[week], keep = true, true
+		}
+		if !keep {
+			out = append(out, b)
+		}
+	}
+	return out
+}
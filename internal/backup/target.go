@@ -0,0 +1,213 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/riccardotacconi/deusvm/internal/config"
+)
+
+// Target stores backup files under slash-separated keys.
+type Target interface {
+	// Put stores size bytes from r under key, replacing any previous content.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// Get fails with an error wrapping os.ErrNotExist for unknown keys.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// List returns all keys under prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+	// DeleteAll removes every key under prefix.
+	DeleteAll(ctx context.Context, prefix string) error
+}
+
+// NewTarget builds the target described by cfg.
+func NewTarget(cfg config.BackupTargetConfig) (Target, error) {
+	switch cfg.Type {
+	case "", "dir":
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("backup target %s: path required", cfg.Name)
+		}
+		if err := os.MkdirAll(cfg.Path, 0o700); err != nil {
+			return nil, fmt.Errorf("backup target %s: %w", cfg.Name, err)
+		}
+		return &dirTarget{dir: cfg.Path}, nil
+	case "s3":
+		if cfg.Bucket == "" {
+			return nil, fmt.Errorf("backup target %s: bucket required", cfg.Name)
+		}
+		return newS3Target(cfg)
+	default:
+		return nil, fmt.Errorf("backup target %s: unknown type %q", cfg.Name, cfg.Type)
+	}
+}
+
+// dirTarget keeps backups in a local directory, typically a mounted disk or
+// network share. Files are written under a temporary name and renamed.
+type dirTarget struct {
+	dir string
+}
+
+func (t *dirTarget) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if clean == "/" || clean != "/"+key {
+		return "", fmt.Errorf("invalid backup key %q", key)
+	}
+	return filepath.Join(t.dir, filepath.FromSlash(key)), nil
+}
+
+func (t *dirTarget) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	p, err := t.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return err
+	}
+	tmp := p + ".part"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, p)
+}
+
+func (t *dirTarget) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := t.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+func (t *dirTarget) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(t.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || strings.HasSuffix(p, ".part") {
+			return nil
+		}
+		rel, err := filepath.Rel(t.dir, p)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	return keys, err
+}
+
+func (t *dirTarget) DeleteAll(ctx context.Context, prefix string) error {
+	p, err := t.path(strings.TrimSuffix(prefix, "/"))
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(p)
+}
+
+// s3Target keeps backups in a bucket of an S3-compatible store, under an
+// optional key prefix.
+type s3Target struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+func newS3Target(cfg config.BackupTargetConfig) (*s3Target, error) {
+	creds := credentials.NewEnvAWS()
+	if cfg.S3.AccessKey != "" {
+		creds = credentials.NewStaticV4(cfg.S3.AccessKey, cfg.S3.SecretKey, "")
+	}
+	lookup := minio.BucketLookupAuto
+	if cfg.S3.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+	client, err := minio.New(cfg.S3.Endpoint, &minio.Options{
+		Creds:        creds,
+		Secure:       !cfg.S3.Insecure,
+		Region:       cfg.S3.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("backup target %s: s3 client: %w", cfg.Name, err)
+	}
+	prefix := strings.Trim(cfg.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &s3Target{client: client, bucket: cfg.Bucket, prefix: prefix}, nil
+}
+
+func (t *s3Target) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	_, err := t.client.PutObject(ctx, t.bucket, t.prefix+key, r, size, minio.PutObjectOptions{ContentType: "application/octet-stream"})
+	if err != nil {
+		return fmt.Errorf("s3 put %s: %w", key, err)
+	}
+	return nil
+}
+
+func (t *s3Target) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	obj, err := t.client.GetObject(ctx, t.bucket, t.prefix+key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("s3 get %s: %w", key, err)
+	}
+	// GetObject is lazy; Stat surfaces a missing key before the first read
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, fmt.Errorf("%s: %w", key, os.ErrNotExist)
+		}
+		return nil, fmt.Errorf("s3 get %s: %w", key, err)
+	}
+	return obj, nil
+}
+
+func (t *s3Target) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for obj := range t.client.ListObjects(ctx, t.bucket, minio.ListObjectsOptions{Prefix: t.prefix + prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("s3 list: %w", obj.Err)
+		}
+		keys = append(keys, strings.TrimPrefix(obj.Key, t.prefix))
+	}
+	return keys, nil
+}
+
+func (t *s3Target) DeleteAll(ctx context.Context, prefix string) error {
+	keys, err := t.List(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, key := range keys {
+		if err := t.client.RemoveObject(ctx, t.bucket, t.prefix+key, minio.RemoveObjectOptions{}); err != nil {
+			errs = append(errs, fmt.Errorf("s3 delete %s: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -16,10 +17,145 @@ type APIConfig struct {
 type StorageConfig struct {
 	ImagesPath string `mapstructure:"images_path"`
 	DisksPath  string `mapstructure:"disks_path"`
+	// StatePath holds daemon state such as the volume registry.
+	StatePath   string       `mapstructure:"state_path"`
+	Pools       []PoolConfig `mapstructure:"pools"`
+	DefaultPool string       `mapstructure:"default_pool"`
+	// ImportDirs are the only directories file:// image sources may read from.
+	ImportDirs []string  `mapstructure:"import_dirs"`
+	S3         S3Config  `mapstructure:"s3"`
+	OCI        OCIConfig `mapstructure:"oci"`
+	// HighWaterMark is the percentage of a filesystem or pool that may fill
+	// up before new disks and image transfers are refused; 0 disables it.
+	// It applies to the images directory and to pools that set none.
+	HighWaterMark float64 `mapstructure:"high_water_mark"`
+	// MaxOvercommit caps the virtual size of a pool's volumes as a multiple
+	// of its capacity for pools that set none; 0 means no cap.
+	MaxOvercommit float64  `mapstructure:"max_overcommit"`
+	GC            GCConfig `mapstructure:"gc"`
+}
+
+// GCConfig schedules garbage collection of orphaned disks, stale transfers
+// and unused images. Collected files wait in QuarantinePath for Retention
+// before they are deleted. An Interval of 0 disables scheduled runs.
+type GCConfig struct {
+	Interval       time.Duration `mapstructure:"interval"`
+	MinAge         time.Duration `mapstructure:"min_age"`
+	Retention      time.Duration `mapstructure:"retention"`
+	QuarantinePath string        `mapstructure:"quarantine_path"`
+}
+
+// PoolConfig declares a named storage pool for VM disks. Type is dir (Path),
+// lvm-thin (VolumeGroup and ThinPool) or zfs (Dataset).
+type PoolConfig struct {
+	Name        string `mapstructure:"name"`
+	Type        string `mapstructure:"type"`
+	Path        string `mapstructure:"path"`
+	VolumeGroup string `mapstructure:"volume_group"`
+	ThinPool    string `mapstructure:"thin_pool"`
+	Dataset     string `mapstructure:"dataset"`
+	// HighWaterMark and MaxOvercommit override the storage-wide limits when
+	// set; 0 disables the limit for this pool. Load fills in unset ones.
+	HighWaterMark *float64 `mapstructure:"high_water_mark"`
+	MaxOvercommit *float64 `mapstructure:"max_overcommit"`
+}
+
+// S3Config points s3://bucket/key image sources at an S3-compatible object
+// store. Without keys, credentials come from the AWS_* environment variables.
+type S3Config struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+	// Insecure talks plain HTTP to the endpoint.
+	Insecure  bool `mapstructure:"insecure"`
+	PathStyle bool `mapstructure:"path_style"`
+}
+
+// OCIConfig holds per-registry settings for oci:// image sources. Registries
+// not listed are accessed anonymously over HTTPS.
+type OCIConfig struct {
+	Registries []RegistryConfig `mapstructure:"registries"`
+}
+
+type RegistryConfig struct {
+	Host     string `mapstructure:"host"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// Insecure talks plain HTTP to the registry.
+	Insecure bool `mapstructure:"insecure"`
+}
+
+// BackupConfig declares where VM backups are stored and when they are taken.
+type BackupConfig struct {
+	// StagingPath holds disk copies and snapshot overlays while a backup or
+	// restore runs.
+	StagingPath string               `mapstructure:"staging_path"`
+	Targets     []BackupTargetConfig `mapstructure:"targets"`
+	Policies    []BackupPolicyConfig `mapstructure:"policies"`
+}
+
+// BackupTargetConfig is a place backups are written to: a local directory
+// (Path) or a bucket on an S3-compatible store (Bucket, Prefix and S3, which
+// defaults to storage.s3).
+type BackupTargetConfig struct {
+	Name   string   `mapstructure:"name"`
+	Type   string   `mapstructure:"type"`
+	Path   string   `mapstructure:"path"`
+	Bucket string   `mapstructure:"bucket"`
+	Prefix string   `mapstructure:"prefix"`
+	S3     S3Config `mapstructure:"s3"`
+}
+
+// BackupPolicyConfig backs up VMs on a schedule, a daily time such as
+// "02:30" or an interval such as "6h". VMs lists names or glob patterns and
+// matches every VM when empty. Retention keeps the newest backup of each of
+// the last KeepDaily days and KeepWeekly weeks; with neither set, backups are
+// kept forever. Consistency is none, freeze or snapshot.
+type BackupPolicyConfig struct {
+	Name        string   `mapstructure:"name"`
+	Schedule    string   `mapstructure:"schedule"`
+	Target      string   `mapstructure:"target"`
+	VMs         []string `mapstructure:"vms"`
+	KeepDaily   int      `mapstructure:"keep_daily"`
+	KeepWeekly  int      `mapstructure:"keep_weekly"`
+	Consistency string   `mapstructure:"consistency"`
+	Compress    bool     `mapstructure:"compress"`
 }
 
 type NetworkConfig struct {
 	Bridge string `mapstructure:"bridge"`
+	// BridgeType is linux or openvswitch.
+	BridgeType string `mapstructure:"bridge_type"`
+	// FirewallInterval is how often security group rules are reapplied to
+	// pick up VMs started outside the daemon.
+	FirewallInterval time.Duration `mapstructure:"firewall_interval"`
+	DNS              DNSConfig     `mapstructure:"dns"`
+	PXE              PXEConfig     `mapstructure:"pxe"`
+}
+
+// DNSConfig configures the DNS server answering for VM names. VMs are
+// published as <name>.<Zone>. The server listens on Port of the gateways of
+// networks created with external DNS and on the Listen addresses, and sends
+// other queries to Upstream, which defaults to the nameservers in
+// /etc/resolv.conf.
+type DNSConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Zone     string        `mapstructure:"zone"`
+	Port     int           `mapstructure:"port"`
+	Listen   []string      `mapstructure:"listen"`
+	Upstream []string      `mapstructure:"upstream"`
+	TTL      time.Duration `mapstructure:"ttl"`
+}
+
+// PXEConfig configures the boot server of networks created with PXE. It
+// listens on TFTPPort and HTTPPort of their gateways, serving iPXE and other
+// files from Root and each VM's iPXE script over HTTP.
+type PXEConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Root     string `mapstructure:"root"`
+	TFTPPort int    `mapstructure:"tftp_port"`
+	HTTPPort int    `mapstructure:"http_port"`
 }
 
 type LibvirtConfig struct {
@@ -40,6 +176,7 @@ type GRPCConfig struct {
 type Config struct {
 	API     APIConfig     `mapstructure:"api"`
 	Storage StorageConfig `mapstructure:"storage"`
+	Backup  BackupConfig  `mapstructure:"backup"`
 	Network NetworkConfig `mapstructure:"network"`
 	Libvirt LibvirtConfig `mapstructure:"libvirt"`
 	GRPC    GRPCConfig    `mapstructure:"grpc"`
@@ -54,10 +191,25 @@ func defaultConfig() Config {
 			ListenAddress: ":9090",
 		},
 		Storage: StorageConfig{
-			ImagesPath: "/var/lib/deusvm/images",
-			DisksPath:  "/var/lib/deusvm/disks",
+			ImagesPath:    "/var/lib/deusvm/images",
+			DisksPath:     "/var/lib/deusvm/disks",
+			StatePath:     "/var/lib/deusvm/state",
+			S3:            S3Config{Endpoint: "s3.amazonaws.com"},
+			HighWaterMark: 90,
+			GC: GCConfig{
+				MinAge:         24 * time.Hour,
+				Retention:      7 * 24 * time.Hour,
+				QuarantinePath: "/var/lib/deusvm/quarantine",
+			},
+		},
+		Backup: BackupConfig{StagingPath: "/var/lib/deusvm/backup-staging"},
+		Network: NetworkConfig{
+			Bridge:           "br0",
+			BridgeType:       "linux",
+			FirewallInterval: 30 * time.Second,
+			DNS:              DNSConfig{Zone: "deusvm.internal", Port: 53, TTL: 30 * time.Second},
+			PXE:              PXEConfig{Root: "/var/lib/deusvm/pxe", TFTPPort: 69, HTTPPort: 8069},
 		},
-		Network: NetworkConfig{Bridge: "br0"},
 	}
 }
 
@@ -86,5 +238,35 @@ func Load() (Config, error) {
 		return cfg, fmt.Errorf("unmarshal config: %w", err)
 	}
 
+	// without explicit pools, disks live in a directory pool at disks_path
+	if len(cfg.Storage.Pools) == 0 {
+		cfg.Storage.Pools = []PoolConfig{{Name: "default", Type: "dir", Path: cfg.Storage.DisksPath}}
+	}
+	if cfg.Storage.HighWaterMark < 0 || cfg.Storage.MaxOvercommit < 0 {
+		return cfg, fmt.Errorf("storage: high_water_mark and max_overcommit must not be negative")
+	}
+	// pools without limits of their own get the storage-wide ones
+	for i := range cfg.Storage.Pools {
+		pc := &cfg.Storage.Pools[i]
+		if pc.HighWaterMark == nil {
+			mark := cfg.Storage.HighWaterMark
+			pc.HighWaterMark = &mark
+		}
+		if pc.MaxOvercommit == nil {
+			ratio := cfg.Storage.MaxOvercommit
+			pc.MaxOvercommit = &ratio
+		}
+		if *pc.HighWaterMark < 0 || *pc.MaxOvercommit < 0 {
+			return cfg, fmt.Errorf("storage pool %s: high_water_mark and max_overcommit must not be negative", pc.Name)
+		}
+	}
+
+	// S3 backup targets without an endpoint use the image source store
+	for i := range cfg.Backup.Targets {
+		if t := &cfg.Backup.Targets[i]; t.Type == "s3" && t.S3.Endpoint == "" {
+			t.S3 = cfg.Storage.S3
+		}
+	}
+
 	return cfg, nil
 }
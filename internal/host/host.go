@@ -0,0 +1,75 @@
+// Package host reports the network interfaces of the host DeusVM runs on,
+// so operators can see which bridges, bonds and VLANs exist before putting
+// VMs on them.
+package host
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupported is returned where the host cannot be inspected.
+var ErrUnsupported = errors.New("host network inventory is only supported on linux")
+
+// Interface kinds. Links of other kernel types, such as veth, tuntap or
+// dummy, keep the kernel's name for their type.
+const (
+	KindPhysical    = "physical"
+	KindLoopback    = "loopback"
+	KindBridge      = "bridge"
+	KindOpenVSwitch = "openvswitch"
+	KindBond        = "bond"
+	KindVLAN        = "vlan"
+)
+
+// Link states.
+const (
+	StateUp   = "up"
+	StateDown = "down"
+)
+
+// Interface is a network interface of the host. State is whether it is
+// administratively up and OperState whether it carries traffic, as the
+// kernel reports it (up, down, lowerlayerdown, unknown...). Master is the
+// bridge or bond the interface is enslaved to and Ports the interfaces
+// enslaved to a bridge or bond; ports of Open vSwitch bridges are all
+// enslaved to the ovs-system datapath. VLAN subinterfaces have a Parent and
+// a VLAN ID. Addresses are in CIDR notation.
+type Interface struct {
+	Name      string   `json:"name"`
+	Index     int      `json:"index"`
+	Kind      string   `json:"kind"`
+	MAC       string   `json:"mac,omitempty"`
+	MTU       int      `json:"mtu"`
+	State     string   `json:"state"`
+	OperState string   `json:"oper_state"`
+	Master    string   `json:"master,omitempty"`
+	Ports     []string `json:"ports,omitempty"`
+	Parent    string   `json:"parent,omitempty"`
+	VLAN      int      `json:"vlan,omitempty"`
+	BondMode  string   `json:"bond_mode,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// CheckBridge makes sure the host has a bridge called name of bridgeType,
+// linux or openvswitch.
+func CheckBridge(name, bridgeType string) error {
+	ifaces, err := ListInterfaces()
+	if err != nil {
+		return err
+	}
+	want := KindBridge
+	if bridgeType == KindOpenVSwitch {
+		want = KindOpenVSwitch
+	}
+	for _, iface := range ifaces {
+		if iface.Name != name {
+			continue
+		}
+		if iface.Kind != want {
+			return fmt.Errorf("%s is a %s interface, not a %s bridge", name, iface.Kind, bridgeType)
+		}
+		return nil
+	}
+	return fmt.Errorf("bridge %s does not exist", name)
+}
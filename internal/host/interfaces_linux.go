@@ -0,0 +1,84 @@
+//go:build linux
+
+package host
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/vishvananda/netlink"
+)
+
+// ListInterfaces lists the network interfaces of the host in index order.
+func ListInterfaces() ([]Interface, error) {
+	links, err := netlink.LinkList()
+	if err != nil {
+		return nil, fmt.Errorf("list links: %w", err)
+	}
+	addrs, err := netlink.AddrList(nil, netlink.FAMILY_ALL)
+	if err != nil {
+		return nil, fmt.Errorf("list addresses: %w", err)
+	}
+
+	names := make(map[int]string, len(links))
+	for _, l := range links {
+		names[l.Attrs().Index] = l.Attrs().Name
+	}
+	ifaces := make([]Interface, 0, len(links))
+	pos := make(map[int]int, len(links))
+	for _, l := range links {
+		a := l.Attrs()
+		iface := Interface{
+			Name:      a.Name,
+			Index:     a.Index,
+			Kind:      linkKind(l),
+			MTU:       a.MTU,
+			State:     StateDown,
+			OperState: a.OperState.String(),
+			Master:    names[a.MasterIndex],
+		}
+		if len(a.HardwareAddr) > 0 {
+			iface.MAC = a.HardwareAddr.String()
+		}
+		if a.Flags&net.FlagUp != 0 {
+			iface.State = StateUp
+		}
+		switch l := l.(type) {
+		case *netlink.Vlan:
+			iface.VLAN = l.VlanId
+			iface.Parent = names[a.ParentIndex]
+		case *netlink.Bond:
+			iface.BondMode = l.Mode.String()
+		}
+		pos[a.Index] = len(ifaces)
+		ifaces = append(ifaces, iface)
+	}
+	for _, l := range links {
+		if m, ok := pos[l.Attrs().MasterIndex]; ok && l.Attrs().MasterIndex != 0 {
+			ifaces[m].Ports = append(ifaces[m].Ports, l.Attrs().Name)
+		}
+	}
+	for _, addr := range addrs {
+		if i, ok := pos[addr.LinkIndex]; ok {
+			ifaces[i].Addresses = append(ifaces[i].Addresses, addr.IPNet.String())
+		}
+	}
+	return ifaces, nil
+}
+
+// linkKind tells physical NICs, which have a device behind them, from other
+// links the kernel also reports as plain devices.
+func linkKind(l netlink.Link) string {
+	a := l.Attrs()
+	switch {
+	case a.Flags&net.FlagLoopback != 0:
+		return KindLoopback
+	case l.Type() == "device":
+		if _, err := os.Stat(filepath.Join("/sys/class/net", a.Name, "device")); err == nil {
+			return KindPhysical
+		}
+	}
+	return l.Type()
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package host
+
+// ListInterfaces lists the network interfaces of the host.
+func ListInterfaces() ([]Interface, error) {
+	return nil, ErrUnsupported
+}
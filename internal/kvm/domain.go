@@ -0,0 +1,318 @@
+package kvm
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// metadataNS is the namespace of the DeusVM element in domain <metadata>.
+const metadataNS = "https://github.com/riccardotacconi/deusvm/xmlns/vm/1.0"
+
+// domainDevices is the subset of a libvirt domain definition read back by the manager.
+type domainDevices struct {
+	Image string `xml:"metadata>vm>image"`
+	Boot  []struct {
+		Dev string `xml:"dev,attr"`
+	} `xml:"os>boot"`
+	Disks []struct {
+		Type   string `xml:"type,attr"`
+		Device string `xml:"device,attr"`
+		Driver struct {
+			Type string `xml:"type,attr"`
+		} `xml:"driver"`
+		Source struct {
+			File string `xml:"file,attr"`
+			Dev  string `xml:"dev,attr"`
+		} `xml:"source"`
+		Target struct {
+			Dev string `xml:"dev,attr"`
+		} `xml:"target"`
+	} `xml:"devices>disk"`
+	Interfaces []struct {
+		Type string `xml:"type,attr"`
+		MAC  struct {
+			Address string `xml:"address,attr"`
+		} `xml:"mac"`
+		Source struct {
+			Network string `xml:"network,attr"`
+			Bridge  string `xml:"bridge,attr"`
+		} `xml:"source"`
+		Model struct {
+			Type string `xml:"type,attr"`
+		} `xml:"model"`
+		Target struct {
+			Dev string `xml:"dev,attr"`
+		} `xml:"target"`
+		VirtualPort struct {
+			Type string `xml:"type,attr"`
+		} `xml:"virtualport"`
+		Link struct {
+			State string `xml:"state,attr"`
+		} `xml:"link"`
+		Bandwidth bandwidthDoc `xml:"bandwidth"`
+		VLAN      struct {
+			Trunk string `xml:"trunk,attr"`
+			Tags  []struct {
+				ID int `xml:"id,attr"`
+			} `xml:"tag"`
+		} `xml:"vlan"`
+	} `xml:"devices>interface"`
+}
+
+func parseDomain(domainXML string) (domainDevices, bool) {
+	var d domainDevices
+	if err := xml.Unmarshal([]byte(domainXML), &d); err != nil {
+		return domainDevices{}, false
+	}
+	return d, true
+}
+
+// domainDisks returns the disks of a domain, skipping CD-ROMs and floppies.
+func domainDisks(domainXML string) []Disk {
+	d, ok := parseDomain(domainXML)
+	if !ok {
+		return nil
+	}
+	var out []Disk
+	for _, disk := range d.Disks {
+		if disk.Device != "" && disk.Device != "disk" {
+			continue
+		}
+		if disk.Type == "block" || (disk.Source.File == "" && disk.Source.Dev != "") {
+			out = append(out, Disk{Path: disk.Source.Dev, Format: disk.Driver.Type, Block: true})
+			continue
+		}
+		out = append(out, Disk{Path: disk.Source.File, Format: disk.Driver.Type})
+	}
+	return out
+}
+
+// applyDomainXML fills in the parts of vm that are read from its definition.
+func applyDomainXML(vm *VM, domainXML string) {
+	vm.Image, vm.Disks = domainImage(domainXML), domainDisks(domainXML)
+	d, ok := parseDomain(domainXML)
+	if !ok {
+		return
+	}
+	for _, disk := range d.Disks {
+		if disk.Device == "cdrom" {
+			vm.CDROM = disk.Source.File
+			break
+		}
+	}
+	vm.BootOrder = nil
+	for _, b := range d.Boot {
+		vm.BootOrder = append(vm.BootOrder, b.Dev)
+	}
+	vm.NICs = nil
+	for _, iface := range d.Interfaces {
+		nic := NIC{
+			MAC: iface.MAC.Address, Model: iface.Model.Type, Device: iface.Target.Dev, LinkState: LinkUp,
+			Inbound: iface.Bandwidth.Inbound, Outbound: iface.Bandwidth.Outbound,
+		}
+		if iface.Link.State == LinkDown {
+			nic.LinkState = LinkDown
+		}
+		// live definitions name the bridge of a network interface as well
+		if iface.Type == "network" {
+			nic.Network = iface.Source.Network
+		} else {
+			nic.Bridge, nic.BridgeType = iface.Source.Bridge, BridgeLinux
+			if iface.VirtualPort.Type == BridgeOpenVSwitch {
+				nic.BridgeType = BridgeOpenVSwitch
+			}
+		}
+		tags := iface.VLAN.Tags
+		switch {
+		case iface.VLAN.Trunk == "yes" || len(tags) > 1:
+			for _, t := range tags {
+				nic.Trunk = append(nic.Trunk, t.ID)
+			}
+		case len(tags) == 1:
+			nic.VLAN = tags[0].ID
+		}
+		vm.NICs = append(vm.NICs, nic)
+	}
+}
+
+// domainImage returns the image a domain was created from, as recorded in its
+// metadata, or else the file or block device backing its first disk.
+func domainImage(domainXML string) string {
+	if d, ok := parseDomain(domainXML); ok && d.Image != "" {
+		return d.Image
+	}
+	if disks := domainDisks(domainXML); len(disks) > 0 {
+		return disks[0].Path
+	}
+	return ""
+}
+
+// metadataXML records the image a VM was created from in the domain definition.
+func metadataXML(image string) string {
+	if image == "" {
+		return ""
+	}
+	return fmt.Sprintf("<metadata><deusvm:vm xmlns:deusvm='%s'><deusvm:image>%s</deusvm:image></deusvm:vm></metadata>", metadataNS, xmlEscape(image))
+}
+
+// diskTarget names the virtio device for the disk at index: vda, vdb, ...
+func diskTarget(index int) string { return "vd" + string(rune('a'+index)) }
+
+// freeDiskTarget picks the first virtio target not used by the domain.
+func freeDiskTarget(domainXML string) (string, error) {
+	d, _ := parseDomain(domainXML)
+	used := make(map[string]bool)
+	for _, disk := range d.Disks {
+		used[disk.Target.Dev] = true
+	}
+	for i := 0; i < 26; i++ {
+		if t := diskTarget(i); !used[t] {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("no free disk target")
+}
+
+// diskTargetOf returns the target device of the disk backed by path.
+func diskTargetOf(domainXML, path string) (string, bool) {
+	d, _ := parseDomain(domainXML)
+	for _, disk := range d.Disks {
+		if disk.Source.File == path || disk.Source.Dev == path {
+			return disk.Target.Dev, true
+		}
+	}
+	return "", false
+}
+
+// diskXML renders a virtio disk attached as target.
+func diskXML(d Disk, target string) string {
+	format := d.Format
+	if format == "" {
+		format = "raw"
+	}
+	if d.Block {
+		return fmt.Sprintf(`
+    <disk type='block' device='disk'>
+      <driver name='qemu' type='%s' cache='none' io='native'/>
+      <source dev='%s'/>
+      <target dev='%s' bus='virtio'/>
+    </disk>`, format, xmlEscape(d.Path), target)
+	}
+	return fmt.Sprintf(`
+    <disk type='file' device='disk'>
+      <driver name='qemu' type='%s'/>
+      <source file='%s'/>
+      <target dev='%s' bus='virtio'/>
+    </disk>`, format, xmlEscape(d.Path), target)
+}
+
+// cdromTarget is the IDE slot of the CD-ROM drive every VM is created with.
+const cdromTarget = "hdc"
+
+// cdromXML renders the CD-ROM drive holding the ISO at path, or an empty drive.
+func cdromXML(path string) string {
+	source := ""
+	if path != "" {
+		source = fmt.Sprintf("\n      <source file='%s'/>", xmlEscape(path))
+	}
+	return fmt.Sprintf(`
+    <disk type='file' device='cdrom'>
+      <driver name='qemu' type='raw'/>%s
+      <target dev='%s' bus='ide'/>
+      <readonly/>
+    </disk>`, source, cdromTarget)
+}
+
+// BootNetwork is the boot mode of VMs installed or run over the network.
+const BootNetwork = "network"
+
+// bootOrder validates the requested boot devices, defaulting to the CD-ROM
+// first when media is inserted and the disks otherwise. With boot set to
+// BootNetwork the network comes first and the disks second.
+func bootOrder(boot string, order []string, cdrom bool) ([]string, error) {
+	switch boot {
+	case "":
+	case BootNetwork:
+		if len(order) > 0 {
+			return nil, errors.New("boot network and a boot order are mutually exclusive")
+		}
+		return []string{"network", "hd"}, nil
+	default:
+		return nil, fmt.Errorf("invalid boot %q (want network)", boot)
+	}
+	if len(order) == 0 {
+		if cdrom {
+			return []string{"cdrom", "hd"}, nil
+		}
+		return []string{"hd"}, nil
+	}
+	seen := make(map[string]bool)
+	for _, dev := range order {
+		switch dev {
+		case "hd", "cdrom", "network":
+		default:
+			return nil, fmt.Errorf("invalid boot device %q (want hd, cdrom or network)", dev)
+		}
+		if seen[dev] {
+			return nil, fmt.Errorf("boot device %q listed twice", dev)
+		}
+		seen[dev] = true
+	}
+	return order, nil
+}
+
+// bootXML renders the <boot> elements of <os>.
+func bootXML(order []string) string {
+	var b strings.Builder
+	for _, dev := range order {
+		fmt.Fprintf(&b, "\n    <boot dev='%s'/>", dev)
+	}
+	return b.String()
+}
+
+// snapshotXML renders a disk-only snapshot that moves every disk of a domain
+// onto a new qcow2 overlay in dir. CD-ROMs are left alone.
+func snapshotXML(domainXML, dir, id string) (string, error) {
+	d, ok := parseDomain(domainXML)
+	if !ok {
+		return "", fmt.Errorf("parse domain xml")
+	}
+	var b strings.Builder
+	b.WriteString("<domainsnapshot>\n  <disks>")
+	for _, disk := range d.Disks {
+		if disk.Device != "" && disk.Device != "disk" {
+			fmt.Fprintf(&b, "\n    <disk name='%s' snapshot='no'/>", disk.Target.Dev)
+			continue
+		}
+		overlay := filepath.Join(dir, id+"-"+disk.Target.Dev+".qcow2")
+		fmt.Fprintf(&b, `
+    <disk name='%s' snapshot='external' type='file'>
+      <driver type='qcow2'/>
+      <source file='%s'/>
+    </disk>`, disk.Target.Dev, xmlEscape(overlay))
+	}
+	b.WriteString("\n  </disks>\n</domainsnapshot>")
+	return b.String(), nil
+}
+
+// overlayTargets lists the disks of a domain that currently write to an
+// overlay in dir.
+func overlayTargets(domainXML, dir string) []string {
+	d, _ := parseDomain(domainXML)
+	var out []string
+	for _, disk := range d.Disks {
+		if disk.Source.File != "" && filepath.Dir(disk.Source.File) == filepath.Clean(dir) {
+			out = append(out, disk.Target.Dev)
+		}
+	}
+	return out
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
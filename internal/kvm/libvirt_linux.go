@@ -4,7 +4,10 @@ package kvm
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -13,15 +16,17 @@ import (
 
 // LibvirtManager implements Manager using libvirt on Linux.
 type LibvirtManager struct {
-	address string
+	address    string
+	bridge     string
+	bridgeType string
 }
 
-func NewLibvirtManager(ctx context.Context, address string) (*LibvirtManager, error) {
+func NewLibvirtManager(ctx context.Context, address, bridge, bridgeType string) (*LibvirtManager, error) {
 	if address == "" {
 		address = "qemu:///system"
 	}
 	// Defer full connection until operations to avoid failing fast on startup.
-	return &LibvirtManager{address: address}, nil
+	return &LibvirtManager{address: address, bridge: bridge, bridgeType: bridgeType}, nil
 }
 
 func (l *LibvirtManager) dial() (*libvirt.Connect, error) {
@@ -33,39 +38,54 @@ func (l *LibvirtManager) dial() (*libvirt.Connect, error) {
 }
 
 func (l *LibvirtManager) CreateVM(ctx context.Context, req CreateVMRequest) (VM, error) {
-	if req.Name == "" || req.CPU <= 0 || req.MemoryBytes <= 0 || req.Image == "" {
+	if req.Name == "" || req.CPU <= 0 || req.MemoryBytes <= 0 || (len(req.Disks) == 0 && req.CDROM == "") {
 		return VM{}, fmt.Errorf("invalid create request")
 	}
+	boot, err := bootOrder(req.Boot, req.BootOrder, req.CDROM != "")
+	if err != nil {
+		return VM{}, err
+	}
+	nics, err := normalizeNICs(req.NICs, l.bridge, l.bridgeType)
+	if err != nil {
+		return VM{}, err
+	}
 	conn, err := l.dial()
 	if err != nil {
 		return VM{}, err
 	}
 	defer conn.Close()
+	for i := range nics {
+		if err := l.checkNIC(conn, &nics[i]); err != nil {
+			return VM{}, fmt.Errorf("nic %d: %w", i, err)
+		}
+	}
 
 	memoryKiB := req.MemoryBytes / 1024
-	diskType := "raw"
-	low := strings.ToLower(req.Image)
-	if strings.HasSuffix(low, ".qcow2") {
-		diskType = "qcow2"
+	var devices strings.Builder
+	for i, d := range req.Disks {
+		devices.WriteString(diskXML(d, diskTarget(i)))
+	}
+	devices.WriteString(cdromXML(req.CDROM))
+	for _, nic := range nics {
+		devices.WriteString(interfaceXML(nic))
 	}
 
 	domainXML := fmt.Sprintf(`
 <domain type='kvm'>
   <name>%s</name>
+  %s
   <memory unit='KiB'>%d</memory>
   <vcpu>%d</vcpu>
   <os>
-    <type arch='x86_64'>hvm</type>
+    <type arch='x86_64'>hvm</type>%s
   </os>
-  <devices>
-    <disk type='file' device='disk'>
-      <driver name='qemu' type='%s'/>
-      <source file='%s'/>
-      <target dev='vda' bus='virtio'/>
-    </disk>
+  <devices>%s
+    <channel type='unix'>
+      <target type='virtio' name='org.qemu.guest_agent.0'/>
+    </channel>
     <graphics type='vnc' autoport='yes'/>
   </devices>
-</domain>`, req.Name, memoryKiB, req.CPU, diskType, req.Image)
+</domain>`, req.Name, metadataXML(req.Image), memoryKiB, req.CPU, bootXML(boot), devices.String())
 
 	dom, err := conn.DomainDefineXML(domainXML)
 	if err != nil {
@@ -80,12 +100,256 @@ func (l *LibvirtManager) CreateVM(ctx context.Context, req CreateVMRequest) (VM,
 		MemoryBytes: req.MemoryBytes,
 		DiskBytes:   req.DiskBytes,
 		Image:       req.Image,
+		Disks:       req.Disks,
+		CDROM:       req.CDROM,
+		BootOrder:   boot,
+		NICs:        nics,
 		Status:      VMStatusStopped,
 		CreatedAt:   time.Now().UTC(),
 	}
 	return vm, nil
 }
 
+// lookup finds a domain by UUID or, failing that, by name.
+func (l *LibvirtManager) lookup(conn *libvirt.Connect, id string) (*libvirt.Domain, error) {
+	dom, err := conn.LookupDomainByUUIDString(id)
+	if err != nil {
+		dom, err = conn.LookupDomainByName(id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lookup domain: %w", err)
+	}
+	return dom, nil
+}
+
+// deviceFlags applies a device change to the persistent definition and, when
+// the domain is running, to the live VM as well.
+func deviceFlags(dom *libvirt.Domain) libvirt.DomainDeviceModifyFlags {
+	flags := libvirt.DOMAIN_DEVICE_MODIFY_CONFIG
+	if active, _ := dom.IsActive(); active {
+		flags |= libvirt.DOMAIN_DEVICE_MODIFY_LIVE
+	}
+	return flags
+}
+
+func (l *LibvirtManager) AttachDisk(ctx context.Context, id string, disk Disk) error {
+	conn, err := l.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	dom, err := l.lookup(conn, id)
+	if err != nil {
+		return err
+	}
+	defer dom.Free()
+	x, err := dom.GetXMLDesc(libvirt.DOMAIN_XML_INACTIVE)
+	if err != nil {
+		return fmt.Errorf("get xml: %w", err)
+	}
+	target, err := freeDiskTarget(x)
+	if err != nil {
+		return err
+	}
+	if err := dom.AttachDeviceFlags(diskXML(disk, target), deviceFlags(dom)); err != nil {
+		return fmt.Errorf("attach disk: %w", err)
+	}
+	return nil
+}
+
+func (l *LibvirtManager) DetachDisk(ctx context.Context, id string, path string) error {
+	conn, err := l.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	dom, err := l.lookup(conn, id)
+	if err != nil {
+		return err
+	}
+	defer dom.Free()
+	x, err := dom.GetXMLDesc(libvirt.DOMAIN_XML_INACTIVE)
+	if err != nil {
+		return fmt.Errorf("get xml: %w", err)
+	}
+	target, ok := diskTargetOf(x, path)
+	if !ok {
+		return fmt.Errorf("disk %s is not attached", path)
+	}
+	var disk Disk
+	for _, d := range domainDisks(x) {
+		if d.Path == path {
+			disk = d
+		}
+	}
+	if err := dom.DetachDeviceFlags(diskXML(disk, target), deviceFlags(dom)); err != nil {
+		return fmt.Errorf("detach disk: %w", err)
+	}
+	return nil
+}
+
+// ResizeDisk tells a running VM that the disk backed by path has grown.
+func (l *LibvirtManager) ResizeDisk(ctx context.Context, id string, path string, sizeBytes int64) error {
+	conn, err := l.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	dom, err := l.lookup(conn, id)
+	if err != nil {
+		return err
+	}
+	defer dom.Free()
+	if err := dom.BlockResize(path, uint64(sizeBytes), libvirt.DOMAIN_BLOCK_RESIZE_BYTES); err != nil {
+		return fmt.Errorf("block resize: %w", err)
+	}
+	return nil
+}
+
+func (l *LibvirtManager) ChangeMedia(ctx context.Context, id string, path string) error {
+	conn, err := l.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	dom, err := l.lookup(conn, id)
+	if err != nil {
+		return err
+	}
+	defer dom.Free()
+	if err := dom.UpdateDeviceFlags(cdromXML(path), deviceFlags(dom)); err != nil {
+		return fmt.Errorf("change media: %w", err)
+	}
+	return nil
+}
+
+func (l *LibvirtManager) AttachInterface(ctx context.Context, id string, nic NIC) (NIC, error) {
+	nic, err := normalizeNIC(nic, l.bridge, l.bridgeType)
+	if err != nil {
+		return NIC{}, err
+	}
+	conn, err := l.dial()
+	if err != nil {
+		return NIC{}, err
+	}
+	defer conn.Close()
+	dom, err := l.lookup(conn, id)
+	if err != nil {
+		return NIC{}, err
+	}
+	defer dom.Free()
+	nics, err := domainNICs(dom)
+	if err != nil {
+		return NIC{}, err
+	}
+	if _, err := findNIC(nics, nic.MAC); err == nil {
+		return NIC{}, fmt.Errorf("%s: %w", nic.MAC, ErrNICExists)
+	}
+	if err := l.checkNIC(conn, &nic); err != nil {
+		return NIC{}, err
+	}
+	if err := dom.AttachDeviceFlags(interfaceXML(nic), deviceFlags(dom)); err != nil {
+		return NIC{}, fmt.Errorf("attach interface: %w", err)
+	}
+	return nic, nil
+}
+
+// DetachInterface unplugs a NIC. On a running VM the guest has to release
+// the device, which libvirt waits for only briefly.
+func (l *LibvirtManager) DetachInterface(ctx context.Context, id string, mac string) error {
+	conn, err := l.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	dom, err := l.lookup(conn, id)
+	if err != nil {
+		return err
+	}
+	defer dom.Free()
+	nics, err := domainNICs(dom)
+	if err != nil {
+		return err
+	}
+	i, err := findNIC(nics, mac)
+	if err != nil {
+		return err
+	}
+	if err := dom.DetachDeviceFlags(interfaceXML(nics[i]), deviceFlags(dom)); err != nil {
+		return fmt.Errorf("detach interface: %w", err)
+	}
+	return nil
+}
+
+// UpdateInterface changes a NIC in place, so the guest keeps the device and
+// its MAC address and only sees the link change.
+func (l *LibvirtManager) UpdateInterface(ctx context.Context, id string, nic NIC) (NIC, error) {
+	conn, err := l.dial()
+	if err != nil {
+		return NIC{}, err
+	}
+	defer conn.Close()
+	dom, err := l.lookup(conn, id)
+	if err != nil {
+		return NIC{}, err
+	}
+	defer dom.Free()
+	nics, err := domainNICs(dom)
+	if err != nil {
+		return NIC{}, err
+	}
+	i, err := findNIC(nics, nic.MAC)
+	if err != nil {
+		return NIC{}, err
+	}
+	next, err := updateNIC(nics[i], nic, l.bridge, l.bridgeType)
+	if err != nil {
+		return NIC{}, err
+	}
+	if err := l.checkNIC(conn, &next); err != nil {
+		return NIC{}, err
+	}
+	if err := dom.UpdateDeviceFlags(interfaceXML(next), deviceFlags(dom)); err != nil {
+		return NIC{}, fmt.Errorf("update interface: %w", err)
+	}
+	return next, nil
+}
+
+// domainNICs returns the NICs of the persistent definition of dom.
+func domainNICs(dom *libvirt.Domain) ([]NIC, error) {
+	x, err := dom.GetXMLDesc(libvirt.DOMAIN_XML_INACTIVE)
+	if err != nil {
+		return nil, fmt.Errorf("get xml: %w", err)
+	}
+	var vm VM
+	applyDomainXML(&vm, x)
+	return vm.NICs, nil
+}
+
+// checkNIC makes sure the network or bridge of nic is there, as libvirt
+// only notices a missing network when the VM starts, and gives a NIC on a
+// network the network's bandwidth limits it does not override.
+func (l *LibvirtManager) checkNIC(conn *libvirt.Connect, nic *NIC) error {
+	if nic.Network == "" {
+		return checkBridgeType(*nic)
+	}
+	n, err := l.lookupNetwork(conn, nic.Network)
+	if err != nil {
+		return err
+	}
+	defer n.Free()
+	x, err := n.GetXMLDesc(0)
+	if err != nil {
+		return fmt.Errorf("get network xml: %w", err)
+	}
+	parsed, err := parseNetworkXML(x)
+	if err != nil {
+		return err
+	}
+	inheritBandwidth(nic, parsed)
+	return nil
+}
+
 func (l *LibvirtManager) DeleteVM(ctx context.Context, id string) error {
 	conn, err := l.dial()
 	if err != nil {
@@ -182,6 +446,9 @@ func (l *LibvirtManager) GetVM(ctx context.Context, id string) (VM, error) {
 		MemoryBytes: int64(info.Memory) * 1024,
 		Status:      status,
 	}
+	if x, err := dom.GetXMLDesc(0); err == nil {
+		applyDomainXML(&vm, x)
+	}
 	return vm, nil
 }
 
@@ -204,8 +471,353 @@ func (l *LibvirtManager) ListVMs(ctx context.Context) ([]VM, error) {
 		if info != nil && info.State == libvirt.DOMAIN_RUNNING {
 			status = VMStatusRunning
 		}
-		out = append(out, VM{ID: uuidStr, Name: name, CPU: int(info.NrVirtCpu), MemoryBytes: int64(info.Memory) * 1024, Status: status})
+		vm := VM{ID: uuidStr, Name: name, CPU: int(info.NrVirtCpu), MemoryBytes: int64(info.Memory) * 1024, Status: status}
+		if x, err := d.GetXMLDesc(0); err == nil {
+			applyDomainXML(&vm, x)
+		}
+		out = append(out, vm)
 		d.Free()
 	}
 	return out, nil
 }
+
+func (l *LibvirtManager) FreezeFilesystems(ctx context.Context, id string) error {
+	conn, err := l.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	dom, err := l.lookup(conn, id)
+	if err != nil {
+		return err
+	}
+	defer dom.Free()
+	if err := dom.FSFreeze(nil, 0); err != nil {
+		return fmt.Errorf("freeze filesystems: %w", err)
+	}
+	return nil
+}
+
+func (l *LibvirtManager) ThawFilesystems(ctx context.Context, id string) error {
+	conn, err := l.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	dom, err := l.lookup(conn, id)
+	if err != nil {
+		return err
+	}
+	defer dom.Free()
+	if err := dom.FSThaw(nil, 0); err != nil {
+		return fmt.Errorf("thaw filesystems: %w", err)
+	}
+	return nil
+}
+
+func (l *LibvirtManager) DomainXML(ctx context.Context, id string) (string, error) {
+	conn, err := l.dial()
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	dom, err := l.lookup(conn, id)
+	if err != nil {
+		return "", err
+	}
+	defer dom.Free()
+	x, err := dom.GetXMLDesc(libvirt.DOMAIN_XML_INACTIVE)
+	if err != nil {
+		return "", fmt.Errorf("get xml: %w", err)
+	}
+	return x, nil
+}
+
+// SnapshotDisks takes an external disk-only snapshot without libvirt
+// metadata, so nothing is left behind once MergeSnapshots pivots back.
+func (l *LibvirtManager) SnapshotDisks(ctx context.Context, id, dir string, quiesce bool) error {
+	conn, err := l.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	dom, err := l.lookup(conn, id)
+	if err != nil {
+		return err
+	}
+	defer dom.Free()
+	x, err := dom.GetXMLDesc(0)
+	if err != nil {
+		return fmt.Errorf("get xml: %w", err)
+	}
+	uuidStr, _ := dom.GetUUIDString()
+	snapXML, err := snapshotXML(x, dir, uuidStr)
+	if err != nil {
+		return err
+	}
+	flags := libvirt.DOMAIN_SNAPSHOT_CREATE_DISK_ONLY | libvirt.DOMAIN_SNAPSHOT_CREATE_ATOMIC | libvirt.DOMAIN_SNAPSHOT_CREATE_NO_METADATA
+	if quiesce {
+		flags |= libvirt.DOMAIN_SNAPSHOT_CREATE_QUIESCE
+	}
+	snap, err := dom.CreateSnapshotXML(snapXML, flags)
+	if err != nil {
+		return fmt.Errorf("snapshot disks: %w", err)
+	}
+	_ = snap.Free()
+	return nil
+}
+
+// MergeSnapshots runs an active block commit for every disk on an overlay in
+// dir and pivots once it has caught up. The overlays are deleted afterwards.
+func (l *LibvirtManager) MergeSnapshots(ctx context.Context, id, dir string) error {
+	conn, err := l.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	dom, err := l.lookup(conn, id)
+	if err != nil {
+		return err
+	}
+	defer dom.Free()
+	x, err := dom.GetXMLDesc(0)
+	if err != nil {
+		return fmt.Errorf("get xml: %w", err)
+	}
+	for _, target := range overlayTargets(x, dir) {
+		if err := dom.BlockCommit(target, "", "", 0, libvirt.DOMAIN_BLOCK_COMMIT_ACTIVE|libvirt.DOMAIN_BLOCK_COMMIT_DELETE); err != nil {
+			return fmt.Errorf("commit %s: %w", target, err)
+		}
+		if err := waitBlockJob(ctx, dom, target); err != nil {
+			return err
+		}
+		if err := dom.BlockJobAbort(target, libvirt.DOMAIN_BLOCK_JOB_ABORT_PIVOT); err != nil {
+			return fmt.Errorf("pivot %s: %w", target, err)
+		}
+	}
+	return nil
+}
+
+// waitBlockJob polls the block job of a disk until it is ready to pivot.
+func waitBlockJob(ctx context.Context, dom *libvirt.Domain, target string) error {
+	for {
+		info, err := dom.GetBlockJobInfo(target, 0)
+		if err != nil {
+			return fmt.Errorf("block job %s: %w", target, err)
+		}
+		if info.End > 0 && info.Cur == info.End {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// lookupNetwork finds a network by name, failing with ErrNetworkNotFound.
+func (l *LibvirtManager) lookupNetwork(conn *libvirt.Connect, name string) (*libvirt.Network, error) {
+	n, err := conn.LookupNetworkByName(name)
+	if err != nil {
+		var lerr libvirt.Error
+		if errors.As(err, &lerr) && lerr.Code == libvirt.ERR_NO_NETWORK {
+			return nil, fmt.Errorf("%s: %w", name, ErrNetworkNotFound)
+		}
+		return nil, fmt.Errorf("lookup network: %w", err)
+	}
+	return n, nil
+}
+
+func (l *LibvirtManager) CreateNetwork(ctx context.Context, spec NetworkSpec) (Network, error) {
+	spec, err := validateNetworkSpec(spec)
+	if err != nil {
+		return Network{}, err
+	}
+	conn, err := l.dial()
+	if err != nil {
+		return Network{}, err
+	}
+	defer conn.Close()
+	if n, err := conn.LookupNetworkByName(spec.Name); err == nil {
+		n.Free()
+		return Network{}, fmt.Errorf("%s: %w", spec.Name, ErrNetworkExists)
+	}
+	n, err := conn.NetworkDefineXML(networkXML(spec))
+	if err != nil {
+		return Network{}, fmt.Errorf("define network: %w", err)
+	}
+	defer n.Free()
+	if err := n.Create(); err != nil {
+		_ = n.Undefine()
+		return Network{}, fmt.Errorf("start network: %w", err)
+	}
+	if err := n.SetAutostart(true); err != nil {
+		_ = n.Destroy()
+		_ = n.Undefine()
+		return Network{}, fmt.Errorf("autostart network: %w", err)
+	}
+	return networkInfo(n)
+}
+
+func (l *LibvirtManager) DeleteNetwork(ctx context.Context, name string) error {
+	conn, err := l.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	n, err := l.lookupNetwork(conn, name)
+	if err != nil {
+		return err
+	}
+	defer n.Free()
+	if active, _ := n.IsActive(); active {
+		if err := n.Destroy(); err != nil {
+			return fmt.Errorf("stop network: %w", err)
+		}
+	}
+	if err := n.Undefine(); err != nil {
+		return fmt.Errorf("undefine network: %w", err)
+	}
+	return nil
+}
+
+func (l *LibvirtManager) GetNetwork(ctx context.Context, name string) (Network, error) {
+	conn, err := l.dial()
+	if err != nil {
+		return Network{}, err
+	}
+	defer conn.Close()
+	n, err := l.lookupNetwork(conn, name)
+	if err != nil {
+		return Network{}, err
+	}
+	defer n.Free()
+	return networkInfo(n)
+}
+
+func (l *LibvirtManager) ListNetworks(ctx context.Context) ([]Network, error) {
+	conn, err := l.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	nets, err := conn.ListAllNetworks(0)
+	if err != nil {
+		return nil, fmt.Errorf("list networks: %w", err)
+	}
+	var out []Network
+	for i := range nets {
+		if info, err := networkInfo(&nets[i]); err == nil {
+			out = append(out, info)
+		}
+		nets[i].Free()
+	}
+	return out, nil
+}
+
+func (l *LibvirtManager) AddDHCPHost(ctx context.Context, network string, host DHCPHost) error {
+	return l.updateDHCPHost(network, host, libvirt.NETWORK_UPDATE_COMMAND_ADD_LAST)
+}
+
+func (l *LibvirtManager) RemoveDHCPHost(ctx context.Context, network string, host DHCPHost) error {
+	return l.updateDHCPHost(network, host, libvirt.NETWORK_UPDATE_COMMAND_DELETE)
+}
+
+func (l *LibvirtManager) GuestAddresses(ctx context.Context, id string) ([]GuestAddress, error) {
+	conn, err := l.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	dom, err := l.lookup(conn, id)
+	if err != nil {
+		return nil, err
+	}
+	defer dom.Free()
+	if active, _ := dom.IsActive(); !active {
+		return nil, nil
+	}
+	var out []GuestAddress
+	for _, src := range []struct {
+		kind libvirt.DomainInterfaceAddressesSource
+		name string
+	}{{libvirt.DOMAIN_INTERFACE_ADDRESSES_SRC_LEASE, "lease"}, {libvirt.DOMAIN_INTERFACE_ADDRESSES_SRC_AGENT, "agent"}} {
+		// guests without an agent, or not booted far enough, have no answer
+		ifaces, err := dom.ListAllInterfaceAddresses(src.kind)
+		if err != nil {
+			continue
+		}
+		for _, iface := range ifaces {
+			for _, a := range iface.Addrs {
+				out = append(out, GuestAddress{MAC: iface.Hwaddr, IP: a.Addr, Source: src.name})
+			}
+		}
+	}
+	return out, nil
+}
+
+// updateDHCPHost changes the static leases of the persistent definition of a
+// network, and of the running dnsmasq when the network is active.
+func (l *LibvirtManager) updateDHCPHost(network string, host DHCPHost, cmd libvirt.NetworkUpdateCommand) error {
+	conn, err := l.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	n, err := l.lookupNetwork(conn, network)
+	if err != nil {
+		return err
+	}
+	defer n.Free()
+	x, err := n.GetXMLDesc(libvirt.NETWORK_XML_INACTIVE)
+	if err != nil {
+		return fmt.Errorf("get network xml: %w", err)
+	}
+	parent, err := dhcpParentIndex(x, host.IP)
+	if err != nil {
+		return err
+	}
+	flags := libvirt.NETWORK_UPDATE_AFFECT_CONFIG
+	if active, _ := n.IsActive(); active {
+		flags |= libvirt.NETWORK_UPDATE_AFFECT_LIVE
+	}
+	if err := n.Update(cmd, libvirt.NETWORK_SECTION_IP_DHCP_HOST, parent, dhcpHostXML(host), flags); err != nil {
+		return fmt.Errorf("update dhcp hosts of %s: %w", network, err)
+	}
+	return nil
+}
+
+// networkInfo reads the definition and state of a network.
+func networkInfo(n *libvirt.Network) (Network, error) {
+	x, err := n.GetXMLDesc(0)
+	if err != nil {
+		return Network{}, fmt.Errorf("get network xml: %w", err)
+	}
+	info, err := parseNetworkXML(x)
+	if err != nil {
+		return Network{}, err
+	}
+	info.Active, _ = n.IsActive()
+	return info, nil
+}
+
+// checkBridgeType makes sure the host bridge of a NIC is of the type the NIC
+// asks for: Linux bridges have a bridge directory in sysfs, Open vSwitch
+// bridges do not. Missing bridges are left for libvirt to report.
+func checkBridgeType(nic NIC) error {
+	if nic.Bridge == "" {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join("/sys/class/net", nic.Bridge)); err != nil {
+		return nil
+	}
+	_, err := os.Stat(filepath.Join("/sys/class/net", nic.Bridge, "bridge"))
+	switch {
+	case err == nil && nic.BridgeType == BridgeOpenVSwitch:
+		return fmt.Errorf("%s is a linux bridge, not an openvswitch one", nic.Bridge)
+	case err != nil && nic.BridgeType == BridgeLinux:
+		return fmt.Errorf("%s is not a linux bridge (set bridge_type openvswitch for ovs bridges)", nic.Bridge)
+	}
+	return nil
+}
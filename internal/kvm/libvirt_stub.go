@@ -9,7 +9,7 @@ import (
 
 type LibvirtManager struct{ address string }
 
-func NewLibvirtManager(ctx context.Context, address string, bridge string) (*LibvirtManager, error) {
+func NewLibvirtManager(ctx context.Context, address, bridge, bridgeType string) (*LibvirtManager, error) {
 	return nil, errors.New("libvirt manager is only supported on linux")
 }
 
@@ -31,3 +31,60 @@ func (l *LibvirtManager) GetVM(ctx context.Context, id string) (VM, error) {
 func (l *LibvirtManager) ListVMs(ctx context.Context) ([]VM, error) {
 	return nil, errors.New("libvirt manager is only supported on linux")
 }
+func (l *LibvirtManager) AttachDisk(ctx context.Context, id string, disk Disk) error {
+	return errors.New("libvirt manager is only supported on linux")
+}
+func (l *LibvirtManager) DetachDisk(ctx context.Context, id string, path string) error {
+	return errors.New("libvirt manager is only supported on linux")
+}
+func (l *LibvirtManager) AttachInterface(ctx context.Context, id string, nic NIC) (NIC, error) {
+	return NIC{}, errors.New("libvirt manager is only supported on linux")
+}
+func (l *LibvirtManager) DetachInterface(ctx context.Context, id string, mac string) error {
+	return errors.New("libvirt manager is only supported on linux")
+}
+func (l *LibvirtManager) UpdateInterface(ctx context.Context, id string, nic NIC) (NIC, error) {
+	return NIC{}, errors.New("libvirt manager is only supported on linux")
+}
+func (l *LibvirtManager) ResizeDisk(ctx context.Context, id string, path string, sizeBytes int64) error {
+	return errors.New("libvirt manager is only supported on linux")
+}
+func (l *LibvirtManager) ChangeMedia(ctx context.Context, id string, path string) error {
+	return errors.New("libvirt manager is only supported on linux")
+}
+func (l *LibvirtManager) FreezeFilesystems(ctx context.Context, id string) error {
+	return errors.New("libvirt manager is only supported on linux")
+}
+func (l *LibvirtManager) ThawFilesystems(ctx context.Context, id string) error {
+	return errors.New("libvirt manager is only supported on linux")
+}
+func (l *LibvirtManager) DomainXML(ctx context.Context, id string) (string, error) {
+	return "", errors.New("libvirt manager is only supported on linux")
+}
+func (l *LibvirtManager) SnapshotDisks(ctx context.Context, id, dir string, quiesce bool) error {
+	return errors.New("libvirt manager is only supported on linux")
+}
+func (l *LibvirtManager) MergeSnapshots(ctx context.Context, id, dir string) error {
+	return errors.New("libvirt manager is only supported on linux")
+}
+func (l *LibvirtManager) CreateNetwork(ctx context.Context, spec NetworkSpec) (Network, error) {
+	return Network{}, errors.New("libvirt manager is only supported on linux")
+}
+func (l *LibvirtManager) DeleteNetwork(ctx context.Context, name string) error {
+	return errors.New("libvirt manager is only supported on linux")
+}
+func (l *LibvirtManager) GetNetwork(ctx context.Context, name string) (Network, error) {
+	return Network{}, errors.New("libvirt manager is only supported on linux")
+}
+func (l *LibvirtManager) ListNetworks(ctx context.Context) ([]Network, error) {
+	return nil, errors.New("libvirt manager is only supported on linux")
+}
+func (l *LibvirtManager) AddDHCPHost(ctx context.Context, network string, host DHCPHost) error {
+	return errors.New("libvirt manager is only supported on linux")
+}
+func (l *LibvirtManager) RemoveDHCPHost(ctx context.Context, network string, host DHCPHost) error {
+	return errors.New("libvirt manager is only supported on linux")
+}
+func (l *LibvirtManager) GuestAddresses(ctx context.Context, id string) ([]GuestAddress, error) {
+	return nil, errors.New("libvirt manager is only supported on linux")
+}
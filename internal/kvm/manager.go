@@ -3,6 +3,7 @@ package kvm
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -24,16 +25,49 @@ type VM struct {
 	MemoryBytes int64     `json:"memory_bytes"`
 	DiskBytes   int64     `json:"disk_bytes"`
 	Image       string    `json:"image"`
+	Disks       []Disk    `json:"disks,omitempty"`
+	CDROM       string    `json:"cdrom,omitempty"` // media in the CD-ROM drive
+	BootOrder   []string  `json:"boot_order,omitempty"`
+	NICs        []NIC     `json:"nics,omitempty"`
 	Status      VMStatus  `json:"status"`
 	CreatedAt   time.Time `json:"created_at"`
+	// BootTemplate is the iPXE template the VM is served when it boots from
+	// the network, kept by the daemon rather than in the domain.
+	BootTemplate string `json:"boot_template,omitempty"`
 }
 
+// Disk is a volume attached to a VM, in attachment order.
+type Disk struct {
+	Path   string `json:"path"`
+	Format string `json:"format"`
+	// Block is set for block devices such as LVM volumes and zvols.
+	Block bool `json:"block,omitempty"`
+}
+
+// CreateVMRequest describes a new VM. Disks are attached in order; Image only
+// records what the root disk was cloned from and is never attached itself.
+// Every VM gets a CD-ROM drive, holding the ISO at CDROM if set, so media can
+// be changed later.
 type CreateVMRequest struct {
 	Name        string
 	CPU         int
 	MemoryBytes int64
 	DiskBytes   int64
 	Image       string
+	Disks       []Disk
+	CDROM       string
+	// BootOrder lists boot devices (hd, cdrom, network); it defaults to the
+	// CD-ROM first when one is inserted, then the disks.
+	BootOrder []string
+	// Boot set to BootNetwork boots the VM from its NICs first and then
+	// from its disks, in place of BootOrder.
+	Boot string
+	// BootTemplate is the iPXE template the VM boots from over the network.
+	// Like security groups it is kept by the daemon, not in the domain.
+	BootTemplate string
+	// NICs connect the VM to networks or bridges; without them it gets one
+	// NIC on the configured bridge. Missing MAC addresses are generated.
+	NICs []NIC
 }
 
 type Manager interface {
@@ -43,28 +77,89 @@ type Manager interface {
 	StopVM(ctx context.Context, id string) error
 	GetVM(ctx context.Context, id string) (VM, error)
 	ListVMs(ctx context.Context) ([]VM, error)
+	// AttachDisk adds a disk to a VM, live when it is running.
+	AttachDisk(ctx context.Context, id string, disk Disk) error
+	// DetachDisk removes the disk backed by path from a VM.
+	DetachDisk(ctx context.Context, id string, path string) error
+	// ResizeDisk makes a running VM pick up the new size of a grown disk.
+	ResizeDisk(ctx context.Context, id string, path string, sizeBytes int64) error
+	// ChangeMedia inserts the ISO at path into the CD-ROM drive, or ejects
+	// the current media when path is empty.
+	ChangeMedia(ctx context.Context, id string, path string) error
+	// AttachInterface adds a NIC to a VM, live when it is running, and
+	// returns it with its MAC address and model filled in.
+	AttachInterface(ctx context.Context, id string, nic NIC) (NIC, error)
+	// DetachInterface removes the NIC with MAC address mac from a VM.
+	DetachInterface(ctx context.Context, id string, mac string) error
+	// UpdateInterface moves the NIC with MAC address nic.MAC to the network
+	// or bridge of nic, if it names one, and sets its link state, if given,
+	// live when the VM is running.
+	UpdateInterface(ctx context.Context, id string, nic NIC) (NIC, error)
+	// FreezeFilesystems asks the guest agent to flush and freeze the guest's
+	// filesystems so its disks can be copied consistently while it runs.
+	FreezeFilesystems(ctx context.Context, id string) error
+	ThawFilesystems(ctx context.Context, id string) error
+	// DomainXML returns the libvirt definition of a VM.
+	DomainXML(ctx context.Context, id string) (string, error)
+	// SnapshotDisks moves the disks of a running VM onto overlays in dir, so
+	// the disks themselves stop changing and can be copied. With quiesce the
+	// guest agent freezes the filesystems while the overlays are created.
+	SnapshotDisks(ctx context.Context, id, dir string, quiesce bool) error
+	// MergeSnapshots commits the overlays created by SnapshotDisks back into
+	// the disks and switches the VM back to them.
+	MergeSnapshots(ctx context.Context, id, dir string) error
+
+	// CreateNetwork defines, starts and autostarts a managed network.
+	CreateNetwork(ctx context.Context, spec NetworkSpec) (Network, error)
+	// DeleteNetwork stops and undefines a network.
+	DeleteNetwork(ctx context.Context, name string) error
+	GetNetwork(ctx context.Context, name string) (Network, error)
+	ListNetworks(ctx context.Context) ([]Network, error)
+	// AddDHCPHost adds a static lease to a network, live when it is active.
+	AddDHCPHost(ctx context.Context, network string, host DHCPHost) error
+	// RemoveDHCPHost removes a static lease added by AddDHCPHost.
+	RemoveDHCPHost(ctx context.Context, network string, host DHCPHost) error
+	// GuestAddresses returns the addresses a running VM got from the DHCP
+	// servers of managed networks or reports through its guest agent.
+	GuestAddresses(ctx context.Context, id string) ([]GuestAddress, error)
 }
 
 // InMemoryManager is a functional placeholder used for local development and API plumbing tests.
 type InMemoryManager struct {
-	mu      sync.RWMutex
-	vms     map[string]VM
-	nameIdx map[string]string
+	mu       sync.RWMutex
+	vms      map[string]VM
+	nameIdx  map[string]string
+	networks map[string]Network
 }
 
 func NewInMemoryManager() *InMemoryManager {
-	return &InMemoryManager{vms: make(map[string]VM), nameIdx: make(map[string]string)}
+	return &InMemoryManager{vms: make(map[string]VM), nameIdx: make(map[string]string), networks: make(map[string]Network)}
 }
 
 func (m *InMemoryManager) CreateVM(ctx context.Context, req CreateVMRequest) (VM, error) {
 	if req.Name == "" || req.CPU <= 0 || req.MemoryBytes <= 0 || req.DiskBytes <= 0 {
 		return VM{}, fmt.Errorf("invalid create request")
 	}
+	boot, err := bootOrder(req.Boot, req.BootOrder, req.CDROM != "")
+	if err != nil {
+		return VM{}, err
+	}
+	nics, err := normalizeNICs(req.NICs, "", "")
+	if err != nil {
+		return VM{}, err
+	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	if _, exists := m.nameIdx[req.Name]; exists {
 		return VM{}, fmt.Errorf("vm with name %q already exists", req.Name)
 	}
+	for i, nic := range nics {
+		n, ok := m.networks[nic.Network]
+		if nic.Network != "" && !ok {
+			return VM{}, fmt.Errorf("%s: %w", nic.Network, ErrNetworkNotFound)
+		}
+		inheritBandwidth(&nics[i], n)
+	}
 	id := uuid.NewString()
 	vm := VM{
 		ID:          id,
@@ -73,6 +168,10 @@ func (m *InMemoryManager) CreateVM(ctx context.Context, req CreateVMRequest) (VM
 		MemoryBytes: req.MemoryBytes,
 		DiskBytes:   req.DiskBytes,
 		Image:       req.Image,
+		Disks:       req.Disks,
+		CDROM:       req.CDROM,
+		BootOrder:   boot,
+		NICs:        nics,
 		Status:      VMStatusStopped,
 		CreatedAt:   time.Now().UTC(),
 	}
@@ -137,4 +236,215 @@ func (m *InMemoryManager) ListVMs(ctx context.Context) ([]VM, error) {
 	return list, nil
 }
 
+func (m *InMemoryManager) AttachDisk(ctx context.Context, id string, disk Disk) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	vm, ok := m.vms[id]
+	if !ok {
+		return notFound(id)
+	}
+	vm.Disks = append(slices.Clone(vm.Disks), disk)
+	m.vms[id] = vm
+	return nil
+}
+
+func (m *InMemoryManager) DetachDisk(ctx context.Context, id string, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	vm, ok := m.vms[id]
+	if !ok {
+		return notFound(id)
+	}
+	i := slices.IndexFunc(vm.Disks, func(d Disk) bool { return d.Path == path })
+	if i < 0 {
+		return fmt.Errorf("disk %s is not attached", path)
+	}
+	vm.Disks = slices.Delete(slices.Clone(vm.Disks), i, i+1)
+	m.vms[id] = vm
+	return nil
+}
+
+func (m *InMemoryManager) AttachInterface(ctx context.Context, id string, nic NIC) (NIC, error) {
+	nic, err := normalizeNIC(nic, "", "")
+	if err != nil {
+		return NIC{}, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	vm, ok := m.vms[id]
+	if !ok {
+		return NIC{}, notFound(id)
+	}
+	n, ok := m.networks[nic.Network]
+	if nic.Network != "" && !ok {
+		return NIC{}, fmt.Errorf("%s: %w", nic.Network, ErrNetworkNotFound)
+	}
+	inheritBandwidth(&nic, n)
+	if _, err := findNIC(vm.NICs, nic.MAC); err == nil {
+		return NIC{}, fmt.Errorf("%s: %w", nic.MAC, ErrNICExists)
+	}
+	vm.NICs = append(slices.Clone(vm.NICs), nic)
+	m.vms[id] = vm
+	return nic, nil
+}
+
+func (m *InMemoryManager) DetachInterface(ctx context.Context, id string, mac string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	vm, ok := m.vms[id]
+	if !ok {
+		return notFound(id)
+	}
+	i, err := findNIC(vm.NICs, mac)
+	if err != nil {
+		return err
+	}
+	vm.NICs = slices.Delete(slices.Clone(vm.NICs), i, i+1)
+	m.vms[id] = vm
+	return nil
+}
+
+func (m *InMemoryManager) UpdateInterface(ctx context.Context, id string, nic NIC) (NIC, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	vm, ok := m.vms[id]
+	if !ok {
+		return NIC{}, notFound(id)
+	}
+	i, err := findNIC(vm.NICs, nic.MAC)
+	if err != nil {
+		return NIC{}, err
+	}
+	next, err := updateNIC(vm.NICs[i], nic, "", "")
+	if err != nil {
+		return NIC{}, err
+	}
+	n, ok := m.networks[next.Network]
+	if next.Network != "" && !ok {
+		return NIC{}, fmt.Errorf("%s: %w", next.Network, ErrNetworkNotFound)
+	}
+	inheritBandwidth(&next, n)
+	vm.NICs = slices.Clone(vm.NICs)
+	vm.NICs[i] = next
+	m.vms[id] = vm
+	return next, nil
+}
+
+func (m *InMemoryManager) ResizeDisk(ctx context.Context, id string, path string, sizeBytes int64) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if _, ok := m.vms[id]; !ok {
+		return notFound(id)
+	}
+	return nil
+}
+
+func (m *InMemoryManager) ChangeMedia(ctx context.Context, id string, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	vm, ok := m.vms[id]
+	if !ok {
+		return notFound(id)
+	}
+	vm.CDROM = path
+	m.vms[id] = vm
+	return nil
+}
+
+// FreezeFilesystems has no guest to talk to; it only checks the VM exists.
+func (m *InMemoryManager) FreezeFilesystems(ctx context.Context, id string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if _, ok := m.vms[id]; !ok {
+		return notFound(id)
+	}
+	return nil
+}
+
+func (m *InMemoryManager) ThawFilesystems(ctx context.Context, id string) error {
+	return m.FreezeFilesystems(ctx, id)
+}
+
+// DomainXML has no libvirt domain to describe; in-memory VMs have an empty
+// definition.
+func (m *InMemoryManager) DomainXML(ctx context.Context, id string) (string, error) {
+	return "", m.FreezeFilesystems(ctx, id)
+}
+
+// SnapshotDisks has no disks being written to; it only checks the VM exists.
+func (m *InMemoryManager) SnapshotDisks(ctx context.Context, id, dir string, quiesce bool) error {
+	return m.FreezeFilesystems(ctx, id)
+}
+
+func (m *InMemoryManager) MergeSnapshots(ctx context.Context, id, dir string) error {
+	return m.FreezeFilesystems(ctx, id)
+}
+
+func (m *InMemoryManager) CreateNetwork(ctx context.Context, spec NetworkSpec) (Network, error) {
+	spec, err := validateNetworkSpec(spec)
+	if err != nil {
+		return Network{}, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.networks[spec.Name]; ok {
+		return Network{}, fmt.Errorf("%s: %w", spec.Name, ErrNetworkExists)
+	}
+	if spec.Bridge == "" {
+		spec.Bridge = fmt.Sprintf("virbr%d", len(m.networks)+1)
+	}
+	n := Network{NetworkSpec: spec, UUID: uuid.NewString(), Active: true}
+	m.networks[n.Name] = n
+	return n, nil
+}
+
+func (m *InMemoryManager) DeleteNetwork(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.networks[name]; !ok {
+		return fmt.Errorf("%s: %w", name, ErrNetworkNotFound)
+	}
+	delete(m.networks, name)
+	return nil
+}
+
+func (m *InMemoryManager) GetNetwork(ctx context.Context, name string) (Network, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	n, ok := m.networks[name]
+	if !ok {
+		return Network{}, fmt.Errorf("%s: %w", name, ErrNetworkNotFound)
+	}
+	return n, nil
+}
+
+func (m *InMemoryManager) ListNetworks(ctx context.Context) ([]Network, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	list := make([]Network, 0, len(m.networks))
+	for _, n := range m.networks {
+		list = append(list, n)
+	}
+	return list, nil
+}
+
+func (m *InMemoryManager) AddDHCPHost(ctx context.Context, network string, host DHCPHost) error {
+	_, err := m.GetNetwork(ctx, network)
+	return err
+}
+
+func (m *InMemoryManager) RemoveDHCPHost(ctx context.Context, network string, host DHCPHost) error {
+	_, err := m.GetNetwork(ctx, network)
+	return err
+}
+
+func (m *InMemoryManager) GuestAddresses(ctx context.Context, id string) ([]GuestAddress, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if _, ok := m.vms[id]; !ok {
+		return nil, notFound(id)
+	}
+	return nil, nil
+}
+
 func notFound(id string) error { return fmt.Errorf("vm %s not found", id) }
@@ -0,0 +1,679 @@
+package kvm
+
+import (
+	"crypto/rand"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// Network modes of managed networks.
+const (
+	// NetworkNAT masquerades guest traffic behind the host's addresses.
+	NetworkNAT = "nat"
+	// NetworkIsolated connects VMs with each other and the host only.
+	NetworkIsolated = "isolated"
+	// NetworkRouted routes the subnet through the host without NAT; the
+	// rest of the network needs a route to it.
+	NetworkRouted = "routed"
+)
+
+var (
+	ErrNetworkNotFound = errors.New("network not found")
+	ErrNetworkExists   = errors.New("network already exists")
+	ErrNICNotFound     = errors.New("nic not found")
+	ErrNICExists       = errors.New("mac address already in use")
+)
+
+// Subnet is an IPv4 or IPv6 subnet of a managed network. The host takes
+// Gateway, by default the first address of CIDR, and serves DHCP leases from
+// DHCPStart to DHCPEnd when both are set.
+type Subnet struct {
+	CIDR      string `json:"cidr"`
+	Gateway   string `json:"gateway,omitempty"`
+	DHCPStart string `json:"dhcp_start,omitempty"`
+	DHCPEnd   string `json:"dhcp_end,omitempty"`
+}
+
+// NetworkSpec describes a managed network. Bridge names the host bridge
+// libvirt creates for it, picked by libvirt when empty; Uplink restricts NAT
+// and routed traffic to one host interface. With ExternalDNS libvirt's
+// dnsmasq only serves DHCP, leaving the gateway's DNS port to DeusVM's DNS
+// server, which DHCP clients are pointed at. Inbound and Outbound are the
+// bandwidth limits of NICs on the network that set none of their own. With
+// PXE, DHCP clients booting from the network are sent to the gateway for
+// their boot files, which DeusVM's boot server serves.
+type NetworkSpec struct {
+	Name        string     `json:"name"`
+	Mode        string     `json:"mode"`
+	Bridge      string     `json:"bridge,omitempty"`
+	Uplink      string     `json:"uplink,omitempty"`
+	IPv4        *Subnet    `json:"ipv4,omitempty"`
+	IPv6        *Subnet    `json:"ipv6,omitempty"`
+	ExternalDNS bool       `json:"external_dns,omitempty"`
+	Inbound     *Bandwidth `json:"inbound,omitempty"`
+	Outbound    *Bandwidth `json:"outbound,omitempty"`
+	PXE         bool       `json:"pxe,omitempty"`
+}
+
+// Network is a managed network as defined in libvirt.
+type Network struct {
+	NetworkSpec
+	UUID   string `json:"uuid"`
+	Active bool   `json:"active"`
+}
+
+// Host bridge types.
+const (
+	BridgeLinux       = "linux"
+	BridgeOpenVSwitch = "openvswitch"
+)
+
+// Bandwidth limits the traffic of a NIC in one direction, as libvirt
+// shapes it: Average and Peak are rates in KiB/s and Burst is how many KiB
+// may be sent at Peak rate. Peak and Burst are optional.
+type Bandwidth struct {
+	Average int `json:"average" xml:"average,attr"`
+	Peak    int `json:"peak,omitempty" xml:"peak,attr"`
+	Burst   int `json:"burst,omitempty" xml:"burst,attr"`
+}
+
+// NIC link states.
+const (
+	LinkUp   = "up"
+	LinkDown = "down"
+)
+
+// NIC is a network interface of a VM, connected to a managed network or
+// straight to a host bridge.
+type NIC struct {
+	Network string `json:"network,omitempty"`
+	Bridge  string `json:"bridge,omitempty"`
+	// BridgeType is linux or openvswitch, for NICs on a host bridge.
+	BridgeType string `json:"bridge_type,omitempty"`
+	// VLAN tags the NIC's traffic on the bridge (access port); Trunk lists
+	// the VLANs a trunk port passes tagged instead. Both need an
+	// openvswitch bridge.
+	VLAN  int    `json:"vlan,omitempty"`
+	Trunk []int  `json:"trunk,omitempty"`
+	MAC   string `json:"mac,omitempty"`
+	// Model is the emulated device, virtio by default.
+	Model string `json:"model,omitempty"`
+	// LinkState is up, the default, or down, which disconnects the NIC as
+	// if its cable were pulled.
+	LinkState string `json:"link_state,omitempty"`
+	// Inbound limits the traffic to the VM and Outbound the traffic from
+	// it. NICs on a managed network default to its limits.
+	Inbound  *Bandwidth `json:"inbound,omitempty"`
+	Outbound *Bandwidth `json:"outbound,omitempty"`
+	// IPv4 and IPv6 request fixed addresses on a managed network; on VMs
+	// they report the addresses reserved for the NIC.
+	IPv4 string `json:"ipv4,omitempty"`
+	IPv6 string `json:"ipv6,omitempty"`
+	// SecurityGroups filter the traffic of the NIC; none leaves it open.
+	SecurityGroups []string `json:"security_groups,omitempty"`
+	// Device is the host tap device of the NIC while the VM runs.
+	Device string `json:"device,omitempty"`
+}
+
+// GuestAddress is an address a running VM uses on one of its NICs, as
+// reported by the DHCP server of its network (Source "lease") or by the
+// guest agent (Source "agent").
+type GuestAddress struct {
+	MAC    string `json:"mac"`
+	IP     string `json:"ip"`
+	Source string `json:"source"`
+}
+
+// DHCPHost is a static DHCP lease of a managed network. IPv4 clients are
+// matched by MAC; DHCPv6 clients identify with a DUID instead, so IPv6 hosts
+// are matched by the host name the guest sends.
+type DHCPHost struct {
+	MAC  string
+	IP   string
+	Name string
+}
+
+// dhcpHostXML renders h as a <host> element of a network <dhcp> section.
+func dhcpHostXML(h DHCPHost) string {
+	if addr, err := netip.ParseAddr(h.IP); err == nil && addr.Is6() {
+		return fmt.Sprintf("<host name='%s' ip='%s'/>", xmlEscape(h.Name), addr)
+	}
+	return fmt.Sprintf("<host mac='%s' name='%s' ip='%s'/>", xmlEscape(h.MAC), xmlEscape(h.Name), xmlEscape(h.IP))
+}
+
+// dhcpParentIndex returns the index of the <ip> element of a network
+// definition in the family of ip, which libvirt needs to update IPv6 hosts.
+func dhcpParentIndex(networkXML, ip string) (int, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return 0, fmt.Errorf("invalid address %q", ip)
+	}
+	var d networkDoc
+	if err := xml.Unmarshal([]byte(networkXML), &d); err != nil {
+		return 0, fmt.Errorf("parse network xml: %w", err)
+	}
+	for i, el := range d.IPs {
+		if (el.Family == "ipv6") == addr.Is6() {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("network %s has no subnet for %s", d.Name, addr)
+}
+
+// validateNetworkSpec checks spec and fills in the gateways.
+func validateNetworkSpec(spec NetworkSpec) (NetworkSpec, error) {
+	if spec.Name == "" || strings.ContainsAny(spec.Name, "/ ") {
+		return NetworkSpec{}, fmt.Errorf("invalid network name %q", spec.Name)
+	}
+	switch spec.Mode {
+	case "":
+		spec.Mode = NetworkNAT
+	case NetworkNAT, NetworkIsolated, NetworkRouted:
+	default:
+		return NetworkSpec{}, fmt.Errorf("invalid network mode %q (want nat, isolated or routed)", spec.Mode)
+	}
+	if spec.Mode == NetworkIsolated && spec.Uplink != "" {
+		return NetworkSpec{}, errors.New("isolated networks have no uplink")
+	}
+	for _, s := range []struct {
+		subnet **Subnet
+		v4     bool
+	}{{&spec.IPv4, true}, {&spec.IPv6, false}} {
+		if *s.subnet == nil {
+			continue
+		}
+		sub, err := validateSubnet(**s.subnet, s.v4)
+		if err != nil {
+			return NetworkSpec{}, err
+		}
+		*s.subnet = &sub
+	}
+	if spec.Mode == NetworkNAT && spec.IPv4 == nil {
+		return NetworkSpec{}, errors.New("nat networks need an ipv4 subnet")
+	}
+	if spec.Mode == NetworkRouted && spec.IPv4 == nil && spec.IPv6 == nil {
+		return NetworkSpec{}, errors.New("routed networks need a subnet")
+	}
+	if spec.PXE && (spec.IPv4 == nil || spec.IPv4.DHCPStart == "") {
+		return NetworkSpec{}, errors.New("pxe needs an ipv4 subnet with dhcp")
+	}
+	var err error
+	if spec.Inbound, err = validateBandwidth(spec.Inbound, "inbound"); err != nil {
+		return NetworkSpec{}, err
+	}
+	if spec.Outbound, err = validateBandwidth(spec.Outbound, "outbound"); err != nil {
+		return NetworkSpec{}, err
+	}
+	return spec, nil
+}
+
+// validateBandwidth checks the limits of one direction. A nil or zero b
+// sets no limit and yields nil.
+func validateBandwidth(b *Bandwidth, direction string) (*Bandwidth, error) {
+	if b == nil || *b == (Bandwidth{}) {
+		return nil, nil
+	}
+	if b.Average <= 0 || b.Peak < 0 || b.Burst < 0 || uint64(b.Average) > math.MaxUint32 || uint64(b.Peak) > math.MaxUint32 || uint64(b.Burst) > math.MaxUint32 {
+		return nil, fmt.Errorf("invalid %s bandwidth: average must be positive, peak and burst not negative", direction)
+	}
+	if b.Peak != 0 && b.Peak < b.Average {
+		return nil, fmt.Errorf("invalid %s bandwidth: peak %d is below average %d", direction, b.Peak, b.Average)
+	}
+	out := *b
+	return &out, nil
+}
+
+// bandwidthXML renders the limits of a NIC or network as <bandwidth>, or
+// nothing when there are none, indented by indent.
+func bandwidthXML(in, out *Bandwidth, indent string) string {
+	if in == nil && out == nil {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n%s<bandwidth>", indent)
+	for _, dir := range []struct {
+		name string
+		bw   *Bandwidth
+	}{{"inbound", in}, {"outbound", out}} {
+		if dir.bw == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s  <%s average='%d'", indent, dir.name, dir.bw.Average)
+		if dir.bw.Peak != 0 {
+			fmt.Fprintf(&b, " peak='%d'", dir.bw.Peak)
+		}
+		if dir.bw.Burst != 0 {
+			fmt.Fprintf(&b, " burst='%d'", dir.bw.Burst)
+		}
+		b.WriteString("/>")
+	}
+	fmt.Fprintf(&b, "\n%s</bandwidth>", indent)
+	return b.String()
+}
+
+// bandwidthDoc reads <bandwidth> back.
+type bandwidthDoc struct {
+	Inbound  *Bandwidth `xml:"inbound"`
+	Outbound *Bandwidth `xml:"outbound"`
+}
+
+// inheritBandwidth gives a NIC on network n that limits only one direction
+// the network's limit for the other: libvirt ignores the network's limits
+// altogether for NICs with limits of their own. NICs without limits take
+// the network's from libvirt as they are plugged in.
+func inheritBandwidth(nic *NIC, n Network) {
+	if nic.Inbound == nil && nic.Outbound == nil {
+		return
+	}
+	if nic.Inbound == nil {
+		nic.Inbound = n.Inbound
+	}
+	if nic.Outbound == nil {
+		nic.Outbound = n.Outbound
+	}
+}
+
+func validateSubnet(s Subnet, v4 bool) (Subnet, error) {
+	family := "ipv6"
+	if v4 {
+		family = "ipv4"
+	}
+	prefix, err := netip.ParsePrefix(s.CIDR)
+	if err != nil || prefix.Addr().Is4() != v4 {
+		return Subnet{}, fmt.Errorf("invalid %s subnet %q", family, s.CIDR)
+	}
+	prefix = prefix.Masked()
+	s.CIDR = prefix.String()
+	gw := prefix.Addr().Next()
+	if s.Gateway != "" {
+		if gw, err = netip.ParseAddr(s.Gateway); err != nil {
+			return Subnet{}, fmt.Errorf("invalid %s gateway %q", family, s.Gateway)
+		}
+	}
+	if !prefix.Contains(gw) || gw == prefix.Addr() {
+		return Subnet{}, fmt.Errorf("gateway %s is not a host address of %s", gw, prefix)
+	}
+	s.Gateway = gw.String()
+	if (s.DHCPStart == "") != (s.DHCPEnd == "") {
+		return Subnet{}, fmt.Errorf("%s dhcp range needs a start and an end", family)
+	}
+	if s.DHCPStart != "" {
+		start, err1 := netip.ParseAddr(s.DHCPStart)
+		end, err2 := netip.ParseAddr(s.DHCPEnd)
+		if err1 != nil || err2 != nil || !prefix.Contains(start) || !prefix.Contains(end) || end.Less(start) {
+			return Subnet{}, fmt.Errorf("invalid %s dhcp range %s-%s for %s", family, s.DHCPStart, s.DHCPEnd, prefix)
+		}
+		if !gw.Less(start) && !end.Less(gw) {
+			return Subnet{}, fmt.Errorf("%s dhcp range %s-%s includes the gateway %s", family, start, end, gw)
+		}
+	}
+	return s, nil
+}
+
+// networkXML renders the libvirt definition of a validated spec.
+func networkXML(spec NetworkSpec) string {
+	var b strings.Builder
+	if spec.ExternalDNS || spec.PXE {
+		fmt.Fprintf(&b, "<network xmlns:dnsmasq='%s'>", dnsmasqNS)
+	} else {
+		b.WriteString("<network>")
+	}
+	fmt.Fprintf(&b, "\n  <name>%s</name>", xmlEscape(spec.Name))
+	switch spec.Mode {
+	case NetworkNAT, NetworkRouted:
+		mode := "nat"
+		if spec.Mode == NetworkRouted {
+			mode = "route"
+		}
+		dev := ""
+		if spec.Uplink != "" {
+			dev = fmt.Sprintf(" dev='%s'", xmlEscape(spec.Uplink))
+		}
+		fmt.Fprintf(&b, "\n  <forward mode='%s'%s/>", mode, dev)
+	}
+	if spec.Bridge != "" {
+		fmt.Fprintf(&b, "\n  <bridge name='%s' stp='on' delay='0'/>", xmlEscape(spec.Bridge))
+	} else {
+		b.WriteString("\n  <bridge stp='on' delay='0'/>")
+	}
+	for _, s := range []*Subnet{spec.IPv4, spec.IPv6} {
+		if s == nil {
+			continue
+		}
+		prefix := netip.MustParsePrefix(s.CIDR)
+		family := ""
+		if !prefix.Addr().Is4() {
+			family = " family='ipv6'"
+		}
+		fmt.Fprintf(&b, "\n  <ip%s address='%s' prefix='%d'>", family, s.Gateway, prefix.Bits())
+		if s.DHCPStart != "" {
+			fmt.Fprintf(&b, "\n    <dhcp>\n      <range start='%s' end='%s'/>\n    </dhcp>", s.DHCPStart, s.DHCPEnd)
+		}
+		b.WriteString("\n  </ip>")
+	}
+	if spec.Inbound != nil || spec.Outbound != nil {
+		// NICs without limits of their own take those of the default portgroup
+		b.WriteString("\n  <portgroup name='default' default='yes'>")
+		b.WriteString(bandwidthXML(spec.Inbound, spec.Outbound, "    "))
+		b.WriteString("\n  </portgroup>")
+	}
+	if spec.ExternalDNS {
+		b.WriteString("\n  <dns enable='no'/>")
+	}
+	if spec.ExternalDNS || spec.PXE {
+		b.WriteString("\n  <dnsmasq:options>")
+	}
+	if spec.ExternalDNS {
+		// dnsmasq only hands out its own address as DNS server while it serves DNS
+		if spec.IPv4 != nil {
+			fmt.Fprintf(&b, "\n    <dnsmasq:option value='dhcp-option=option:dns-server,%s'/>", spec.IPv4.Gateway)
+		}
+		if spec.IPv6 != nil {
+			fmt.Fprintf(&b, "\n    <dnsmasq:option value='dhcp-option=option6:dns-server,[%s]'/>", spec.IPv6.Gateway)
+		}
+	}
+	if spec.PXE {
+		// iPXE, which QEMU's NIC ROMs are built from, gets the script chaining
+		// to the VM's own; other PXE clients get iPXE first
+		gw := spec.IPv4.Gateway
+		fmt.Fprintf(&b, "\n    <dnsmasq:option value='%s'/>", pxeMatch)
+		b.WriteString("\n    <dnsmasq:option value='dhcp-match=set:efi64,option:client-arch,7'/>")
+		fmt.Fprintf(&b, "\n    <dnsmasq:option value='dhcp-boot=tag:ipxe,%s,,%s'/>", BootScript, gw)
+		fmt.Fprintf(&b, "\n    <dnsmasq:option value='dhcp-boot=tag:!ipxe,tag:efi64,%s,,%s'/>", BootLoaderEFI, gw)
+		fmt.Fprintf(&b, "\n    <dnsmasq:option value='dhcp-boot=tag:!ipxe,tag:!efi64,%s,,%s'/>", BootLoaderBIOS, gw)
+	}
+	if spec.ExternalDNS || spec.PXE {
+		b.WriteString("\n  </dnsmasq:options>")
+	}
+	b.WriteString("\n</network>")
+	return b.String()
+}
+
+// dnsmasqNS is the namespace of the dnsmasq options of a network definition.
+const dnsmasqNS = "http://libvirt.org/schemas/network/dnsmasq/1.0"
+
+// Files DHCP clients of networks with PXE are told to fetch over TFTP from
+// the gateway: the iPXE script for clients already running iPXE, and iPXE
+// itself for the others.
+const (
+	BootScript     = "boot.ipxe"
+	BootLoaderBIOS = "undionly.kpxe"
+	BootLoaderEFI  = "ipxe.efi"
+)
+
+// pxeMatch is the dnsmasq option tagging iPXE clients, which tells networks
+// with PXE apart when their definition is read back.
+const pxeMatch = "dhcp-match=set:ipxe,175"
+
+// networkDoc is the subset of a libvirt network definition read back.
+type networkDoc struct {
+	Name    string `xml:"name"`
+	UUID    string `xml:"uuid"`
+	Forward *struct {
+		Mode string `xml:"mode,attr"`
+		Dev  string `xml:"dev,attr"`
+	} `xml:"forward"`
+	Bridge struct {
+		Name string `xml:"name,attr"`
+	} `xml:"bridge"`
+	DNS *struct {
+		Enable string `xml:"enable,attr"`
+	} `xml:"dns"`
+	PortGroups []struct {
+		Default   string       `xml:"default,attr"`
+		Bandwidth bandwidthDoc `xml:"bandwidth"`
+	} `xml:"portgroup"`
+	DnsmasqOptions []struct {
+		Value string `xml:"value,attr"`
+	} `xml:"options>option"`
+	IPs []struct {
+		Family  string `xml:"family,attr"`
+		Address string `xml:"address,attr"`
+		Prefix  int    `xml:"prefix,attr"`
+		Netmask string `xml:"netmask,attr"`
+		Range   struct {
+			Start string `xml:"start,attr"`
+			End   string `xml:"end,attr"`
+		} `xml:"dhcp>range"`
+	} `xml:"ip"`
+}
+
+// parseNetworkXML reads a network definition. Networks forwarding in modes
+// other than nat and route, such as host bridges defined outside DeusVM,
+// keep the libvirt mode name.
+func parseNetworkXML(networkXML string) (Network, error) {
+	var d networkDoc
+	if err := xml.Unmarshal([]byte(networkXML), &d); err != nil {
+		return Network{}, fmt.Errorf("parse network xml: %w", err)
+	}
+	n := Network{NetworkSpec: NetworkSpec{Name: d.Name, Mode: NetworkIsolated, Bridge: d.Bridge.Name}, UUID: d.UUID}
+	n.ExternalDNS = d.DNS != nil && d.DNS.Enable == "no"
+	for _, o := range d.DnsmasqOptions {
+		n.PXE = n.PXE || o.Value == pxeMatch
+	}
+	for _, pg := range d.PortGroups {
+		if pg.Default == "yes" {
+			n.Inbound, n.Outbound = pg.Bandwidth.Inbound, pg.Bandwidth.Outbound
+		}
+	}
+	if d.Forward != nil {
+		n.Uplink = d.Forward.Dev
+		switch d.Forward.Mode {
+		case "", "nat":
+			n.Mode = NetworkNAT
+		case "route":
+			n.Mode = NetworkRouted
+		default:
+			n.Mode = d.Forward.Mode
+		}
+	}
+	for _, ip := range d.IPs {
+		addr, err := netip.ParseAddr(ip.Address)
+		if err != nil {
+			continue
+		}
+		bits := ip.Prefix
+		if ip.Netmask != "" {
+			bits, _ = net.IPMask(net.ParseIP(ip.Netmask).To4()).Size()
+		}
+		prefix, err := addr.Prefix(bits)
+		if err != nil {
+			continue
+		}
+		s := &Subnet{CIDR: prefix.String(), Gateway: addr.String(), DHCPStart: ip.Range.Start, DHCPEnd: ip.Range.End}
+		if addr.Is4() {
+			n.IPv4 = s
+		} else {
+			n.IPv6 = s
+		}
+	}
+	return n, nil
+}
+
+// normalizeNICs checks the NICs of a new VM and generates missing MAC
+// addresses. Without NICs the VM gets one on defaultBridge, if set, whose
+// type is defaultBridgeType; other bridges are Linux bridges unless the NIC
+// says otherwise. Addresses and security groups are kept by the daemon, not
+// in the domain, and are dropped.
+func normalizeNICs(nics []NIC, defaultBridge, defaultBridgeType string) ([]NIC, error) {
+	if len(nics) == 0 && defaultBridge != "" {
+		nics = []NIC{{Bridge: defaultBridge}}
+	}
+	out := make([]NIC, 0, len(nics))
+	seen := make(map[string]bool)
+	for i, nic := range nics {
+		nic, err := normalizeNIC(nic, defaultBridge, defaultBridgeType)
+		if err != nil {
+			return nil, fmt.Errorf("nic %d: %w", i, err)
+		}
+		if seen[nic.MAC] {
+			return nil, fmt.Errorf("nic %d: mac address %s used twice", i, nic.MAC)
+		}
+		seen[nic.MAC] = true
+		out = append(out, nic)
+	}
+	return out, nil
+}
+
+// normalizeNIC checks one NIC for normalizeNICs and fills in its defaults.
+func normalizeNIC(nic NIC, defaultBridge, defaultBridgeType string) (NIC, error) {
+	if (nic.Network == "") == (nic.Bridge == "") {
+		return NIC{}, errors.New("set either a network or a bridge")
+	}
+	if err := normalizeVLANs(&nic, defaultBridge, defaultBridgeType); err != nil {
+		return NIC{}, err
+	}
+	if nic.MAC == "" {
+		nic.MAC = randomMAC()
+	}
+	mac, err := net.ParseMAC(nic.MAC)
+	if err != nil || len(mac) != 6 || mac[0]&1 != 0 {
+		return NIC{}, fmt.Errorf("invalid mac address %q", nic.MAC)
+	}
+	nic.MAC = mac.String()
+	if nic.Model == "" {
+		nic.Model = "virtio"
+	}
+	switch nic.LinkState {
+	case "":
+		nic.LinkState = LinkUp
+	case LinkUp, LinkDown:
+	default:
+		return NIC{}, fmt.Errorf("invalid link state %q (want up or down)", nic.LinkState)
+	}
+	if nic.Inbound, err = validateBandwidth(nic.Inbound, "inbound"); err != nil {
+		return NIC{}, err
+	}
+	if nic.Outbound, err = validateBandwidth(nic.Outbound, "outbound"); err != nil {
+		return NIC{}, err
+	}
+	nic.IPv4, nic.IPv6, nic.SecurityGroups, nic.Device = "", "", nil, ""
+	return nic, nil
+}
+
+// updateNIC applies the changes in upd to cur, a NIC of a VM: a new network
+// or bridge, with the bridge type and VLANs that go with it, when upd names
+// one, and a new link state when it has one. The MAC address and model
+// stay.
+func updateNIC(cur, upd NIC, defaultBridge, defaultBridgeType string) (NIC, error) {
+	next := cur
+	if upd.Network != "" || upd.Bridge != "" {
+		next.Network, next.Bridge = upd.Network, upd.Bridge
+		next.BridgeType, next.VLAN, next.Trunk = upd.BridgeType, upd.VLAN, upd.Trunk
+	}
+	if upd.LinkState != "" {
+		next.LinkState = upd.LinkState
+	}
+	// a zero limit lifts the current one
+	if upd.Inbound != nil {
+		next.Inbound = upd.Inbound
+	}
+	if upd.Outbound != nil {
+		next.Outbound = upd.Outbound
+	}
+	return normalizeNIC(next, defaultBridge, defaultBridgeType)
+}
+
+// findNIC returns the index of the NIC with MAC address mac in nics.
+func findNIC(nics []NIC, mac string) (int, error) {
+	if hw, err := net.ParseMAC(mac); err == nil {
+		mac = hw.String()
+	}
+	i := slices.IndexFunc(nics, func(n NIC) bool { return n.MAC == mac })
+	if i < 0 {
+		return -1, fmt.Errorf("%s: %w", mac, ErrNICNotFound)
+	}
+	return i, nil
+}
+
+// normalizeVLANs fills in the bridge type of a NIC on a host bridge and
+// checks its VLANs against it: libvirt only tags traffic on Open vSwitch
+// bridges. Managed networks are Linux bridges, so NICs on them take
+// neither.
+func normalizeVLANs(nic *NIC, defaultBridge, defaultBridgeType string) error {
+	if nic.Network != "" {
+		if nic.BridgeType != "" || nic.VLAN != 0 || len(nic.Trunk) > 0 {
+			return errors.New("bridge types and vlans only apply to nics on a host bridge")
+		}
+		return nil
+	}
+	if nic.BridgeType == "" {
+		nic.BridgeType = BridgeLinux
+		if nic.Bridge == defaultBridge && defaultBridgeType != "" {
+			nic.BridgeType = defaultBridgeType
+		}
+	}
+	switch nic.BridgeType {
+	case BridgeLinux, BridgeOpenVSwitch:
+	default:
+		return fmt.Errorf("invalid bridge type %q (want linux or openvswitch)", nic.BridgeType)
+	}
+	if nic.VLAN == 0 && len(nic.Trunk) == 0 {
+		return nil
+	}
+	if nic.BridgeType != BridgeOpenVSwitch {
+		return fmt.Errorf("vlans need an openvswitch bridge, %s is a linux bridge", nic.Bridge)
+	}
+	if nic.VLAN != 0 && len(nic.Trunk) > 0 {
+		return errors.New("set either a vlan or a trunk")
+	}
+	ids := slices.Clone(nic.Trunk)
+	if nic.VLAN != 0 {
+		ids = []int{nic.VLAN}
+	}
+	for _, id := range ids {
+		if id < 1 || id > 4094 {
+			return fmt.Errorf("invalid vlan %d (want 1-4094)", id)
+		}
+	}
+	if len(nic.Trunk) > 0 {
+		slices.Sort(ids)
+		nic.Trunk = slices.Compact(ids)
+	}
+	return nil
+}
+
+// randomMAC returns an address in the 52:54:00 range QEMU uses for guests.
+func randomMAC() string {
+	var b [3]byte
+	_, _ = rand.Read(b[:])
+	return fmt.Sprintf("52:54:00:%02x:%02x:%02x", b[0], b[1], b[2])
+}
+
+// interfaceXML renders a NIC as a domain <interface>.
+func interfaceXML(nic NIC) string {
+	kind, source := "bridge", fmt.Sprintf("bridge='%s'", xmlEscape(nic.Bridge))
+	if nic.Network != "" {
+		kind, source = "network", fmt.Sprintf("network='%s'", xmlEscape(nic.Network))
+	}
+	var extra strings.Builder
+	if nic.BridgeType == BridgeOpenVSwitch {
+		extra.WriteString("\n      <virtualport type='openvswitch'/>")
+	}
+	if nic.LinkState == LinkDown {
+		extra.WriteString("\n      <link state='down'/>")
+	}
+	extra.WriteString(bandwidthXML(nic.Inbound, nic.Outbound, "      "))
+	switch {
+	case nic.VLAN != 0:
+		fmt.Fprintf(&extra, "\n      <vlan>\n        <tag id='%d'/>\n      </vlan>", nic.VLAN)
+	case len(nic.Trunk) > 0:
+		extra.WriteString("\n      <vlan trunk='yes'>")
+		for _, id := range nic.Trunk {
+			fmt.Fprintf(&extra, "\n        <tag id='%d'/>", id)
+		}
+		extra.WriteString("\n      </vlan>")
+	}
+	return fmt.Sprintf(`
+    <interface type='%s'>
+      <mac address='%s'/>
+      <source %s/>%s
+      <model type='%s'/>
+    </interface>`, kind, nic.MAC, source, extra.String(), xmlEscape(nic.Model))
+}
@@ -0,0 +1,126 @@
+package network
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/riccardotacconi/deusvm/internal/state"
+)
+
+var (
+	ErrTemplateNotFound = errors.New("boot template not found")
+	ErrTemplateExists   = errors.New("boot template already exists")
+	ErrTemplateInUse    = errors.New("boot template is in use")
+)
+
+// DefaultBootTemplate is served to VMs booting from the network that have
+// no template of their own.
+const DefaultBootTemplate = "default"
+
+// templateName limits names to what URLs take as is.
+var templateName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,62}$`)
+
+// BootTemplate is an iPXE script rendered for each VM booting from it with
+// Go's text/template and a BootInfo.
+type BootTemplate struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Script      string `json:"script"`
+}
+
+// BootInfo is what boot templates are rendered with: the VM, the NIC it
+// boots from and the addresses reserved for that NIC. Server is the base
+// URL of the boot server as the VM reaches it; the files of the boot
+// server's root are under Server/files/.
+type BootInfo struct {
+	ID      string
+	Name    string
+	MAC     string
+	Network string
+	IPv4    string
+	IPv6    string
+	Server  string
+}
+
+// validateTemplate checks the name of t and that its script is an iPXE
+// script and a valid template.
+func validateTemplate(t BootTemplate) (BootTemplate, error) {
+	if !templateName.MatchString(t.Name) {
+		return BootTemplate{}, fmt.Errorf("invalid boot template name %q", t.Name)
+	}
+	if !strings.HasPrefix(t.Script, "#!ipxe") {
+		return BootTemplate{}, errors.New("boot template script must start with #!ipxe")
+	}
+	if _, err := parseTemplate(t); err != nil {
+		return BootTemplate{}, err
+	}
+	return t, nil
+}
+
+func parseTemplate(t BootTemplate) (*template.Template, error) {
+	tmpl, err := template.New(t.Name).Option("missingkey=error").Parse(t.Script)
+	if err != nil {
+		return nil, fmt.Errorf("parse boot template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// render executes t for info.
+func (t BootTemplate) render(info BootInfo) ([]byte, error) {
+	tmpl, err := parseTemplate(t)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, info); err != nil {
+		return nil, fmt.Errorf("render boot template %s: %w", t.Name, err)
+	}
+	return b.Bytes(), nil
+}
+
+// templateRegistry persists the boot templates and the template of each VM,
+// by VM ID.
+type templateRegistry struct {
+	mu        sync.Mutex
+	path      string
+	Templates map[string]BootTemplate `json:"templates"`
+	VMs       map[string]string       `json:"vms"`
+}
+
+func loadTemplates(dir string) (*templateRegistry, error) {
+	r := &templateRegistry{path: filepath.Join(dir, "boot_templates.json")}
+	if err := state.Load(r.path, r); err != nil {
+		return nil, err
+	}
+	if r.Templates == nil {
+		r.Templates = make(map[string]BootTemplate)
+	}
+	if r.VMs == nil {
+		r.VMs = make(map[string]string)
+	}
+	return r, nil
+}
+
+// update applies fn to copies of the templates and VM assignments and saves
+// the result, keeping the old state if fn or saving fails. The caller holds
+// mu.
+func (r *templateRegistry) update(fn func(templates map[string]BootTemplate, vms map[string]string) error) error {
+	templates, vms := maps.Clone(r.Templates), maps.Clone(r.VMs)
+	if err := fn(templates, vms); err != nil {
+		return err
+	}
+	prevTemplates, prevVMs := r.Templates, r.VMs
+	r.Templates, r.VMs = templates, vms
+	if err := state.Save(r.path, r); err != nil {
+		r.Templates, r.VMs = prevTemplates, prevVMs
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/riccardotacconi/deusvm/internal/kvm"
+)
+
+// NetworkConfig renders the cloud-init network-config (version 2) of vm,
+// which sets the addresses reserved for its NICs statically, for images
+// that do not configure their network with DHCP. The gateways of the
+// managed networks are the routers and DNS servers; NICs on host bridges
+// use DHCP. It returns nil when vm has no reserved addresses.
+func (s *Service) NetworkConfig(ctx context.Context, vm kvm.VM) ([]byte, error) {
+	var allocs []Allocation
+	for _, a := range s.Allocations("") {
+		if a.VM == vm.ID {
+			allocs = append(allocs, a)
+		}
+	}
+	if len(allocs) == 0 {
+		return nil, nil
+	}
+	networks := make(map[string]kvm.Network)
+	for _, nic := range vm.NICs {
+		if nic.Network == "" || networks[nic.Network].Name != "" {
+			continue
+		}
+		n, err := s.manager.GetNetwork(ctx, nic.Network)
+		if err != nil {
+			return nil, err
+		}
+		networks[n.Name] = n
+	}
+	return []byte(renderNetworkConfig(vm.NICs, networks, allocs)), nil
+}
+
+// renderNetworkConfig writes the network-config of NetworkConfig. Only the
+// first NIC on a network that reaches beyond the host gets the default route
+// of each family, so the guest does not pick between several.
+func renderNetworkConfig(nics []kvm.NIC, networks map[string]kvm.Network, allocs []Allocation) string {
+	var b strings.Builder
+	b.WriteString("version: 2\nethernets:\n")
+	var routed4, routed6 bool
+	for i, nic := range nics {
+		fmt.Fprintf(&b, "  nic%d:\n    match:\n      macaddress: %q\n", i, nic.MAC)
+		n, managed := networks[nic.Network]
+		var addrs, dns []string
+		var routes [][2]string // destination, gateway
+		for _, sub := range []*kvm.Subnet{n.IPv4, n.IPv6} {
+			if !managed || sub == nil {
+				continue
+			}
+			prefix, err := netip.ParsePrefix(sub.CIDR)
+			if err != nil {
+				continue
+			}
+			found := false
+			for _, a := range allocs {
+				addr, err := netip.ParseAddr(a.IP)
+				if err == nil && a.MAC == nic.MAC && a.Network == n.Name && prefix.Contains(addr) {
+					addrs = append(addrs, netip.PrefixFrom(addr, prefix.Bits()).String())
+					found = true
+				}
+			}
+			if !found {
+				continue
+			}
+			gw := sub.Gateway
+			if gw == "" {
+				gw = prefix.Addr().Next().String()
+			}
+			dns = append(dns, gw)
+			switch {
+			case n.Mode == kvm.NetworkIsolated:
+			case prefix.Addr().Is4() && !routed4:
+				routed4 = true
+				routes = append(routes, [2]string{"0.0.0.0/0", gw})
+			case prefix.Addr().Is6() && !routed6:
+				routed6 = true
+				routes = append(routes, [2]string{"::/0", gw})
+			}
+		}
+		if len(addrs) == 0 {
+			b.WriteString("    dhcp4: true\n")
+			continue
+		}
+		b.WriteString("    dhcp4: false\n    dhcp6: false\n    addresses:\n")
+		for _, a := range addrs {
+			fmt.Fprintf(&b, "      - %q\n", a)
+		}
+		if len(routes) > 0 {
+			b.WriteString("    routes:\n")
+			for _, r := range routes {
+				fmt.Fprintf(&b, "      - to: %q\n        via: %q\n", r[0], r[1])
+			}
+		}
+		b.WriteString("    nameservers:\n      addresses:\n")
+		for _, d := range dns {
+			fmt.Fprintf(&b, "        - %q\n", d)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/riccardotacconi/deusvm/internal/kvm"
+)
+
+func TestRenderNetworkConfig(t *testing.T) {
+	networks := map[string]kvm.Network{
+		"lab": {NetworkSpec: kvm.NetworkSpec{Name: "lab", Mode: kvm.NetworkNAT,
+			IPv4: &kvm.Subnet{CIDR: "10.10.0.0/24", Gateway: "10.10.0.1"},
+			IPv6: &kvm.Subnet{CIDR: "fd00:10::/64", Gateway: "fd00:10::1"}}},
+		"backend": {NetworkSpec: kvm.NetworkSpec{Name: "backend", Mode: kvm.NetworkIsolated,
+			IPv4: &kvm.Subnet{CIDR: "10.20.0.0/16"}}},
+		"dmz": {NetworkSpec: kvm.NetworkSpec{Name: "dmz", Mode: kvm.NetworkRouted,
+			IPv4: &kvm.Subnet{CIDR: "192.0.2.0/28", Gateway: "192.0.2.14"}}},
+	}
+	nics := []kvm.NIC{
+		{Network: "lab", MAC: "52:54:00:00:00:01"},
+		{Network: "backend", MAC: "52:54:00:00:00:02"},
+		{Network: "dmz", MAC: "52:54:00:00:00:03"},
+		{Bridge: "br0", MAC: "52:54:00:00:00:04"},
+	}
+	allocs := []Allocation{
+		{Network: "lab", IP: "10.10.0.10", MAC: "52:54:00:00:00:01"},
+		{Network: "lab", IP: "fd00:10::2", MAC: "52:54:00:00:00:01"},
+		{Network: "backend", IP: "10.20.0.2", MAC: "52:54:00:00:00:02"},
+		{Network: "dmz", IP: "192.0.2.2", MAC: "52:54:00:00:00:03"},
+		// another VM's NIC on the same network is not this one's business
+		{Network: "lab", IP: "10.10.0.11", MAC: "52:54:00:00:00:09"},
+	}
+	want := `version: 2
+ethernets:
+  nic0:
+    match:
+      macaddress: "52:54:00:00:00:01"
+    dhcp4: false
+    dhcp6: false
+    addresses:
+      - "10.10.0.10/24"
+      - "fd00:10::2/64"
+    routes:
+      - to: "0.0.0.0/0"
+        via: "10.10.0.1"
+      - to: "::/0"
+        via: "fd00:10::1"
+    nameservers:
+      addresses:
+        - "10.10.0.1"
+        - "fd00:10::1"
+  nic1:
+    match:
+      macaddress: "52:54:00:00:00:02"
+    dhcp4: false
+    dhcp6: false
+    addresses:
+      - "10.20.0.2/16"
+    nameservers:
+      addresses:
+        - "10.20.0.1"
+  nic2:
+    match:
+      macaddress: "52:54:00:00:00:03"
+    dhcp4: false
+    dhcp6: false
+    addresses:
+      - "192.0.2.2/28"
+    nameservers:
+      addresses:
+        - "192.0.2.14"
+  nic3:
+    match:
+      macaddress: "52:54:00:00:00:04"
+    dhcp4: true
+`
+	if got := renderNetworkConfig(nics, networks, allocs); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
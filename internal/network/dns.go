@@ -0,0 +1,431 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/riccardotacconi/deusvm/internal/config"
+	"github.com/riccardotacconi/deusvm/internal/kvm"
+	"github.com/riccardotacconi/deusvm/internal/logging"
+	"go.uber.org/zap"
+)
+
+const (
+	// dnsRefreshInterval is how often the records and the listen addresses
+	// are brought up to date with the VMs and networks.
+	dnsRefreshInterval = 10 * time.Second
+	dnsUpstreamTimeout = 2 * time.Second
+	dnsTCPIdleTimeout  = 10 * time.Second
+)
+
+// DNSServer answers queries for <vm-name>.<zone> with the addresses the
+// daemon knows for each VM: those reserved on managed networks and those
+// reported by DHCP leases and the guest agent. It also answers the reverse
+// names of those addresses and forwards every other query upstream. It
+// listens on the gateways of the networks created with external DNS and on
+// any extra configured address.
+type DNSServer struct {
+	manager  kvm.Manager
+	networks *Service
+	origin   string
+	ttl      uint32
+	port     string
+	listen   []string
+	upstream []string
+
+	zone atomic.Pointer[dnsZone]
+
+	mu        sync.Mutex
+	listeners map[string]*dnsListener
+	failed    map[string]string // last bind error per address, logged once
+}
+
+// NewDNSServer checks cfg and prepares a server answering from the VMs of
+// manager and the allocations of networks. It does not listen until Serve.
+func NewDNSServer(cfg config.DNSConfig, manager kvm.Manager, networks *Service) (*DNSServer, error) {
+	zone := strings.Trim(strings.ToLower(cfg.Zone), ".")
+	if zone == "" {
+		return nil, errors.New("dns zone is required")
+	}
+	for _, l := range strings.Split(zone, ".") {
+		if !dnsLabel.MatchString(l) {
+			return nil, fmt.Errorf("invalid dns zone %q", cfg.Zone)
+		}
+	}
+	if cfg.Port < 1 || cfg.Port > 65535 {
+		return nil, fmt.Errorf("invalid dns port %d", cfg.Port)
+	}
+	ttl := cfg.TTL / time.Second
+	if ttl < 0 {
+		return nil, fmt.Errorf("invalid dns ttl %s", cfg.TTL)
+	}
+	d := &DNSServer{
+		manager:   manager,
+		networks:  networks,
+		origin:    zone + ".",
+		ttl:       uint32(ttl),
+		port:      strconv.Itoa(cfg.Port),
+		listeners: make(map[string]*dnsListener),
+		failed:    make(map[string]string),
+	}
+	for _, addr := range cfg.Listen {
+		addr, err := withPort(addr, d.port)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dns listen address: %w", err)
+		}
+		d.listen = append(d.listen, addr)
+	}
+	upstream := cfg.Upstream
+	if len(upstream) == 0 {
+		upstream = resolvConfServers("/etc/resolv.conf")
+	}
+	for _, addr := range upstream {
+		addr, err := withPort(addr, "53")
+		if err != nil {
+			return nil, fmt.Errorf("invalid dns upstream: %w", err)
+		}
+		d.upstream = append(d.upstream, addr)
+	}
+	d.zone.Store(newDNSZone(d.origin, d.ttl, 0))
+	return d, nil
+}
+
+// Serve keeps the records and listeners up to date until ctx is done, then
+// closes the listeners.
+func (d *DNSServer) Serve(ctx context.Context, logger *zap.Logger) {
+	ticker := time.NewTicker(dnsRefreshInterval)
+	defer ticker.Stop()
+	for {
+		nets, err := d.refresh(ctx)
+		if err != nil {
+			logger.Warn("failed to refresh DNS records", logging.FieldError(err))
+		} else {
+			d.bind(ctx, logger, nets)
+		}
+		select {
+		case <-ctx.Done():
+			d.bind(ctx, logger, nil)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh rebuilds the zone from the VMs and returns the managed networks.
+// The host is published under its short host name, with the gateway
+// addresses of the active networks, unless a VM has the same name.
+func (d *DNSServer) refresh(ctx context.Context) ([]kvm.Network, error) {
+	vms, err := d.manager.ListVMs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nets, err := d.manager.ListNetworks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	z := newDNSZone(d.origin, d.ttl, uint32(time.Now().Unix()))
+	for _, vm := range vms {
+		d.networks.Annotate(&vm)
+		for _, nic := range vm.NICs {
+			for _, ip := range []string{nic.IPv4, nic.IPv6} {
+				if addr, err := netip.ParseAddr(ip); err == nil {
+					z.add(vm.Name, addr)
+				}
+			}
+		}
+		if vm.Status != kvm.VMStatusRunning {
+			continue
+		}
+		// errors only mean there is nothing more to learn about this VM
+		addrs, _ := d.manager.GuestAddresses(ctx, vm.ID)
+		for _, ga := range addrs {
+			addr, err := netip.ParseAddr(ga.IP)
+			if err != nil || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+				continue
+			}
+			z.add(vm.Name, addr)
+		}
+	}
+	if host, err := os.Hostname(); err == nil {
+		host, _, _ = strings.Cut(host, ".")
+		if !z.has(host) {
+			for _, n := range nets {
+				for _, s := range []*kvm.Subnet{n.IPv4, n.IPv6} {
+					if addr, err := netip.ParseAddr(gateway(n, s)); err == nil {
+						z.add(host, addr)
+					}
+				}
+			}
+		}
+	}
+	d.zone.Store(z)
+	return nets, nil
+}
+
+// gateway returns the gateway address of subnet s of n while n is active.
+func gateway(n kvm.Network, s *kvm.Subnet) string {
+	if !n.Active || s == nil {
+		return ""
+	}
+	return s.Gateway
+}
+
+// bind listens on the gateways of the active networks among nets that were
+// created with external DNS and on the configured addresses, closing the
+// listeners of addresses no longer wanted. Addresses that cannot be bound,
+// such as IPv6 gateways still being set up, are retried on the next call.
+func (d *DNSServer) bind(ctx context.Context, logger *zap.Logger, nets []kvm.Network) {
+	var want []string
+	if nets != nil {
+		want = slices.Clone(d.listen)
+		for _, n := range nets {
+			if !n.ExternalDNS {
+				continue
+			}
+			for _, s := range []*kvm.Subnet{n.IPv4, n.IPv6} {
+				if gw := gateway(n, s); gw != "" {
+					want = append(want, net.JoinHostPort(gw, d.port))
+				}
+			}
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for addr, l := range d.listeners {
+		if !slices.Contains(want, addr) {
+			l.close()
+			delete(d.listeners, addr)
+			logger.Info("stopped DNS server", logging.Field("addr", addr))
+		}
+	}
+	for _, addr := range want {
+		if _, ok := d.listeners[addr]; ok {
+			continue
+		}
+		l, err := listenDNS(addr)
+		if err != nil {
+			if d.failed[addr] != err.Error() {
+				logger.Warn("failed to start DNS server", logging.Field("addr", addr), logging.FieldError(err))
+				d.failed[addr] = err.Error()
+			}
+			continue
+		}
+		delete(d.failed, addr)
+		d.listeners[addr] = l
+		go d.serveUDP(ctx, l.udp)
+		go d.serveTCP(ctx, l.tcp)
+		logger.Info("starting DNS server", logging.Field("addr", addr), logging.Field("zone", d.origin))
+	}
+}
+
+// dnsListener is the pair of sockets serving one address.
+type dnsListener struct {
+	udp net.PacketConn
+	tcp net.Listener
+}
+
+func listenDNS(addr string) (*dnsListener, error) {
+	udp, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	tcp, err := net.Listen("tcp", addr)
+	if err != nil {
+		_ = udp.Close()
+		return nil, err
+	}
+	return &dnsListener{udp: udp, tcp: tcp}, nil
+}
+
+func (l *dnsListener) close() {
+	_ = l.udp.Close()
+	_ = l.tcp.Close()
+}
+
+func (d *DNSServer) serveUDP(ctx context.Context, conn net.PacketConn) {
+	buf := make([]byte, 65535)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		req := slices.Clone(buf[:n])
+		go func() {
+			if resp := d.handle(ctx, "udp", req); resp != nil {
+				_, _ = conn.WriteTo(resp, from)
+			}
+		}()
+	}
+}
+
+func (d *DNSServer) serveTCP(ctx context.Context, ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		go func() {
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+			for {
+				_ = conn.SetDeadline(time.Now().Add(dnsTCPIdleTimeout))
+				req, err := readTCPMessage(r)
+				if err != nil {
+					return
+				}
+				resp := d.handle(ctx, "tcp", req)
+				if resp == nil || writeTCPMessage(conn, resp) != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+// handle answers req from the zone or from upstream, with SERVFAIL when no
+// upstream answers. It returns nil for messages not worth a response.
+func (d *DNSServer) handle(ctx context.Context, network string, req []byte) []byte {
+	resp, forward, err := d.zone.Load().answer(req)
+	if err != nil {
+		return nil
+	}
+	if !forward {
+		return resp
+	}
+	if resp, err := d.forward(ctx, network, req); err == nil {
+		return resp
+	}
+	return serverFailure(req)
+}
+
+// forward sends req to each upstream server in turn, over network, and
+// returns the first response. Upstreams the server itself listens on are
+// skipped so a host resolving through the daemon cannot make it loop.
+func (d *DNSServer) forward(ctx context.Context, network string, req []byte) ([]byte, error) {
+	d.mu.Lock()
+	upstream := slices.DeleteFunc(slices.Clone(d.upstream), func(addr string) bool {
+		_, ok := d.listeners[addr]
+		return ok
+	})
+	d.mu.Unlock()
+	if len(upstream) == 0 {
+		return nil, errors.New("no dns upstream")
+	}
+	var errs []error
+	for _, addr := range upstream {
+		resp, err := exchange(ctx, network, addr, req)
+		if err == nil {
+			return resp, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// exchange sends req to addr and reads the response with the same ID.
+func exchange(ctx context.Context, network, addr string, req []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, dnsUpstreamTimeout)
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+	if network == "tcp" {
+		if err := writeTCPMessage(conn, req); err != nil {
+			return nil, err
+		}
+		return readTCPMessage(conn)
+	}
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		if n >= 2 && buf[0] == req[0] && buf[1] == req[1] {
+			return slices.Clone(buf[:n]), nil
+		}
+	}
+}
+
+// readTCPMessage reads a DNS message prefixed with its two byte length.
+func readTCPMessage(r io.Reader) ([]byte, error) {
+	var size [2]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, binary.BigEndian.Uint16(size[:]))
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func writeTCPMessage(w io.Writer, msg []byte) error {
+	if len(msg) > 65535 {
+		return errors.New("dns message too long")
+	}
+	out := binary.BigEndian.AppendUint16(make([]byte, 0, 2+len(msg)), uint16(len(msg)))
+	_, err := w.Write(append(out, msg...))
+	return err
+}
+
+// withPort adds port to addr unless it has one.
+func withPort(addr, port string) (string, error) {
+	if host, p, err := net.SplitHostPort(addr); err == nil {
+		if _, err := netip.ParseAddr(host); err != nil {
+			return "", fmt.Errorf("%q is not an ip address", host)
+		}
+		return net.JoinHostPort(host, p), nil
+	}
+	ip, err := netip.ParseAddr(strings.Trim(addr, "[]"))
+	if err != nil {
+		return "", fmt.Errorf("%q is not an ip address", addr)
+	}
+	return net.JoinHostPort(ip.String(), port), nil
+}
+
+// resolvConfServers returns the nameservers listed in the resolv.conf at
+// path.
+func resolvConfServers(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var out []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			out = append(out, fields[1])
+		}
+	}
+	return out
+}
@@ -0,0 +1,267 @@
+package network
+
+import (
+	"errors"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dnsLabel matches the names that can be published as a single DNS label.
+var dnsLabel = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// dnsZone is a snapshot of the records the DNS server answers from: the
+// addresses of each VM under <name>.<origin> and the reverse name of every
+// one of those addresses.
+type dnsZone struct {
+	origin string // lower case and fully qualified, such as "deusvm.internal."
+	ttl    uint32
+	serial uint32
+	names  map[string][]netip.Addr
+	ptrs   map[netip.Addr]string
+}
+
+func newDNSZone(origin string, ttl, serial uint32) *dnsZone {
+	return &dnsZone{
+		origin: origin,
+		ttl:    ttl,
+		serial: serial,
+		names:  make(map[string][]netip.Addr),
+		ptrs:   make(map[netip.Addr]string),
+	}
+}
+
+// add publishes addr under name, which is skipped unless it is a valid
+// label once lower cased. An address already published under another name
+// keeps its first reverse name.
+func (z *dnsZone) add(name string, addr netip.Addr) {
+	label := strings.ToLower(name)
+	if !dnsLabel.MatchString(label) || !addr.IsValid() {
+		return
+	}
+	addr = addr.Unmap()
+	if slices.Contains(z.names[label], addr) {
+		return
+	}
+	z.names[label] = append(z.names[label], addr)
+	if _, ok := z.ptrs[addr]; !ok {
+		z.ptrs[addr] = label + "." + z.origin
+	}
+}
+
+// has reports whether name has any address in the zone.
+func (z *dnsZone) has(name string) bool {
+	return len(z.names[strings.ToLower(name)]) > 0
+}
+
+// answer builds the response to the query req. forward is set instead when
+// the question is neither in the zone nor the reverse name of an address
+// in it, so it should be sent upstream. Malformed queries get an error and
+// no response.
+func (z *dnsZone) answer(req []byte) (resp []byte, forward bool, err error) {
+	var p dnsmessage.Parser
+	h, err := p.Start(req)
+	if err != nil {
+		return nil, false, err
+	}
+	if h.Response {
+		return nil, false, errors.New("not a query")
+	}
+	if h.OpCode != 0 {
+		return dnsError(h, nil, dnsmessage.RCodeNotImplemented)
+	}
+	qs, err := p.AllQuestions()
+	if err != nil || len(qs) != 1 {
+		return dnsError(h, nil, dnsmessage.RCodeFormatError)
+	}
+	q := qs[0]
+	if q.Class != dnsmessage.ClassINET {
+		return nil, true, nil
+	}
+	name := strings.ToLower(q.Name.String())
+
+	if addr, ok := reverseName(name); ok {
+		target, ok := z.ptrs[addr]
+		if !ok {
+			return nil, true, nil
+		}
+		b := z.response(h, q, dnsmessage.RCodeSuccess)
+		if q.Type == dnsmessage.TypePTR || q.Type == dnsmessage.TypeALL {
+			if err := b.StartAnswers(); err != nil {
+				return nil, false, err
+			}
+			err := b.PTRResource(z.header(q.Name, dnsmessage.TypePTR), dnsmessage.PTRResource{PTR: dnsmessage.MustNewName(target)})
+			if err != nil {
+				return nil, false, err
+			}
+		}
+		resp, err := b.Finish()
+		return resp, false, err
+	}
+
+	var label string
+	switch {
+	case name == z.origin:
+	case strings.HasSuffix(name, "."+z.origin):
+		label = strings.TrimSuffix(name, "."+z.origin)
+	default:
+		return nil, true, nil
+	}
+	addrs := z.names[label]
+	if label != "" && len(addrs) == 0 {
+		b := z.response(h, q, dnsmessage.RCodeNameError)
+		if err := z.soa(&b); err != nil {
+			return nil, false, err
+		}
+		resp, err := b.Finish()
+		return resp, false, err
+	}
+
+	b := z.response(h, q, dnsmessage.RCodeSuccess)
+	var answered bool
+	if err := b.StartAnswers(); err != nil {
+		return nil, false, err
+	}
+	for _, addr := range addrs {
+		var err error
+		switch {
+		case addr.Is4() && (q.Type == dnsmessage.TypeA || q.Type == dnsmessage.TypeALL):
+			err = b.AResource(z.header(q.Name, dnsmessage.TypeA), dnsmessage.AResource{A: addr.As4()})
+		case addr.Is6() && (q.Type == dnsmessage.TypeAAAA || q.Type == dnsmessage.TypeALL):
+			err = b.AAAAResource(z.header(q.Name, dnsmessage.TypeAAAA), dnsmessage.AAAAResource{AAAA: addr.As16()})
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		answered = true
+	}
+	if !answered {
+		if err := z.soa(&b); err != nil {
+			return nil, false, err
+		}
+	}
+	resp, err = b.Finish()
+	return resp, false, err
+}
+
+// response starts an authoritative response to the question q of h.
+func (z *dnsZone) response(h dnsmessage.Header, q dnsmessage.Question, rcode dnsmessage.RCode) dnsmessage.Builder {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{
+		ID:                 h.ID,
+		Response:           true,
+		Authoritative:      true,
+		RecursionDesired:   h.RecursionDesired,
+		RecursionAvailable: true,
+		RCode:              rcode,
+	})
+	b.EnableCompression()
+	_ = b.StartQuestions()
+	_ = b.Question(q)
+	return b
+}
+
+func (z *dnsZone) header(name dnsmessage.Name, typ dnsmessage.Type) dnsmessage.ResourceHeader {
+	return dnsmessage.ResourceHeader{Name: name, Type: typ, Class: dnsmessage.ClassINET, TTL: z.ttl}
+}
+
+// soa adds the zone's SOA record to the authority section, which lets
+// resolvers cache negative answers for the zone's TTL.
+func (z *dnsZone) soa(b *dnsmessage.Builder) error {
+	if err := b.StartAuthorities(); err != nil {
+		return err
+	}
+	origin := dnsmessage.MustNewName(z.origin)
+	return b.SOAResource(z.header(origin, dnsmessage.TypeSOA), dnsmessage.SOAResource{
+		NS:      dnsmessage.MustNewName("ns." + z.origin),
+		MBox:    dnsmessage.MustNewName("hostmaster." + z.origin),
+		Serial:  z.serial,
+		Refresh: 3600,
+		Retry:   600,
+		Expire:  86400,
+		MinTTL:  z.ttl,
+	})
+}
+
+// dnsError builds a response to h carrying only rcode and the question q,
+// if any.
+func dnsError(h dnsmessage.Header, q *dnsmessage.Question, rcode dnsmessage.RCode) ([]byte, bool, error) {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{
+		ID:                 h.ID,
+		Response:           true,
+		OpCode:             h.OpCode,
+		RecursionDesired:   h.RecursionDesired,
+		RecursionAvailable: true,
+		RCode:              rcode,
+	})
+	if q != nil {
+		_ = b.StartQuestions()
+		if err := b.Question(*q); err != nil {
+			return nil, false, err
+		}
+	}
+	resp, err := b.Finish()
+	return resp, false, err
+}
+
+// serverFailure answers req with SERVFAIL, or returns nil when req is not a
+// query.
+func serverFailure(req []byte) []byte {
+	var p dnsmessage.Parser
+	h, err := p.Start(req)
+	if err != nil || h.Response {
+		return nil
+	}
+	var q *dnsmessage.Question
+	if qq, err := p.Question(); err == nil {
+		q = &qq
+	}
+	resp, _, _ := dnsError(h, q, dnsmessage.RCodeServerFailure)
+	return resp
+}
+
+// reverseName parses a fully qualified in-addr.arpa or ip6.arpa name into
+// the address it stands for.
+func reverseName(name string) (netip.Addr, bool) {
+	if rest, ok := strings.CutSuffix(name, ".in-addr.arpa."); ok {
+		labels := strings.Split(rest, ".")
+		if len(labels) != 4 {
+			return netip.Addr{}, false
+		}
+		var ip [4]byte
+		for i, l := range labels {
+			n, err := strconv.ParseUint(l, 10, 8)
+			if err != nil || (len(l) > 1 && l[0] == '0') {
+				return netip.Addr{}, false
+			}
+			ip[3-i] = byte(n)
+		}
+		return netip.AddrFrom4(ip), true
+	}
+	if rest, ok := strings.CutSuffix(name, ".ip6.arpa."); ok {
+		labels := strings.Split(rest, ".")
+		if len(labels) != 32 {
+			return netip.Addr{}, false
+		}
+		var ip [16]byte
+		for i, l := range labels {
+			n, err := strconv.ParseUint(l, 16, 4)
+			if err != nil || len(l) != 1 {
+				return netip.Addr{}, false
+			}
+			pos := 31 - i
+			if pos%2 == 0 {
+				ip[pos/2] |= byte(n) << 4
+			} else {
+				ip[pos/2] |= byte(n)
+			}
+		}
+		return netip.AddrFrom16(ip), true
+	}
+	return netip.Addr{}, false
+}
@@ -0,0 +1,319 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/netip"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Applier loads a complete nftables ruleset.
+type Applier interface {
+	Apply(ctx context.Context, ruleset string) error
+}
+
+// NFT applies rulesets with nft -f, which commits a whole file in one
+// transaction, so the old rules stay in place until the new ones are loaded.
+type NFT struct{}
+
+func (NFT) Apply(ctx context.Context, ruleset string) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "nft", "-f", "-")
+	cmd.Stdin = strings.NewReader(ruleset)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("nft: %w: %s", err, msg)
+		}
+		return fmt.Errorf("nft: %w", err)
+	}
+	return nil
+}
+
+// tableName is the nftables table the daemon owns; nothing else touches it.
+const tableName = "deusvm"
+
+// port is a VM NIC as the firewall sees it. Device is empty while the VM is
+// stopped; such NICs still lend their addresses to the groups they are in.
+type port struct {
+	VM     string
+	Device string
+	MAC    string
+	Addrs  []string
+	Groups []string
+}
+
+// ruleset is everything the daemon enforces with nftables. Forwards are
+// the port forwards to running VMs, with their Target set.
+type ruleset struct {
+	Groups   map[string]SecurityGroup
+	Ports    []port
+	Forwards []Forward
+}
+
+// filtered returns the ports of running VMs in at least one group, sorted
+// by device.
+func (rs ruleset) filtered() []port {
+	var out []port
+	for _, p := range rs.Ports {
+		if p.Device != "" && len(p.Groups) > 0 {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Device < out[j].Device })
+	return out
+}
+
+// render returns an nft script that replaces the daemon's tables in one
+// transaction.
+func (rs ruleset) render() string {
+	var b strings.Builder
+	rs.renderFilter(&b)
+	rs.renderForwards(&b)
+	return b.String()
+}
+
+// renderFilter writes the bridge table enforcing security groups. Traffic
+// of every NIC in a group passes through a chain per direction, filtering
+// both bridged traffic and traffic with the host: a NIC may only send from
+// its own MAC and reserved addresses, and only the traffic some rule of its
+// groups allows, replies, ARP, neighbour discovery and DHCP get through.
+// Egress is only restricted once one of the groups has an egress rule, so
+// NICs whose groups only filter ingress still reach DNS, TFTP and boot
+// files on the gateway and everything else. NICs in no group are not
+// filtered.
+func (rs ruleset) renderFilter(b *strings.Builder) {
+	// declaring the table first makes the delete succeed when it is missing
+	fmt.Fprintf(b, "table bridge %s\ndelete table bridge %s\n", tableName, tableName)
+	ports := rs.filtered()
+	if len(ports) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(rs.Groups))
+	for name := range rs.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	setIndex := make(map[string]int, len(names))
+	for i, name := range names {
+		setIndex[name] = i
+	}
+	members := make(map[string][]netip.Addr)
+	for _, p := range rs.Ports {
+		for _, g := range p.Groups {
+			for _, a := range p.Addrs {
+				if addr, err := netip.ParseAddr(a); err == nil {
+					members[g] = append(members[g], addr)
+				}
+			}
+		}
+	}
+
+	fmt.Fprintf(b, "table bridge %s {\n", tableName)
+	for _, name := range names {
+		for _, fam := range []family{ipv4, ipv6} {
+			fmt.Fprintf(b, "\tset g%d_%s {\n\t\ttype %s_addr\n", setIndex[name], fam.suffix, fam.set)
+			var elems []string
+			for _, a := range members[name] {
+				if fam.has(a) && !slices.Contains(elems, a.String()) {
+					elems = append(elems, a.String())
+				}
+			}
+			if len(elems) > 0 {
+				fmt.Fprintf(b, "\t\telements = { %s }\n", strings.Join(elems, ", "))
+			}
+			b.WriteString("\t}\n")
+		}
+	}
+
+	b.WriteString("\tchain forward {\n\t\ttype filter hook forward priority 0; policy accept;\n")
+	for i, p := range ports {
+		fmt.Fprintf(b, "\t\tiifname %q jump p%d_out\n\t\toifname %q jump p%d_in\n", p.Device, i, p.Device, i)
+	}
+	b.WriteString("\t}\n\tchain input {\n\t\ttype filter hook input priority 0; policy accept;\n")
+	for i, p := range ports {
+		fmt.Fprintf(b, "\t\tiifname %q jump p%d_out\n", p.Device, i)
+	}
+	b.WriteString("\t}\n\tchain output {\n\t\ttype filter hook output priority 0; policy accept;\n")
+	for i, p := range ports {
+		fmt.Fprintf(b, "\t\toifname %q jump p%d_in\n", p.Device, i)
+	}
+	b.WriteString("\t}\n")
+
+	for i, p := range ports {
+		// traffic leaving the VM
+		fmt.Fprintf(b, "\tchain p%d_out {\n", i)
+		fmt.Fprintf(b, "\t\tether saddr != %s drop\n", p.MAC)
+		b.WriteString("\t\tether type arp accept\n")
+		b.WriteString("\t\tip protocol udp udp sport 68 udp dport 67 accept\n")
+		b.WriteString("\t\tip6 nexthdr udp udp sport 546 udp dport 547 accept\n")
+		b.WriteString("\t\tip6 nexthdr ipv6-icmp icmpv6 type { nd-router-solicit, nd-neighbor-solicit, nd-neighbor-advert } accept\n")
+		for _, fam := range []family{ipv4, ipv6} {
+			var own []string
+			for _, a := range p.Addrs {
+				if addr, err := netip.ParseAddr(a); err == nil && fam.has(addr) {
+					own = append(own, addr.String())
+				}
+			}
+			if len(own) > 0 {
+				if fam == ipv6 {
+					own = append(own, "fe80::/10")
+				}
+				fmt.Fprintf(b, "\t\t%s saddr != { %s } drop\n", fam.proto, strings.Join(own, ", "))
+			}
+		}
+		if !rs.restricts(p, Egress) {
+			b.WriteString("\t\taccept\n\t}\n")
+		} else {
+			b.WriteString("\t\tct state established,related accept\n")
+			rs.renderRules(b, p, Egress, setIndex)
+			b.WriteString("\t\tdrop\n\t}\n")
+		}
+
+		// traffic to the VM
+		fmt.Fprintf(b, "\tchain p%d_in {\n", i)
+		b.WriteString("\t\tct state established,related accept\n")
+		b.WriteString("\t\tether type arp accept\n")
+		b.WriteString("\t\tip protocol udp udp sport 67 udp dport 68 accept\n")
+		b.WriteString("\t\tip6 nexthdr udp udp sport 547 udp dport 546 accept\n")
+		b.WriteString("\t\tip6 nexthdr ipv6-icmp icmpv6 type { nd-router-advert, nd-neighbor-solicit, nd-neighbor-advert } accept\n")
+		rs.renderRules(b, p, Ingress, setIndex)
+		b.WriteString("\t\tdrop\n\t}\n")
+	}
+	b.WriteString("}\n")
+}
+
+// renderForwards writes the ip table with the DNAT rules of the port
+// forwards, for connections from outside in prerouting and from the host
+// itself in output. Forwards without a host address take the port on every
+// local address. It adds no forward accepts: an accept only ends its own
+// base chain, so it cannot override libvirt's reject of new connections into
+// NAT networks, which the host has to lift itself (see the README).
+func (rs ruleset) renderForwards(b *strings.Builder) {
+	fmt.Fprintf(b, "table ip %s\ndelete table ip %s\n", tableName, tableName)
+	if len(rs.Forwards) == 0 {
+		return
+	}
+	forwards := slices.Clone(rs.Forwards)
+	sort.Slice(forwards, func(i, j int) bool { return forwards[i].Name < forwards[j].Name })
+	fmt.Fprintf(b, "table ip %s {\n", tableName)
+	for _, hook := range []string{"prerouting", "output"} {
+		fmt.Fprintf(b, "\tchain %s {\n\t\ttype nat hook %s priority -100; policy accept;\n", hook, hook)
+		for _, f := range forwards {
+			daddr := "fib daddr type local"
+			if f.HostIP != "" {
+				daddr = "ip daddr " + f.HostIP
+			}
+			fmt.Fprintf(b, "\t\t%s %s dport %d dnat to %s:%d comment %q\n", daddr, f.Protocol, f.HostPort, f.Target, f.Port, f.Name)
+		}
+		b.WriteString("\t}\n")
+	}
+	b.WriteString("}\n")
+}
+
+// restricts reports whether a group of p has a rule in direction.
+func (rs ruleset) restricts(p port, direction string) bool {
+	for _, name := range p.Groups {
+		for _, r := range rs.Groups[name].Rules {
+			if r.Direction == direction {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// renderRules writes the accept statements of the rules in direction of the
+// groups of p.
+func (rs ruleset) renderRules(b *strings.Builder, p port, direction string, setIndex map[string]int) {
+	peer := "daddr"
+	if direction == Ingress {
+		peer = "saddr"
+	}
+	groups := slices.Clone(p.Groups)
+	sort.Strings(groups)
+	for _, name := range groups {
+		g, ok := rs.Groups[name]
+		if !ok {
+			continue
+		}
+		for _, r := range g.Rules {
+			if r.Direction != direction {
+				continue
+			}
+			for _, fam := range []family{ipv4, ipv6} {
+				l4 := fam.l4(r)
+				if l4 == "-" {
+					continue
+				}
+				var matches []string
+				var cidrs []string
+				for _, c := range r.CIDRs {
+					if prefix, err := netip.ParsePrefix(c); err == nil && fam.has(prefix.Addr()) {
+						cidrs = append(cidrs, prefix.String())
+					}
+				}
+				if len(cidrs) > 0 {
+					matches = append(matches, fmt.Sprintf("%s %s { %s }", fam.proto, peer, strings.Join(cidrs, ", ")))
+				}
+				for _, ref := range r.Groups {
+					if i, ok := setIndex[ref]; ok {
+						matches = append(matches, fmt.Sprintf("%s %s @g%d_%s", fam.proto, peer, i, fam.suffix))
+					}
+				}
+				if len(r.CIDRs) == 0 && len(r.Groups) == 0 {
+					matches = append(matches, "ether type "+fam.proto)
+				}
+				for _, m := range matches {
+					fmt.Fprintf(b, "\t\t%s%s accept comment %q\n", m, l4, p.VM+" "+g.Name)
+				}
+			}
+		}
+	}
+}
+
+// family holds the nftables names of an address family.
+type family struct {
+	proto  string // ip or ip6
+	set    string // ipv4 or ipv6
+	suffix string
+	icmp   string
+}
+
+var (
+	ipv4 = family{proto: "ip", set: "ipv4", suffix: "4", icmp: "icmp"}
+	ipv6 = family{proto: "ip6", set: "ipv6", suffix: "6", icmp: "icmpv6"}
+)
+
+func (f family) has(a netip.Addr) bool { return a.Unmap().Is4() == (f == ipv4) }
+
+// l4 renders the protocol and port match of r for the family, or "-" when
+// r cannot match in it.
+func (f family) l4(r Rule) string {
+	switch r.Protocol {
+	case "tcp", "udp":
+		switch {
+		case r.PortFrom == 0:
+			return " meta l4proto " + r.Protocol
+		case r.PortFrom == r.PortTo:
+			return fmt.Sprintf(" %s dport %d", r.Protocol, r.PortFrom)
+		default:
+			return fmt.Sprintf(" %s dport %d-%d", r.Protocol, r.PortFrom, r.PortTo)
+		}
+	case "icmp", "icmpv6":
+		if r.Protocol != f.icmp {
+			return "-"
+		}
+		if f == ipv6 {
+			return " meta l4proto ipv6-icmp"
+		}
+		return " meta l4proto icmp"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,242 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/riccardotacconi/deusvm/internal/kvm"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// golden compares got with testdata/<name>.nft, or rewrites it with -update.
+func golden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".nft")
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("ruleset differs from %s:\n%s", path, got)
+	}
+}
+
+var testGroups = map[string]SecurityGroup{
+	"web": {Name: "web", Rules: []Rule{
+		{Direction: Ingress, Protocol: "tcp", PortFrom: 80, PortTo: 443, CIDRs: []string{"0.0.0.0/0", "::/0"}},
+		{Direction: Ingress, Protocol: "tcp", PortFrom: 22, PortTo: 22, CIDRs: []string{"10.0.0.0/8"}},
+		{Direction: Ingress, Protocol: "udp", CIDRs: []string{"2001:db8::/32"}},
+		{Direction: Egress, Protocol: "any"},
+	}},
+	"db": {Name: "db", Rules: []Rule{
+		{Direction: Ingress, Protocol: "tcp", PortFrom: 5432, PortTo: 5432, Groups: []string{"web"}},
+		{Direction: Egress, Protocol: "udp", PortFrom: 53, PortTo: 53, CIDRs: []string{"10.0.0.1/32"}},
+	}},
+	"ping": {Name: "ping", Rules: []Rule{
+		{Direction: Ingress, Protocol: "icmp"},
+		{Direction: Ingress, Protocol: "icmpv6"},
+	}},
+	"empty": {Name: "empty"},
+}
+
+func TestRenderFilter(t *testing.T) {
+	web := port{VM: "web-01", Device: "vnet0", MAC: "52:54:00:00:00:01", Addrs: []string{"10.0.0.11", "fd00::11"}, Groups: []string{"web"}}
+	// a stopped VM has no device but still lends its addresses to its groups
+	stopped := port{VM: "web-02", MAC: "52:54:00:00:00:02", Addrs: []string{"10.0.0.12"}, Groups: []string{"web"}}
+	db := port{VM: "db-01", Device: "vnet1", MAC: "52:54:00:00:00:03", Addrs: []string{"10.0.0.21"}, Groups: []string{"db"}}
+	ping := port{VM: "lab-01", Device: "vnet2", MAC: "52:54:00:00:00:04", Addrs: []string{"10.0.0.31", "fd00::31"}, Groups: []string{"ping", "empty"}}
+	unfiltered := port{VM: "lab-02", Device: "vnet3", MAC: "52:54:00:00:00:05", Addrs: []string{"10.0.0.32"}}
+
+	tests := []struct {
+		name  string
+		ports []port
+	}{
+		{name: "no-ports"},
+		{name: "cidrs", ports: []port{web, unfiltered}},
+		{name: "group-refs", ports: []port{db, web, stopped}},
+		{name: "icmp-ingress-only", ports: []port{ping}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			ruleset{Groups: testGroups, Ports: tt.ports}.renderFilter(&b)
+			golden(t, "filter-"+tt.name, b.String())
+		})
+	}
+}
+
+func TestRenderFilterSkipsUnfilteredNICs(t *testing.T) {
+	rs := ruleset{Groups: testGroups, Ports: []port{
+		{VM: "lab-02", Device: "vnet3", MAC: "52:54:00:00:00:05", Addrs: []string{"10.0.0.32"}},
+		{VM: "web-02", MAC: "52:54:00:00:00:02", Addrs: []string{"10.0.0.12"}, Groups: []string{"web"}},
+	}}
+	var b strings.Builder
+	rs.renderFilter(&b)
+	if want := "table bridge deusvm\ndelete table bridge deusvm\n"; b.String() != want {
+		t.Errorf("got %q, want only the table reset %q", b.String(), want)
+	}
+}
+
+func TestRenderRules(t *testing.T) {
+	rs := ruleset{Groups: testGroups}
+	setIndex := map[string]int{"db": 0, "empty": 1, "ping": 2, "web": 3}
+	tests := []struct {
+		name      string
+		groups    []string
+		direction string
+		want      []string
+	}{
+		{
+			name:      "port ranges and cidrs split by family",
+			groups:    []string{"web"},
+			direction: Ingress,
+			want: []string{
+				`ip saddr { 0.0.0.0/0 } tcp dport 80-443 accept comment "vm web"`,
+				`ip6 saddr { ::/0 } tcp dport 80-443 accept comment "vm web"`,
+				`ip saddr { 10.0.0.0/8 } tcp dport 22 accept comment "vm web"`,
+				`ip6 saddr { 2001:db8::/32 } meta l4proto udp accept comment "vm web"`,
+			},
+		},
+		{
+			name:      "group reference",
+			groups:    []string{"db"},
+			direction: Ingress,
+			want: []string{
+				`ip saddr @g3_4 tcp dport 5432 accept comment "vm db"`,
+				`ip6 saddr @g3_6 tcp dport 5432 accept comment "vm db"`,
+			},
+		},
+		{
+			name:      "egress matches the peer",
+			groups:    []string{"db"},
+			direction: Egress,
+			want:      []string{`ip daddr { 10.0.0.1/32 } udp dport 53 accept comment "vm db"`},
+		},
+		{
+			name:      "any protocol without peers",
+			groups:    []string{"web"},
+			direction: Egress,
+			want: []string{
+				`ether type ip accept comment "vm web"`,
+				`ether type ip6 accept comment "vm web"`,
+			},
+		},
+		{
+			name:      "icmp per family",
+			groups:    []string{"ping"},
+			direction: Ingress,
+			want: []string{
+				`ether type ip meta l4proto icmp accept comment "vm ping"`,
+				`ether type ip6 meta l4proto ipv6-icmp accept comment "vm ping"`,
+			},
+		},
+		{
+			name:      "unknown and empty groups",
+			groups:    []string{"gone", "empty"},
+			direction: Ingress,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			rs.renderRules(&b, port{VM: "vm", Groups: tt.groups}, tt.direction, setIndex)
+			var want string
+			for _, line := range tt.want {
+				want += "\t\t" + line + "\n"
+			}
+			if b.String() != want {
+				t.Errorf("got:\n%swant:\n%s", b.String(), want)
+			}
+		})
+	}
+}
+
+func TestRenderForwards(t *testing.T) {
+	tests := []struct {
+		name     string
+		forwards []Forward
+	}{
+		{name: "none"},
+		{name: "host-ip", forwards: []Forward{
+			{Name: "web-dns", HostIP: "192.0.2.10", HostPort: 53, Protocol: "udp", Port: 53, Target: "10.0.0.11"},
+			{Name: "web-ssh", HostPort: 2222, Protocol: "tcp", Port: 22, Target: "10.0.0.11"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			ruleset{Forwards: tt.forwards}.renderForwards(&b)
+			golden(t, "forwards-"+tt.name, b.String())
+		})
+	}
+}
+
+// fakeApplier records the rulesets applied and fails with err when set.
+type fakeApplier struct {
+	applied []string
+	err     error
+}
+
+func (a *fakeApplier) Apply(ctx context.Context, ruleset string) error {
+	a.applied = append(a.applied, ruleset)
+	return a.err
+}
+
+func TestSyncFirewall(t *testing.T) {
+	ctx := context.Background()
+	applier := &fakeApplier{}
+	s, err := NewService(kvm.NewInMemoryManager(), t.TempDir(), applier, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SyncFirewall(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(applier.applied) != 1 {
+		t.Fatalf("applied %d rulesets, want 1", len(applier.applied))
+	}
+	if want := (ruleset{}).render(); applier.applied[0] != want {
+		t.Errorf("applied %q, want %q", applier.applied[0], want)
+	}
+	if err := s.SyncFirewall(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(applier.applied) != 1 {
+		t.Errorf("an unchanged ruleset was applied again")
+	}
+
+	// nothing to enforce: hosts without nft are fine
+	applier = &fakeApplier{err: exec.ErrNotFound}
+	s.applier, s.applied = applier, ""
+	if err := s.SyncFirewall(ctx); err != nil {
+		t.Errorf("missing nft with nothing to enforce: %v", err)
+	}
+
+	// other failures are reported and retried on the next sync
+	boom := errors.New("boom")
+	applier = &fakeApplier{err: boom}
+	s.applier, s.applied = applier, ""
+	if err := s.SyncFirewall(ctx); !errors.Is(err, boom) {
+		t.Errorf("got %v, want %v", err, boom)
+	}
+	applier.err = nil
+	if err := s.SyncFirewall(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(applier.applied) != 2 {
+		t.Errorf("applied %d times, want a retry after the failure", len(applier.applied))
+	}
+}
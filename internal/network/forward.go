@@ -0,0 +1,121 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"github.com/riccardotacconi/deusvm/internal/state"
+)
+
+var (
+	ErrForwardNotFound = errors.New("port forward not found")
+	ErrForwardExists   = errors.New("port forward already exists")
+	ErrPortInUse       = errors.New("host port already in use")
+)
+
+// forwardName limits names to what nftables comments and URLs take as is.
+var forwardName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,62}$`)
+
+// Forward sends connections to HostPort of the host, on HostIP or on every
+// host address when it is empty, to Port of the address reserved for a VM's
+// NIC on a NAT network. Target is that address and Active tells whether the
+// rule is in place, which it is while the VM runs.
+type Forward struct {
+	Name     string `json:"name"`
+	HostIP   string `json:"host_ip,omitempty"`
+	HostPort int    `json:"host_port"`
+	Protocol string `json:"protocol"`
+	VM       string `json:"vm"`
+	VMName   string `json:"vm_name"`
+	Network  string `json:"network,omitempty"`
+	Port     int    `json:"port"`
+	Target   string `json:"target,omitempty"`
+	Active   bool   `json:"active"`
+}
+
+// validateForward checks the fields of f that do not depend on the VM and
+// fills in the default protocol.
+func validateForward(f Forward) (Forward, error) {
+	if !forwardName.MatchString(f.Name) {
+		return Forward{}, fmt.Errorf("invalid port forward name %q", f.Name)
+	}
+	if f.Protocol == "" {
+		f.Protocol = "tcp"
+	}
+	if f.Protocol != "tcp" && f.Protocol != "udp" {
+		return Forward{}, fmt.Errorf("invalid protocol %q (want tcp or udp)", f.Protocol)
+	}
+	if f.HostIP != "" {
+		addr, err := netip.ParseAddr(f.HostIP)
+		if err != nil || !addr.Unmap().Is4() {
+			return Forward{}, fmt.Errorf("invalid host ip %q (want an IPv4 address)", f.HostIP)
+		}
+		f.HostIP = addr.Unmap().String()
+		if addr.IsUnspecified() {
+			f.HostIP = ""
+		}
+	}
+	for _, p := range []int{f.HostPort, f.Port} {
+		if p < 1 || p > 65535 {
+			return Forward{}, fmt.Errorf("invalid port %d", p)
+		}
+	}
+	return f, nil
+}
+
+// overlaps reports whether f and g would take the same host port.
+func (f Forward) overlaps(g Forward) bool {
+	return f.Protocol == g.Protocol && f.HostPort == g.HostPort &&
+		(f.HostIP == "" || g.HostIP == "" || f.HostIP == g.HostIP)
+}
+
+// listenerForward turns a listen address such as ":8080" into the tcp
+// forward it would conflict with. Host names count as every address.
+func listenerForward(addr string) (Forward, bool) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return Forward{}, false
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return Forward{}, false
+	}
+	f := Forward{Protocol: "tcp", HostPort: port}
+	if ip, err := netip.ParseAddr(host); err == nil && !ip.IsUnspecified() {
+		f.HostIP = ip.Unmap().String()
+	}
+	return f, true
+}
+
+// forwardRegistry persists the port forwards.
+type forwardRegistry struct {
+	mu       sync.Mutex
+	path     string
+	Forwards []Forward `json:"forwards"`
+}
+
+func loadForwards(dir string) (*forwardRegistry, error) {
+	r := &forwardRegistry{path: filepath.Join(dir, "forwards.json")}
+	if err := state.Load(r.path, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// set replaces the forwards, keeping the old ones if they cannot be saved.
+// The caller holds mu.
+func (r *forwardRegistry) set(forwards []Forward) error {
+	prev := r.Forwards
+	r.Forwards = forwards
+	if err := state.Save(r.path, r); err != nil {
+		r.Forwards = prev
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/riccardotacconi/deusvm/internal/kvm"
+	"github.com/riccardotacconi/deusvm/internal/state"
+)
+
+var (
+	ErrAddressInUse = errors.New("address already allocated")
+	ErrExhausted    = errors.New("no free address left")
+)
+
+// Allocation is an address reserved for a VM NIC on a managed network.
+// Static allocations were requested by address; the others were picked from
+// the subnet.
+type Allocation struct {
+	Network   string    `json:"network"`
+	IP        string    `json:"ip"`
+	MAC       string    `json:"mac"`
+	VM        string    `json:"vm"`
+	VMName    string    `json:"vm_name"`
+	Static    bool      `json:"static,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ipamRegistry persists the allocations of every managed network.
+type ipamRegistry struct {
+	mu          sync.Mutex
+	path        string
+	Allocations []Allocation `json:"allocations"`
+}
+
+func loadIPAM(dir string) (*ipamRegistry, error) {
+	r := &ipamRegistry{path: filepath.Join(dir, "ipam.json")}
+	if err := state.Load(r.path, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// set replaces the allocations, keeping the old ones if they cannot be
+// saved. The caller holds mu.
+func (r *ipamRegistry) set(allocs []Allocation) error {
+	prev := r.Allocations
+	r.Allocations = allocs
+	if err := state.Save(r.path, r); err != nil {
+		r.Allocations = prev
+		return err
+	}
+	return nil
+}
+
+// used returns the addresses allocated on network.
+func (r *ipamRegistry) used(network string) map[netip.Addr]bool {
+	out := make(map[netip.Addr]bool)
+	for _, a := range r.Allocations {
+		if addr, err := netip.ParseAddr(a.IP); err == nil && a.Network == network {
+			out[addr] = true
+		}
+	}
+	return out
+}
+
+// pickAddress reserves requested in s, or the first free host address
+// outside the DHCP range when requested is empty. The gateway, the subnet
+// address and the IPv4 broadcast address are never handed out; neither are
+// addresses in the DHCP range, which dnsmasq may have leased already.
+func pickAddress(s kvm.Subnet, used map[netip.Addr]bool, requested string) (netip.Addr, error) {
+	prefix, err := netip.ParsePrefix(s.CIDR)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("invalid subnet %q", s.CIDR)
+	}
+	gw, _ := netip.ParseAddr(s.Gateway)
+	start, _ := netip.ParseAddr(s.DHCPStart)
+	end, _ := netip.ParseAddr(s.DHCPEnd)
+	inRange := func(a netip.Addr) bool { return start.IsValid() && !a.Less(start) && !end.Less(a) }
+	reserved := func(a netip.Addr) bool {
+		if a == prefix.Addr() || a == gw {
+			return true
+		}
+		// the last address of an IPv4 subnet is its broadcast address
+		return a.Is4() && prefix.Bits() < 31 && !prefix.Contains(a.Next())
+	}
+	if requested != "" {
+		addr, err := netip.ParseAddr(requested)
+		if err != nil || !prefix.Contains(addr) || reserved(addr) {
+			return netip.Addr{}, fmt.Errorf("%s is not a usable address of %s", requested, prefix)
+		}
+		if inRange(addr) {
+			return netip.Addr{}, fmt.Errorf("%s is in the dhcp range %s-%s of %s", addr, start, end, prefix)
+		}
+		if used[addr] {
+			return netip.Addr{}, fmt.Errorf("%s: %w", addr, ErrAddressInUse)
+		}
+		return addr, nil
+	}
+	for a := prefix.Addr().Next(); a.IsValid() && prefix.Contains(a); a = a.Next() {
+		switch {
+		case inRange(a):
+			a = end
+		case reserved(a), used[a]:
+		default:
+			return a, nil
+		}
+	}
+	return netip.Addr{}, fmt.Errorf("%s: %w", prefix, ErrExhausted)
+}
@@ -0,0 +1,868 @@
+// Package network keeps the daemon's view of managed networks on top of the
+// libvirt definitions: the addresses reserved for VM NICs, the security
+// groups filtering their traffic, the host ports forwarded to them and the
+// iPXE scripts VMs boot from over them.
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/riccardotacconi/deusvm/internal/kvm"
+	"github.com/riccardotacconi/deusvm/internal/logging"
+	"go.uber.org/zap"
+)
+
+// Service reserves addresses for VM NICs on managed networks, pushing the
+// reservations into the networks' DHCP servers, and enforces security groups
+// and port forwards on the NICs with nftables.
+type Service struct {
+	manager   kvm.Manager
+	ipam      *ipamRegistry
+	groups    *groupRegistry
+	forwards  *forwardRegistry
+	templates *templateRegistry
+	applier   Applier
+	listeners []Forward
+
+	fwMu    sync.Mutex
+	applied string // last ruleset applied, empty before the first sync
+}
+
+// NewService loads the allocations, security groups, port forwards and boot
+// templates persisted under stateDir. Firewall rules are loaded through applier;
+// without one security groups and forwards are kept but not enforced.
+// listeners are the daemon's own TCP listen addresses, which no forward may
+// take.
+func NewService(manager kvm.Manager, stateDir string, applier Applier, listeners []string) (*Service, error) {
+	ipam, err := loadIPAM(stateDir)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := loadGroups(stateDir)
+	if err != nil {
+		return nil, err
+	}
+	forwards, err := loadForwards(stateDir)
+	if err != nil {
+		return nil, err
+	}
+	templates, err := loadTemplates(stateDir)
+	if err != nil {
+		return nil, err
+	}
+	s := &Service{manager: manager, ipam: ipam, groups: groups, forwards: forwards, templates: templates, applier: applier}
+	for _, addr := range listeners {
+		if f, ok := listenerForward(addr); ok {
+			f.Name = addr
+			s.listeners = append(s.listeners, f)
+		}
+	}
+	return s, nil
+}
+
+// Assign reserves an address on every subnet of the managed networks vm has
+// NICs on, honouring the addresses requested for the NIC at the same index
+// in requested, and adds them as static DHCP leases. It also puts the NICs
+// in the security groups requested for them. vm may list only some of its
+// NICs, such as one being plugged in, and only those are touched. Either
+// everything is set up or nothing is.
+func (s *Service) Assign(ctx context.Context, vm kvm.VM, requested []kvm.NIC) ([]Allocation, error) {
+	allocs, err := s.reserve(ctx, vm, requested)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.bindRequested(vm, requested); err != nil {
+		_ = s.drop(reservedBy(allocs))
+		return nil, err
+	}
+	if err := s.addLeases(ctx, allocs); err != nil {
+		for _, nic := range vm.NICs {
+			_ = s.unbindNIC(vm.ID, nic.MAC)
+		}
+		return nil, err
+	}
+	return allocs, nil
+}
+
+// Reserve is Assign without the security groups, for NICs moving to
+// another network: they stay in their groups, and the addresses they had
+// are released with ReleaseAddresses once they have moved.
+func (s *Service) Reserve(ctx context.Context, vm kvm.VM, requested []kvm.NIC) ([]Allocation, error) {
+	allocs, err := s.reserve(ctx, vm, requested)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.addLeases(ctx, allocs); err != nil {
+		return nil, err
+	}
+	return allocs, nil
+}
+
+// addLeases adds allocs as static DHCP leases. If one cannot be added, the
+// others are removed again and allocs are dropped.
+func (s *Service) addLeases(ctx context.Context, allocs []Allocation) error {
+	for i, a := range allocs {
+		if err := s.manager.AddDHCPHost(ctx, a.Network, dhcpHost(a)); err != nil {
+			for _, done := range allocs[:i] {
+				_ = s.manager.RemoveDHCPHost(ctx, done.Network, dhcpHost(done))
+			}
+			_ = s.drop(reservedBy(allocs))
+			return fmt.Errorf("add dhcp lease for %s: %w", a.IP, err)
+		}
+	}
+	return nil
+}
+
+// reservedBy matches the allocations in allocs.
+func reservedBy(allocs []Allocation) func(Allocation) bool {
+	return func(x Allocation) bool {
+		return slices.ContainsFunc(allocs, func(a Allocation) bool { return a.Network == x.Network && a.IP == x.IP })
+	}
+}
+
+// reserve picks and records the addresses of Assign.
+func (s *Service) reserve(ctx context.Context, vm kvm.VM, requested []kvm.NIC) ([]Allocation, error) {
+	s.ipam.mu.Lock()
+	defer s.ipam.mu.Unlock()
+	var allocs []Allocation
+	for i, nic := range vm.NICs {
+		var want kvm.NIC
+		if i < len(requested) {
+			want = requested[i]
+		}
+		if nic.Network == "" {
+			if want.IPv4 != "" || want.IPv6 != "" {
+				return nil, fmt.Errorf("nic %d: fixed addresses need a managed network", i)
+			}
+			continue
+		}
+		n, err := s.manager.GetNetwork(ctx, nic.Network)
+		if err != nil {
+			return nil, err
+		}
+		for _, sub := range []struct {
+			subnet *kvm.Subnet
+			want   string
+		}{{n.IPv4, want.IPv4}, {n.IPv6, want.IPv6}} {
+			if sub.subnet == nil {
+				if sub.want != "" {
+					return nil, fmt.Errorf("nic %d: network %s has no subnet for %s", i, n.Name, sub.want)
+				}
+				continue
+			}
+			used := s.ipam.used(n.Name)
+			for _, a := range allocs {
+				if a.Network == n.Name {
+					used[netip.MustParseAddr(a.IP)] = true
+				}
+			}
+			addr, err := pickAddress(*sub.subnet, used, sub.want)
+			if err != nil {
+				return nil, fmt.Errorf("nic %d: %w", i, err)
+			}
+			allocs = append(allocs, Allocation{
+				Network: n.Name, IP: addr.String(), MAC: nic.MAC, VM: vm.ID, VMName: vm.Name,
+				Static: sub.want != "", CreatedAt: time.Now().UTC(),
+			})
+		}
+	}
+	if len(allocs) == 0 {
+		return nil, nil
+	}
+	if err := s.ipam.set(append(slices.Clone(s.ipam.Allocations), allocs...)); err != nil {
+		return nil, err
+	}
+	return allocs, nil
+}
+
+// Release removes the addresses reserved for a VM and their DHCP leases,
+// takes its NICs out of their security groups and drops its port forwards
+// and its boot template.
+func (s *Service) Release(ctx context.Context, vmID string) error {
+	var errs []error
+	for _, a := range s.Allocations("") {
+		if a.VM != vmID {
+			continue
+		}
+		err := s.manager.RemoveDHCPHost(ctx, a.Network, dhcpHost(a))
+		if err != nil && !errors.Is(err, kvm.ErrNetworkNotFound) {
+			errs = append(errs, fmt.Errorf("remove dhcp lease for %s: %w", a.IP, err))
+		}
+	}
+	if err := s.drop(func(a Allocation) bool { return a.VM == vmID }); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.unbind(vmID); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.dropForwards(vmID); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.SetBootTemplate(vmID, ""); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// ReleaseNIC removes what is kept for the NIC of a VM with the MAC address
+// mac, such as one being unplugged: its addresses, their DHCP leases and its
+// security groups.
+func (s *Service) ReleaseNIC(ctx context.Context, vmID, mac string) error {
+	return errors.Join(s.ReleaseAddresses(ctx, vmID, mac, ""), s.unbindNIC(vmID, mac))
+}
+
+// ReleaseAddresses removes the addresses reserved for the NIC of a VM with
+// the MAC address mac on network, or on every network when it is empty, and
+// their DHCP leases.
+func (s *Service) ReleaseAddresses(ctx context.Context, vmID, mac, network string) error {
+	match := func(a Allocation) bool {
+		return a.VM == vmID && a.MAC == mac && (network == "" || a.Network == network)
+	}
+	var errs []error
+	for _, a := range s.Allocations("") {
+		if !match(a) {
+			continue
+		}
+		err := s.manager.RemoveDHCPHost(ctx, a.Network, dhcpHost(a))
+		if err != nil && !errors.Is(err, kvm.ErrNetworkNotFound) {
+			errs = append(errs, fmt.Errorf("remove dhcp lease for %s: %w", a.IP, err))
+		}
+	}
+	if err := s.drop(match); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Prune releases the addresses of VMs that no longer exist, such as VMs
+// undefined outside the daemon.
+func (s *Service) Prune(ctx context.Context) error {
+	vms, err := s.manager.ListVMs(ctx)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool)
+	for _, vm := range vms {
+		known[vm.ID] = true
+	}
+	gone := make(map[string]bool)
+	for _, a := range s.Allocations("") {
+		if !known[a.VM] {
+			gone[a.VM] = true
+		}
+	}
+	s.groups.mu.Lock()
+	for _, b := range s.groups.Bindings {
+		if !known[b.VM] {
+			gone[b.VM] = true
+		}
+	}
+	s.groups.mu.Unlock()
+	s.forwards.mu.Lock()
+	for _, f := range s.forwards.Forwards {
+		if !known[f.VM] {
+			gone[f.VM] = true
+		}
+	}
+	s.forwards.mu.Unlock()
+	s.templates.mu.Lock()
+	for id := range s.templates.VMs {
+		if !known[id] {
+			gone[id] = true
+		}
+	}
+	s.templates.mu.Unlock()
+	var errs []error
+	for id := range gone {
+		errs = append(errs, s.Release(ctx, id))
+	}
+	return errors.Join(errs...)
+}
+
+// Allocations lists the addresses reserved on network, or on every network
+// when it is empty.
+func (s *Service) Allocations(network string) []Allocation {
+	s.ipam.mu.Lock()
+	defer s.ipam.mu.Unlock()
+	var out []Allocation
+	for _, a := range s.ipam.Allocations {
+		if network == "" || a.Network == network {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// Annotate fills in what the daemon keeps about vm: the addresses reserved
+// for its NICs, their security groups and its boot template.
+func (s *Service) Annotate(vm *kvm.VM) {
+	allocs := s.Allocations("")
+	s.groups.mu.Lock()
+	bindings := slices.Clone(s.groups.Bindings)
+	s.groups.mu.Unlock()
+	s.templates.mu.Lock()
+	vm.BootTemplate = s.templates.VMs[vm.ID]
+	s.templates.mu.Unlock()
+	for i := range vm.NICs {
+		nic := &vm.NICs[i]
+		for _, b := range bindings {
+			if b.VM == vm.ID && b.MAC == nic.MAC {
+				nic.SecurityGroups = b.Groups
+			}
+		}
+		for _, a := range allocs {
+			if a.VM != vm.ID || a.MAC != nic.MAC || a.Network != nic.Network {
+				continue
+			}
+			if netip.MustParseAddr(a.IP).Is4() {
+				nic.IPv4 = a.IP
+			} else {
+				nic.IPv6 = a.IP
+			}
+		}
+	}
+}
+
+// drop removes the allocations matching fn.
+func (s *Service) drop(fn func(Allocation) bool) error {
+	s.ipam.mu.Lock()
+	defer s.ipam.mu.Unlock()
+	next := slices.DeleteFunc(slices.Clone(s.ipam.Allocations), fn)
+	if len(next) == len(s.ipam.Allocations) {
+		return nil
+	}
+	return s.ipam.set(next)
+}
+
+// CreateSecurityGroup adds a security group; its rules may refer to groups
+// that already exist and to itself.
+func (s *Service) CreateSecurityGroup(ctx context.Context, g SecurityGroup) (SecurityGroup, error) {
+	s.groups.mu.Lock()
+	defer s.groups.mu.Unlock()
+	if _, ok := s.groups.Groups[g.Name]; ok {
+		return SecurityGroup{}, fmt.Errorf("%s: %w", g.Name, ErrGroupExists)
+	}
+	g, err := validateGroup(g, s.groups.Groups)
+	if err != nil {
+		return SecurityGroup{}, err
+	}
+	err = s.groups.update(func(groups map[string]SecurityGroup, bindings []binding) ([]binding, error) {
+		groups[g.Name] = g
+		return bindings, nil
+	})
+	return g, err
+}
+
+// UpdateSecurityGroup replaces the description and rules of a group and
+// applies them to its NICs.
+func (s *Service) UpdateSecurityGroup(ctx context.Context, g SecurityGroup) (SecurityGroup, error) {
+	err := func() error {
+		s.groups.mu.Lock()
+		defer s.groups.mu.Unlock()
+		if _, ok := s.groups.Groups[g.Name]; !ok {
+			return fmt.Errorf("%s: %w", g.Name, ErrGroupNotFound)
+		}
+		var err error
+		if g, err = validateGroup(g, s.groups.Groups); err != nil {
+			return err
+		}
+		return s.groups.update(func(groups map[string]SecurityGroup, bindings []binding) ([]binding, error) {
+			groups[g.Name] = g
+			return bindings, nil
+		})
+	}()
+	if err != nil {
+		return SecurityGroup{}, err
+	}
+	return g, s.SyncFirewall(ctx)
+}
+
+func (s *Service) GetSecurityGroup(name string) (SecurityGroup, error) {
+	s.groups.mu.Lock()
+	defer s.groups.mu.Unlock()
+	g, ok := s.groups.Groups[name]
+	if !ok {
+		return SecurityGroup{}, fmt.Errorf("%s: %w", name, ErrGroupNotFound)
+	}
+	return g, nil
+}
+
+func (s *Service) ListSecurityGroups() []SecurityGroup {
+	s.groups.mu.Lock()
+	defer s.groups.mu.Unlock()
+	out := make([]SecurityGroup, 0, len(s.groups.Groups))
+	for _, g := range s.groups.Groups {
+		out = append(out, g)
+	}
+	slices.SortFunc(out, func(a, b SecurityGroup) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
+
+// DeleteSecurityGroup removes a group no NIC is in and no other group's
+// rules refer to.
+func (s *Service) DeleteSecurityGroup(ctx context.Context, name string) error {
+	s.groups.mu.Lock()
+	defer s.groups.mu.Unlock()
+	if _, ok := s.groups.Groups[name]; !ok {
+		return fmt.Errorf("%s: %w", name, ErrGroupNotFound)
+	}
+	vms, groups := s.groups.users(name)
+	if len(vms) > 0 || len(groups) > 0 {
+		var users []string
+		for _, id := range vms {
+			if vm, err := s.manager.GetVM(ctx, id); err == nil {
+				id = vm.Name
+			}
+			users = append(users, "vm "+id)
+		}
+		for _, g := range groups {
+			users = append(users, "group "+g)
+		}
+		return fmt.Errorf("%s: %w by %s", name, ErrGroupInUse, strings.Join(users, ", "))
+	}
+	return s.groups.update(func(groups map[string]SecurityGroup, bindings []binding) ([]binding, error) {
+		delete(groups, name)
+		return bindings, nil
+	})
+}
+
+// SetSecurityGroups puts the NIC of vm with the MAC address mac in groups,
+// replacing the groups it was in, and applies the change. No groups leaves
+// the NIC unfiltered.
+func (s *Service) SetSecurityGroups(ctx context.Context, vm kvm.VM, mac string, groups []string) error {
+	i := slices.IndexFunc(vm.NICs, func(n kvm.NIC) bool { return n.MAC == mac })
+	if i < 0 {
+		return fmt.Errorf("vm %s has no nic %s: %w", vm.Name, mac, os.ErrNotExist)
+	}
+	requested := make([]kvm.NIC, len(vm.NICs))
+	requested[i].SecurityGroups = groups
+	if err := s.bindRequested(kvm.VM{ID: vm.ID, Name: vm.Name, NICs: vm.NICs[i : i+1]}, requested[i:i+1]); err != nil {
+		return err
+	}
+	return s.SyncFirewall(ctx)
+}
+
+// bindRequested records the groups requested for the NICs of vm, matched by
+// index, replacing earlier bindings of those NICs.
+func (s *Service) bindRequested(vm kvm.VM, requested []kvm.NIC) error {
+	s.groups.mu.Lock()
+	defer s.groups.mu.Unlock()
+	return s.groups.update(func(groups map[string]SecurityGroup, bindings []binding) ([]binding, error) {
+		for i, nic := range vm.NICs {
+			if i >= len(requested) {
+				break
+			}
+			var names []string
+			for _, g := range requested[i].SecurityGroups {
+				if _, ok := groups[g]; !ok {
+					return nil, fmt.Errorf("nic %d: %s: %w", i, g, ErrGroupNotFound)
+				}
+				if !slices.Contains(names, g) {
+					names = append(names, g)
+				}
+			}
+			bindings = slices.DeleteFunc(bindings, func(b binding) bool { return b.VM == vm.ID && b.MAC == nic.MAC })
+			if len(names) > 0 {
+				bindings = append(bindings, binding{VM: vm.ID, MAC: nic.MAC, Groups: names})
+			}
+		}
+		return bindings, nil
+	})
+}
+
+// unbind takes every NIC of a VM out of its groups.
+func (s *Service) unbind(vmID string) error {
+	s.groups.mu.Lock()
+	defer s.groups.mu.Unlock()
+	if !slices.ContainsFunc(s.groups.Bindings, func(b binding) bool { return b.VM == vmID }) {
+		return nil
+	}
+	return s.groups.update(func(groups map[string]SecurityGroup, bindings []binding) ([]binding, error) {
+		return slices.DeleteFunc(bindings, func(b binding) bool { return b.VM == vmID }), nil
+	})
+}
+
+// unbindNIC takes one NIC of a VM out of its groups.
+func (s *Service) unbindNIC(vmID, mac string) error {
+	s.groups.mu.Lock()
+	defer s.groups.mu.Unlock()
+	match := func(b binding) bool { return b.VM == vmID && b.MAC == mac }
+	if !slices.ContainsFunc(s.groups.Bindings, match) {
+		return nil
+	}
+	return s.groups.update(func(groups map[string]SecurityGroup, bindings []binding) ([]binding, error) {
+		return slices.DeleteFunc(bindings, match), nil
+	})
+}
+
+// SyncFirewall renders the rules for the NICs and port forwards of every VM
+// and applies them when they changed since the last sync. Tap devices change
+// whenever a VM starts, so this runs after VM changes and periodically from
+// Enforce.
+func (s *Service) SyncFirewall(ctx context.Context) error {
+	if s.applier == nil {
+		return nil
+	}
+	s.fwMu.Lock()
+	defer s.fwMu.Unlock()
+	vms, err := s.manager.ListVMs(ctx)
+	if err != nil {
+		return err
+	}
+	rs := ruleset{Groups: make(map[string]SecurityGroup)}
+	s.groups.mu.Lock()
+	for name, g := range s.groups.Groups {
+		rs.Groups[name] = g
+	}
+	s.groups.mu.Unlock()
+	for _, f := range s.resolveForwards(vms, "") {
+		if f.Active {
+			rs.Forwards = append(rs.Forwards, f)
+		}
+	}
+	for _, vm := range vms {
+		s.Annotate(&vm)
+		for _, nic := range vm.NICs {
+			p := port{VM: vm.Name, MAC: nic.MAC, Groups: nic.SecurityGroups}
+			if vm.Status == kvm.VMStatusRunning {
+				p.Device = nic.Device
+			}
+			for _, ip := range []string{nic.IPv4, nic.IPv6} {
+				if ip != "" {
+					p.Addrs = append(p.Addrs, ip)
+				}
+			}
+			rs.Ports = append(rs.Ports, p)
+		}
+	}
+	script := rs.render()
+	if script == s.applied {
+		return nil
+	}
+	if err := s.applier.Apply(ctx, script); err != nil {
+		// hosts without nft need no rules as long as nothing is filtered or forwarded
+		if !errors.Is(err, exec.ErrNotFound) || len(rs.filtered()) > 0 || len(rs.Forwards) > 0 {
+			return fmt.Errorf("apply firewall: %w", err)
+		}
+	}
+	s.applied = script
+	return nil
+}
+
+// CreateForward adds a port forward to a VM's NIC on a NAT network, the
+// first one when f.Network is empty, and applies it. f.VM is the VM's ID or
+// name. The host port may not be taken by another forward or by one of the
+// daemon's listeners.
+func (s *Service) CreateForward(ctx context.Context, f Forward) (Forward, error) {
+	f, err := validateForward(f)
+	if err != nil {
+		return Forward{}, err
+	}
+	vm, err := s.manager.GetVM(ctx, f.VM)
+	if err != nil {
+		return Forward{}, err
+	}
+	f.VM, f.VMName, f.Target, f.Active = vm.ID, vm.Name, "", false
+	s.Annotate(&vm)
+	var nic *kvm.NIC
+	for i := range vm.NICs {
+		n := &vm.NICs[i]
+		if n.Network == "" || (f.Network != "" && n.Network != f.Network) {
+			continue
+		}
+		if managed, err := s.manager.GetNetwork(ctx, n.Network); err != nil || managed.Mode != kvm.NetworkNAT {
+			continue
+		}
+		nic = n
+		break
+	}
+	switch {
+	case nic == nil && f.Network != "":
+		return Forward{}, fmt.Errorf("vm %s has no nic on nat network %s", vm.Name, f.Network)
+	case nic == nil:
+		return Forward{}, fmt.Errorf("vm %s has no nic on a nat network", vm.Name)
+	case nic.IPv4 == "":
+		return Forward{}, fmt.Errorf("vm %s has no ipv4 address on network %s", vm.Name, nic.Network)
+	}
+	f.Network = nic.Network
+
+	err = func() error {
+		s.forwards.mu.Lock()
+		defer s.forwards.mu.Unlock()
+		for _, l := range s.listeners {
+			if f.overlaps(l) {
+				return fmt.Errorf("%s/%d: %w by the daemon listening on %s", f.Protocol, f.HostPort, ErrPortInUse, l.Name)
+			}
+		}
+		for _, other := range s.forwards.Forwards {
+			if other.Name == f.Name {
+				return fmt.Errorf("%s: %w", f.Name, ErrForwardExists)
+			}
+			if f.overlaps(other) {
+				return fmt.Errorf("%s/%d: %w by forward %s", f.Protocol, f.HostPort, ErrPortInUse, other.Name)
+			}
+		}
+		return s.forwards.set(append(slices.Clone(s.forwards.Forwards), f))
+	}()
+	if err != nil {
+		return Forward{}, err
+	}
+	if err := s.SyncFirewall(ctx); err != nil {
+		return Forward{}, err
+	}
+	return s.GetForward(ctx, f.Name)
+}
+
+func (s *Service) GetForward(ctx context.Context, name string) (Forward, error) {
+	vms, err := s.manager.ListVMs(ctx)
+	if err != nil {
+		return Forward{}, err
+	}
+	for _, f := range s.resolveForwards(vms, "") {
+		if f.Name == name {
+			return f, nil
+		}
+	}
+	return Forward{}, fmt.Errorf("%s: %w", name, ErrForwardNotFound)
+}
+
+// ListForwards lists the port forwards to the VM with ID vmID, or all of them
+// when it is empty.
+func (s *Service) ListForwards(ctx context.Context, vmID string) ([]Forward, error) {
+	vms, err := s.manager.ListVMs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.resolveForwards(vms, vmID), nil
+}
+
+// DeleteForward removes a port forward and its rules.
+func (s *Service) DeleteForward(ctx context.Context, name string) error {
+	err := func() error {
+		s.forwards.mu.Lock()
+		defer s.forwards.mu.Unlock()
+		next := slices.DeleteFunc(slices.Clone(s.forwards.Forwards), func(f Forward) bool { return f.Name == name })
+		if len(next) == len(s.forwards.Forwards) {
+			return fmt.Errorf("%s: %w", name, ErrForwardNotFound)
+		}
+		return s.forwards.set(next)
+	}()
+	if err != nil {
+		return err
+	}
+	return s.SyncFirewall(ctx)
+}
+
+// resolveForwards returns the forwards to vmID, or every forward when it is
+// empty, sorted by name, with the address each currently goes to. A forward
+// is active while its VM runs and has that address.
+func (s *Service) resolveForwards(vms []kvm.VM, vmID string) []Forward {
+	s.forwards.mu.Lock()
+	forwards := slices.Clone(s.forwards.Forwards)
+	s.forwards.mu.Unlock()
+	allocs := s.Allocations("")
+	out := make([]Forward, 0, len(forwards))
+	for _, f := range forwards {
+		if vmID != "" && f.VM != vmID {
+			continue
+		}
+		for _, a := range allocs {
+			if a.VM == f.VM && a.Network == f.Network && netip.MustParseAddr(a.IP).Is4() {
+				f.Target = a.IP
+				break
+			}
+		}
+		for _, vm := range vms {
+			if vm.ID == f.VM {
+				f.VMName = vm.Name
+				f.Active = f.Target != "" && vm.Status == kvm.VMStatusRunning
+			}
+		}
+		out = append(out, f)
+	}
+	slices.SortFunc(out, func(a, b Forward) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
+
+// dropForwards removes the port forwards to a VM.
+func (s *Service) dropForwards(vmID string) error {
+	s.forwards.mu.Lock()
+	defer s.forwards.mu.Unlock()
+	next := slices.DeleteFunc(slices.Clone(s.forwards.Forwards), func(f Forward) bool { return f.VM == vmID })
+	if len(next) == len(s.forwards.Forwards) {
+		return nil
+	}
+	return s.forwards.set(next)
+}
+
+// CreateBootTemplate adds an iPXE template VMs can boot from.
+func (s *Service) CreateBootTemplate(t BootTemplate) (BootTemplate, error) {
+	t, err := validateTemplate(t)
+	if err != nil {
+		return BootTemplate{}, err
+	}
+	s.templates.mu.Lock()
+	defer s.templates.mu.Unlock()
+	if _, ok := s.templates.Templates[t.Name]; ok {
+		return BootTemplate{}, fmt.Errorf("%s: %w", t.Name, ErrTemplateExists)
+	}
+	err = s.templates.update(func(templates map[string]BootTemplate, vms map[string]string) error {
+		templates[t.Name] = t
+		return nil
+	})
+	return t, err
+}
+
+// UpdateBootTemplate replaces the description and script of a template,
+// which VMs get the next time they boot.
+func (s *Service) UpdateBootTemplate(t BootTemplate) (BootTemplate, error) {
+	t, err := validateTemplate(t)
+	if err != nil {
+		return BootTemplate{}, err
+	}
+	s.templates.mu.Lock()
+	defer s.templates.mu.Unlock()
+	if _, ok := s.templates.Templates[t.Name]; !ok {
+		return BootTemplate{}, fmt.Errorf("%s: %w", t.Name, ErrTemplateNotFound)
+	}
+	err = s.templates.update(func(templates map[string]BootTemplate, vms map[string]string) error {
+		templates[t.Name] = t
+		return nil
+	})
+	return t, err
+}
+
+func (s *Service) GetBootTemplate(name string) (BootTemplate, error) {
+	s.templates.mu.Lock()
+	defer s.templates.mu.Unlock()
+	t, ok := s.templates.Templates[name]
+	if !ok {
+		return BootTemplate{}, fmt.Errorf("%s: %w", name, ErrTemplateNotFound)
+	}
+	return t, nil
+}
+
+func (s *Service) ListBootTemplates() []BootTemplate {
+	s.templates.mu.Lock()
+	defer s.templates.mu.Unlock()
+	out := make([]BootTemplate, 0, len(s.templates.Templates))
+	for _, t := range s.templates.Templates {
+		out = append(out, t)
+	}
+	slices.SortFunc(out, func(a, b BootTemplate) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
+
+// DeleteBootTemplate removes a template no VM boots from.
+func (s *Service) DeleteBootTemplate(ctx context.Context, name string) error {
+	s.templates.mu.Lock()
+	defer s.templates.mu.Unlock()
+	if _, ok := s.templates.Templates[name]; !ok {
+		return fmt.Errorf("%s: %w", name, ErrTemplateNotFound)
+	}
+	var users []string
+	for id, t := range s.templates.VMs {
+		if t != name {
+			continue
+		}
+		if vm, err := s.manager.GetVM(ctx, id); err == nil {
+			id = vm.Name
+		}
+		users = append(users, "vm "+id)
+	}
+	if len(users) > 0 {
+		slices.Sort(users)
+		return fmt.Errorf("%s: %w by %s", name, ErrTemplateInUse, strings.Join(users, ", "))
+	}
+	return s.templates.update(func(templates map[string]BootTemplate, vms map[string]string) error {
+		delete(templates, name)
+		return nil
+	})
+}
+
+// SetBootTemplate makes the VM with ID vmID boot from the template called
+// name, or from the default template when name is empty.
+func (s *Service) SetBootTemplate(vmID, name string) error {
+	s.templates.mu.Lock()
+	defer s.templates.mu.Unlock()
+	if name == s.templates.VMs[vmID] {
+		return nil
+	}
+	if _, ok := s.templates.Templates[name]; name != "" && !ok {
+		return fmt.Errorf("%s: %w", name, ErrTemplateNotFound)
+	}
+	return s.templates.update(func(templates map[string]BootTemplate, vms map[string]string) error {
+		if name == "" {
+			delete(vms, vmID)
+		} else {
+			vms[vmID] = name
+		}
+		return nil
+	})
+}
+
+// BootScript renders the iPXE script of the VM with a NIC with the MAC
+// address mac, from the VM's template or the default one, for a boot server
+// reached at server.
+func (s *Service) BootScript(ctx context.Context, mac, server string) ([]byte, error) {
+	hw, err := net.ParseMAC(strings.ReplaceAll(mac, "-", ":"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid mac address %q", mac)
+	}
+	mac = hw.String()
+	vms, err := s.manager.ListVMs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, vm := range vms {
+		i := slices.IndexFunc(vm.NICs, func(n kvm.NIC) bool { return strings.EqualFold(n.MAC, mac) })
+		if i < 0 {
+			continue
+		}
+		s.Annotate(&vm)
+		name := vm.BootTemplate
+		if name == "" {
+			name = DefaultBootTemplate
+		}
+		t, err := s.GetBootTemplate(name)
+		if err != nil {
+			return nil, err
+		}
+		nic := vm.NICs[i]
+		return t.render(BootInfo{
+			ID: vm.ID, Name: vm.Name, MAC: nic.MAC, Network: nic.Network, IPv4: nic.IPv4, IPv6: nic.IPv6, Server: server,
+		})
+	}
+	return nil, fmt.Errorf("%s: %w", mac, kvm.ErrNICNotFound)
+}
+
+// Enforce syncs the firewall every interval until ctx is done, so VMs
+// started or restarted outside the daemon get their rules.
+func (s *Service) Enforce(ctx context.Context, logger *zap.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.SyncFirewall(ctx); err != nil {
+			logger.Warn("failed to sync firewall", logging.FieldError(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func dhcpHost(a Allocation) kvm.DHCPHost {
+	return kvm.DHCPHost{MAC: a.MAC, IP: a.IP, Name: a.VMName}
+}
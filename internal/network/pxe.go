@@ -0,0 +1,243 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/riccardotacconi/deusvm/internal/config"
+	"github.com/riccardotacconi/deusvm/internal/kvm"
+	"github.com/riccardotacconi/deusvm/internal/logging"
+	"go.uber.org/zap"
+)
+
+// bootRefreshInterval is how often the boot server picks up networks with
+// PXE that were started or stopped.
+const bootRefreshInterval = 10 * time.Second
+
+// BootServer serves network boots on the gateways of the networks created
+// with PXE. Over TFTP it serves the files under its root, such as iPXE
+// itself, and the script that chains iPXE to the boot script of the VM
+// over HTTP. Over HTTP it serves that script, each VM's own script keyed by
+// the MAC address it boots from, and the files under its root.
+type BootServer struct {
+	networks *Service
+	manager  kvm.Manager
+	root     http.Dir
+	tftpPort string
+	httpPort string
+	handler  http.Handler
+
+	mu        sync.Mutex
+	listeners map[string]*bootListener // by gateway address
+	failed    map[string]string        // last bind error per address, logged once
+}
+
+// NewBootServer checks cfg and prepares a server rendering scripts from the
+// templates of networks. It does not listen until Serve.
+func NewBootServer(cfg config.PXEConfig, manager kvm.Manager, networks *Service) (*BootServer, error) {
+	for _, p := range []int{cfg.TFTPPort, cfg.HTTPPort} {
+		if p < 1 || p > 65535 {
+			return nil, fmt.Errorf("invalid pxe port %d", p)
+		}
+	}
+	if cfg.Root == "" {
+		return nil, errors.New("pxe root is required")
+	}
+	b := &BootServer{
+		networks:  networks,
+		manager:   manager,
+		root:      http.Dir(cfg.Root),
+		tftpPort:  strconv.Itoa(cfg.TFTPPort),
+		httpPort:  strconv.Itoa(cfg.HTTPPort),
+		listeners: make(map[string]*bootListener),
+		failed:    make(map[string]string),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /"+kvm.BootScript, func(w http.ResponseWriter, r *http.Request) {
+		writeScript(w, b.chainScript(r.Host))
+	})
+	mux.HandleFunc("GET /ipxe", b.serveScript)
+	mux.Handle("GET /files/", http.StripPrefix("/files", http.FileServer(b.root)))
+	b.handler = mux
+	return b, nil
+}
+
+// Serve keeps the listeners in line with the networks until ctx is done,
+// then closes them.
+func (b *BootServer) Serve(ctx context.Context, logger *zap.Logger) {
+	ticker := time.NewTicker(bootRefreshInterval)
+	defer ticker.Stop()
+	for {
+		nets, err := b.manager.ListNetworks(ctx)
+		if err != nil {
+			logger.Warn("failed to list networks for the boot server", logging.FieldError(err))
+		} else {
+			b.bind(logger, nets)
+		}
+		select {
+		case <-ctx.Done():
+			b.bind(logger, nil)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// bind listens on the IPv4 gateways of the active networks among nets that
+// were created with PXE, closing the listeners of gateways no longer
+// wanted. Gateways that cannot be bound are retried on the next call.
+func (b *BootServer) bind(logger *zap.Logger, nets []kvm.Network) {
+	var want []string
+	for _, n := range nets {
+		if gw := gateway(n, n.IPv4); n.PXE && gw != "" {
+			want = append(want, gw)
+		}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for gw, l := range b.listeners {
+		if !slices.Contains(want, gw) {
+			l.close()
+			delete(b.listeners, gw)
+			logger.Info("stopped boot server", logging.Field("addr", gw))
+		}
+	}
+	for _, gw := range want {
+		if _, ok := b.listeners[gw]; ok {
+			continue
+		}
+		l, err := b.listen(gw)
+		if err != nil {
+			if b.failed[gw] != err.Error() {
+				logger.Warn("failed to start boot server", logging.Field("addr", gw), logging.FieldError(err))
+				b.failed[gw] = err.Error()
+			}
+			continue
+		}
+		delete(b.failed, gw)
+		b.listeners[gw] = l
+		go b.serveTFTP(gw, l.tftp)
+		go func() { _ = l.http.Serve(l.ln) }()
+		logger.Info("starting boot server", logging.Field("addr", gw),
+			logging.Field("tftp_port", b.tftpPort), logging.Field("http_port", b.httpPort))
+	}
+}
+
+// bootListener is the pair of servers on one gateway.
+type bootListener struct {
+	tftp net.PacketConn
+	ln   net.Listener
+	http *http.Server
+}
+
+func (b *BootServer) listen(gw string) (*bootListener, error) {
+	tftp, err := net.ListenPacket("udp4", net.JoinHostPort(gw, b.tftpPort))
+	if err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("tcp4", net.JoinHostPort(gw, b.httpPort))
+	if err != nil {
+		_ = tftp.Close()
+		return nil, err
+	}
+	srv := &http.Server{Handler: b.handler, ReadHeaderTimeout: 10 * time.Second}
+	return &bootListener{tftp: tftp, ln: ln, http: srv}, nil
+}
+
+func (l *bootListener) close() {
+	_ = l.tftp.Close()
+	_ = l.http.Close()
+}
+
+// chainScript is the iPXE script every VM gets first, which fetches the
+// VM's own script from the boot server at host and falls through to the
+// next boot device when there is none.
+func (b *BootServer) chainScript(host string) []byte {
+	return []byte("#!ipxe\nchain http://" + host + "/ipxe?mac=${mac:hexhyp} || exit\n")
+}
+
+// serveScript renders the script of the VM with the NIC in the mac query
+// parameter.
+func (b *BootServer) serveScript(w http.ResponseWriter, r *http.Request) {
+	mac := r.URL.Query().Get("mac")
+	if _, err := net.ParseMAC(strings.ReplaceAll(mac, "-", ":")); err != nil {
+		http.Error(w, fmt.Sprintf("invalid mac address %q", mac), http.StatusBadRequest)
+		return
+	}
+	script, err := b.networks.BootScript(r.Context(), mac, "http://"+r.Host)
+	switch {
+	case errors.Is(err, kvm.ErrNICNotFound), errors.Is(err, ErrTemplateNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		writeScript(w, script)
+	}
+}
+
+func writeScript(w http.ResponseWriter, script []byte) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write(script)
+}
+
+// serveTFTP answers the read requests sent to the gateway gw.
+func (b *BootServer) serveTFTP(gw string, conn net.PacketConn) {
+	buf := make([]byte, 2048)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		req, err := parseTFTPRequest(buf[:n])
+		if err != nil {
+			_, _ = conn.WriteTo(tftpErrorPacket(tftpErrIllegal, err.Error()), from)
+			continue
+		}
+		if req.op == tftpWRQ {
+			_, _ = conn.WriteTo(tftpErrorPacket(tftpErrAccess, "read only"), from)
+			continue
+		}
+		go func() {
+			r, size, err := b.open(gw, req.filename)
+			if err != nil {
+				_, _ = conn.WriteTo(tftpErrorPacket(tftpErrNotFound, "file not found"), from)
+				return
+			}
+			defer r.Close()
+			_ = sendTFTP(gw, from, req, r, size)
+		}()
+	}
+}
+
+// open opens a file served over TFTP on the gateway gw: the chain script
+// or a file under the root.
+func (b *BootServer) open(gw, name string) (io.ReadCloser, int64, error) {
+	name = strings.TrimPrefix(name, "/")
+	if name == kvm.BootScript {
+		script := b.chainScript(net.JoinHostPort(gw, b.httpPort))
+		return io.NopCloser(bytes.NewReader(script)), int64(len(script)), nil
+	}
+	f, err := b.root.Open("/" + name)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		_ = f.Close()
+		return nil, 0, fmt.Errorf("%s is not a file", name)
+	}
+	return f, info.Size(), nil
+}
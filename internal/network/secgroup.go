@@ -0,0 +1,175 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+
+	"github.com/riccardotacconi/deusvm/internal/state"
+)
+
+// Rule directions, as seen from the VM.
+const (
+	Ingress = "ingress"
+	Egress  = "egress"
+)
+
+var (
+	ErrGroupNotFound = errors.New("security group not found")
+	ErrGroupExists   = errors.New("security group already exists")
+	ErrGroupInUse    = errors.New("security group is in use")
+)
+
+// groupName limits names to what nftables identifiers and URLs take as is.
+var groupName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,62}$`)
+
+// Rule allows traffic in one direction. Protocol is tcp, udp, icmp, icmpv6
+// or any; tcp and udp rules may be limited to the ports PortFrom to PortTo
+// (just PortFrom when PortTo is zero) on the VM for ingress and on the peer
+// for egress. The peer is any address in CIDRs or of a NIC in one of Groups;
+// a rule with neither matches every address.
+type Rule struct {
+	Direction string   `json:"direction"`
+	Protocol  string   `json:"protocol"`
+	PortFrom  int      `json:"port_from,omitempty"`
+	PortTo    int      `json:"port_to,omitempty"`
+	CIDRs     []string `json:"cidrs,omitempty"`
+	Groups    []string `json:"groups,omitempty"`
+}
+
+// SecurityGroup is a named set of rules. A NIC in one or more groups only
+// passes the traffic some rule allows, plus replies, ARP, neighbour
+// discovery and DHCP. Without egress rules in any of its groups a NIC may
+// send anything from its own addresses.
+type SecurityGroup struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Rules       []Rule `json:"rules"`
+}
+
+// validateGroup checks g and normalizes its CIDRs; groups lists the names
+// rules may refer to besides g itself.
+func validateGroup(g SecurityGroup, groups map[string]SecurityGroup) (SecurityGroup, error) {
+	if !groupName.MatchString(g.Name) {
+		return SecurityGroup{}, fmt.Errorf("invalid security group name %q", g.Name)
+	}
+	rules := make([]Rule, 0, len(g.Rules))
+	for i, r := range g.Rules {
+		if r.Protocol == "" {
+			r.Protocol = "any"
+		}
+		switch r.Direction {
+		case Ingress, Egress:
+		default:
+			return SecurityGroup{}, fmt.Errorf("rule %d: invalid direction %q (want ingress or egress)", i, r.Direction)
+		}
+		switch r.Protocol {
+		case "tcp", "udp":
+			if r.PortTo == 0 {
+				r.PortTo = r.PortFrom
+			}
+			if r.PortFrom < 0 || r.PortTo > 65535 || r.PortTo < r.PortFrom || (r.PortFrom == 0 && r.PortTo != 0) {
+				return SecurityGroup{}, fmt.Errorf("rule %d: invalid port range %d-%d", i, r.PortFrom, r.PortTo)
+			}
+		case "icmp", "icmpv6", "any":
+			if r.PortFrom != 0 || r.PortTo != 0 {
+				return SecurityGroup{}, fmt.Errorf("rule %d: %s has no ports", i, r.Protocol)
+			}
+		default:
+			return SecurityGroup{}, fmt.Errorf("rule %d: invalid protocol %q (want tcp, udp, icmp, icmpv6 or any)", i, r.Protocol)
+		}
+		cidrs := make([]string, 0, len(r.CIDRs))
+		for _, c := range r.CIDRs {
+			prefix, err := netip.ParsePrefix(c)
+			if err != nil {
+				addr, aerr := netip.ParseAddr(c)
+				if aerr != nil {
+					return SecurityGroup{}, fmt.Errorf("rule %d: invalid cidr %q", i, c)
+				}
+				prefix = netip.PrefixFrom(addr, addr.BitLen())
+			}
+			cidrs = append(cidrs, prefix.Masked().String())
+		}
+		r.CIDRs = cidrs
+		for _, ref := range r.Groups {
+			if _, ok := groups[ref]; !ok && ref != g.Name {
+				return SecurityGroup{}, fmt.Errorf("rule %d: %s: %w", i, ref, ErrGroupNotFound)
+			}
+		}
+		rules = append(rules, r)
+	}
+	g.Rules = rules
+	return g, nil
+}
+
+// binding puts a NIC, identified by its VM and MAC address, in groups.
+type binding struct {
+	VM     string   `json:"vm"`
+	MAC    string   `json:"mac"`
+	Groups []string `json:"groups"`
+}
+
+// groupRegistry persists security groups and the NICs in them.
+type groupRegistry struct {
+	mu       sync.Mutex
+	path     string
+	Groups   map[string]SecurityGroup `json:"groups"`
+	Bindings []binding                `json:"bindings"`
+}
+
+func loadGroups(dir string) (*groupRegistry, error) {
+	r := &groupRegistry{path: filepath.Join(dir, "security_groups.json")}
+	if err := state.Load(r.path, r); err != nil {
+		return nil, err
+	}
+	if r.Groups == nil {
+		r.Groups = make(map[string]SecurityGroup)
+	}
+	return r, nil
+}
+
+// update applies fn to copies of the groups and bindings and saves the
+// result, keeping the old state if fn or saving fails. The caller holds mu.
+func (r *groupRegistry) update(fn func(groups map[string]SecurityGroup, bindings []binding) ([]binding, error)) error {
+	groups, bindings := make(map[string]SecurityGroup, len(r.Groups)), slices.Clone(r.Bindings)
+	for k, v := range r.Groups {
+		groups[k] = v
+	}
+	bindings, err := fn(groups, bindings)
+	if err != nil {
+		return err
+	}
+	prevGroups, prevBindings := r.Groups, r.Bindings
+	r.Groups, r.Bindings = groups, bindings
+	if err := state.Save(r.path, r); err != nil {
+		r.Groups, r.Bindings = prevGroups, prevBindings
+		return err
+	}
+	return nil
+}
+
+// users lists the VMs with a NIC in group, and the groups whose rules refer
+// to it.
+func (r *groupRegistry) users(group string) (vms, groups []string) {
+	for _, b := range r.Bindings {
+		if slices.Contains(b.Groups, group) && !slices.Contains(vms, b.VM) {
+			vms = append(vms, b.VM)
+		}
+	}
+	for _, g := range r.Groups {
+		if g.Name == group {
+			continue
+		}
+		for _, rule := range g.Rules {
+			if slices.Contains(rule.Groups, group) {
+				groups = append(groups, g.Name)
+				break
+			}
+		}
+	}
+	return vms, groups
+}
@@ -0,0 +1,173 @@
+package network
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TFTP opcodes (RFC 1350 and 2347).
+const (
+	tftpRRQ   = 1
+	tftpWRQ   = 2
+	tftpDATA  = 3
+	tftpACK   = 4
+	tftpERROR = 5
+	tftpOACK  = 6
+)
+
+// TFTP error codes.
+const (
+	tftpErrUndefined    = 0
+	tftpErrNotFound     = 1
+	tftpErrAccess       = 2
+	tftpErrIllegal      = 4
+	tftpErrUnknownID    = 5
+	tftpErrOptionDenied = 8
+)
+
+const (
+	tftpBlockSize    = 512
+	tftpMaxBlockSize = 65464
+	tftpTimeout      = 2 * time.Second
+	tftpRetries      = 5
+)
+
+// tftpRequest is a read or write request with the options it asks for.
+type tftpRequest struct {
+	op       uint16
+	filename string
+	mode     string
+	options  map[string]string
+}
+
+// parseTFTPRequest reads a read or write request packet.
+func parseTFTPRequest(p []byte) (tftpRequest, error) {
+	if len(p) < 2 {
+		return tftpRequest{}, errors.New("short packet")
+	}
+	req := tftpRequest{op: binary.BigEndian.Uint16(p), options: make(map[string]string)}
+	if req.op != tftpRRQ && req.op != tftpWRQ {
+		return tftpRequest{}, fmt.Errorf("unexpected opcode %d", req.op)
+	}
+	fields := strings.Split(string(p[2:]), "\x00")
+	// the packet ends with a NUL, which leaves an empty last field
+	if len(fields) < 3 || fields[len(fields)-1] != "" {
+		return tftpRequest{}, errors.New("malformed request")
+	}
+	fields = fields[:len(fields)-1]
+	req.filename, req.mode = fields[0], strings.ToLower(fields[1])
+	for i := 2; i+1 < len(fields); i += 2 {
+		req.options[strings.ToLower(fields[i])] = fields[i+1]
+	}
+	return req, nil
+}
+
+func tftpErrorPacket(code uint16, msg string) []byte {
+	p := binary.BigEndian.AppendUint16(nil, tftpERROR)
+	p = binary.BigEndian.AppendUint16(p, code)
+	return append(append(p, msg...), 0)
+}
+
+// tftpTransfer sends one file to a client from a socket of its own, as
+// TFTP wants, retransmitting unacknowledged packets.
+type tftpTransfer struct {
+	conn net.PacketConn
+	peer net.Addr
+	buf  []byte
+}
+
+// sendTFTP sends size bytes from r to peer from a new socket on localIP,
+// honouring the blksize and tsize options of req.
+func sendTFTP(localIP string, peer net.Addr, req tftpRequest, r io.Reader, size int64) error {
+	conn, err := net.ListenPacket("udp", net.JoinHostPort(localIP, "0"))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	t := &tftpTransfer{conn: conn, peer: peer, buf: make([]byte, 2048)}
+
+	blockSize := tftpBlockSize
+	var oack []byte
+	if v, ok := req.options["blksize"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 8 {
+			_, _ = conn.WriteTo(tftpErrorPacket(tftpErrOptionDenied, "invalid blksize"), peer)
+			return fmt.Errorf("invalid blksize %q", v)
+		}
+		blockSize = min(n, tftpMaxBlockSize)
+		oack = append(append(append(oack, "blksize\x00"...), strconv.Itoa(blockSize)...), 0)
+	}
+	if _, ok := req.options["tsize"]; ok {
+		oack = append(append(append(oack, "tsize\x00"...), strconv.FormatInt(size, 10)...), 0)
+	}
+	if oack != nil {
+		if err := t.send(append(binary.BigEndian.AppendUint16(nil, tftpOACK), oack...), 0); err != nil {
+			return err
+		}
+	}
+
+	data := make([]byte, blockSize)
+	for block := uint16(1); ; block++ {
+		n, err := io.ReadFull(r, data)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			_, _ = conn.WriteTo(tftpErrorPacket(tftpErrUndefined, "read failed"), peer)
+			return err
+		}
+		p := binary.BigEndian.AppendUint16(nil, tftpDATA)
+		p = binary.BigEndian.AppendUint16(p, block)
+		if err := t.send(append(p, data[:n]...), block); err != nil {
+			return err
+		}
+		// a short block, possibly empty, ends the transfer
+		if n < blockSize {
+			return nil
+		}
+	}
+}
+
+// send writes p and waits for the acknowledgement of block, sending p again
+// when none comes in time.
+func (t *tftpTransfer) send(p []byte, block uint16) error {
+	for try := 0; try < tftpRetries; try++ {
+		if _, err := t.conn.WriteTo(p, t.peer); err != nil {
+			return err
+		}
+		deadline := time.Now().Add(tftpTimeout)
+		for {
+			_ = t.conn.SetReadDeadline(deadline)
+			n, from, err := t.conn.ReadFrom(t.buf)
+			if err != nil {
+				var ne net.Error
+				if errors.As(err, &ne) && ne.Timeout() {
+					break
+				}
+				return err
+			}
+			if from.String() != t.peer.String() {
+				_, _ = t.conn.WriteTo(tftpErrorPacket(tftpErrUnknownID, "unknown transfer id"), from)
+				continue
+			}
+			if n < 4 {
+				continue
+			}
+			switch binary.BigEndian.Uint16(t.buf) {
+			case tftpACK:
+				// acknowledgements of earlier blocks are duplicates and
+				// resending on them would double the traffic
+				if binary.BigEndian.Uint16(t.buf[2:]) == block {
+					return nil
+				}
+			case tftpERROR:
+				return fmt.Errorf("client error: %s", bytes.TrimRight(t.buf[4:n], "\x00"))
+			}
+		}
+	}
+	return errors.New("transfer timed out")
+}
@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Image content is stored once per sha256 under .blobs/sha256/<digest>. Each
+// image name in the images directory is a hardlink to its blob, so paths of
+// existing images keep working and identical content is only stored once.
+const blobsDir = ".blobs/sha256"
+
+// ErrImageExists is returned when a new image name is already taken.
+var ErrImageExists = errors.New("image already exists")
+
+func (m *LocalManager) blobPath(digest string) string {
+	return filepath.Join(m.imagesDir, blobsDir, digest)
+}
+
+// blobLock serializes the placing, linking and pruning of one blob.
+type blobLock struct {
+	mu    sync.Mutex
+	users int
+}
+
+// lockBlob holds the lock of digest until the returned func is called, so a
+// blob found in place is not pruned before the name being placed links to it.
+func (m *LocalManager) lockBlob(digest string) func() {
+	m.mu.Lock()
+	l, ok := m.blobLocks[digest]
+	if !ok {
+		l = &blobLock{}
+		m.blobLocks[digest] = l
+	}
+	l.users++
+	m.mu.Unlock()
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		m.mu.Lock()
+		if l.users--; l.users == 0 {
+			delete(m.blobLocks, digest)
+		}
+		m.mu.Unlock()
+	}
+}
+
+// placeImage moves staged content at tmp into the blob store, unless a blob
+// with the same digest exists already, and points name at it.
+func (m *LocalManager) placeImage(tmp, name string, meta catalogEntry) error {
+	release := m.lockBlob(meta.SHA256)
+	blob := m.blobPath(meta.SHA256)
+	if _, err := os.Stat(blob); err == nil {
+		if err := os.Remove(tmp); err != nil {
+			release()
+			return fmt.Errorf("remove duplicate: %w", err)
+		}
+	} else if err := os.Rename(tmp, blob); err != nil {
+		release()
+		return fmt.Errorf("store blob: %w", err)
+	}
+	prev, err := m.linkBlob(name, meta)
+	release()
+	if err != nil {
+		return err
+	}
+	m.pruneBlob(prev)
+	return nil
+}
+
+// linkName (re)points name at the blob for meta.SHA256 and records it in the
+// catalog. A blob the name pointed at before is removed if nothing else uses it.
+func (m *LocalManager) linkName(name string, meta catalogEntry) error {
+	release := m.lockBlob(meta.SHA256)
+	prev, err := m.linkBlob(name, meta)
+	release()
+	if err != nil {
+		return err
+	}
+	m.pruneBlob(prev)
+	return nil
+}
+
+// linkBlob does the work of linkName with the blob lock held and returns the
+// digest the name pointed at before when that differs. The caller prunes it
+// after releasing the lock, as taking a second blob lock here could deadlock.
+func (m *LocalManager) linkBlob(name string, meta catalogEntry) (string, error) {
+	path, err := m.imagePath(name)
+	if err != nil {
+		return "", err
+	}
+	// link under a temporary name first so replacing an image is atomic; rename
+	// does nothing when both names already are the same file, so skip that case
+	if blob := m.blobPath(meta.SHA256); !sameFile(blob, path) {
+		tmp := path + linkSuffix
+		_ = os.Remove(tmp)
+		if err := os.Link(blob, tmp); err != nil {
+			return "", fmt.Errorf("link image: %w", err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			_ = os.Remove(tmp)
+			return "", fmt.Errorf("link image: %w", err)
+		}
+	}
+	prev, err := m.catalog.setImage(name, meta)
+	if err != nil || prev == meta.SHA256 {
+		return "", err
+	}
+	return prev, nil
+}
+
+// pruneBlob removes a blob once no image name refers to it. A blob that
+// qcow2 overlays still back onto, such as after a forced delete, is left for
+// garbage collection once they are gone.
+func (m *LocalManager) pruneBlob(digest string) {
+	if digest == "" {
+		return
+	}
+	defer m.lockBlob(digest)()
+	if m.catalog.digestUsers(digest) > 0 || len(m.overlaysOf(m.blobPath(digest))) > 0 {
+		return
+	}
+	_ = os.Remove(m.blobPath(digest))
+}
+
+// TagImage gives the content of the image source an additional name.
+func (m *LocalManager) TagImage(ctx context.Context, source, name string) (Image, error) {
+	if _, err := m.imagePath(source); err != nil {
+		return Image{}, err
+	}
+	path, err := m.imagePath(name)
+	if err != nil {
+		return Image{}, err
+	}
+	meta, ok := m.catalog.lookup(source)
+	if !ok || meta.SHA256 == "" {
+		return Image{}, fmt.Errorf("image %s: %w", source, os.ErrNotExist)
+	}
+	release, err := m.lockName(name)
+	if err != nil {
+		return Image{}, err
+	}
+	defer release()
+	if _, err := os.Lstat(path); err == nil {
+		return Image{}, fmt.Errorf("%s: %w", name, ErrImageExists)
+	}
+	meta.Refs = nil
+	if err := m.linkName(name, meta); err != nil {
+		return Image{}, err
+	}
+	return m.image(name)
+}
+
+// image describes a stored image from its file and catalog entry.
+func (m *LocalManager) image(name string) (Image, error) {
+	path, err := m.imagePath(name)
+	if err != nil {
+		return Image{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return Image{}, err
+	}
+	meta, _ := m.catalog.lookup(name)
+	return Image{
+		Name:         name,
+		Path:         path,
+		Size:         info.Size(),
+		Format:       detectFormat(path, name),
+		SHA256:       meta.SHA256,
+		Compression:  meta.Compression,
+		SourceSHA256: meta.SourceSHA256,
+		Lineage:      meta.Lineage,
+		UsedBy:       len(m.dependents(name)),
+	}, nil
+}
+
+// adoptUntracked moves image files that are not in the blob store yet, such
+// as images from before content addressing, into it. Files with identical
+// content end up sharing one blob.
+func (m *LocalManager) adoptUntracked() error {
+	if err := os.MkdirAll(filepath.Join(m.imagesDir, blobsDir), 0o755); err != nil {
+		return fmt.Errorf("mkdir blobs: %w", err)
+	}
+	entries, err := os.ReadDir(m.imagesDir)
+	if err != nil {
+		return fmt.Errorf("readdir: %w", err)
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() || strings.HasPrefix(name, ".") || isStaging(name) {
+			continue
+		}
+		path := filepath.Join(m.imagesDir, name)
+		if meta, ok := m.catalog.lookup(name); ok && meta.SHA256 != "" && sameFile(path, m.blobPath(meta.SHA256)) {
+			continue
+		}
+		digest, err := fileSHA256(path)
+		if err != nil {
+			return fmt.Errorf("hash %s: %w", name, err)
+		}
+		meta, _ := m.catalog.lookup(name)
+		meta.SHA256 = digest
+		if err := m.adoptFile(path, name, meta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// adoptFile makes the file at path the blob for meta.SHA256 unless one is
+// stored already, and points name at the blob.
+func (m *LocalManager) adoptFile(path, name string, meta catalogEntry) error {
+	release := m.lockBlob(meta.SHA256)
+	blob := m.blobPath(meta.SHA256)
+	if _, err := os.Stat(blob); err != nil {
+		if err := os.Link(path, blob); err != nil {
+			release()
+			return fmt.Errorf("store blob: %w", err)
+		}
+	}
+	prev, err := m.linkBlob(name, meta)
+	release()
+	if err != nil {
+		return err
+	}
+	m.pruneBlob(prev)
+	return nil
+}
+
+func sameFile(a, b string) bool {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(ai, bi)
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Lineage records where a captured image came from.
+type Lineage struct {
+	SourceVM string `json:"source_vm"`
+	// SourceDisk is the volume name, or the disk path for disks that are not volumes.
+	SourceDisk string `json:"source_disk"`
+	// Parent is the image the captured disk was cloned from, if any.
+	Parent     string    `json:"parent,omitempty"`
+	CapturedAt time.Time `json:"captured_at"`
+}
+
+// CaptureSpec describes a VM disk to store as an image.
+type CaptureSpec struct {
+	Name   string
+	Path   string
+	Format string
+	// Shared reads a disk that a running VM holds open. The caller is
+	// responsible for quiescing the guest first.
+	Shared   bool
+	Sparsify bool
+	Lineage  Lineage
+}
+
+// CaptureImage converts the disk into a standalone qcow2 image, which flattens
+// any backing chain, and optionally sparsifies it with virt-sparsify so blocks
+// freed inside the guest filesystems take no space.
+func (m *LocalManager) CaptureImage(ctx context.Context, spec CaptureSpec) (Image, error) {
+	path, err := m.imagePath(spec.Name)
+	if err != nil {
+		return Image{}, err
+	}
+	release, err := m.lockName(spec.Name)
+	if err != nil {
+		return Image{}, err
+	}
+	defer release()
+	if _, err := os.Lstat(path); err == nil {
+		return Image{}, fmt.Errorf("%s: %w", spec.Name, ErrImageExists)
+	}
+	// the copy takes at most what the disk holds; block devices report no
+	// blocks, so they are only checked against current usage
+	written, _ := allocatedBytes(spec.Path)
+	if err := m.checkImageStore(written); err != nil {
+		return Image{}, err
+	}
+	tmp := path + partSuffix
+	args := []string{"convert", "-f", qemuFormat(spec.Format), "-O", "qcow2"}
+	if spec.Shared {
+		args = append(args, "-U")
+	}
+	if _, err := m.run.Run(ctx, "qemu-img", append(args, spec.Path, tmp)...); err != nil {
+		_ = os.Remove(tmp)
+		return Image{}, fmt.Errorf("convert: %w", err)
+	}
+	if spec.Sparsify {
+		if _, err := m.run.Run(ctx, "virt-sparsify", "--in-place", "--format", "qcow2", tmp); err != nil {
+			_ = os.Remove(tmp)
+			return Image{}, fmt.Errorf("sparsify: %w", err)
+		}
+	}
+	digest, err := fileSHA256(tmp)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return Image{}, fmt.Errorf("hash: %w", err)
+	}
+	lineage := spec.Lineage
+	lineage.CapturedAt = time.Now().UTC()
+	if err := m.placeImage(tmp, spec.Name, catalogEntry{SHA256: digest, Lineage: &lineage}); err != nil {
+		_ = os.Remove(tmp)
+		return Image{}, err
+	}
+	return m.image(spec.Name)
+}
@@ -0,0 +1,259 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/riccardotacconi/deusvm/internal/state"
+)
+
+const catalogFile = ".catalog.json"
+
+// Ref identifies something that depends on an image.
+type Ref struct {
+	Kind string `json:"kind"` // vm|volume|cdrom
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+func (r Ref) String() string {
+	if r.Name != "" && r.Name != r.ID {
+		return fmt.Sprintf("%s %s (%s)", r.Kind, r.Name, r.ID)
+	}
+	return r.Kind + " " + r.ID
+}
+
+// ErrImageInUse is matched by errors.Is for an ImageInUseError.
+var ErrImageInUse = errors.New("image in use")
+
+// ImageInUseError is returned when deleting an image others depend on.
+type ImageInUseError struct {
+	Name   string
+	UsedBy []Ref
+}
+
+func (e *ImageInUseError) Error() string {
+	deps := make([]string, len(e.UsedBy))
+	for i, r := range e.UsedBy {
+		deps[i] = r.String()
+	}
+	return fmt.Sprintf("image %s is used by %s", e.Name, strings.Join(deps, ", "))
+}
+
+func (e *ImageInUseError) Is(target error) bool { return target == ErrImageInUse }
+
+type catalogEntry struct {
+	SHA256       string   `json:"sha256,omitempty"`
+	Compression  string   `json:"compression,omitempty"`
+	SourceSHA256 string   `json:"source_sha256,omitempty"`
+	Lineage      *Lineage `json:"lineage,omitempty"`
+	Refs         []Ref    `json:"refs,omitempty"`
+}
+
+// catalog is the image metadata kept in the images directory. pins, by
+// image name, are the volumes being cloned from an image, which keep it
+// from being deleted until the clone is done.
+type catalog struct {
+	mu     sync.Mutex
+	path   string
+	Images map[string]*catalogEntry `json:"images"`
+	pins   map[string][]string
+}
+
+func loadCatalog(dir string) (*catalog, error) {
+	c := &catalog{path: filepath.Join(dir, catalogFile), pins: make(map[string][]string)}
+	if err := state.Load(c.path, c); err != nil {
+		return nil, err
+	}
+	if c.Images == nil {
+		c.Images = make(map[string]*catalogEntry)
+	}
+	return c, nil
+}
+
+// save writes the catalog atomically. Callers hold c.mu.
+func (c *catalog) save() error { return state.Save(c.path, c) }
+
+func (c *catalog) entry(name string) *catalogEntry {
+	e, ok := c.Images[name]
+	if !ok {
+		e = &catalogEntry{}
+		c.Images[name] = e
+	}
+	return e
+}
+
+func (c *catalog) lookup(name string) (catalogEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.Images[name]
+	if !ok {
+		return catalogEntry{}, false
+	}
+	out := *e
+	out.Refs = slices.Clone(e.Refs)
+	return out, true
+}
+
+// setImage records the content name points at, keeping its references, and
+// returns the digest it pointed at before.
+func (c *catalog) setImage(name string, meta catalogEntry) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.entry(name)
+	prev := e.SHA256
+	e.SHA256, e.Compression, e.SourceSHA256, e.Lineage = meta.SHA256, meta.Compression, meta.SourceSHA256, meta.Lineage
+	return prev, c.save()
+}
+
+// digestUsers counts the image names pointing at a blob.
+func (c *catalog) digestUsers(digest string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.usersLocked(digest)
+}
+
+func (c *catalog) usersLocked(digest string) int {
+	n := 0
+	for _, e := range c.Images {
+		if e.SHA256 == digest {
+			n++
+		}
+	}
+	return n
+}
+
+// addRef records ref on the image name stored at path, unless the image was
+// deleted meanwhile.
+func (c *catalog) addRef(name, path string, ref Ref) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	e := c.entry(name)
+	for _, r := range e.Refs {
+		if r.Kind == ref.Kind && r.ID == ref.ID {
+			return nil
+		}
+	}
+	e.Refs = append(e.Refs, ref)
+	return c.save()
+}
+
+// releaseRef drops ref, matched by kind and id, from every image.
+func (c *catalog) releaseRef(ref Ref) error {
+	return c.releaseWhere(func(r Ref) bool { return r.Kind == ref.Kind && r.ID == ref.ID })
+}
+
+// releaseWhere drops the references fn matches from every image.
+func (c *catalog) releaseWhere(fn func(Ref) bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	changed := false
+	for _, e := range c.Images {
+		n := len(e.Refs)
+		e.Refs = slices.DeleteFunc(e.Refs, fn)
+		changed = changed || len(e.Refs) != n
+	}
+	if !changed {
+		return nil
+	}
+	return c.save()
+}
+
+// refsWhere lists, by image name, the references fn matches.
+func (c *catalog) refsWhere(fn func(Ref) bool) map[string][]Ref {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string][]Ref)
+	for name, e := range c.Images {
+		for _, r := range e.Refs {
+			if fn(r) {
+				out[name] = append(out[name], r)
+			}
+		}
+	}
+	return out
+}
+
+// dropRef removes ref, matched by kind and id, from one image.
+func (c *catalog) dropRef(name string, ref Ref) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.Images[name]
+	if !ok {
+		return nil
+	}
+	n := len(e.Refs)
+	e.Refs = slices.DeleteFunc(e.Refs, func(r Ref) bool { return r.Kind == ref.Kind && r.ID == ref.ID })
+	if len(e.Refs) == n {
+		return nil
+	}
+	return c.save()
+}
+
+// pin keeps the image name stored at path from being deleted while volume
+// is cloned from it, failing if it is already gone. The returned func
+// unpins it.
+func (c *catalog) pin(name, path, volume string) (func(), error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("image %s: %w", name, os.ErrNotExist)
+	}
+	c.pins[name] = append(c.pins[name], volume)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if i := slices.Index(c.pins[name], volume); i >= 0 {
+				c.pins[name] = slices.Delete(c.pins[name], i, i+1)
+			}
+			if len(c.pins[name]) == 0 {
+				delete(c.pins, name)
+			}
+		})
+	}, nil
+}
+
+// remove deletes the image name with unlink and forgets it, returning the
+// digest it pointed at. check is given the references of the image and
+// whether other names share its content, and stops the removal by failing; a
+// pinned image is never removed. Holding the lock throughout keeps
+// references and pins from being added in between.
+func (c *catalog) remove(name string, check func(refs []Ref, shared bool) error, unlink func() error) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if vols := c.pins[name]; len(vols) > 0 {
+		err := &ImageInUseError{Name: name}
+		for _, vol := range vols {
+			err.UsedBy = append(err.UsedBy, Ref{Kind: "volume", ID: vol})
+		}
+		return "", err
+	}
+	var refs []Ref
+	shared := false
+	if e, ok := c.Images[name]; ok {
+		refs = slices.Clone(e.Refs)
+		shared = e.SHA256 != "" && c.usersLocked(e.SHA256) > 1
+	}
+	if err := check(refs, shared); err != nil {
+		return "", err
+	}
+	if err := unlink(); err != nil {
+		return "", err
+	}
+	e, ok := c.Images[name]
+	if !ok {
+		return "", nil
+	}
+	delete(c.Images, name)
+	return e.SHA256, c.save()
+}
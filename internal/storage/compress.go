@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+const (
+	compressionGzip  = "gzip"
+	compressionXZ    = "xz"
+	compressionZstd  = "zstd"
+	compressionBzip2 = "bzip2"
+)
+
+// sniffLen is enough bytes to recognise every supported compression magic.
+const sniffLen = 6
+
+var compressionMagic = []struct {
+	kind  string
+	magic []byte
+}{
+	{compressionXZ, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}},
+	{compressionZstd, []byte{0x28, 0xb5, 0x2f, 0xfd}},
+	{compressionGzip, []byte{0x1f, 0x8b}},
+	{compressionBzip2, []byte{'B', 'Z', 'h'}},
+}
+
+// compressionExt maps file name suffixes of compressed images to their kind.
+var compressionExt = map[string]string{
+	".gz":  compressionGzip,
+	".xz":  compressionXZ,
+	".zst": compressionZstd,
+	".bz2": compressionBzip2,
+}
+
+// sniffCompression identifies a compressed stream from its first bytes, or
+// returns "" for content that is not compressed.
+func sniffCompression(head []byte) string {
+	for _, c := range compressionMagic {
+		if bytes.HasPrefix(head, c.magic) {
+			return c.kind
+		}
+	}
+	return ""
+}
+
+// trimCompressionExt strips a compression suffix, so "debian-13.qcow2.xz" is
+// stored as "debian-13.qcow2" once its content was decompressed.
+func trimCompressionExt(name string) string {
+	low := strings.ToLower(name)
+	for ext := range compressionExt {
+		if strings.HasSuffix(low, ext) && len(name) > len(ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return name
+}
+
+func newDecompressor(kind string, r io.Reader) (io.ReadCloser, error) {
+	switch kind {
+	case compressionGzip:
+		return gzip.NewReader(r)
+	case compressionXZ:
+		xr, err := xz.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(xr), nil
+	case compressionZstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	case compressionBzip2:
+		return io.NopCloser(bzip2.NewReader(r)), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", kind)
+	}
+}
+
+// decoder is written the image bytes as they are transferred. It hashes them,
+// recognises compression from the first bytes and writes the decompressed
+// content to dst. Close must be called to flush the decompressor.
+type decoder struct {
+	dst    io.Writer
+	sniff  bool
+	hasher hash.Hash
+	head   []byte
+	kind   string
+
+	// onStart is called once the compression of the stream is known.
+	onStart func(kind string)
+
+	started bool
+	pw      *io.PipeWriter
+	done    chan error
+}
+
+// newDecoder returns a decoder writing to dst. Without sniff the stream is
+// passed through as is, which is used when resuming a partial transfer.
+func newDecoder(dst io.Writer, sniff bool) *decoder {
+	return &decoder{dst: dst, sniff: sniff, hasher: sha256.New()}
+}
+
+func (d *decoder) Write(p []byte) (int, error) {
+	d.hasher.Write(p)
+	if !d.started {
+		d.head = append(d.head, p...)
+		if len(d.head) < sniffLen {
+			return len(p), nil
+		}
+		return len(p), d.start()
+	}
+	if d.pw != nil {
+		return d.pw.Write(p)
+	}
+	return d.dst.Write(p)
+}
+
+func (d *decoder) start() error {
+	d.started = true
+	head := d.head
+	d.head = nil
+	if d.sniff {
+		d.kind = sniffCompression(head)
+	}
+	if d.onStart != nil {
+		d.onStart(d.kind)
+	}
+	if d.kind == "" {
+		_, err := d.dst.Write(head)
+		return err
+	}
+	pr, pw := io.Pipe()
+	d.pw = pw
+	d.done = make(chan error, 1)
+	go func() {
+		dec, err := newDecompressor(d.kind, pr)
+		if err == nil {
+			_, err = io.Copy(d.dst, dec)
+			dec.Close()
+		}
+		pr.CloseWithError(err)
+		d.done <- err
+	}()
+	_, err := pw.Write(head)
+	return err
+}
+
+// Close flushes the decompressor and reports any decoding error.
+func (d *decoder) Close() error {
+	if !d.started {
+		if err := d.start(); err != nil {
+			return err
+		}
+	}
+	if d.pw == nil {
+		return nil
+	}
+	d.pw.Close()
+	d.pw = nil
+	if err := <-d.done; err != nil {
+		return fmt.Errorf("decompress %s: %w", d.kind, err)
+	}
+	return nil
+}
+
+// abort stops a running decompressor without waiting for more input.
+func (d *decoder) abort() {
+	if d.pw != nil {
+		d.pw.CloseWithError(io.ErrUnexpectedEOF)
+		<-d.done
+		d.pw = nil
+	}
+}
+
+// sum is the checksum of the bytes as transferred, before decompression.
+func (d *decoder) sum() string { return fmt.Sprintf("%x", d.hasher.Sum(nil)) }
+
+// formatHeaderLen covers the ISO 9660 volume descriptor at offset 0x8001.
+const formatHeaderLen = 0x8001 + 5
+
+// detectFormat identifies a disk image from its header, falling back to the
+// file name for formats without a magic number such as raw.
+func detectFormat(path, name string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return detectFormatByName(name)
+	}
+	defer f.Close()
+	head := make([]byte, formatHeaderLen)
+	n, _ := io.ReadFull(f, head)
+	head = head[:n]
+	switch {
+	case bytes.HasPrefix(head, []byte{'Q', 'F', 'I', 0xfb}):
+		return "qcow2"
+	case bytes.HasPrefix(head, []byte("KDMV")):
+		return "vmdk"
+	case bytes.HasPrefix(head, []byte("vhdxfile")):
+		return "vhdx"
+	case n >= formatHeaderLen && string(head[0x8001:]) == "CD001":
+		return "iso"
+	}
+	return detectFormatByName(name)
+}
@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ConvertSpec describes a format conversion of an image or a volume. Exactly
+// one of Image and Volume is set.
+type ConvertSpec struct {
+	Image  string
+	Volume string
+	// Name stores a converted image under a new name; by default the image
+	// is replaced. Volumes are always converted in place.
+	Name string
+	// Format is raw, qcow2 or vmdk (images only). Empty keeps the current
+	// format, which just drops zeroed regions.
+	Format string
+	// Compress writes compressed qcow2 clusters.
+	Compress bool
+}
+
+// Convert checks spec and starts a background job that rewrites the image or
+// volume with qemu-img convert. Regions that read as zeroes are left
+// unallocated in the output, so conversions also sparsify.
+func (m *LocalManager) Convert(ctx context.Context, spec ConvertSpec) (Job, error) {
+	if (spec.Image == "") == (spec.Volume == "") {
+		return Job{}, errors.New("convert needs either an image or a volume")
+	}
+	if spec.Image != "" {
+		return m.convertImage(spec)
+	}
+	return m.convertVolume(ctx, spec)
+}
+
+func (m *LocalManager) convertImage(spec ConvertSpec) (Job, error) {
+	src, ok := m.resolveImageName(spec.Image)
+	if !ok {
+		return Job{}, fmt.Errorf("image %s: %w", spec.Image, os.ErrNotExist)
+	}
+	img, err := m.image(src)
+	if err != nil {
+		return Job{}, err
+	}
+	if img.Format == "iso" {
+		return Job{}, fmt.Errorf("%s is an ISO image and cannot be converted", src)
+	}
+	format := spec.Format
+	if format == "" {
+		format = qemuFormat(img.Format)
+	}
+	if format != "raw" && format != "qcow2" && format != "vmdk" {
+		return Job{}, fmt.Errorf("unsupported image format %q", format)
+	}
+	if spec.Compress && format != "qcow2" {
+		return Job{}, fmt.Errorf("only qcow2 can be compressed, not %s", format)
+	}
+	name := spec.Name
+	if name == "" {
+		name = src
+		// overlays and volumes are backed by the current content
+		if deps := m.dependents(src); len(deps) > 0 {
+			return Job{}, &ImageInUseError{Name: src, UsedBy: deps}
+		}
+	}
+	path, err := m.imagePath(name)
+	if err != nil {
+		return Job{}, err
+	}
+	release, err := m.lockName(name)
+	if err != nil {
+		return Job{}, err
+	}
+	if _, err := os.Lstat(path); err == nil && name != src {
+		release()
+		return Job{}, fmt.Errorf("%s: %w", name, ErrImageExists)
+	}
+	written, _ := allocatedBytes(img.Path)
+	if err := m.checkImageStore(written); err != nil {
+		release()
+		return Job{}, err
+	}
+	meta, _ := m.catalog.lookup(src)
+	job := m.jobs.start("convert", "image/"+src, func(ctx context.Context, progress func(float64)) error {
+		defer release()
+		tmp := path + partSuffix
+		if err := m.qemuConvert(ctx, img.Path, img.Format, tmp, format, spec.Compress, progress); err != nil {
+			_ = os.Remove(tmp)
+			return err
+		}
+		digest, err := fileSHA256(tmp)
+		if err != nil {
+			_ = os.Remove(tmp)
+			return fmt.Errorf("hash: %w", err)
+		}
+		if deps := m.dependents(src); name == src && len(deps) > 0 {
+			_ = os.Remove(tmp)
+			return &ImageInUseError{Name: src, UsedBy: deps}
+		}
+		if err := m.placeImage(tmp, name, catalogEntry{SHA256: digest, Lineage: meta.Lineage}); err != nil {
+			_ = os.Remove(tmp)
+			return err
+		}
+		return nil
+	})
+	return job, nil
+}
+
+// convertVolume rewrites a detached file volume in place. Block volumes are
+// always raw and allocated by their pool, so there is nothing to convert.
+func (m *LocalManager) convertVolume(ctx context.Context, spec ConvertSpec) (Job, error) {
+	if spec.Name != "" {
+		return Job{}, errors.New("volumes are converted in place; clone the volume first to keep the original")
+	}
+	vol, ok := m.volumes.get(spec.Volume)
+	if !ok {
+		return Job{}, fmt.Errorf("%s: %w", spec.Volume, ErrVolumeNotFound)
+	}
+	if vol.VM != "" {
+		return Job{}, fmt.Errorf("detach %s from vm %s first: %w", vol.Name, vol.VM, ErrVolumeInUse)
+	}
+	if vol.Block {
+		return Job{}, fmt.Errorf("%s is a raw block volume and cannot be converted", vol.Name)
+	}
+	format := spec.Format
+	if format == "" {
+		format = vol.Format
+	}
+	if format != "raw" && format != "qcow2" {
+		return Job{}, fmt.Errorf("unsupported volume format %q", format)
+	}
+	if spec.Compress && format != "qcow2" {
+		return Job{}, fmt.Errorf("only qcow2 can be compressed, not %s", format)
+	}
+	// the converted copy sits next to the volume until it replaces it
+	_, b, err := m.pool(vol.Pool)
+	if err != nil {
+		return Job{}, err
+	}
+	if err := m.checkPool(ctx, vol.Pool, b, m.volumeAllocated(ctx, vol), 0); err != nil {
+		return Job{}, err
+	}
+	// volume names cannot contain slashes, so this never clashes with an image
+	release, err := m.lockName("volume/" + vol.Name)
+	if err != nil {
+		return Job{}, err
+	}
+	job := m.jobs.start("convert", "volume/"+vol.Name, func(ctx context.Context, progress func(float64)) error {
+		defer release()
+		tmp := filepath.Join(filepath.Dir(vol.Path), "."+vol.Name+".convert")
+		if err := m.qemuConvert(ctx, vol.Path, vol.Format, tmp, format, spec.Compress, progress); err != nil {
+			_ = os.Remove(tmp)
+			return err
+		}
+		if err := os.Rename(tmp, vol.Path); err != nil {
+			_ = os.Remove(tmp)
+			return fmt.Errorf("replace volume: %w", err)
+		}
+		return m.volumes.update(vol.Name, func(v *Volume) error {
+			v.Format = format
+			return nil
+		})
+	})
+	return job, nil
+}
+
+// qemuConvert runs qemu-img convert, passing its progress on when the runner
+// can stream output.
+func (m *LocalManager) qemuConvert(ctx context.Context, src, srcFormat, dst, dstFormat string, compress bool, progress func(float64)) error {
+	args := []string{"convert", "-f", qemuFormat(srcFormat), "-O", dstFormat}
+	if compress {
+		args = append(args, "-c")
+	}
+	args = append(args, src, dst)
+	sr, ok := m.run.(StreamRunner)
+	if !ok {
+		_, err := m.run.Run(ctx, "qemu-img", args...)
+		return err
+	}
+	return sr.RunStream(ctx, &progressParser{fn: progress}, "qemu-img", append([]string{"convert", "-p"}, args[1:]...)...)
+}
+
+// progressParser reads the "(12.34/100%)" updates qemu-img -p writes,
+// separated by carriage returns.
+type progressParser struct {
+	fn  func(float64)
+	buf []byte
+}
+
+func (p *progressParser) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexAny(p.buf, "\r\n")
+		if i < 0 {
+			return len(b), nil
+		}
+		line := strings.TrimSpace(string(p.buf[:i]))
+		p.buf = p.buf[i+1:]
+		if v, ok := strings.CutPrefix(line, "("); ok {
+			if v, _, ok := strings.Cut(v, "/"); ok {
+				if pct, err := strconv.ParseFloat(v, 64); err == nil {
+					p.fn(pct)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	partSuffix     = ".part"
+	partMetaSuffix = ".part.json"
+
+	defaultDownloadRetries = 5
+	defaultRetryBackoff    = time.Second
+	maxRetryBackoff        = 30 * time.Second
+	progressInterval       = 500 * time.Millisecond
+)
+
+// Progress reports how far an image transfer has got, in bytes as
+// transferred, which are compressed ones for compressed sources. BytesTotal is
+// -1 when the source does not advertise a length.
+type Progress struct {
+	BytesDone  int64 `json:"bytes_done"`
+	BytesTotal int64 `json:"bytes_total"`
+}
+
+// ProgressFunc receives periodic progress updates during a transfer. It may be nil.
+type ProgressFunc func(Progress)
+
+// errRestart signals that the source will not resume a partial download, or
+// that the object changed, and the transfer has to start again from the
+// first byte.
+var errRestart = errors.New("source cannot resume this download")
+
+// partState is persisted next to a .part file so that a later attempt can
+// resume it, but only if the remote object is still the same one.
+type partState struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Total        int64  `json:"total"`
+}
+
+func loadPartState(path string) (partState, bool) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return partState{}, false
+	}
+	var st partState
+	if err := json.Unmarshal(b, &st); err != nil {
+		return partState{}, false
+	}
+	return st, true
+}
+
+func savePartState(path string, st partState) error {
+	b, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// remoteReader streams an object from a Source and transparently reopens it
+// at the current offset when the connection drops. Sources make sure a
+// reopened object is still the same one.
+type remoteReader struct {
+	ctx     context.Context
+	src     Source
+	url     *url.URL
+	retries int
+	backoff time.Duration
+
+	body     io.ReadCloser
+	offset   int64
+	info     SourceInfo
+	failures int
+}
+
+func newRemoteReader(ctx context.Context, src Source, u *url.URL, st partState, offset int64, retries int, backoff time.Duration) *remoteReader {
+	return &remoteReader{
+		ctx:     ctx,
+		src:     src,
+		url:     u,
+		retries: retries,
+		backoff: backoff,
+		offset:  offset,
+		info:    SourceInfo{Size: st.Total, ETag: st.ETag, LastModified: st.LastModified},
+	}
+}
+
+// state returns what is known about the remote object so far.
+func (r *remoteReader) state() partState {
+	return partState{URL: r.url.String(), ETag: r.info.ETag, LastModified: r.info.LastModified, Total: r.info.Size}
+}
+
+// connect opens the object for the remaining bytes, retrying transient failures.
+func (r *remoteReader) connect() error {
+	for {
+		body, info, err := r.src.Open(r.ctx, r.url, r.offset, r.info)
+		if err == nil {
+			r.body, r.info = body, info
+			return nil
+		}
+		if !isRetryable(err) {
+			return err
+		}
+		if werr := r.wait(err); werr != nil {
+			return werr
+		}
+	}
+}
+
+func (r *remoteReader) Read(p []byte) (int, error) {
+	for {
+		if r.body == nil {
+			if err := r.connect(); err != nil {
+				return 0, err
+			}
+		}
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if n > 0 {
+			r.failures = 0
+		}
+		if err == nil {
+			return n, nil
+		}
+		r.body.Close()
+		r.body = nil
+		if err == io.EOF && (r.info.Size < 0 || r.offset >= r.info.Size) {
+			return n, io.EOF
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if werr := r.wait(err); werr != nil {
+			return n, werr
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// wait sleeps with exponential backoff before the next attempt, or returns
+// the last error once retries are exhausted or the context is done.
+func (r *remoteReader) wait(cause error) error {
+	if r.failures >= r.retries {
+		return fmt.Errorf("giving up after %d retries: %w", r.retries, cause)
+	}
+	delay := r.backoff << r.failures
+	if delay > maxRetryBackoff || delay <= 0 {
+		delay = maxRetryBackoff
+	}
+	r.failures++
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func (r *remoteReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
+
+// parseContentRange parses "bytes start-end/total". total is -1 when it is "*".
+func parseContentRange(v string) (start, total int64, ok bool) {
+	v, found := strings.CutPrefix(v, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	rng, size, found := strings.Cut(v, "/")
+	if !found {
+		return 0, 0, false
+	}
+	first, _, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if size == "*" {
+		return start, -1, true
+	}
+	total, err = strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, total, true
+}
+
+// parseUnsatisfiedRange parses the "bytes */total" a server answers a range
+// past the end of the object with.
+func parseUnsatisfiedRange(v string) (int64, bool) {
+	v, found := strings.CutPrefix(v, "bytes */")
+	if !found {
+		return 0, false
+	}
+	total, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return total, true
+}
+
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// permanent marks an error that retrying the same request cannot fix.
+func permanent(err error) error { return &permanentError{err: err} }
+
+func isRetryable(err error) bool {
+	var p *permanentError
+	return !errors.As(err, &p) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) &&
+		!errors.Is(err, ErrInsufficientStorage)
+}
+
+// progressReporter counts bytes written through it and calls fn at most
+// once per progressInterval.
+type progressReporter struct {
+	fn    ProgressFunc
+	total func() int64
+	done  int64
+	last  time.Time
+}
+
+func (p *progressReporter) Write(b []byte) (int, error) {
+	p.done += int64(len(b))
+	if p.fn != nil && time.Since(p.last) >= progressInterval {
+		p.report()
+	}
+	return len(b), nil
+}
+
+func (p *progressReporter) report() {
+	if p.fn == nil {
+		return
+	}
+	p.last = time.Now()
+	p.fn(Progress{BytesDone: p.done, BytesTotal: p.total()})
+}
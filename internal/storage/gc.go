@@ -0,0 +1,361 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultGCMinAge    = 24 * time.Hour
+	defaultGCRetention = 7 * 24 * time.Hour
+)
+
+// GCPolicy controls how garbage is collected. Files are first moved to
+// QuarantineDir and deleted for good once they have been there for
+// Retention, so a mistaken collection can still be undone by moving a file
+// back.
+type GCPolicy struct {
+	QuarantineDir string
+	// MinAge protects files modified more recently, which may belong to an
+	// operation still in progress.
+	MinAge    time.Duration
+	Retention time.Duration
+}
+
+// GCSpec describes one collection. The caller knows the hypervisor and
+// passes what its domains use.
+type GCSpec struct {
+	// VMs holds the ids of all defined VMs.
+	VMs map[string]bool
+	// InUse holds the paths of disks and CD-ROM media of all VMs.
+	InUse map[string]bool
+	// MinAge overrides the policy when not zero.
+	MinAge time.Duration
+	// DryRun only reports what would be collected.
+	DryRun bool
+}
+
+// GCItem is something the collector found. Kind is one of:
+//
+//	part        a .part file left by an interrupted image transfer
+//	blob        image content no name points at any more
+//	disk        a file in a directory pool that is neither a volume nor used by a VM
+//	temp        a half-written copy, such as an interrupted volume conversion
+//	volume      a volume created with a VM that no longer exists
+//	attachment  a volume still recorded as attached to a VM that no longer exists
+//	ref         an image reference held by a VM that no longer exists
+type GCItem struct {
+	Kind      string    `json:"kind"`
+	Path      string    `json:"path,omitempty"`
+	Pool      string    `json:"pool,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	SizeBytes int64     `json:"size_bytes"`
+	ModTime   time.Time `json:"mod_time,omitempty"`
+	Reason    string    `json:"reason"`
+	// Ref is the stale reference of a ref item.
+	Ref *Ref `json:"ref,omitempty"`
+	// ReportOnly is set for items left for an operator, such as block volumes.
+	ReportOnly bool `json:"report_only,omitempty"`
+	// Quarantine is where the file was moved to.
+	Quarantine string `json:"quarantine,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// GCReport lists what a collection found and, unless it was a dry run, what
+// it did about it.
+type GCReport struct {
+	DryRun bool     `json:"dry_run"`
+	Items  []GCItem `json:"items"`
+	// ReclaimableBytes adds up the space taken by the files found.
+	ReclaimableBytes int64 `json:"reclaimable_bytes"`
+	// Purged lists quarantined files deleted for good.
+	Purged []string `json:"purged,omitempty"`
+}
+
+// SetGCPolicy sets where collected files go and for how long. Zero ages get
+// the defaults of one day and one week.
+func (m *LocalManager) SetGCPolicy(p GCPolicy) error {
+	if p.QuarantineDir == "" {
+		return errors.New("quarantine directory required")
+	}
+	if err := os.MkdirAll(p.QuarantineDir, 0o700); err != nil {
+		return fmt.Errorf("mkdir quarantine: %w", err)
+	}
+	if p.MinAge == 0 {
+		p.MinAge = defaultGCMinAge
+	}
+	if p.Retention == 0 {
+		p.Retention = defaultGCRetention
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gc = p
+	return nil
+}
+
+// CollectGarbage cross-references the image catalog, the volume registry and
+// the VMs in spec with the files in the images directory and directory
+// pools. Files older than the minimum age that nothing uses are moved to the
+// quarantine directory, and quarantined files past their retention are
+// deleted. Block device pools are not scanned.
+func (m *LocalManager) CollectGarbage(ctx context.Context, spec GCSpec) (GCReport, error) {
+	m.mu.Lock()
+	policy := m.gc
+	m.mu.Unlock()
+	if policy.QuarantineDir == "" && !spec.DryRun {
+		return GCReport{}, errors.New("garbage collection is not configured")
+	}
+	minAge := policy.MinAge
+	if spec.MinAge != 0 {
+		minAge = spec.MinAge
+	}
+	cutoff := time.Now().Add(-minAge)
+
+	items := m.imageGarbage(cutoff)
+	items = append(items, m.poolGarbage(ctx, spec, cutoff)...)
+	items = append(items, m.refGarbage(spec)...)
+
+	report := GCReport{DryRun: spec.DryRun, Items: items}
+	for i := range report.Items {
+		it := &report.Items[i]
+		report.ReclaimableBytes += it.SizeBytes
+		if spec.DryRun {
+			continue
+		}
+		if err := m.collect(ctx, policy.QuarantineDir, it); err != nil {
+			it.Error = err.Error()
+		}
+	}
+	if !spec.DryRun {
+		report.Purged = purgeQuarantine(policy.QuarantineDir, time.Now().Add(-policy.Retention))
+	}
+	return report, nil
+}
+
+// imageGarbage finds stale .part files of transfers no longer running and
+// blobs without names or overlays backed by them.
+func (m *LocalManager) imageGarbage(cutoff time.Time) []GCItem {
+	var items []GCItem
+	entries, _ := os.ReadDir(m.imagesDir)
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() {
+			continue
+		}
+		image, ok := strings.CutSuffix(name, partMetaSuffix)
+		if !ok {
+			image, ok = strings.CutSuffix(name, partSuffix)
+		}
+		if !ok {
+			image, ok = strings.CutSuffix(name, uploadSuffix)
+		}
+		if !ok || m.transferring(image) {
+			continue
+		}
+		if it, ok := staleFile(filepath.Join(m.imagesDir, name), cutoff); ok {
+			it.Kind, it.Name, it.Reason = "part", image, "left by an interrupted transfer"
+			items = append(items, it)
+		}
+	}
+	blobs, _ := os.ReadDir(filepath.Join(m.imagesDir, blobsDir))
+	for _, e := range blobs {
+		digest := e.Name()
+		if !e.Type().IsRegular() || m.catalog.digestUsers(digest) > 0 {
+			continue
+		}
+		path := m.blobPath(digest)
+		if len(m.overlaysOf(path)) > 0 {
+			continue
+		}
+		if it, ok := staleFile(path, cutoff); ok {
+			it.Kind, it.Name, it.Reason = "blob", digest, "no image name points at it"
+			items = append(items, it)
+		}
+	}
+	return items
+}
+
+// poolGarbage looks at volumes whose VM is gone and at files in directory
+// pools that no volume or VM accounts for.
+func (m *LocalManager) poolGarbage(ctx context.Context, spec GCSpec, cutoff time.Time) []GCItem {
+	var items []GCItem
+	known := make(map[string]bool)
+	for _, vol := range m.volumes.list() {
+		known[vol.Pool+"/"+vol.Name] = true
+		if vol.VM == "" || spec.VMs[vol.VM] {
+			continue
+		}
+		if !vol.DeleteWithVM {
+			items = append(items, GCItem{Kind: "attachment", Pool: vol.Pool, Name: vol.Name, Path: vol.Path,
+				Reason: fmt.Sprintf("attached to vm %s, which no longer exists", vol.VM)})
+			continue
+		}
+		it := GCItem{Kind: "volume", Pool: vol.Pool, Name: vol.Name, Path: vol.Path,
+			Reason: fmt.Sprintf("created with vm %s, which no longer exists", vol.VM)}
+		if vol.Block {
+			// block devices cannot be moved to quarantine
+			it.ReportOnly = true
+			items = append(items, it)
+			continue
+		}
+		if st, ok := staleFile(vol.Path, cutoff); ok && !spec.InUse[vol.Path] {
+			it.SizeBytes, it.ModTime = st.SizeBytes, st.ModTime
+			items = append(items, it)
+		}
+	}
+
+	m.mu.Lock()
+	pools := make(map[string]*dirBackend)
+	var names []string
+	for name, b := range m.pools {
+		if d, ok := b.(*dirBackend); ok {
+			pools[name] = d
+			names = append(names, name)
+		}
+	}
+	m.mu.Unlock()
+	sort.Strings(names)
+	for _, pool := range names {
+		d := pools[pool]
+		entries, err := os.ReadDir(d.dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name := e.Name()
+			path := filepath.Join(d.dir, name)
+			if !e.Type().IsRegular() || known[pool+"/"+name] || spec.InUse[path] {
+				continue
+			}
+			it, ok := staleFile(path, cutoff)
+			if !ok {
+				continue
+			}
+			it.Pool, it.Name = pool, name
+			switch {
+			case strings.HasPrefix(name, "."):
+				vol, ok := strings.CutSuffix(strings.TrimPrefix(name, "."), ".convert")
+				if !ok || m.transferring("volume/"+vol) {
+					continue
+				}
+				it.Kind, it.Reason = "temp", "left by an interrupted volume conversion"
+			case strings.EqualFold(filepath.Ext(name), ".iso"):
+				it.Kind, it.Reason = "disk", "seed ISO no VM has in its CD-ROM drive"
+			default:
+				it.Kind, it.Reason = "disk", "not a volume and not used by any VM"
+			}
+			items = append(items, it)
+		}
+	}
+	return items
+}
+
+// refGarbage finds image references held by VMs that no longer exist.
+func (m *LocalManager) refGarbage(spec GCSpec) []GCItem {
+	stale := m.catalog.refsWhere(func(r Ref) bool {
+		return (r.Kind == "vm" || r.Kind == "cdrom") && !spec.VMs[r.ID]
+	})
+	names := make([]string, 0, len(stale))
+	for name := range stale {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var items []GCItem
+	for _, name := range names {
+		for _, r := range stale[name] {
+			items = append(items, GCItem{Kind: "ref", Name: name, Ref: &r, Reason: r.String() + " no longer exists"})
+		}
+	}
+	return items
+}
+
+// collect acts on one item: files move to quarantine, stale attachments
+// and references are dropped.
+func (m *LocalManager) collect(ctx context.Context, quarantine string, it *GCItem) error {
+	switch {
+	case it.ReportOnly:
+		return nil
+	case it.Kind == "attachment":
+		return m.SetVolumeAttachment(ctx, it.Name, "")
+	case it.Kind == "ref":
+		return m.catalog.dropRef(it.Name, *it.Ref)
+	case it.Kind == "blob":
+		// an image may have been placed on the blob since it was found
+		defer m.lockBlob(it.Name)()
+		if m.catalog.digestUsers(it.Name) > 0 {
+			return fmt.Errorf("blob %s is in use again", it.Name)
+		}
+	}
+	dst, err := quarantineFile(quarantine, it)
+	if err != nil {
+		return err
+	}
+	it.Quarantine = dst
+	if it.Kind == "volume" {
+		return m.volumes.remove(it.Name)
+	}
+	return nil
+}
+
+// quarantineFile moves the item's file into dir under a name recording when
+// and where it came from. The file's modification time is reset so that
+// retention counts from now.
+func quarantineFile(dir string, it *GCItem) (string, error) {
+	from := it.Pool
+	if from == "" {
+		from = "images"
+	}
+	dst := filepath.Join(dir, fmt.Sprintf("%s-%s-%s", time.Now().UTC().Format("20060102T150405"), from, filepath.Base(it.Path)))
+	if err := os.Rename(it.Path, dst); err != nil {
+		return "", fmt.Errorf("quarantine: %w", err)
+	}
+	now := time.Now()
+	_ = os.Chtimes(dst, now, now)
+	return dst, nil
+}
+
+// purgeQuarantine deletes quarantined files last touched before cutoff.
+func purgeQuarantine(dir string, cutoff time.Time) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var purged []string
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.Mode().IsRegular() || info.ModTime().After(cutoff) {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		if os.Remove(path) == nil {
+			purged = append(purged, path)
+		}
+	}
+	return purged
+}
+
+// staleFile describes path if it was last modified before cutoff.
+func staleFile(path string, cutoff time.Time) (GCItem, bool) {
+	info, err := os.Stat(path)
+	if err != nil || info.ModTime().After(cutoff) {
+		return GCItem{}, false
+	}
+	size, err := allocatedBytes(path)
+	if err != nil {
+		size = info.Size()
+	}
+	return GCItem{Path: path, SizeBytes: size, ModTime: info.ModTime().UTC()}, true
+}
+
+// transferring reports whether a transfer or conversion holds name.
+func (m *LocalManager) transferring(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.inflight[name]
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrJobNotFound is returned for unknown job ids.
+var ErrJobNotFound = errors.New("job not found")
+
+type JobState string
+
+const (
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+)
+
+// maxFinishedJobs bounds how many completed jobs are remembered.
+const maxFinishedJobs = 100
+
+// Job is a long-running storage operation such as a format conversion. Jobs
+// live in memory and are forgotten when the daemon restarts.
+type Job struct {
+	ID     string   `json:"id"`
+	Kind   string   `json:"kind"`
+	Target string   `json:"target"`
+	State  JobState `json:"state"`
+	// Progress is in percent.
+	Progress   float64    `json:"progress"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+type jobRegistry struct {
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+func newJobRegistry() *jobRegistry {
+	return &jobRegistry{jobs: make(map[string]*Job)}
+}
+
+// start runs fn in the background as a new job. fn reports progress in
+// percent; its context is independent of the request that started it.
+func (r *jobRegistry) start(kind, target string, fn func(ctx context.Context, progress func(float64)) error) Job {
+	job := &Job{ID: uuid.NewString(), Kind: kind, Target: target, State: JobRunning, CreatedAt: time.Now().UTC()}
+	r.mu.Lock()
+	r.jobs[job.ID] = job
+	out := *job
+	r.mu.Unlock()
+	go func() {
+		err := fn(context.Background(), func(p float64) {
+			r.mu.Lock()
+			job.Progress = p
+			r.mu.Unlock()
+		})
+		r.finish(job, err)
+	}()
+	return out
+}
+
+func (r *jobRegistry) finish(job *Job, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now().UTC()
+	job.FinishedAt = &now
+	if err != nil {
+		job.State, job.Error = JobFailed, err.Error()
+	} else {
+		job.State, job.Progress = JobSucceeded, 100
+	}
+	r.prune()
+}
+
+// prune drops the oldest finished jobs beyond maxFinishedJobs. Callers hold r.mu.
+func (r *jobRegistry) prune() {
+	var finished []*Job
+	for _, j := range r.jobs {
+		if j.State != JobRunning {
+			finished = append(finished, j)
+		}
+	}
+	if len(finished) <= maxFinishedJobs {
+		return
+	}
+	slices.SortFunc(finished, func(a, b *Job) int { return a.FinishedAt.Compare(*b.FinishedAt) })
+	for _, j := range finished[:len(finished)-maxFinishedJobs] {
+		delete(r.jobs, j.ID)
+	}
+}
+
+func (r *jobRegistry) get(id string) (Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j, ok := r.jobs[id]
+	if !ok {
+		return Job{}, fmt.Errorf("%s: %w", id, ErrJobNotFound)
+	}
+	return *j, nil
+}
+
+// list returns all known jobs, oldest first.
+func (r *jobRegistry) list() []Job {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Job, 0, len(r.jobs))
+	for _, j := range r.jobs {
+		out = append(out, *j)
+	}
+	slices.SortFunc(out, func(a, b Job) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return out
+}
+
+// StartJob runs fn in the background as a job, for long-running work that
+// storage takes part in but does not drive, such as backups.
+func (m *LocalManager) StartJob(kind, target string, fn func(ctx context.Context, progress func(float64)) error) Job {
+	return m.jobs.start(kind, target, fn)
+}
+
+func (m *LocalManager) GetJob(ctx context.Context, id string) (Job, error) {
+	return m.jobs.get(id)
+}
+
+func (m *LocalManager) ListJobs(ctx context.Context) ([]Job, error) {
+	return m.jobs.list(), nil
+}
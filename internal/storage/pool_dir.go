@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// dirBackend keeps volumes as qcow2 or raw files in a directory. Clones are
+// qcow2 overlays backed by the image, so they are created instantly.
+type dirBackend struct {
+	dir string
+	run CommandRunner
+}
+
+func (b *dirBackend) Type() string { return "dir" }
+
+func (b *dirBackend) path(name string) (string, error) {
+	if err := validVolumeName(name); err != nil {
+		return "", err
+	}
+	return filepath.Join(b.dir, name), nil
+}
+
+func (b *dirBackend) Create(ctx context.Context, name string, sizeBytes int64, format string) (Volume, error) {
+	path, err := b.path(name)
+	if err != nil {
+		return Volume{}, err
+	}
+	if format == "" {
+		format = "qcow2"
+	}
+	if format != "qcow2" && format != "raw" {
+		return Volume{}, fmt.Errorf("unsupported volume format %q", format)
+	}
+	if _, err := os.Lstat(path); err == nil {
+		return Volume{}, fmt.Errorf("%s: %w", name, ErrVolumeExists)
+	}
+	if _, err := b.run.Run(ctx, "qemu-img", "create", "-q", "-f", format, path, strconv.FormatInt(sizeBytes, 10)); err != nil {
+		return Volume{}, err
+	}
+	return Volume{Name: name, Path: path, Format: format, SizeBytes: sizeBytes}, nil
+}
+
+func (b *dirBackend) CloneFromImage(ctx context.Context, name, imagePath, imageFormat string, sizeBytes int64) (Volume, error) {
+	path, err := b.path(name)
+	if err != nil {
+		return Volume{}, err
+	}
+	if _, err := os.Lstat(path); err == nil {
+		return Volume{}, fmt.Errorf("%s: %w", name, ErrVolumeExists)
+	}
+	virtual, err := imageVirtualSize(ctx, b.run, imagePath)
+	if err != nil {
+		return Volume{}, err
+	}
+	if sizeBytes < virtual {
+		sizeBytes = virtual
+	}
+	args := []string{"create", "-q", "-f", "qcow2", "-b", imagePath, "-F", qemuFormat(imageFormat), path, strconv.FormatInt(sizeBytes, 10)}
+	if _, err := b.run.Run(ctx, "qemu-img", args...); err != nil {
+		return Volume{}, err
+	}
+	return Volume{Name: name, Path: path, Format: "qcow2", SizeBytes: sizeBytes}, nil
+}
+
+func (b *dirBackend) Copy(ctx context.Context, name, srcPath, srcFormat string, sizeBytes int64) (Volume, error) {
+	path, err := b.path(name)
+	if err != nil {
+		return Volume{}, err
+	}
+	if _, err := os.Lstat(path); err == nil {
+		return Volume{}, fmt.Errorf("%s: %w", name, ErrVolumeExists)
+	}
+	if _, err := b.run.Run(ctx, "qemu-img", "convert", "-f", qemuFormat(srcFormat), "-O", "qcow2", srcPath, path); err != nil {
+		_ = os.Remove(path)
+		return Volume{}, err
+	}
+	virtual, err := imageVirtualSize(ctx, b.run, path)
+	if err == nil && sizeBytes > virtual {
+		err = b.Resize(ctx, name, sizeBytes)
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return Volume{}, err
+	}
+	return Volume{Name: name, Path: path, Format: "qcow2", SizeBytes: max(sizeBytes, virtual)}, nil
+}
+
+func (b *dirBackend) Resize(ctx context.Context, name string, sizeBytes int64) error {
+	path, err := b.path(name)
+	if err != nil {
+		return err
+	}
+	_, err = b.run.Run(ctx, "qemu-img", "resize", "-q", path, strconv.FormatInt(sizeBytes, 10))
+	return err
+}
+
+func (b *dirBackend) Delete(ctx context.Context, name string) error {
+	path, err := b.path(name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// Snapshot takes an internal qcow2 snapshot; raw files have no snapshots.
+func (b *dirBackend) Snapshot(ctx context.Context, name, snapshot string) error {
+	path, err := b.path(name)
+	if err != nil {
+		return err
+	}
+	if detectFormat(path, name) != "qcow2" {
+		return errors.New("snapshots need a qcow2 volume")
+	}
+	_, err = b.run.Run(ctx, "qemu-img", "snapshot", "-c", snapshot, path)
+	return err
+}
+
+func (b *dirBackend) Usage(ctx context.Context) (PoolUsage, error) {
+	return fsUsage(b.dir)
+}
+
+func (b *dirBackend) List(ctx context.Context) ([]Volume, error) {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return nil, fmt.Errorf("readdir: %w", err)
+	}
+	var out []Volume
+	for _, e := range entries {
+		if !e.Type().IsRegular() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(b.dir, e.Name())
+		vol := Volume{Name: e.Name(), Path: path, Format: "raw"}
+		if hdr, err := readQcow2Header(path); err == nil {
+			vol.Format, vol.SizeBytes = "qcow2", hdr.VirtualSize
+		} else if info, err := e.Info(); err == nil {
+			vol.SizeBytes = info.Size()
+		}
+		out = append(out, vol)
+	}
+	return out, nil
+}
+
+// Allocations counts the blocks of each file, so sparse files and qcow2
+// overlays only report what they hold.
+func (b *dirBackend) Allocations(ctx context.Context) (map[string]int64, error) {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return nil, fmt.Errorf("readdir: %w", err)
+	}
+	out := make(map[string]int64)
+	for _, e := range entries {
+		if !e.Type().IsRegular() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		n, err := allocatedBytes(filepath.Join(b.dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		out[e.Name()] = n
+	}
+	return out, nil
+}
+
+// imageVirtualSize asks qemu-img for the size of the disk an image holds.
+func imageVirtualSize(ctx context.Context, run CommandRunner, path string) (int64, error) {
+	out, err := run.Run(ctx, "qemu-img", "info", "--output=json", path)
+	if err != nil {
+		return 0, err
+	}
+	var info struct {
+		VirtualSize int64 `json:"virtual-size"`
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return 0, fmt.Errorf("parse qemu-img info: %w", err)
+	}
+	return info.VirtualSize, nil
+}
+
+// qemuFormat maps detected image formats to qemu-img format names; images
+// without a recognised header are treated as raw.
+func qemuFormat(format string) string {
+	switch format {
+	case "qcow2", "vmdk", "vhdx":
+		return format
+	default:
+		return "raw"
+	}
+}
+
+// convertInto writes the content of an image to an existing raw block device.
+func convertInto(ctx context.Context, run CommandRunner, imagePath, imageFormat, dev string) error {
+	_, err := run.Run(ctx, "qemu-img", "convert", "-n", "-f", qemuFormat(imageFormat), "-O", "raw", imagePath, dev)
+	return err
+}
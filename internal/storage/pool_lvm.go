@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// lvmThinBackend allocates thin logical volumes from a thin pool. Volumes are
+// raw block devices at /dev/<vg>/<name>.
+type lvmThinBackend struct {
+	vg       string
+	thinPool string
+	run      CommandRunner
+}
+
+func (b *lvmThinBackend) Type() string { return "lvm-thin" }
+
+func (b *lvmThinBackend) lv(name string) string { return b.vg + "/" + name }
+
+func (b *lvmThinBackend) Create(ctx context.Context, name string, sizeBytes int64, format string) (Volume, error) {
+	if err := validVolumeName(name); err != nil {
+		return Volume{}, err
+	}
+	if format != "" && format != "raw" {
+		return Volume{}, fmt.Errorf("lvm-thin volumes are raw, not %s", format)
+	}
+	if _, err := b.run.Run(ctx, "lvcreate", "-q", "-y", "-n", name, "-V", bytesArg(sizeBytes), "-T", b.lv(b.thinPool)); err != nil {
+		return Volume{}, err
+	}
+	return Volume{Name: name, Path: "/dev/" + b.lv(name), Format: "raw", SizeBytes: sizeBytes, Block: true}, nil
+}
+
+func (b *lvmThinBackend) CloneFromImage(ctx context.Context, name, imagePath, imageFormat string, sizeBytes int64) (Volume, error) {
+	return b.Copy(ctx, name, imagePath, imageFormat, sizeBytes)
+}
+
+// Copy creates a volume large enough for the source and converts it in.
+func (b *lvmThinBackend) Copy(ctx context.Context, name, srcPath, srcFormat string, sizeBytes int64) (Volume, error) {
+	virtual, err := imageVirtualSize(ctx, b.run, srcPath)
+	if err != nil {
+		return Volume{}, err
+	}
+	vol, err := b.Create(ctx, name, max(sizeBytes, virtual), "raw")
+	if err != nil {
+		return Volume{}, err
+	}
+	if err := convertInto(ctx, b.run, srcPath, srcFormat, vol.Path); err != nil {
+		_ = b.Delete(ctx, name)
+		return Volume{}, err
+	}
+	return vol, nil
+}
+
+func (b *lvmThinBackend) Resize(ctx context.Context, name string, sizeBytes int64) error {
+	_, err := b.run.Run(ctx, "lvresize", "-q", "-y", "-L", bytesArg(sizeBytes), b.lv(name))
+	return err
+}
+
+// Delete removes the volume together with its thin snapshots, which would
+// otherwise live on as independent volumes in the pool.
+func (b *lvmThinBackend) Delete(ctx context.Context, name string) error {
+	out, err := b.run.Run(ctx, "lvs", "--noheadings", "--separator", "|", "-o", "lv_name,origin", b.vg)
+	if err != nil {
+		return err
+	}
+	args := []string{"-q", "-y"}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "|")
+		if len(fields) == 2 && fields[1] == name {
+			args = append(args, b.lv(fields[0]))
+		}
+	}
+	_, err = b.run.Run(ctx, "lvremove", append(args, b.lv(name))...)
+	return err
+}
+
+// Snapshot creates a thin snapshot named <name>_<snapshot>.
+func (b *lvmThinBackend) Snapshot(ctx context.Context, name, snapshot string) error {
+	_, err := b.run.Run(ctx, "lvcreate", "-q", "-y", "-s", "-n", name+"_"+snapshot, b.lv(name))
+	return err
+}
+
+// Usage reports the thin pool size and how much of its data area is allocated.
+func (b *lvmThinBackend) Usage(ctx context.Context) (PoolUsage, error) {
+	out, err := b.run.Run(ctx, "lvs", "--noheadings", "--units", "b", "--nosuffix", "-o", "lv_size,data_percent", b.lv(b.thinPool))
+	if err != nil {
+		return PoolUsage{}, err
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) != 2 {
+		return PoolUsage{}, fmt.Errorf("unexpected lvs output %q", strings.TrimSpace(string(out)))
+	}
+	size, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return PoolUsage{}, fmt.Errorf("parse lvs size: %w", err)
+	}
+	pct, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return PoolUsage{}, fmt.Errorf("parse lvs data_percent: %w", err)
+	}
+	used := int64(float64(size) * pct / 100)
+	return PoolUsage{CapacityBytes: size, UsedBytes: used, FreeBytes: size - used}, nil
+}
+
+func (b *lvmThinBackend) List(ctx context.Context) ([]Volume, error) {
+	out, err := b.run.Run(ctx, "lvs", "--noheadings", "--units", "b", "--nosuffix", "--separator", "|", "-o", "lv_name,lv_size,pool_lv", b.vg)
+	if err != nil {
+		return nil, err
+	}
+	var vols []Volume
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "|")
+		if len(fields) != 3 || fields[2] != b.thinPool {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, errors.New("unexpected lvs output")
+		}
+		vols = append(vols, Volume{Name: fields[0], Path: "/dev/" + b.lv(fields[0]), Format: "raw", SizeBytes: size, Block: true})
+	}
+	return vols, nil
+}
+
+// Allocations reports how much of each thin volume's data area is mapped.
+func (b *lvmThinBackend) Allocations(ctx context.Context) (map[string]int64, error) {
+	out, err := b.run.Run(ctx, "lvs", "--noheadings", "--units", "b", "--nosuffix", "--separator", "|", "-o", "lv_name,lv_size,pool_lv,data_percent", b.vg)
+	if err != nil {
+		return nil, err
+	}
+	allocs := make(map[string]int64)
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "|")
+		if len(fields) != 4 || fields[2] != b.thinPool {
+			continue
+		}
+		size, err1 := strconv.ParseInt(fields[1], 10, 64)
+		pct, err2 := strconv.ParseFloat(fields[3], 64)
+		if err1 != nil || err2 != nil {
+			return nil, errors.New("unexpected lvs output")
+		}
+		allocs[fields[0]] = int64(float64(size) * pct / 100)
+	}
+	return allocs, nil
+}
+
+// bytesArg formats a size for lvcreate and lvresize, which round up to whole extents.
+func bytesArg(n int64) string { return strconv.FormatInt(n, 10) + "B" }
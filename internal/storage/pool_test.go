@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeRunner records the commands a backend runs. Commands starting with a
+// key of out get that output, and ones starting with fail an error.
+type fakeRunner struct {
+	out   map[string]string
+	fail  string
+	calls []string
+}
+
+func (r *fakeRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := strings.Join(append([]string{name}, args...), " ")
+	r.calls = append(r.calls, cmd)
+	if r.fail != "" && strings.HasPrefix(cmd, r.fail) {
+		return nil, errors.New(name + " failed")
+	}
+	for prefix, out := range r.out {
+		if strings.HasPrefix(cmd, prefix) {
+			return []byte(out), nil
+		}
+	}
+	return nil, nil
+}
+
+const qemuInfo = `{"virtual-size": 2147483648}`
+
+func TestBackendCommands(t *testing.T) {
+	const gib = 1 << 30
+	lvs := "  web-root|\n  web-root_before-upgrade|web-root\n  db-root|\n  db-root_nightly|db-root\n"
+
+	tests := []struct {
+		name    string
+		backend func(dir string, run CommandRunner) VolumeBackend
+		op      func(ctx context.Context, b VolumeBackend) error
+		out     map[string]string
+		fail    string
+		wantErr bool
+		want    []string
+	}{
+		{
+			name: "dir create",
+			op:   create("web-root", gib, ""),
+			want: []string{"qemu-img create -q -f qcow2 {dir}/web-root 1073741824"},
+		},
+		{
+			name: "dir clone grows to the image size",
+			op:   clone("web-root", gib),
+			out:  map[string]string{"qemu-img info": qemuInfo},
+			want: []string{
+				"qemu-img info --output=json /images/debian.qcow2",
+				"qemu-img create -q -f qcow2 -b /images/debian.qcow2 -F qcow2 {dir}/web-root 2147483648",
+			},
+		},
+		{
+			name: "dir copy resizes past the source",
+			op:   copyVolume("web-root", 4*gib),
+			out:  map[string]string{"qemu-img info": qemuInfo},
+			want: []string{
+				"qemu-img convert -f qcow2 -O qcow2 /images/debian.qcow2 {dir}/web-root",
+				"qemu-img info --output=json {dir}/web-root",
+				"qemu-img resize -q {dir}/web-root 4294967296",
+			},
+		},
+		{
+			name:    "lvm create",
+			backend: lvm,
+			op:      create("web-root", gib, "raw"),
+			want:    []string{"lvcreate -q -y -n web-root -V 1073741824B -T vg0/thin"},
+		},
+		{
+			name:    "lvm copy",
+			backend: lvm,
+			op:      copyVolume("web-root", gib),
+			out:     map[string]string{"qemu-img info": qemuInfo},
+			want: []string{
+				"qemu-img info --output=json /images/debian.qcow2",
+				"lvcreate -q -y -n web-root -V 2147483648B -T vg0/thin",
+				"qemu-img convert -n -f qcow2 -O raw /images/debian.qcow2 /dev/vg0/web-root",
+			},
+		},
+		{
+			name:    "lvm copy removes the volume when the convert fails",
+			backend: lvm,
+			op:      copyVolume("web-root", gib),
+			out:     map[string]string{"qemu-img info": qemuInfo, "lvs": lvs},
+			fail:    "qemu-img convert",
+			wantErr: true,
+			want: []string{
+				"qemu-img info --output=json /images/debian.qcow2",
+				"lvcreate -q -y -n web-root -V 2147483648B -T vg0/thin",
+				"qemu-img convert -n -f qcow2 -O raw /images/debian.qcow2 /dev/vg0/web-root",
+				"lvs --noheadings --separator | -o lv_name,origin vg0",
+				"lvremove -q -y vg0/web-root_before-upgrade vg0/web-root",
+			},
+		},
+		{
+			name:    "lvm delete takes the thin snapshots along",
+			backend: lvm,
+			op:      deleteVolume("db-root"),
+			out:     map[string]string{"lvs": lvs},
+			want: []string{
+				"lvs --noheadings --separator | -o lv_name,origin vg0",
+				"lvremove -q -y vg0/db-root_nightly vg0/db-root",
+			},
+		},
+		{
+			name:    "lvm snapshot",
+			backend: lvm,
+			op:      snapshot("db-root", "nightly"),
+			want:    []string{"lvcreate -q -y -s -n db-root_nightly vg0/db-root"},
+		},
+		{
+			name:    "zfs create waits for the device",
+			backend: zfs,
+			op:      create("web-root", gib+1, ""),
+			want: []string{
+				"zfs create -s -V 1074790400 tank/vms/web-root",
+				"udevadm settle --timeout=30 --exit-if-exists=/dev/zvol/tank/vms/web-root",
+			},
+		},
+		{
+			name:    "zfs create destroys a zvol whose device does not appear",
+			backend: zfs,
+			op:      create("web-root", gib, ""),
+			fail:    "udevadm",
+			wantErr: true,
+			want: []string{
+				"zfs create -s -V 1073741824 tank/vms/web-root",
+				"udevadm settle --timeout=30 --exit-if-exists=/dev/zvol/tank/vms/web-root",
+				"zfs destroy -r tank/vms/web-root",
+			},
+		},
+		{
+			name:    "zfs copy converts once the device is there",
+			backend: zfs,
+			op:      copyVolume("web-root", gib),
+			out:     map[string]string{"qemu-img info": qemuInfo},
+			want: []string{
+				"qemu-img info --output=json /images/debian.qcow2",
+				"zfs create -s -V 2147483648 tank/vms/web-root",
+				"udevadm settle --timeout=30 --exit-if-exists=/dev/zvol/tank/vms/web-root",
+				"qemu-img convert -n -f qcow2 -O raw /images/debian.qcow2 /dev/zvol/tank/vms/web-root",
+			},
+		},
+		{
+			name:    "zfs resize rounds up",
+			backend: zfs,
+			op:      func(ctx context.Context, b VolumeBackend) error { return b.Resize(ctx, "web-root", 3*gib+1) },
+			want:    []string{"zfs set volsize=3222274048 tank/vms/web-root"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			run := &fakeRunner{out: tt.out, fail: tt.fail}
+			backend := tt.backend
+			if backend == nil {
+				backend = func(dir string, run CommandRunner) VolumeBackend { return &dirBackend{dir: dir, run: run} }
+			}
+			err := tt.op(context.Background(), backend(dir, run))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			want := make([]string, len(tt.want))
+			for i, cmd := range tt.want {
+				want[i] = strings.ReplaceAll(cmd, "{dir}", dir)
+			}
+			if !reflect.DeepEqual(run.calls, want) {
+				t.Errorf("commands:\n  %s\nwant:\n  %s", strings.Join(run.calls, "\n  "), strings.Join(want, "\n  "))
+			}
+		})
+	}
+}
+
+func TestZFSList(t *testing.T) {
+	run := &fakeRunner{out: map[string]string{"zfs list": "tank/vms/web-root\t2147483648\ntank/vms/db-root\t1073741824\n"}}
+	vols, err := (&zfsBackend{dataset: "tank/vms", run: run}).List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Volume{
+		{Name: "web-root", Path: "/dev/zvol/tank/vms/web-root", Format: "raw", SizeBytes: 2147483648, Block: true},
+		{Name: "db-root", Path: "/dev/zvol/tank/vms/db-root", Format: "raw", SizeBytes: 1073741824, Block: true},
+	}
+	if !reflect.DeepEqual(vols, want) {
+		t.Errorf("got %+v, want %+v", vols, want)
+	}
+}
+
+func TestLVMUsage(t *testing.T) {
+	run := &fakeRunner{out: map[string]string{"lvs": "  10737418240 25.00\n"}}
+	u, err := (&lvmThinBackend{vg: "vg0", thinPool: "thin", run: run}).Usage(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (PoolUsage{CapacityBytes: 10737418240, UsedBytes: 2684354560, FreeBytes: 8053063680}); u != want {
+		t.Errorf("got %+v, want %+v", u, want)
+	}
+}
+
+func lvm(dir string, run CommandRunner) VolumeBackend {
+	return &lvmThinBackend{vg: "vg0", thinPool: "thin", run: run}
+}
+
+func zfs(dir string, run CommandRunner) VolumeBackend {
+	return &zfsBackend{dataset: "tank/vms", run: run}
+}
+
+func create(name string, size int64, format string) func(context.Context, VolumeBackend) error {
+	return func(ctx context.Context, b VolumeBackend) error {
+		_, err := b.Create(ctx, name, size, format)
+		return err
+	}
+}
+
+func clone(name string, size int64) func(context.Context, VolumeBackend) error {
+	return func(ctx context.Context, b VolumeBackend) error {
+		_, err := b.CloneFromImage(ctx, name, "/images/debian.qcow2", "qcow2", size)
+		return err
+	}
+}
+
+func copyVolume(name string, size int64) func(context.Context, VolumeBackend) error {
+	return func(ctx context.Context, b VolumeBackend) error {
+		_, err := b.Copy(ctx, name, "/images/debian.qcow2", "qcow2", size)
+		return err
+	}
+}
+
+func deleteVolume(name string) func(context.Context, VolumeBackend) error {
+	return func(ctx context.Context, b VolumeBackend) error { return b.Delete(ctx, name) }
+}
+
+func snapshot(name, snap string) func(context.Context, VolumeBackend) error {
+	return func(ctx context.Context, b VolumeBackend) error { return b.Snapshot(ctx, name, snap) }
+}
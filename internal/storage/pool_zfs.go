@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// zvolAlign is the granularity zvol sizes are rounded up to, a multiple of
+// every volblocksize ZFS allows.
+const zvolAlign = 1 << 20
+
+// zfsBackend allocates sparse zvols below a dataset. Volumes are raw block
+// devices at /dev/zvol/<dataset>/<name>.
+type zfsBackend struct {
+	dataset string
+	run     CommandRunner
+}
+
+func (b *zfsBackend) Type() string { return "zfs" }
+
+func (b *zfsBackend) zvol(name string) string { return b.dataset + "/" + name }
+
+func (b *zfsBackend) Create(ctx context.Context, name string, sizeBytes int64, format string) (Volume, error) {
+	if err := validVolumeName(name); err != nil {
+		return Volume{}, err
+	}
+	if format != "" && format != "raw" {
+		return Volume{}, fmt.Errorf("zfs volumes are raw, not %s", format)
+	}
+	sizeBytes = alignUp(sizeBytes, zvolAlign)
+	if _, err := b.run.Run(ctx, "zfs", "create", "-s", "-V", strconv.FormatInt(sizeBytes, 10), b.zvol(name)); err != nil {
+		return Volume{}, err
+	}
+	// udev creates the device node asynchronously; nothing may open it before
+	path := "/dev/zvol/" + b.zvol(name)
+	if _, err := b.run.Run(ctx, "udevadm", "settle", "--timeout=30", "--exit-if-exists="+path); err != nil {
+		_ = b.Delete(ctx, name)
+		return Volume{}, fmt.Errorf("wait for %s: %w", path, err)
+	}
+	return Volume{Name: name, Path: path, Format: "raw", SizeBytes: sizeBytes, Block: true}, nil
+}
+
+func (b *zfsBackend) CloneFromImage(ctx context.Context, name, imagePath, imageFormat string, sizeBytes int64) (Volume, error) {
+	return b.Copy(ctx, name, imagePath, imageFormat, sizeBytes)
+}
+
+// Copy creates a volume large enough for the source and converts it in.
+func (b *zfsBackend) Copy(ctx context.Context, name, srcPath, srcFormat string, sizeBytes int64) (Volume, error) {
+	virtual, err := imageVirtualSize(ctx, b.run, srcPath)
+	if err != nil {
+		return Volume{}, err
+	}
+	vol, err := b.Create(ctx, name, max(sizeBytes, virtual), "raw")
+	if err != nil {
+		return Volume{}, err
+	}
+	if err := convertInto(ctx, b.run, srcPath, srcFormat, vol.Path); err != nil {
+		_ = b.Delete(ctx, name)
+		return Volume{}, err
+	}
+	return vol, nil
+}
+
+func (b *zfsBackend) Resize(ctx context.Context, name string, sizeBytes int64) error {
+	_, err := b.run.Run(ctx, "zfs", "set", "volsize="+strconv.FormatInt(alignUp(sizeBytes, zvolAlign), 10), b.zvol(name))
+	return err
+}
+
+func (b *zfsBackend) Delete(ctx context.Context, name string) error {
+	_, err := b.run.Run(ctx, "zfs", "destroy", "-r", b.zvol(name))
+	return err
+}
+
+func (b *zfsBackend) Snapshot(ctx context.Context, name, snapshot string) error {
+	_, err := b.run.Run(ctx, "zfs", "snapshot", b.zvol(name)+"@"+snapshot)
+	return err
+}
+
+func (b *zfsBackend) Usage(ctx context.Context) (PoolUsage, error) {
+	out, err := b.run.Run(ctx, "zfs", "get", "-Hp", "-o", "value", "used,available", b.dataset)
+	if err != nil {
+		return PoolUsage{}, err
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) != 2 {
+		return PoolUsage{}, fmt.Errorf("unexpected zfs output %q", strings.TrimSpace(string(out)))
+	}
+	used, err1 := strconv.ParseInt(fields[0], 10, 64)
+	avail, err2 := strconv.ParseInt(fields[1], 10, 64)
+	if err1 != nil || err2 != nil {
+		return PoolUsage{}, fmt.Errorf("unexpected zfs output %q", strings.TrimSpace(string(out)))
+	}
+	return PoolUsage{CapacityBytes: used + avail, UsedBytes: used, FreeBytes: avail}, nil
+}
+
+func (b *zfsBackend) List(ctx context.Context) ([]Volume, error) {
+	out, err := b.run.Run(ctx, "zfs", "list", "-Hp", "-d", "1", "-t", "volume", "-o", "name,volsize", b.dataset)
+	if err != nil {
+		return nil, err
+	}
+	var vols []Volume
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected zfs output %q", line)
+		}
+		name := strings.TrimPrefix(fields[0], b.dataset+"/")
+		vols = append(vols, Volume{Name: name, Path: "/dev/zvol/" + fields[0], Format: "raw", SizeBytes: size, Block: true})
+	}
+	return vols, nil
+}
+
+// Allocations reports the space referenced by each zvol.
+func (b *zfsBackend) Allocations(ctx context.Context) (map[string]int64, error) {
+	out, err := b.run.Run(ctx, "zfs", "list", "-Hp", "-d", "1", "-t", "volume", "-o", "name,referenced", b.dataset)
+	if err != nil {
+		return nil, err
+	}
+	allocs := make(map[string]int64)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		n, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected zfs output %q", line)
+		}
+		allocs[strings.TrimPrefix(fields[0], b.dataset+"/")] = n
+	}
+	return allocs, nil
+}
+
+func alignUp(n, align int64) int64 {
+	return (n + align - 1) / align * align
+}
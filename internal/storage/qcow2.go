@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+var errNotQcow2 = errors.New("not a qcow2 image")
+
+// qcow2Header holds the fields of a qcow2 header that storage cares about.
+type qcow2Header struct {
+	BackingFile string
+	VirtualSize int64
+}
+
+// readQcow2Header parses the fixed part of a qcow2 header (version 2 and 3
+// share the first 32 bytes) and the backing file name it points to.
+func readQcow2Header(path string) (qcow2Header, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return qcow2Header{}, err
+	}
+	defer f.Close()
+	var hdr [32]byte
+	if _, err := io.ReadFull(f, hdr[:]); err != nil {
+		return qcow2Header{}, errNotQcow2
+	}
+	if string(hdr[:4]) != "QFI\xfb" {
+		return qcow2Header{}, errNotQcow2
+	}
+	out := qcow2Header{VirtualSize: int64(binary.BigEndian.Uint64(hdr[24:32]))}
+	offset := binary.BigEndian.Uint64(hdr[8:16])
+	size := binary.BigEndian.Uint32(hdr[16:20])
+	if offset != 0 && size > 0 && size <= 1023 {
+		name := make([]byte, size)
+		if _, err := f.ReadAt(name, int64(offset)); err != nil {
+			return qcow2Header{}, fmt.Errorf("read backing file name: %w", err)
+		}
+		out.BackingFile = string(name)
+		if !filepath.IsAbs(out.BackingFile) {
+			out.BackingFile = filepath.Join(filepath.Dir(path), out.BackingFile)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AddImageRef records that ref depends on image, given by name or by its
+// path in the images directory. References to anything else, such as a disk
+// path outside the catalog, are ignored.
+func (m *LocalManager) AddImageRef(ctx context.Context, image string, ref Ref) error {
+	name, ok := m.resolveImageName(image)
+	if !ok {
+		return nil
+	}
+	return m.catalog.addRef(name, filepath.Join(m.imagesDir, name), ref)
+}
+
+// ReleaseStaleImageRefs drops the references of VMs, on their disk image or
+// the ISO in their CD-ROM drive, whose IDs are not in vms.
+func (m *LocalManager) ReleaseStaleImageRefs(ctx context.Context, vms map[string]bool) error {
+	return m.catalog.releaseWhere(func(r Ref) bool {
+		return (r.Kind == "vm" || r.Kind == "cdrom") && !vms[r.ID]
+	})
+}
+
+// pinImage keeps image, when it is a stored image, from being deleted while
+// volume is cloned from it.
+func (m *LocalManager) pinImage(image, volume string) (func(), error) {
+	name, ok := m.resolveImageName(image)
+	if !ok {
+		return func() {}, nil
+	}
+	return m.catalog.pin(name, filepath.Join(m.imagesDir, name), volume)
+}
+
+// ReleaseImageRefs drops ref, matched by kind and id, from all images.
+func (m *LocalManager) ReleaseImageRefs(ctx context.Context, ref Ref) error {
+	return m.catalog.releaseRef(ref)
+}
+
+// resolveImageName maps an image name or a path inside the images directory
+// to the name of an existing image.
+func (m *LocalManager) resolveImageName(image string) (string, bool) {
+	name := image
+	if strings.ContainsRune(image, filepath.Separator) {
+		if filepath.Dir(filepath.Clean(image)) != filepath.Clean(m.imagesDir) {
+			return "", false
+		}
+		name = filepath.Base(image)
+	}
+	path, err := m.imagePath(name)
+	if err != nil {
+		return "", false
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return name, true
+}
+
+// dependents lists what uses an image: recorded references plus any qcow2
+// overlay in a directory pool backed by it.
+func (m *LocalManager) dependents(name string) []Ref {
+	meta, _ := m.catalog.lookup(name)
+	shared := meta.SHA256 != "" && m.catalog.digestUsers(meta.SHA256) > 1
+	return m.withOverlays(name, meta.Refs, shared)
+}
+
+// withOverlays adds to refs, the references of the image name, the volumes
+// that are overlays of it without a reference of their own. Overlays back
+// onto the blob that every name of the content links to, so they only count
+// against the last name: while the content is shared, another name keeps it.
+func (m *LocalManager) withOverlays(name string, refs []Ref, shared bool) []Ref {
+	if shared {
+		return refs
+	}
+	path := filepath.Join(m.imagesDir, name)
+	for _, disk := range m.overlaysOf(path) {
+		known := false
+		for _, r := range refs {
+			if r.Kind == "volume" && r.ID == disk {
+				known = true
+				break
+			}
+		}
+		if !known {
+			refs = append(refs, Ref{Kind: "volume", ID: disk})
+		}
+	}
+	return refs
+}
+
+// overlaysOf returns the names of volumes in directory pools whose qcow2
+// backing file is path, or the blob it links to. Block device pools hold
+// full copies and never depend on the image.
+func (m *LocalManager) overlaysOf(path string) []string {
+	m.mu.Lock()
+	var dirs []string
+	for _, b := range m.pools {
+		if d, ok := b.(*dirBackend); ok {
+			dirs = append(dirs, d.dir)
+		}
+	}
+	m.mu.Unlock()
+	var out []string
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			hdr, err := readQcow2Header(filepath.Join(dir, e.Name()))
+			if err != nil || hdr.BackingFile == "" {
+				continue
+			}
+			if sameFile(hdr.BackingFile, path) {
+				out = append(out, e.Name())
+			}
+		}
+	}
+	return out
+}
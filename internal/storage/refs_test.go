@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestManager returns a manager with a directory pool named default.
+func newTestManager(t *testing.T) (*LocalManager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	m, err := NewLocalManager(filepath.Join(dir, "images"), filepath.Join(dir, "state"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := filepath.Join(dir, "pool")
+	if err := os.Mkdir(pool, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	m.AddPool("default", &dirBackend{dir: pool, run: &fakeRunner{}}, true)
+	return m, pool
+}
+
+// uploadImage stores data as the image name and returns its digest.
+func uploadImage(t *testing.T, m *LocalManager, name string, data []byte) string {
+	t.Helper()
+	u, err := m.BeginUpload(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := u.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	digest := fmt.Sprintf("%x", sha256.Sum256(data))
+	if _, err := u.Commit(digest); err != nil {
+		t.Fatal(err)
+	}
+	return digest
+}
+
+// writeOverlay writes a qcow2 header backed by backing, as qemu-img create -b
+// would.
+func writeOverlay(t *testing.T, path, backing string) {
+	t.Helper()
+	hdr := make([]byte, 512)
+	copy(hdr, "QFI\xfb")
+	binary.BigEndian.PutUint32(hdr[4:], 3)
+	binary.BigEndian.PutUint64(hdr[8:], 128)
+	binary.BigEndian.PutUint32(hdr[16:], uint32(len(backing)))
+	binary.BigEndian.PutUint64(hdr[24:], 1<<30)
+	copy(hdr[128:], backing)
+	if err := os.WriteFile(path, hdr, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeleteImageWithOverlays(t *testing.T) {
+	ctx := context.Background()
+	m, pool := newTestManager(t)
+	digest := uploadImage(t, m, "debian-13.raw", []byte("root filesystem"))
+	if _, err := m.TagImage(ctx, "debian-13.raw", "debian-13-2026-10.raw"); err != nil {
+		t.Fatal(err)
+	}
+	// clones are overlays backed by the blob both names link to
+	writeOverlay(t, filepath.Join(pool, "vm-root"), m.blobPath(digest))
+
+	// another name keeps the content, so a tag can be retired
+	if err := m.DeleteImage(ctx, "debian-13-2026-10.raw", false); err != nil {
+		t.Fatalf("delete tag: %v", err)
+	}
+	img, err := m.GetImage(ctx, "debian-13.raw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.UsedBy != 1 {
+		t.Errorf("last name used by %d, want the overlay", img.UsedBy)
+	}
+
+	// the last name is in use by the overlay
+	var inUse *ImageInUseError
+	if err := m.DeleteImage(ctx, "debian-13.raw", false); !errors.As(err, &inUse) {
+		t.Fatalf("delete last name: got %v, want ImageInUseError", err)
+	}
+
+	// forcing removes the name but leaves the content the overlay needs
+	if err := m.DeleteImage(ctx, "debian-13.raw", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(m.blobPath(digest)); err != nil {
+		t.Fatalf("blob removed under its overlay: %v", err)
+	}
+
+	// once the overlay is gone, the blob goes too
+	if err := os.Remove(filepath.Join(pool, "vm-root")); err != nil {
+		t.Fatal(err)
+	}
+	m.pruneBlob(digest)
+	if _, err := os.Stat(m.blobPath(digest)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("blob kept without users: %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// seedSuffix ends the file names of the seed ISOs CreateSeed writes.
+const seedSuffix = "-seed.iso"
+
+// SeedSpec describes a cloud-init NoCloud seed: an ISO labelled cidata
+// holding Files, such as meta-data, user-data and network-config. It is
+// written as <Name>-seed.iso to Pool when that is a directory pool, else to
+// the first directory pool.
+type SeedSpec struct {
+	Name  string
+	Pool  string
+	Files map[string][]byte
+}
+
+// CreateSeed builds the seed ISO described by spec with genisoimage,
+// replacing an older seed of the same name, and returns its path.
+func (m *LocalManager) CreateSeed(ctx context.Context, spec SeedSpec) (string, error) {
+	if err := validVolumeName(spec.Name + seedSuffix); err != nil {
+		return "", err
+	}
+	d, err := m.seedPool(spec.Pool)
+	if err != nil {
+		return "", err
+	}
+	staging, err := os.MkdirTemp("", "deusvm-seed-")
+	if err != nil {
+		return "", fmt.Errorf("seed staging: %w", err)
+	}
+	defer os.RemoveAll(staging)
+	names := make([]string, 0, len(spec.Files))
+	for name := range spec.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	files := make([]string, 0, len(names))
+	for _, name := range names {
+		if name == "" || strings.ContainsRune(name, filepath.Separator) {
+			return "", fmt.Errorf("invalid seed file name %q", name)
+		}
+		path := filepath.Join(staging, name)
+		if err := os.WriteFile(path, spec.Files[name], 0o600); err != nil {
+			return "", fmt.Errorf("write %s: %w", name, err)
+		}
+		files = append(files, path)
+	}
+	path := filepath.Join(d.dir, spec.Name+seedSuffix)
+	// garbage collection skips dot files, so it never takes a seed being built
+	tmp := filepath.Join(d.dir, "."+spec.Name+seedSuffix)
+	args := append([]string{"-quiet", "-output", tmp, "-volid", "cidata", "-joliet", "-rock"}, files...)
+	if _, err := m.run.Run(ctx, "genisoimage", args...); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("build seed iso: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("rename: %w", err)
+	}
+	return path, nil
+}
+
+// DeleteSeed removes the seed ISO at path. Paths that are not seeds in a
+// directory pool, such as installer ISOs in the images directory, are left
+// alone.
+func (m *LocalManager) DeleteSeed(ctx context.Context, path string) error {
+	if !strings.HasSuffix(path, seedSuffix) {
+		return nil
+	}
+	m.mu.Lock()
+	var ok bool
+	for _, b := range m.pools {
+		if d, isDir := b.(*dirBackend); isDir && filepath.Dir(filepath.Clean(path)) == filepath.Clean(d.dir) {
+			ok = true
+		}
+	}
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove seed: %w", err)
+	}
+	return nil
+}
+
+// seedPool returns the directory pool seeds for VMs on pool go to.
+func (m *LocalManager) seedPool(pool string) (*dirBackend, error) {
+	if _, b, err := m.pool(pool); err == nil {
+		if d, ok := b.(*dirBackend); ok {
+			return d, nil
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.pools))
+	for name := range m.pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if d, ok := m.pools[name].(*dirBackend); ok {
+			return d, nil
+		}
+	}
+	return nil, errors.New("seed ISOs need a directory pool")
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// isoRunner stands in for genisoimage, writing the files it was given to
+// the output path.
+type isoRunner struct {
+	fakeRunner
+}
+
+func (r *isoRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	out, err := r.fakeRunner.Run(ctx, name, args...)
+	if err != nil || name != "genisoimage" {
+		return out, err
+	}
+	var content []string
+	for _, f := range args[7:] {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, filepath.Base(f)+"="+string(b))
+	}
+	return nil, os.WriteFile(args[2], []byte(strings.Join(content, ";")), 0o644)
+}
+
+func TestCreateSeed(t *testing.T) {
+	ctx := context.Background()
+	m, pool := newTestManager(t)
+	m.AddPool("fast", &lvmThinBackend{vg: "vg0", thinPool: "thin", run: &fakeRunner{}}, false)
+	run := &isoRunner{}
+	m.run = run
+
+	// seeds of VMs on block pools go to a directory pool
+	path, err := m.CreateSeed(ctx, SeedSpec{Name: "web-01", Pool: "fast", Files: map[string][]byte{
+		"user-data": []byte("#cloud-config\n"),
+		"meta-data": []byte("local-hostname: web-01\n"),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(pool, "web-01-seed.iso"); path != want {
+		t.Errorf("seed at %s, want %s", path, want)
+	}
+	if len(run.calls) != 1 || !strings.HasPrefix(run.calls[0], "genisoimage -quiet -output "+filepath.Join(pool, ".web-01-seed.iso")+" -volid cidata -joliet -rock ") {
+		t.Errorf("commands: %v", run.calls)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "meta-data=local-hostname: web-01\n;user-data=#cloud-config\n"; string(b) != want {
+		t.Errorf("seed holds %q, want %q", b, want)
+	}
+
+	// a failed build leaves no file behind
+	run.fail = "genisoimage"
+	if _, err := m.CreateSeed(ctx, SeedSpec{Name: "web-02", Files: map[string][]byte{"meta-data": nil}}); err == nil {
+		t.Error("failed build succeeded")
+	}
+	if entries, _ := os.ReadDir(pool); len(entries) != 1 {
+		t.Errorf("pool holds %d files after a failed build, want the first seed only", len(entries))
+	}
+
+	// only seeds in directory pools are deleted
+	iso := filepath.Join(m.imagesDir, "installer-seed.iso")
+	if err := os.WriteFile(iso, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{path, iso, ""} {
+		if err := m.DeleteSeed(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("seed kept: %v", err)
+	}
+	if _, err := os.Stat(iso); err != nil {
+		t.Errorf("iso outside the pools removed: %v", err)
+	}
+}
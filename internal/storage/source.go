@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// ErrSourceNotAllowed is returned for image sources the daemon may not read,
+// such as local files outside the import directories.
+var ErrSourceNotAllowed = errors.New("image source not allowed")
+
+// SourceInfo describes a remote object. ETag and LastModified identify its
+// content, so that a resumed transfer only continues the same object.
+type SourceInfo struct {
+	// Size is the length of the whole object, -1 when unknown.
+	Size         int64
+	ETag         string
+	LastModified string
+	// SHA256 is the expected checksum of the object, when the source knows it.
+	SHA256 string
+}
+
+// Source fetches image content for one URL scheme.
+//
+// Open returns the object at u starting at offset, along with a description
+// of the whole object. When offset is not zero, prev describes the object the
+// earlier bytes came from; if it changed, or the source cannot resume,
+// Open fails with errRestart. Failures Open marks as permanent are not
+// retried.
+type Source interface {
+	Open(ctx context.Context, u *url.URL, offset int64, prev SourceInfo) (io.ReadCloser, SourceInfo, error)
+}
+
+// RegisterSource makes SaveImageFromURL fetch URLs with the given scheme
+// from src. http and https are registered by NewLocalManager.
+func (m *LocalManager) RegisterSource(scheme string, src Source) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sources[scheme] = src
+}
+
+// source parses sourceURL and picks the Source for its scheme.
+func (m *LocalManager) source(sourceURL string) (Source, *url.URL, error) {
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse source: %w", err)
+	}
+	m.mu.Lock()
+	src, ok := m.sources[u.Scheme]
+	m.mu.Unlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported source scheme %q", u.Scheme)
+	}
+	return src, u, nil
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fileSource reads file:// URLs, but only below the configured import
+// directories so that API clients cannot make the daemon copy arbitrary
+// host files into the image store.
+type fileSource struct {
+	dirs []string
+}
+
+// NewFileSource returns a Source for file:// URLs restricted to dirs. With no
+// dirs, every file URL is refused.
+func NewFileSource(dirs []string) Source {
+	return &fileSource{dirs: dirs}
+}
+
+func (s *fileSource) Open(ctx context.Context, u *url.URL, offset int64, prev SourceInfo) (io.ReadCloser, SourceInfo, error) {
+	if u.Host != "" && u.Host != "localhost" {
+		return nil, SourceInfo{}, permanent(fmt.Errorf("file url must not name host %q", u.Host))
+	}
+	path, err := s.resolve(filepath.FromSlash(u.Path))
+	if err != nil {
+		return nil, SourceInfo{}, permanent(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, SourceInfo{}, permanent(fmt.Errorf("open: %w", err))
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, SourceInfo{}, permanent(fmt.Errorf("stat: %w", err))
+	}
+	if !fi.Mode().IsRegular() {
+		f.Close()
+		return nil, SourceInfo{}, permanent(fmt.Errorf("%s is not a regular file", u.Path))
+	}
+	info := SourceInfo{Size: fi.Size(), LastModified: fi.ModTime().UTC().Format(time.RFC3339Nano)}
+	if offset > 0 && (info.LastModified != prev.LastModified || info.Size != prev.Size) {
+		f.Close()
+		return nil, SourceInfo{}, permanent(errRestart)
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, SourceInfo{}, permanent(fmt.Errorf("seek: %w", err))
+	}
+	return f, info, nil
+}
+
+// resolve follows symlinks in path and checks that both the path and the
+// file it ends up at lie inside one of the import directories.
+func (s *fileSource) resolve(path string) (string, error) {
+	if len(s.dirs) == 0 {
+		return "", fmt.Errorf("no import directories configured: %w", ErrSourceNotAllowed)
+	}
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("file url path %q is not absolute", path)
+	}
+	path = filepath.Clean(path)
+	if !s.within(path, false) {
+		return "", fmt.Errorf("%s is outside the import directories: %w", path, ErrSourceNotAllowed)
+	}
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	if !s.within(real, true) {
+		return "", fmt.Errorf("%s is outside the import directories: %w", path, ErrSourceNotAllowed)
+	}
+	return real, nil
+}
+
+// within reports whether path is below one of the import directories, whose
+// symlinks are resolved first when real is set.
+func (s *fileSource) within(path string, real bool) bool {
+	for _, dir := range s.dirs {
+		dir = filepath.Clean(dir)
+		if real {
+			var err error
+			if dir, err = filepath.EvalSymlinks(dir); err != nil {
+				continue
+			}
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSource(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	imports := filepath.Join(dir, "imports")
+	if err := os.Mkdir(imports, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(path string) {
+		if err := os.WriteFile(path, []byte("disk"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(imports, "debian-13.qcow2"))
+	write(filepath.Join(dir, "shadow"))
+	if err := os.Symlink(filepath.Join(dir, "shadow"), filepath.Join(imports, "escape.qcow2")); err != nil {
+		t.Fatal(err)
+	}
+	src := NewFileSource([]string{imports})
+
+	u := &url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Join(imports, "debian-13.qcow2"))}
+	body, info, err := src.Open(ctx, u, 0, SourceInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(body)
+	body.Close()
+	if string(b) != "disk" || info.Size != 4 {
+		t.Errorf("got %q %+v", b, info)
+	}
+
+	refused := []struct {
+		name string
+		src  Source
+		path string
+	}{
+		{name: "outside", src: src, path: filepath.Join(dir, "shadow")},
+		{name: "dot-dot", src: src, path: imports + "/../shadow"},
+		{name: "symlink out", src: src, path: filepath.Join(imports, "escape.qcow2")},
+		{name: "the directory itself", src: src, path: imports},
+		{name: "no import dirs", src: NewFileSource(nil), path: filepath.Join(imports, "debian-13.qcow2")},
+	}
+	for _, tt := range refused {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tt.src.Open(ctx, &url.URL{Scheme: "file", Path: filepath.ToSlash(tt.path)}, 0, SourceInfo{})
+			if !errors.Is(err, ErrSourceNotAllowed) || isRetryable(err) {
+				t.Errorf("got %v, want a permanent ErrSourceNotAllowed", err)
+			}
+		})
+	}
+
+	u.Host = "fileserver"
+	if _, _, err := src.Open(ctx, u, 0, SourceInfo{}); err == nil {
+		t.Error("a file url naming another host was accepted")
+	}
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// httpSource fetches http and https URLs. Resumed transfers use a Range
+// request; If-Range makes the server send the whole object again if it
+// changed, which is reported as errRestart.
+type httpSource struct {
+	client *http.Client
+}
+
+// NewHTTPSource returns a Source for http and https URLs using client.
+func NewHTTPSource(client *http.Client) Source {
+	return &httpSource{client: client}
+}
+
+func (s *httpSource) Open(ctx context.Context, u *url.URL, offset int64, prev SourceInfo) (io.ReadCloser, SourceInfo, error) {
+	return s.get(ctx, u.String(), nil, offset, prev)
+}
+
+// get requests rawURL from offset with the extra header, if any.
+func (s *httpSource) get(ctx context.Context, rawURL string, header http.Header, offset int64, prev SourceInfo) (io.ReadCloser, SourceInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, SourceInfo{}, permanent(fmt.Errorf("new request: %w", err))
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		if v := validator(prev); v != "" {
+			req.Header.Set("If-Range", v)
+		}
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, SourceInfo{}, fmt.Errorf("download: %w", err)
+	}
+	info := prev
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		start, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			resp.Body.Close()
+			return nil, SourceInfo{}, permanent(fmt.Errorf("unexpected content range %q", resp.Header.Get("Content-Range")))
+		}
+		if etag := resp.Header.Get("ETag"); etag != "" && prev.ETag != "" && etag != prev.ETag {
+			resp.Body.Close()
+			return nil, SourceInfo{}, permanent(errRestart)
+		}
+		info.Size = total
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		resp.Body.Close()
+		// nothing is left past offset when the part already holds the whole
+		// unchanged object, which then only needs committing
+		total := prev.Size
+		if t, ok := parseUnsatisfiedRange(resp.Header.Get("Content-Range")); ok {
+			total = t
+		}
+		etag := resp.Header.Get("ETag")
+		if total != offset || (etag != "" && prev.ETag != "" && etag != prev.ETag) {
+			return nil, SourceInfo{}, permanent(errRestart)
+		}
+		info.Size = total
+		return io.NopCloser(strings.NewReader("")), info, nil
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		if offset > 0 {
+			// Range ignored or If-Range failed: the object must be fetched again.
+			resp.Body.Close()
+			return nil, SourceInfo{}, permanent(errRestart)
+		}
+		info = SourceInfo{
+			Size:         resp.ContentLength,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		}
+	default:
+		resp.Body.Close()
+		err := fmt.Errorf("download status: %s", resp.Status)
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout {
+			return nil, SourceInfo{}, err
+		}
+		return nil, SourceInfo{}, permanent(err)
+	}
+	return resp.Body, info, nil
+}
+
+// validator picks the header value for If-Range. Weak ETags are not allowed
+// there, so Last-Modified is used instead.
+func validator(info SourceInfo) string {
+	if info.ETag != "" && !strings.HasPrefix(info.ETag, "W/") {
+		return info.ETag
+	}
+	return info.LastModified
+}
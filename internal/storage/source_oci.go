@@ -0,0 +1,344 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/riccardotacconi/deusvm/internal/config"
+)
+
+const (
+	mediaTypeOCIManifest    = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeOCIIndex       = "application/vnd.oci.image.index.v1+json"
+	mediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerList     = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	// annotationTitle names the file a layer was pushed from, e.g. by oras.
+	annotationTitle = "org.opencontainers.image.title"
+)
+
+// diskImageExts are the file name suffixes of layers that hold a disk image.
+var diskImageExts = []string{".qcow2", ".raw", ".img", ".iso", ".vmdk", ".vhdx"}
+
+// ociSource reads disk images stored as OCI artifacts:
+// oci://registry/repository[:tag|@digest][#layer-title]. The layer holding
+// the disk image is found by its title annotation or media type; the fragment
+// picks one by title when there are several. Layers are addressed by digest,
+// so a resumed transfer only continues while the tag still points at the
+// same layer, and the content is checked against that digest.
+type ociSource struct {
+	http       *httpSource
+	registries map[string]config.RegistryConfig
+
+	mu     sync.Mutex
+	tokens map[string]string // registry/repository -> bearer token
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Platform    *struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+	} `json:"platform,omitempty"`
+}
+
+// ociManifest covers both image manifests (Layers) and indexes (Manifests).
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Manifests []ociDescriptor `json:"manifests"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+// NewOCISource returns a Source for oci:// URLs. Registries listed in cfg get
+// their credentials and transport settings; others are accessed anonymously.
+func NewOCISource(cfg config.OCIConfig, client *http.Client) Source {
+	regs := make(map[string]config.RegistryConfig, len(cfg.Registries))
+	for _, r := range cfg.Registries {
+		regs[r.Host] = r
+	}
+	return &ociSource{http: &httpSource{client: client}, registries: regs, tokens: make(map[string]string)}
+}
+
+func (s *ociSource) Open(ctx context.Context, u *url.URL, offset int64, prev SourceInfo) (io.ReadCloser, SourceInfo, error) {
+	repo, ref, err := parseOCIReference(u)
+	if err != nil {
+		return nil, SourceInfo{}, permanent(err)
+	}
+	layer, err := s.resolve(ctx, u.Host, repo, ref, u.Fragment)
+	if err != nil {
+		return nil, SourceInfo{}, err
+	}
+	if offset > 0 && prev.ETag != layer.Digest {
+		return nil, SourceInfo{}, permanent(errRestart)
+	}
+	body, _, err := s.http.get(ctx, s.endpoint(u.Host, repo, "blobs", layer.Digest), s.authHeader(u.Host, repo), offset, SourceInfo{})
+	if err != nil {
+		return nil, SourceInfo{}, err
+	}
+	info := SourceInfo{Size: layer.Size, ETag: layer.Digest}
+	if sum, ok := strings.CutPrefix(layer.Digest, "sha256:"); ok {
+		info.SHA256 = sum
+	}
+	return body, info, nil
+}
+
+// parseOCIReference splits the URL path into repository and tag or digest.
+// The tag defaults to latest.
+func parseOCIReference(u *url.URL) (repo, ref string, err error) {
+	path := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || path == "" {
+		return "", "", fmt.Errorf("oci url %q must be oci://registry/repository[:tag|@digest]", u.String())
+	}
+	if repo, digest, ok := strings.Cut(path, "@"); ok {
+		return repo, digest, nil
+	}
+	slash := strings.LastIndex(path, "/")
+	if colon := strings.LastIndex(path, ":"); colon > slash {
+		return path[:colon], path[colon+1:], nil
+	}
+	return path, "latest", nil
+}
+
+// resolve fetches the manifest for ref, following an index to the manifest
+// for this platform, and picks the disk image layer.
+func (s *ociSource) resolve(ctx context.Context, host, repo, ref, title string) (ociDescriptor, error) {
+	m, err := s.manifest(ctx, host, repo, ref)
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+	if len(m.Manifests) > 0 {
+		d, err := platformManifest(m.Manifests)
+		if err != nil {
+			return ociDescriptor{}, permanent(err)
+		}
+		if m, err = s.manifest(ctx, host, repo, d.Digest); err != nil {
+			return ociDescriptor{}, err
+		}
+	}
+	layer, err := diskLayer(m.Layers, title)
+	if err != nil {
+		return ociDescriptor{}, permanent(fmt.Errorf("%s/%s:%s: %w", host, repo, ref, err))
+	}
+	return layer, nil
+}
+
+func (s *ociSource) manifest(ctx context.Context, host, repo, ref string) (ociManifest, error) {
+	resp, err := s.do(ctx, host, repo, s.endpoint(host, repo, "manifests", ref),
+		strings.Join([]string{mediaTypeOCIManifest, mediaTypeOCIIndex, mediaTypeDockerManifest, mediaTypeDockerList}, ", "))
+	if err != nil {
+		return ociManifest{}, err
+	}
+	defer resp.Body.Close()
+	var m ociManifest
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&m); err != nil {
+		return ociManifest{}, permanent(fmt.Errorf("decode manifest: %w", err))
+	}
+	return m, nil
+}
+
+// do sends an authenticated GET to the registry. On a bearer challenge it
+// fetches a pull token for the repository and tries once more.
+func (s *ociSource) do(ctx context.Context, host, repo, rawURL, accept string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return nil, permanent(fmt.Errorf("new request: %w", err))
+		}
+		for k, v := range s.authHeader(host, repo) {
+			req.Header[k] = v
+		}
+		req.Header.Set("Accept", accept)
+		resp, err := s.http.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("registry: %w", err)
+		}
+		switch {
+		case resp.StatusCode == http.StatusOK:
+			return resp, nil
+		case resp.StatusCode == http.StatusUnauthorized && attempt == 0:
+			challenge := resp.Header.Get("WWW-Authenticate")
+			resp.Body.Close()
+			if err := s.login(ctx, host, repo, challenge); err != nil {
+				return nil, err
+			}
+		default:
+			resp.Body.Close()
+			err := fmt.Errorf("registry status: %s", resp.Status)
+			if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+				return nil, err
+			}
+			return nil, permanent(err)
+		}
+	}
+}
+
+// login answers a WWW-Authenticate challenge. Basic challenges use the
+// configured credentials directly; bearer challenges exchange them for a
+// token at the realm.
+func (s *ociSource) login(ctx context.Context, host, repo, challenge string) error {
+	reg := s.registries[host]
+	scheme, params := parseChallenge(challenge)
+	switch scheme {
+	case "basic":
+		if reg.Username == "" {
+			return permanent(fmt.Errorf("registry %s requires credentials", host))
+		}
+		return nil
+	case "bearer":
+	default:
+		return permanent(fmt.Errorf("registry %s: unsupported auth challenge %q", host, challenge))
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return permanent(fmt.Errorf("registry %s: bad token realm %q", host, params["realm"]))
+	}
+	q := realm.Query()
+	if params["service"] != "" {
+		q.Set("service", params["service"])
+	}
+	q.Set("scope", "repository:"+repo+":pull")
+	realm.RawQuery = q.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return permanent(fmt.Errorf("new request: %w", err))
+	}
+	if reg.Username != "" {
+		req.SetBasicAuth(reg.Username, reg.Password)
+	}
+	resp, err := s.http.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("registry token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("registry token status: %s", resp.Status)
+		if resp.StatusCode >= 500 {
+			return err
+		}
+		return permanent(err)
+	}
+	var tok struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&tok); err != nil {
+		return permanent(fmt.Errorf("decode token: %w", err))
+	}
+	if tok.Token == "" {
+		tok.Token = tok.AccessToken
+	}
+	if tok.Token == "" {
+		return permanent(errors.New("registry returned an empty token"))
+	}
+	s.mu.Lock()
+	s.tokens[host+"/"+repo] = tok.Token
+	s.mu.Unlock()
+	return nil
+}
+
+// authHeader returns the Authorization header for the repository: a cached
+// bearer token, else the configured basic credentials, else none.
+func (s *ociSource) authHeader(host, repo string) http.Header {
+	s.mu.Lock()
+	tok := s.tokens[host+"/"+repo]
+	s.mu.Unlock()
+	h := http.Header{}
+	if tok != "" {
+		h.Set("Authorization", "Bearer "+tok)
+	} else if reg := s.registries[host]; reg.Username != "" {
+		h.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(reg.Username+":"+reg.Password)))
+	}
+	return h
+}
+
+func (s *ociSource) endpoint(host, repo, kind, ref string) string {
+	scheme := "https"
+	if s.registries[host].Insecure {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/v2/%s/%s/%s", scheme, host, repo, kind, ref)
+}
+
+// parseChallenge splits a WWW-Authenticate header such as
+// `Bearer realm="https://auth/token",service="registry"`.
+func parseChallenge(v string) (scheme string, params map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(v), " ")
+	params = make(map[string]string)
+	for rest != "" {
+		var key, val string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, ", "), "=")
+		if strings.HasPrefix(rest, `"`) {
+			val, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			val, rest, _ = strings.Cut(rest, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = val
+	}
+	return strings.ToLower(scheme), params
+}
+
+// platformManifest picks the index entry for this host's platform, or the
+// only entry when there is just one.
+func platformManifest(ds []ociDescriptor) (ociDescriptor, error) {
+	if len(ds) == 1 {
+		return ds[0], nil
+	}
+	for _, d := range ds {
+		if d.Platform != nil && d.Platform.OS == "linux" && d.Platform.Architecture == runtime.GOARCH {
+			return d, nil
+		}
+	}
+	return ociDescriptor{}, fmt.Errorf("index has no manifest for linux/%s", runtime.GOARCH)
+}
+
+// diskLayer picks the layer holding the disk image: the one titled title if
+// given, else the only layer that looks like a disk image.
+func diskLayer(layers []ociDescriptor, title string) (ociDescriptor, error) {
+	var found []ociDescriptor
+	for _, l := range layers {
+		if title != "" {
+			if l.Annotations[annotationTitle] == title {
+				return l, nil
+			}
+			continue
+		}
+		if isDiskLayer(l) {
+			found = append(found, l)
+		}
+	}
+	switch {
+	case title != "":
+		return ociDescriptor{}, fmt.Errorf("no layer titled %q", title)
+	case len(found) == 1:
+		return found[0], nil
+	case len(found) == 0 && len(layers) == 1 && layers[0].Annotations[annotationTitle] != "":
+		return layers[0], nil
+	case len(found) == 0:
+		return ociDescriptor{}, errors.New("no disk image layer")
+	default:
+		return ociDescriptor{}, fmt.Errorf("%d disk image layers; pick one with #<title>", len(found))
+	}
+}
+
+func isDiskLayer(l ociDescriptor) bool {
+	title := strings.ToLower(trimCompressionExt(l.Annotations[annotationTitle]))
+	for _, ext := range diskImageExts {
+		if strings.HasSuffix(title, ext) {
+			return true
+		}
+	}
+	mt := strings.ToLower(l.MediaType)
+	return strings.Contains(mt, "qcow2") || strings.Contains(mt, "disk") || strings.Contains(mt, "iso9660")
+}
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/riccardotacconi/deusvm/internal/config"
+)
+
+// fakeRegistry serves manifests by tag or digest and blobs by digest under
+// the distribution API paths of a single repository.
+type fakeRegistry struct {
+	repo      string
+	manifests map[string]any
+	blobs     map[string]string
+}
+
+func (r *fakeRegistry) blob(data string) string {
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(data)))
+	r.blobs[digest] = data
+	return digest
+}
+
+func (r *fakeRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	rest, ok := strings.CutPrefix(req.URL.Path, "/v2/"+r.repo+"/")
+	kind, ref, _ := strings.Cut(rest, "/")
+	switch {
+	case ok && kind == "manifests" && r.manifests[ref] != nil:
+		w.Header().Set("Content-Type", mediaTypeOCIManifest)
+		json.NewEncoder(w).Encode(r.manifests[ref])
+	case ok && kind == "blobs" && r.blobs[ref] != "":
+		http.ServeContent(w, req, "", time.Time{}, strings.NewReader(r.blobs[ref]))
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func titled(mediaType, digest, title string, size int) ociDescriptor {
+	d := ociDescriptor{MediaType: mediaType, Digest: digest, Size: int64(size)}
+	if title != "" {
+		d.Annotations = map[string]string{annotationTitle: title}
+	}
+	return d
+}
+
+func TestOCISource(t *testing.T) {
+	ctx := context.Background()
+	reg := &fakeRegistry{repo: "images/debian", manifests: map[string]any{}, blobs: map[string]string{}}
+	srv := httptest.NewServer(reg)
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+	src := NewOCISource(config.OCIConfig{Registries: []config.RegistryConfig{{Host: host, Insecure: true}}}, srv.Client())
+
+	disk := reg.blob("qcow2 disk")
+	readme := reg.blob("# Debian 13")
+	reg.manifests["13"] = ociManifest{MediaType: mediaTypeOCIManifest, Layers: []ociDescriptor{
+		titled("text/markdown", readme, "README.md", 11),
+		titled("application/octet-stream", disk, "debian-13.qcow2.zst", 10),
+	}}
+	arm := reg.blob("other platform")
+	armManifest := reg.blob("arm manifest")
+	reg.manifests[armManifest] = ociManifest{Layers: []ociDescriptor{titled("application/vnd.qemu.qcow2", arm, "", 14)}}
+	hostManifest := reg.blob("host manifest")
+	reg.manifests[hostManifest] = reg.manifests["13"]
+	otherArch := "arm64"
+	if runtime.GOARCH == otherArch {
+		otherArch = "amd64"
+	}
+	reg.manifests["multi"] = map[string]any{"mediaType": mediaTypeOCIIndex, "manifests": []map[string]any{
+		{"mediaType": mediaTypeOCIManifest, "digest": armManifest, "platform": map[string]string{"os": "linux", "architecture": otherArch}},
+		{"mediaType": mediaTypeOCIManifest, "digest": hostManifest, "platform": map[string]string{"os": "linux", "architecture": runtime.GOARCH}},
+	}}
+
+	tests := []struct {
+		name string
+		ref  string
+		want string
+	}{
+		{name: "disk layer by title extension", ref: "images/debian:13", want: "qcow2 disk"},
+		{name: "fragment picks a layer", ref: "images/debian:13#README.md", want: "# Debian 13"},
+		{name: "index follows this platform", ref: "images/debian:multi", want: "qcow2 disk"},
+		{name: "pinned by digest", ref: "images/debian@" + armManifest, want: "other platform"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse("oci://" + host + "/" + tt.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, info, err := src.Open(ctx, u, 0, SourceInfo{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer body.Close()
+			b, _ := io.ReadAll(body)
+			if string(b) != tt.want {
+				t.Errorf("got %q, want %q", b, tt.want)
+			}
+			if sum := fmt.Sprintf("%x", sha256.Sum256(b)); info.SHA256 != sum || info.ETag != "sha256:"+sum {
+				t.Errorf("info %+v does not name the layer digest %s", info, sum)
+			}
+		})
+	}
+
+	// a tag moved to other content cannot be resumed
+	u, _ := url.Parse("oci://" + host + "/images/debian:13")
+	if _, _, err := src.Open(ctx, u, 4, SourceInfo{Size: 14, ETag: arm}); !errors.Is(err, errRestart) {
+		t.Errorf("resume after the tag moved: got %v, want errRestart", err)
+	}
+	body, _, err := src.Open(ctx, u, 6, SourceInfo{Size: 10, ETag: disk})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(body)
+	body.Close()
+	if string(b) != "disk" {
+		t.Errorf("resume got %q, want the rest of the layer", b)
+	}
+
+	u, _ = url.Parse("oci://" + host + "/images/debian:13#missing.qcow2")
+	if _, _, err := src.Open(ctx, u, 0, SourceInfo{}); err == nil || isRetryable(err) {
+		t.Errorf("unknown title: got %v, want a permanent error", err)
+	}
+}
+
+func TestDiskLayer(t *testing.T) {
+	qcow := titled("application/octet-stream", "sha256:a", "debian.qcow2", 1)
+	iso := titled("application/octet-stream", "sha256:b", "seed.iso.gz", 1)
+	byType := titled("application/vnd.deusvm.disk.raw", "sha256:c", "", 1)
+	notes := titled("text/plain", "sha256:d", "NOTES.txt", 1)
+
+	tests := []struct {
+		name    string
+		layers  []ociDescriptor
+		title   string
+		want    string
+		wantErr bool
+	}{
+		{name: "by extension", layers: []ociDescriptor{notes, qcow}, want: "sha256:a"},
+		{name: "compressed extension", layers: []ociDescriptor{notes, iso}, want: "sha256:b"},
+		{name: "by media type", layers: []ociDescriptor{byType, notes}, want: "sha256:c"},
+		{name: "a single titled layer", layers: []ociDescriptor{notes}, want: "sha256:d"},
+		{name: "title", layers: []ociDescriptor{qcow, iso}, title: "seed.iso.gz", want: "sha256:b"},
+		{name: "several disks", layers: []ociDescriptor{qcow, iso}, wantErr: true},
+		{name: "no disk", layers: []ociDescriptor{notes, notes}, wantErr: true},
+		{name: "unknown title", layers: []ociDescriptor{qcow}, title: "other.qcow2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := diskLayer(tt.layers, tt.title)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if got.Digest != tt.want {
+				t.Errorf("picked %s, want %s", got.Digest, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/riccardotacconi/deusvm/internal/config"
+)
+
+// s3Source reads s3://bucket/key URLs from an S3-compatible object store.
+// Resumed transfers request a byte range and insist on the original ETag.
+type s3Source struct {
+	client *minio.Core
+}
+
+// NewS3Source returns a Source for s3:// URLs on the store described by cfg.
+func NewS3Source(cfg config.S3Config) (Source, error) {
+	creds := credentials.NewEnvAWS()
+	if cfg.AccessKey != "" {
+		creds = credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, "")
+	}
+	lookup := minio.BucketLookupAuto
+	if cfg.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+	client, err := minio.NewCore(cfg.Endpoint, &minio.Options{
+		Creds:        creds,
+		Secure:       !cfg.Insecure,
+		Region:       cfg.Region,
+		BucketLookup: lookup,
+		// remoteReader does its own retrying
+		MaxRetries: 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s3 client: %w", err)
+	}
+	return &s3Source{client: client}, nil
+}
+
+func (s *s3Source) Open(ctx context.Context, u *url.URL, offset int64, prev SourceInfo) (io.ReadCloser, SourceInfo, error) {
+	bucket, key := u.Host, strings.TrimPrefix(u.Path, "/")
+	if bucket == "" || key == "" {
+		return nil, SourceInfo{}, permanent(fmt.Errorf("s3 url %q must be s3://bucket/key", u.String()))
+	}
+	if offset > 0 && offset == prev.Size {
+		return s.complete(ctx, bucket, key, prev)
+	}
+	var opts minio.GetObjectOptions
+	if offset > 0 {
+		if err := opts.SetRange(offset, 0); err != nil {
+			return nil, SourceInfo{}, permanent(err)
+		}
+		if prev.ETag != "" {
+			if err := opts.SetMatchETag(prev.ETag); err != nil {
+				return nil, SourceInfo{}, permanent(err)
+			}
+		}
+	}
+	body, obj, header, err := s.client.GetObject(ctx, bucket, key, opts)
+	if err != nil {
+		return nil, SourceInfo{}, s3Error(err)
+	}
+	info := SourceInfo{Size: obj.Size, ETag: obj.ETag, LastModified: obj.LastModified.UTC().Format(http.TimeFormat)}
+	if offset > 0 {
+		start, total, ok := parseContentRange(header.Get("Content-Range"))
+		if !ok || start != offset {
+			body.Close()
+			return nil, SourceInfo{}, permanent(fmt.Errorf("unexpected content range %q", header.Get("Content-Range")))
+		}
+		info.Size = total
+	}
+	return body, info, nil
+}
+
+// complete checks that the object a part file already holds all of is
+// unchanged, so the part only needs committing; S3 refuses the empty range
+// past its end.
+func (s *s3Source) complete(ctx context.Context, bucket, key string, prev SourceInfo) (io.ReadCloser, SourceInfo, error) {
+	obj, err := s.client.StatObject(ctx, bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, SourceInfo{}, s3Error(err)
+	}
+	if obj.Size != prev.Size || (prev.ETag != "" && obj.ETag != prev.ETag) {
+		return nil, SourceInfo{}, permanent(errRestart)
+	}
+	return io.NopCloser(strings.NewReader("")), prev, nil
+}
+
+// s3Error classifies a client error: server-side and network failures are
+// retried, a changed object restarts the transfer, and the rest is permanent.
+func s3Error(err error) error {
+	var resp minio.ErrorResponse
+	if !errors.As(err, &resp) {
+		return fmt.Errorf("s3: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusPreconditionFailed, resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		return permanent(errRestart)
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("s3: %w", err)
+	default:
+		return permanent(fmt.Errorf("s3: %w", err))
+	}
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/riccardotacconi/deusvm/internal/config"
+)
+
+// fakeS3 stands in for an S3-compatible store holding one object at
+// /images/debian-13.qcow2, answering path-style requests the way MinIO does.
+type fakeS3 struct {
+	mu   sync.Mutex
+	data string
+	etag string
+}
+
+func (f *fakeS3) set(data, etag string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.data, f.etag = data, etag
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	data, etag := f.data, f.etag
+	f.mu.Unlock()
+	if r.URL.Path != "/images/debian-13.qcow2" {
+		s3Fail(w, http.StatusNotFound, "NoSuchKey")
+		return
+	}
+	if m := r.Header.Get("If-Match"); m != "" && strings.Trim(m, `"`) != etag {
+		s3Fail(w, http.StatusPreconditionFailed, "PreconditionFailed")
+		return
+	}
+	h := w.Header()
+	h.Set("ETag", `"`+etag+`"`)
+	h.Set("Last-Modified", time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat))
+	h.Set("Content-Type", "application/octet-stream")
+	body := data
+	status := http.StatusOK
+	if rng := r.Header.Get("Range"); rng != "" {
+		var start int
+		if _, err := fmt.Sscanf(rng, "bytes=%d-", &start); err != nil || start >= len(data) {
+			h.Set("Content-Range", fmt.Sprintf("bytes */%d", len(data)))
+			s3Fail(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange")
+			return
+		}
+		h.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(data)-1, len(data)))
+		body, status = data[start:], http.StatusPartialContent
+	}
+	h.Set("Content-Length", fmt.Sprint(len(body)))
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		io.WriteString(w, body)
+	}
+}
+
+func s3Fail(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message></Error>`, code, code)
+}
+
+func TestS3Source(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeS3{}
+	store.set("0123456789", "v1")
+	srv := httptest.NewServer(store)
+	defer srv.Close()
+	src, err := NewS3Source(config.S3Config{
+		Endpoint:  strings.TrimPrefix(srv.URL, "http://"),
+		Region:    "us-east-1",
+		AccessKey: "minio",
+		SecretKey: "minio123",
+		Insecure:  true,
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse("s3://images/debian-13.qcow2")
+
+	read := func(offset int64, prev SourceInfo) (string, SourceInfo, error) {
+		body, info, err := src.Open(ctx, u, offset, prev)
+		if err != nil {
+			return "", SourceInfo{}, err
+		}
+		defer body.Close()
+		b, err := io.ReadAll(body)
+		return string(b), info, err
+	}
+
+	got, full, err := read(0, SourceInfo{})
+	if err != nil {
+		t.Fatalf("full get: %v", err)
+	}
+	if got != "0123456789" || full.Size != 10 || full.ETag != "v1" {
+		t.Errorf("full get = %q %+v", got, full)
+	}
+
+	// a resume continues the same object from offset
+	got, info, err := read(4, full)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if got != "456789" || info.Size != 10 {
+		t.Errorf("resume = %q %+v, want the rest of 10 bytes", got, info)
+	}
+
+	// a part holding all of the unchanged object only needs committing
+	if got, _, err := read(10, full); err != nil || got != "" {
+		t.Errorf("complete part = %q, %v", got, err)
+	}
+
+	// once the object changed, the transfer has to start over
+	store.set("abcdefghijkl", "v2")
+	for _, offset := range []int64{4, 10} {
+		_, _, err := read(offset, full)
+		if !errors.Is(err, errRestart) || isRetryable(err) {
+			t.Errorf("resume at %d after change: got %v, want a permanent restart", offset, err)
+		}
+	}
+
+	u, _ = url.Parse("s3://images/missing.qcow2")
+	if _, _, err := read(0, SourceInfo{}); err == nil || isRetryable(err) {
+		t.Errorf("missing object: got %v, want a permanent error", err)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !unix
+
+package storage
+
+import (
+	"errors"
+	"os"
+)
+
+func fsUsage(dir string) (PoolUsage, error) {
+	return PoolUsage{}, errors.New("pool usage is not supported on this platform")
+}
+
+// allocatedBytes falls back to the file size where block counts are unknown.
+func allocatedBytes(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
@@ -0,0 +1,30 @@
+//go:build unix
+
+package storage
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// fsUsage reports the capacity of the filesystem holding dir.
+func fsUsage(dir string) (PoolUsage, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return PoolUsage{}, fmt.Errorf("statfs: %w", err)
+	}
+	bsize := int64(st.Bsize)
+	total := int64(st.Blocks) * bsize
+	free := int64(st.Bavail) * bsize
+	return PoolUsage{CapacityBytes: total, UsedBytes: total - int64(st.Bfree)*bsize, FreeBytes: free}, nil
+}
+
+// allocatedBytes reports the space a file takes on disk, which is less than
+// its size when it is sparse.
+func allocatedBytes(path string) (int64, error) {
+	var st syscall.Stat_t
+	if err := syscall.Stat(path, &st); err != nil {
+		return 0, fmt.Errorf("stat: %w", err)
+	}
+	return int64(st.Blocks) * 512, nil
+}
@@ -2,14 +2,16 @@ package storage
 
 import (
 	"context"
-	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 )
 
 type Image struct {
@@ -18,82 +20,225 @@ type Image struct {
 	Size   int64  `json:"size_bytes"`
 	Format string `json:"format"`
 	SHA256 string `json:"sha256"`
+	// Compression and SourceSHA256 describe the transferred stream when the
+	// image was decompressed on import.
+	Compression  string `json:"compression,omitempty"`
+	SourceSHA256 string `json:"source_sha256,omitempty"`
+	// Lineage is set for images captured from a VM.
+	Lineage *Lineage `json:"lineage,omitempty"`
+	// UsedBy counts the VMs and disks that depend on the image.
+	UsedBy int `json:"used_by"`
 }
 
 type Manager interface {
-	SaveImageFromURL(ctx context.Context, name, sourceURL string) (Image, error)
+	SaveImageFromURL(ctx context.Context, name, sourceURL string, progress ProgressFunc) (Image, error)
 	ListImages(ctx context.Context) ([]Image, error)
-	DeleteImage(ctx context.Context, name string) error
-	CreateDiskFromBase(ctx context.Context, baseImageName, diskName string, sizeBytes int64) (string, error)
+	// GetImage looks up an image by name or by its path in the images directory.
+	GetImage(ctx context.Context, name string) (Image, error)
+	BeginUpload(name string) (*Upload, error)
+	// DeleteImage fails with an *ImageInUseError while VMs or disks depend
+	// on the image, unless force is set.
+	DeleteImage(ctx context.Context, name string, force bool) error
+	AddImageRef(ctx context.Context, image string, ref Ref) error
+	ReleaseImageRefs(ctx context.Context, ref Ref) error
+	ReleaseStaleImageRefs(ctx context.Context, vms map[string]bool) error
+	// TagImage gives the content of an existing image another name.
+	TagImage(ctx context.Context, source, name string) (Image, error)
+	// CaptureImage stores a copy of a VM disk as a standalone qcow2 image.
+	CaptureImage(ctx context.Context, spec CaptureSpec) (Image, error)
+
+	// CreateVolume allocates a disk in a storage pool, optionally cloned from an image.
+	CreateVolume(ctx context.Context, spec VolumeSpec) (Volume, error)
+	// DeleteVolume fails with ErrVolumeInUse while the volume is attached.
+	DeleteVolume(ctx context.Context, name string) error
+	GetVolume(ctx context.Context, name string) (Volume, error)
+	ListVolumes(ctx context.Context) ([]Volume, error)
+	// ResizeVolume only grows volumes.
+	ResizeVolume(ctx context.Context, name string, sizeBytes int64) (Volume, error)
+	// CloneVolume makes an independent copy of a volume, optionally in another pool.
+	CloneVolume(ctx context.Context, source string, spec VolumeSpec) (Volume, error)
+	SetVolumeAttachment(ctx context.Context, name, vmID string) error
+	// ImportVolume creates a volume holding a copy of the disk file at path.
+	ImportVolume(ctx context.Context, spec VolumeSpec, path, format string) (Volume, error)
+	// CreateSeed writes a cloud-init NoCloud seed ISO to a directory pool and
+	// returns its path.
+	CreateSeed(ctx context.Context, spec SeedSpec) (string, error)
+	// DeleteSeed removes a seed ISO written by CreateSeed; other paths are
+	// ignored.
+	DeleteSeed(ctx context.Context, path string) error
+
+	// Convert starts a background job that changes the format of an image or
+	// volume; follow it with GetJob.
+	Convert(ctx context.Context, spec ConvertSpec) (Job, error)
+	// StartJob runs fn in the background as a job of kind on target.
+	StartJob(kind, target string, fn func(ctx context.Context, progress func(float64)) error) Job
+	GetJob(ctx context.Context, id string) (Job, error)
+	ListJobs(ctx context.Context) ([]Job, error)
+
+	// Usage reports capacity and consumption of the image store and pools.
+	Usage(ctx context.Context) (Usage, error)
+	// CollectGarbage quarantines files nothing uses any more, or only
+	// reports them with spec.DryRun.
+	CollectGarbage(ctx context.Context, spec GCSpec) (GCReport, error)
 }
 
 type LocalManager struct {
-	imagesDir string
+	mu        sync.Mutex
+	inflight  map[string]bool
+	blobLocks map[string]*blobLock
+
+	imagesDir    string
+	catalog      *catalog
+	pools        map[string]VolumeBackend
+	defaultPool  string
+	limits       map[string]Limits
+	imageLimits  Limits
+	gc           GCPolicy
+	volumes      *volumeRegistry
+	run          CommandRunner
+	jobs         *jobRegistry
+	sources      map[string]Source
+	retries      int
+	retryBackoff time.Duration
 }
 
-func NewLocalManager(imagesDir string) (*LocalManager, error) {
+// NewLocalManager stores images in imagesDir and its volume registry in
+// stateDir. Pools for volumes are registered with AddPool.
+func NewLocalManager(imagesDir, stateDir string) (*LocalManager, error) {
 	if imagesDir == "" {
 		return nil, errors.New("imagesDir required")
 	}
+	if stateDir == "" {
+		return nil, errors.New("stateDir required")
+	}
 	if err := os.MkdirAll(imagesDir, 0o755); err != nil {
 		return nil, fmt.Errorf("mkdir images: %w", err)
 	}
-	return &LocalManager{imagesDir: imagesDir}, nil
+	if err := os.MkdirAll(stateDir, 0o755); err != nil {
+		return nil, fmt.Errorf("mkdir state: %w", err)
+	}
+	cat, err := loadCatalog(imagesDir)
+	if err != nil {
+		return nil, err
+	}
+	vols, err := loadVolumeRegistry(stateDir)
+	if err != nil {
+		return nil, err
+	}
+	m := &LocalManager{
+		inflight:     make(map[string]bool),
+		blobLocks:    make(map[string]*blobLock),
+		imagesDir:    imagesDir,
+		catalog:      cat,
+		pools:        make(map[string]VolumeBackend),
+		limits:       make(map[string]Limits),
+		volumes:      vols,
+		run:          ExecRunner{},
+		jobs:         newJobRegistry(),
+		sources:      map[string]Source{"http": NewHTTPSource(http.DefaultClient), "https": NewHTTPSource(http.DefaultClient)},
+		retries:      defaultDownloadRetries,
+		retryBackoff: defaultRetryBackoff,
+	}
+	if err := m.adoptUntracked(); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
 func (m *LocalManager) imagePath(name string) (string, error) {
-	if name == "" || strings.Contains(name, "..") || strings.ContainsRune(name, filepath.Separator) {
+	if name == "" || strings.HasPrefix(name, ".") || strings.Contains(name, "..") || strings.ContainsRune(name, filepath.Separator) {
 		return "", errors.New("invalid image name")
 	}
 	return filepath.Join(m.imagesDir, name), nil
 }
 
-func (m *LocalManager) SaveImageFromURL(ctx context.Context, name, sourceURL string) (Image, error) {
+// SaveImageFromURL downloads sourceURL into the images directory, using the
+// Source registered for its scheme. Data is written to a .part file that
+// survives failures: the next call for the same name and URL resumes it as
+// long as the remote object is unchanged. Transient errors are retried with
+// backoff.
+// Compressed sources are decompressed while streaming; those cannot be
+// resumed across calls since the .part file holds decompressed data.
+func (m *LocalManager) SaveImageFromURL(ctx context.Context, name, sourceURL string, progress ProgressFunc) (Image, error) {
 	path, err := m.imagePath(name)
 	if err != nil {
 		return Image{}, err
 	}
-	// stream download to file
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	src, u, err := m.source(sourceURL)
 	if err != nil {
-		return Image{}, fmt.Errorf("new request: %w", err)
+		return Image{}, err
 	}
-	resp, err := http.DefaultClient.Do(req)
+	release, err := m.lockName(name)
 	if err != nil {
-		return Image{}, fmt.Errorf("download: %w", err)
+		return Image{}, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return Image{}, fmt.Errorf("download status: %s", resp.Status)
+	defer release()
+	for restarts := 0; ; restarts++ {
+		img, err := m.download(ctx, name, path, src, u, progress)
+		if errors.Is(err, errRestart) && restarts < m.retries {
+			removePart(path)
+			continue
+		}
+		return img, err
 	}
-	tmp := path + ".part"
-	f, err := os.Create(tmp)
-	if err != nil {
-		return Image{}, fmt.Errorf("create tmp: %w", err)
+}
+
+func (m *LocalManager) download(ctx context.Context, name, path string, src Source, u *url.URL, progress ProgressFunc) (Image, error) {
+	st, ok := loadPartState(path + partMetaSuffix)
+	resume := ok && st.URL == u.String()
+	if !resume {
+		st = partState{URL: u.String(), Total: -1}
 	}
-	defer f.Close()
-	hasher := sha256.New()
-	n, err := io.Copy(io.MultiWriter(f, hasher), resp.Body)
+	part, err := m.openPart(name, path, partSuffix, resume)
 	if err != nil {
-		_ = os.Remove(tmp)
-		return Image{}, fmt.Errorf("write: %w", err)
+		return Image{}, err
+	}
+	defer part.close()
+
+	rr := newRemoteReader(ctx, src, u, st, part.size, m.retries, m.retryBackoff)
+	defer rr.Close()
+	if err := rr.connect(); err != nil {
+		return Image{}, downloadFailed(part, err)
+	}
+	// the size is that of the transferred stream; decompressed content is
+	// bigger, which the part file keeps checking while it grows
+	if err := m.checkImageStore(max(rr.info.Size-part.size, 0)); err != nil {
+		return Image{}, downloadFailed(part, err)
+	}
+	if err := savePartState(path+partMetaSuffix, rr.state()); err != nil {
+		return Image{}, fmt.Errorf("save download state: %w", err)
+	}
+	// only a fresh transfer can start with a compression magic
+	dec := newDecoder(part, part.size == 0)
+	dec.onStart = func(kind string) {
+		if kind != "" {
+			_ = os.Remove(path + partMetaSuffix)
+		}
+	}
+	pr := &progressReporter{fn: progress, total: func() int64 { return rr.info.Size }, done: part.size}
+	pr.report()
+	if _, err := io.Copy(io.MultiWriter(dec, pr), rr); err != nil {
+		dec.abort()
+		return Image{}, downloadFailed(part, fmt.Errorf("write: %w", err))
 	}
-	if err := f.Sync(); err != nil {
-		_ = os.Remove(tmp)
-		return Image{}, fmt.Errorf("sync: %w", err)
+	if err := dec.Close(); err != nil {
+		part.abort()
+		return Image{}, err
 	}
-	if err := os.Rename(tmp, path); err != nil {
-		_ = os.Remove(tmp)
-		return Image{}, fmt.Errorf("rename: %w", err)
+	pr.report()
+	if dec.kind != "" {
+		part.compression, part.sourceSHA256 = dec.kind, dec.sum()
 	}
-	img := Image{
-		Name:   name,
-		Path:   path,
-		Size:   n,
-		Format: detectFormatByName(name),
-		SHA256: fmt.Sprintf("%x", hasher.Sum(nil)),
+	return part.commit(rr.info.SHA256)
+}
+
+// downloadFailed drops the partial file when the error leaves nothing to
+// resume; cancellations and exhausted retries keep it for the next attempt.
+func downloadFailed(part *partFile, err error) error {
+	if !errors.Is(err, errRestart) && !isRetryable(err) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		part.abort()
 	}
-	return img, nil
+	return err
 }
 
 func (m *LocalManager) ListImages(ctx context.Context) ([]Image, error) {
@@ -106,25 +251,47 @@ func (m *LocalManager) ListImages(ctx context.Context) ([]Image, error) {
 		if e.IsDir() {
 			continue
 		}
-		info, err := e.Info()
+		name := e.Name()
+		if strings.HasPrefix(name, ".") || isStaging(name) {
+			continue
+		}
+		img, err := m.image(name)
 		if err != nil {
 			continue
 		}
-		name := e.Name()
-		path := filepath.Join(m.imagesDir, name)
-		out = append(out, Image{Name: name, Path: path, Size: info.Size(), Format: detectFormatByName(name)})
+		out = append(out, img)
 	}
 	return out, nil
 }
 
-func (m *LocalManager) DeleteImage(ctx context.Context, name string) error {
+func (m *LocalManager) GetImage(ctx context.Context, name string) (Image, error) {
+	resolved, ok := m.resolveImageName(name)
+	if !ok {
+		return Image{}, fmt.Errorf("image %s: %w", name, os.ErrNotExist)
+	}
+	return m.image(resolved)
+}
+
+func (m *LocalManager) DeleteImage(ctx context.Context, name string, force bool) error {
 	path, err := m.imagePath(name)
 	if err != nil {
 		return err
 	}
-	if err := os.Remove(path); err != nil {
-		return fmt.Errorf("remove: %w", err)
+	digest, err := m.catalog.remove(name, func(refs []Ref, shared bool) error {
+		if deps := m.withOverlays(name, refs, shared); len(deps) > 0 && !force {
+			return &ImageInUseError{Name: name, UsedBy: deps}
+		}
+		return nil
+	}, func() error {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("remove: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	m.pruneBlob(digest)
 	return nil
 }
 
@@ -135,38 +302,11 @@ func detectFormatByName(name string) string {
 		return "qcow2"
 	case strings.HasSuffix(low, ".raw"):
 		return "raw"
+	case strings.HasSuffix(low, ".vmdk"):
+		return "vmdk"
+	case strings.HasSuffix(low, ".iso"):
+		return "iso"
 	default:
 		return "unknown"
 	}
 }
-
-// CreateDiskFromBase copies the base image to a new disk path (placeholder implementation).
-func (m *LocalManager) CreateDiskFromBase(ctx context.Context, baseImageName, diskName string, sizeBytes int64) (string, error) {
-	base, err := m.imagePath(baseImageName)
-	if err != nil {
-		return "", err
-	}
-	out, err := m.imagePath(diskName)
-	if err != nil {
-		return "", err
-	}
-	src, err := os.Open(base)
-	if err != nil {
-		return "", fmt.Errorf("open base: %w", err)
-	}
-	defer src.Close()
-	dst, err := os.Create(out)
-	if err != nil {
-		return "", fmt.Errorf("create disk: %w", err)
-	}
-	defer dst.Close()
-	if _, err := io.Copy(dst, src); err != nil {
-		_ = os.Remove(out)
-		return "", fmt.Errorf("copy: %w", err)
-	}
-	if err := dst.Sync(); err != nil {
-		_ = os.Remove(out)
-		return "", fmt.Errorf("sync: %w", err)
-	}
-	return out, nil
-}
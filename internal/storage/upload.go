@@ -0,0 +1,239 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// uploadSuffix stages uploads apart from the .part file a download of the
+// same name may still resume, and linkSuffix the new link of a name being
+// pointed at another blob.
+const (
+	uploadSuffix = ".upload"
+	linkSuffix   = ".link"
+)
+
+// isStaging reports whether name in the images directory is a transfer or
+// link in progress rather than an image.
+func isStaging(name string) bool {
+	for _, suffix := range []string{partSuffix, partMetaSuffix, uploadSuffix, linkSuffix} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	ErrChecksumRequired = errors.New("upload needs a sha256 checksum")
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+)
+
+// partFile stages image content in <path><suffix> and atomically moves it
+// into place on commit, so readers never see a half-written image.
+type partFile struct {
+	name   string
+	path   string
+	suffix string
+	f      *os.File
+	hasher hash.Hash
+	size   int64
+	place  func(tmp, name string, meta catalogEntry) error
+	// guard is called every spaceCheckInterval bytes to stop a transfer
+	// that fills the image store
+	guard     func() error
+	unchecked int64
+
+	// set when the content was decompressed on the way in
+	compression  string
+	sourceSHA256 string
+}
+
+// openPart creates the staging file for path with suffix, .part for
+// downloads. With resume set, existing content is kept and rehashed so the
+// checksum still covers the whole image.
+func (m *LocalManager) openPart(name, path, suffix string, resume bool) (*partFile, error) {
+	f, err := os.OpenFile(path+suffix, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("create tmp: %w", err)
+	}
+	p := &partFile{name: name, path: path, suffix: suffix, f: f, hasher: sha256.New(), place: m.placeImage}
+	p.guard = func() error { return m.checkImageStore(0) }
+	if resume {
+		n, err := io.Copy(p.hasher, f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("read tmp: %w", err)
+		}
+		p.size = n
+	} else if err := f.Truncate(0); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("truncate tmp: %w", err)
+	}
+	return p, nil
+}
+
+func (p *partFile) Write(b []byte) (int, error) {
+	if p.unchecked += int64(len(b)); p.unchecked >= spaceCheckInterval {
+		p.unchecked = 0
+		if err := p.guard(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := p.f.Write(b)
+	p.hasher.Write(b[:n])
+	p.size += int64(n)
+	return n, err
+}
+
+// commit verifies the checksum when one is expected and moves the image into
+// place. For decompressed content the checksum may be that of either stream.
+func (p *partFile) commit(expectedSHA256 string) (Image, error) {
+	sum := fmt.Sprintf("%x", p.hasher.Sum(nil))
+	if expectedSHA256 != "" && !strings.EqualFold(expectedSHA256, sum) && !strings.EqualFold(expectedSHA256, p.sourceSHA256) {
+		p.abort()
+		return Image{}, fmt.Errorf("%w: got %s, want %s", ErrChecksumMismatch, sum, expectedSHA256)
+	}
+	if err := p.f.Sync(); err != nil {
+		return Image{}, fmt.Errorf("sync: %w", err)
+	}
+	if err := p.f.Close(); err != nil {
+		return Image{}, fmt.Errorf("close: %w", err)
+	}
+	p.f = nil
+	// the suffix only goes when the content really was compressed
+	name, path := p.name, p.path
+	if trimmed := trimCompressionExt(name); p.compression != "" && trimmed != name {
+		name, path = trimmed, filepath.Join(filepath.Dir(path), trimmed)
+	}
+	meta := catalogEntry{SHA256: sum, Compression: p.compression, SourceSHA256: p.sourceSHA256}
+	if err := p.place(p.path+p.suffix, name, meta); err != nil {
+		return Image{}, err
+	}
+	if p.suffix == partSuffix {
+		_ = os.Remove(p.path + partMetaSuffix)
+	}
+	return Image{
+		Name:         name,
+		Path:         path,
+		Size:         p.size,
+		Format:       detectFormat(path, name),
+		SHA256:       sum,
+		SourceSHA256: p.sourceSHA256,
+		Compression:  p.compression,
+	}, nil
+}
+
+// close releases the file handle and keeps the .part file for a later resume.
+func (p *partFile) close() {
+	if p.f != nil {
+		_ = p.f.Close()
+		p.f = nil
+	}
+}
+
+// abort discards the staged content.
+func (p *partFile) abort() {
+	p.close()
+	if p.suffix == partSuffix {
+		removePart(p.path)
+		return
+	}
+	_ = os.Remove(p.path + p.suffix)
+}
+
+func removePart(path string) {
+	_ = os.Remove(path + partSuffix)
+	_ = os.Remove(path + partMetaSuffix)
+}
+
+// Upload receives image content pushed by a client. Write the data, then
+// call Commit, or Abort to discard it.
+type Upload struct {
+	part    *partFile
+	dec     *decoder
+	release func()
+}
+
+// BeginUpload starts receiving an image under name. Compressed content is
+// decompressed on the fly, and a compression suffix is then dropped from
+// name.
+func (m *LocalManager) BeginUpload(name string) (*Upload, error) {
+	path, err := m.imagePath(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkImageStore(0); err != nil {
+		return nil, err
+	}
+	release, err := m.lockName(name)
+	if err != nil {
+		return nil, err
+	}
+	part, err := m.openPart(name, path, uploadSuffix, false)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &Upload{part: part, dec: newDecoder(part, true), release: release}, nil
+}
+
+func (u *Upload) Write(b []byte) (int, error) { return u.dec.Write(b) }
+
+// Commit stores the image. expectedSHA256 is required and must match the
+// checksum of the received data, otherwise the upload is discarded.
+func (u *Upload) Commit(expectedSHA256 string) (Image, error) {
+	defer u.release()
+	if expectedSHA256 == "" {
+		u.dec.abort()
+		u.part.abort()
+		return Image{}, ErrChecksumRequired
+	}
+	if err := u.dec.Close(); err != nil {
+		u.part.abort()
+		return Image{}, err
+	}
+	if u.dec.kind != "" {
+		u.part.compression, u.part.sourceSHA256 = u.dec.kind, u.dec.sum()
+	}
+	img, err := u.part.commit(expectedSHA256)
+	if err != nil {
+		u.part.abort()
+		return Image{}, err
+	}
+	return img, nil
+}
+
+// Abort discards everything received so far.
+func (u *Upload) Abort() {
+	u.dec.abort()
+	u.part.abort()
+	u.release()
+}
+
+var errBusy = errors.New("image is already being transferred")
+
+// lockName makes sure only one transfer at a time writes a given image name.
+func (m *LocalManager) lockName(name string) (func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.inflight[name] {
+		return nil, fmt.Errorf("%s: %w", name, errBusy)
+	}
+	m.inflight[name] = true
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mu.Lock()
+			delete(m.inflight, name)
+			m.mu.Unlock()
+		})
+	}, nil
+}
@@ -0,0 +1,258 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// ErrInsufficientStorage is returned when an operation would fill a pool or
+// the image store past its limits.
+var ErrInsufficientStorage = errors.New("insufficient storage")
+
+// spaceCheckInterval is how many bytes an image transfer writes between
+// checks of the image store's high-water mark.
+const spaceCheckInterval = 64 << 20
+
+// Limits are the capacity guardrails of a pool or of the image store.
+type Limits struct {
+	// HighWaterMark is the percentage of capacity past which new disks and
+	// image transfers are refused; 0 disables it.
+	HighWaterMark float64 `json:"high_water_mark"`
+	// MaxOvercommit caps the virtual size of all volumes in a pool as a
+	// multiple of its capacity; 0 means no cap.
+	MaxOvercommit float64 `json:"max_overcommit"`
+}
+
+// PoolReport is the usage of one pool, or of the filesystem holding images.
+type PoolReport struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	PoolUsage
+	// ProvisionedBytes is the virtual size of all volumes in the pool, which
+	// may exceed its capacity since volumes are thin provisioned.
+	ProvisionedBytes int64 `json:"provisioned_bytes"`
+	// Overcommit is ProvisionedBytes as a multiple of the capacity.
+	Overcommit float64 `json:"overcommit"`
+	Limits
+	// Error is set when the pool could not be queried.
+	Error string `json:"error,omitempty"`
+}
+
+// ImageUsage compares the space an image takes with the size of the disk it
+// holds. Names tagged on the same content share one allocation.
+type ImageUsage struct {
+	Name           string `json:"name"`
+	SHA256         string `json:"sha256"`
+	AllocatedBytes int64  `json:"allocated_bytes"`
+	VirtualBytes   int64  `json:"virtual_bytes"`
+}
+
+// VMUsage adds up the volumes attached to one VM.
+type VMUsage struct {
+	VM string `json:"vm"`
+	// Name is left empty by storage, which only knows VM ids.
+	Name           string   `json:"name,omitempty"`
+	Volumes        []string `json:"volumes"`
+	AllocatedBytes int64    `json:"allocated_bytes"`
+	VirtualBytes   int64    `json:"virtual_bytes"`
+}
+
+// Usage is a snapshot of storage consumption across the host.
+type Usage struct {
+	ImageStore PoolReport   `json:"image_store"`
+	Pools      []PoolReport `json:"pools"`
+	Images     []ImageUsage `json:"images"`
+	VMs        []VMUsage    `json:"vms"`
+}
+
+// SetImageLimits sets the guardrails for the filesystem holding images.
+// Only the high-water mark applies there.
+func (m *LocalManager) SetImageLimits(l Limits) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.imageLimits = l
+}
+
+// SetPoolLimits sets the guardrails of a pool registered with AddPool.
+func (m *LocalManager) SetPoolLimits(name string, l Limits) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limits[name] = l
+}
+
+func (m *LocalManager) poolLimits(name string) Limits {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.limits[name]
+}
+
+// Usage reports capacity and consumption of the image store and every pool,
+// along with allocated versus virtual size per image and per VM. A pool that
+// cannot be queried is reported with Error set rather than failing the call.
+func (m *LocalManager) Usage(ctx context.Context) (Usage, error) {
+	m.mu.Lock()
+	out := Usage{ImageStore: PoolReport{Name: "images", Type: "dir", Limits: Limits{HighWaterMark: m.imageLimits.HighWaterMark}}}
+	names := make([]string, 0, len(m.pools))
+	for name := range m.pools {
+		names = append(names, name)
+	}
+	m.mu.Unlock()
+	sort.Strings(names)
+
+	if u, err := fsUsage(m.imagesDir); err != nil {
+		out.ImageStore.Error = err.Error()
+	} else {
+		out.ImageStore.PoolUsage = u
+	}
+	imgs, err := m.ListImages(ctx)
+	if err != nil {
+		return Usage{}, err
+	}
+	for _, img := range imgs {
+		alloc, err := allocatedBytes(img.Path)
+		if err != nil {
+			continue
+		}
+		out.Images = append(out.Images, ImageUsage{Name: img.Name, SHA256: img.SHA256, AllocatedBytes: alloc, VirtualBytes: virtualSize(img.Path)})
+	}
+
+	allocs := make(map[string]map[string]int64)
+	for _, name := range names {
+		_, b, err := m.pool(name)
+		if err != nil {
+			continue
+		}
+		report := PoolReport{Name: name, Type: b.Type(), Limits: m.poolLimits(name)}
+		if err := fillPoolReport(ctx, b, &report); err != nil {
+			report.Error = err.Error()
+		}
+		if a, err := b.Allocations(ctx); err == nil {
+			allocs[name] = a
+		}
+		out.Pools = append(out.Pools, report)
+	}
+
+	byVM := make(map[string]*VMUsage)
+	for _, vol := range m.volumes.list() {
+		if vol.VM == "" {
+			continue
+		}
+		u, ok := byVM[vol.VM]
+		if !ok {
+			u = &VMUsage{VM: vol.VM}
+			byVM[vol.VM] = u
+		}
+		u.Volumes = append(u.Volumes, vol.Name)
+		u.AllocatedBytes += allocs[vol.Pool][vol.Name]
+		u.VirtualBytes += vol.SizeBytes
+	}
+	for _, u := range byVM {
+		out.VMs = append(out.VMs, *u)
+	}
+	sort.Slice(out.VMs, func(i, j int) bool { return out.VMs[i].VM < out.VMs[j].VM })
+	return out, nil
+}
+
+// fillPoolReport sets the capacity of a pool and how much virtual size its
+// volumes add up to.
+func fillPoolReport(ctx context.Context, b VolumeBackend, r *PoolReport) error {
+	u, err := b.Usage(ctx)
+	if err != nil {
+		return err
+	}
+	r.PoolUsage = u
+	vols, err := b.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, v := range vols {
+		r.ProvisionedBytes += v.SizeBytes
+	}
+	if u.CapacityBytes > 0 {
+		r.Overcommit = float64(r.ProvisionedBytes) / float64(u.CapacityBytes)
+	}
+	return nil
+}
+
+// checkPool refuses an operation that writes write bytes into a pool and
+// provisions provision bytes of virtual size in it, when that would cross
+// the pool's high-water mark or overcommit limit.
+func (m *LocalManager) checkPool(ctx context.Context, name string, b VolumeBackend, write, provision int64) error {
+	l := m.poolLimits(name)
+	if l.HighWaterMark <= 0 && (l.MaxOvercommit <= 0 || provision <= 0) {
+		return nil
+	}
+	r := PoolReport{Name: name}
+	if err := fillPoolReport(ctx, b, &r); err != nil {
+		return fmt.Errorf("pool %s usage: %w", name, err)
+	}
+	if err := checkHighWater("pool "+name, r.PoolUsage, write, l.HighWaterMark); err != nil {
+		return err
+	}
+	if l.MaxOvercommit > 0 && provision > 0 && r.CapacityBytes > 0 {
+		ratio := float64(r.ProvisionedBytes+provision) / float64(r.CapacityBytes)
+		if ratio > l.MaxOvercommit {
+			return fmt.Errorf("pool %s would be overcommitted %.2fx, above its %gx limit: %w", name, ratio, l.MaxOvercommit, ErrInsufficientStorage)
+		}
+	}
+	return nil
+}
+
+// checkImageStore refuses to write write more bytes of images once that
+// would cross the high-water mark of the images filesystem.
+func (m *LocalManager) checkImageStore(write int64) error {
+	m.mu.Lock()
+	mark := m.imageLimits.HighWaterMark
+	m.mu.Unlock()
+	if mark <= 0 {
+		return nil
+	}
+	u, err := fsUsage(m.imagesDir)
+	if err != nil {
+		return fmt.Errorf("image store usage: %w", err)
+	}
+	return checkHighWater("image store", u, write, mark)
+}
+
+// DirUsage reports the capacity of the filesystem holding dir, for staging
+// areas kept outside the pools and the image store.
+func DirUsage(dir string) (PoolUsage, error) { return fsUsage(dir) }
+
+func checkHighWater(what string, u PoolUsage, write int64, mark float64) error {
+	if mark <= 0 || u.CapacityBytes <= 0 {
+		return nil
+	}
+	pct := float64(u.UsedBytes+write) * 100 / float64(u.CapacityBytes)
+	if pct > mark {
+		return fmt.Errorf("%s would be %.1f%% full, above its %g%% high-water mark: %w", what, pct, mark, ErrInsufficientStorage)
+	}
+	return nil
+}
+
+// virtualSize is the size of the disk held by a file: the qcow2 virtual
+// size, or the file size for raw images and ISOs.
+func virtualSize(path string) int64 {
+	if hdr, err := readQcow2Header(path); err == nil {
+		return hdr.VirtualSize
+	}
+	if info, err := os.Stat(path); err == nil {
+		return info.Size()
+	}
+	return 0
+}
+
+// volumeAllocated reports the bytes a volume occupies in its pool, falling
+// back to its virtual size when the pool cannot tell.
+func (m *LocalManager) volumeAllocated(ctx context.Context, vol Volume) int64 {
+	if _, b, err := m.pool(vol.Pool); err == nil {
+		if allocs, err := b.Allocations(ctx); err == nil {
+			if n, ok := allocs[vol.Name]; ok {
+				return n
+			}
+		}
+	}
+	return vol.SizeBytes
+}
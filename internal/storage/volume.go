@@ -0,0 +1,415 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/riccardotacconi/deusvm/internal/config"
+	"github.com/riccardotacconi/deusvm/internal/state"
+)
+
+// Volume is a disk allocated in a storage pool.
+type Volume struct {
+	Name      string `json:"name"`
+	Pool      string `json:"pool"`
+	Path      string `json:"path"`
+	Format    string `json:"format"`
+	SizeBytes int64  `json:"size_bytes"`
+	// Block is set when Path is a block device rather than a file.
+	Block bool `json:"block,omitempty"`
+	// Image is the image the volume was cloned from, if any.
+	Image string `json:"image,omitempty"`
+	// VM is the id of the VM the volume is attached to.
+	VM string `json:"vm,omitempty"`
+	// DeleteWithVM marks disks created together with a VM, which go away with it.
+	DeleteWithVM bool `json:"delete_with_vm,omitempty"`
+}
+
+// VolumeSpec describes a volume to create. With Image set the volume starts
+// with the content of that image, given by name or path.
+type VolumeSpec struct {
+	Name         string
+	Pool         string // default pool when empty
+	SizeBytes    int64  // may be 0 when cloning, to keep the image size
+	Format       string // qcow2 or raw; backends on block devices are always raw
+	Image        string
+	DeleteWithVM bool
+}
+
+// PoolUsage reports the capacity of a pool.
+type PoolUsage struct {
+	CapacityBytes int64 `json:"capacity_bytes"`
+	UsedBytes     int64 `json:"used_bytes"`
+	FreeBytes     int64 `json:"free_bytes"`
+}
+
+// VolumeBackend manages the volumes of one storage pool. Backends return
+// volumes with Name, Path, Format, SizeBytes and Block filled in.
+type VolumeBackend interface {
+	Type() string
+	Create(ctx context.Context, name string, sizeBytes int64, format string) (Volume, error)
+	CloneFromImage(ctx context.Context, name, imagePath, imageFormat string, sizeBytes int64) (Volume, error)
+	// Copy creates a volume holding a full, independent copy of a disk.
+	Copy(ctx context.Context, name, srcPath, srcFormat string, sizeBytes int64) (Volume, error)
+	Resize(ctx context.Context, name string, sizeBytes int64) error
+	Delete(ctx context.Context, name string) error
+	Snapshot(ctx context.Context, name, snapshot string) error
+	Usage(ctx context.Context) (PoolUsage, error)
+	List(ctx context.Context) ([]Volume, error)
+	// Allocations reports the bytes each volume in the pool occupies, by name.
+	Allocations(ctx context.Context) (map[string]int64, error)
+}
+
+var (
+	ErrVolumeNotFound = errors.New("volume not found")
+	ErrVolumeExists   = errors.New("volume already exists")
+	ErrVolumeInUse    = errors.New("volume is attached")
+)
+
+// NewBackend builds the backend for a configured pool.
+func NewBackend(pc config.PoolConfig, run CommandRunner) (VolumeBackend, error) {
+	switch pc.Type {
+	case "", "dir":
+		if pc.Path == "" {
+			return nil, fmt.Errorf("pool %s: path required", pc.Name)
+		}
+		if err := os.MkdirAll(pc.Path, 0o755); err != nil {
+			return nil, fmt.Errorf("pool %s: %w", pc.Name, err)
+		}
+		return &dirBackend{dir: pc.Path, run: run}, nil
+	case "lvm-thin":
+		if pc.VolumeGroup == "" || pc.ThinPool == "" {
+			return nil, fmt.Errorf("pool %s: volume_group and thin_pool required", pc.Name)
+		}
+		return &lvmThinBackend{vg: pc.VolumeGroup, thinPool: pc.ThinPool, run: run}, nil
+	case "zfs":
+		if pc.Dataset == "" {
+			return nil, fmt.Errorf("pool %s: dataset required", pc.Name)
+		}
+		return &zfsBackend{dataset: pc.Dataset, run: run}, nil
+	default:
+		return nil, fmt.Errorf("pool %s: unknown type %q", pc.Name, pc.Type)
+	}
+}
+
+// AddPool registers a pool. The first pool added, or one added with
+// isDefault, receives volumes that do not name a pool.
+func (m *LocalManager) AddPool(name string, b VolumeBackend, isDefault bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pools[name] = b
+	if isDefault || m.defaultPool == "" {
+		m.defaultPool = name
+	}
+}
+
+func (m *LocalManager) pool(name string) (string, VolumeBackend, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if name == "" {
+		name = m.defaultPool
+	}
+	b, ok := m.pools[name]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown storage pool %q", name)
+	}
+	return name, b, nil
+}
+
+// CreateVolume allocates a volume, cloning an image into it when spec.Image is set.
+func (m *LocalManager) CreateVolume(ctx context.Context, spec VolumeSpec) (Volume, error) {
+	if err := validVolumeName(spec.Name); err != nil {
+		return Volume{}, err
+	}
+	poolName, b, err := m.pool(spec.Pool)
+	if err != nil {
+		return Volume{}, err
+	}
+	if _, ok := m.volumes.get(spec.Name); ok {
+		return Volume{}, fmt.Errorf("%s: %w", spec.Name, ErrVolumeExists)
+	}
+	var vol Volume
+	if spec.Image != "" {
+		unpin, err := m.pinImage(spec.Image, spec.Name)
+		if err != nil {
+			return Volume{}, err
+		}
+		defer unpin()
+		path, err := m.imageSourcePath(spec.Image)
+		if err != nil {
+			return Volume{}, err
+		}
+		format := detectFormat(path, filepath.Base(path))
+		if format == "iso" {
+			return Volume{}, fmt.Errorf("%s is an ISO image; boot it from a CD-ROM instead", spec.Image)
+		}
+		// directory pools clone into an overlay; other pools copy the image in
+		var written int64
+		if _, ok := b.(*dirBackend); !ok {
+			written, _ = allocatedBytes(path)
+		}
+		if err := m.checkPool(ctx, poolName, b, written, max(spec.SizeBytes, virtualSize(path))); err != nil {
+			return Volume{}, err
+		}
+		vol, err = b.CloneFromImage(ctx, spec.Name, path, format, spec.SizeBytes)
+		if err != nil {
+			return Volume{}, fmt.Errorf("clone image: %w", err)
+		}
+	} else {
+		if spec.SizeBytes <= 0 {
+			return Volume{}, errors.New("volume size required")
+		}
+		if err := m.checkPool(ctx, poolName, b, 0, spec.SizeBytes); err != nil {
+			return Volume{}, err
+		}
+		vol, err = b.Create(ctx, spec.Name, spec.SizeBytes, spec.Format)
+		if err != nil {
+			return Volume{}, fmt.Errorf("create volume: %w", err)
+		}
+	}
+	vol.Pool = poolName
+	vol.Image = spec.Image
+	vol.DeleteWithVM = spec.DeleteWithVM
+	if err := m.volumes.put(vol); err != nil {
+		_ = b.Delete(ctx, vol.Name)
+		return Volume{}, err
+	}
+	return vol, nil
+}
+
+// imageSourcePath resolves an image name, or any existing absolute path, to a
+// file to clone from. Stored images resolve to their blob, which never changes
+// even if the name is later pointed at other content.
+func (m *LocalManager) imageSourcePath(image string) (string, error) {
+	if name, ok := m.resolveImageName(image); ok {
+		if meta, ok := m.catalog.lookup(name); ok && meta.SHA256 != "" {
+			return m.blobPath(meta.SHA256), nil
+		}
+		return m.imagePath(name)
+	}
+	if filepath.IsAbs(image) {
+		if _, err := os.Stat(image); err == nil {
+			return image, nil
+		}
+	}
+	return "", fmt.Errorf("image %s: %w", image, os.ErrNotExist)
+}
+
+// DeleteVolume removes a volume that is not attached to a VM.
+func (m *LocalManager) DeleteVolume(ctx context.Context, name string) error {
+	vol, ok := m.volumes.get(name)
+	if !ok {
+		return fmt.Errorf("%s: %w", name, ErrVolumeNotFound)
+	}
+	if vol.VM != "" {
+		return fmt.Errorf("%s is attached to vm %s: %w", name, vol.VM, ErrVolumeInUse)
+	}
+	_, b, err := m.pool(vol.Pool)
+	if err != nil {
+		return err
+	}
+	if err := b.Delete(ctx, name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("delete volume: %w", err)
+	}
+	return m.volumes.remove(name)
+}
+
+func (m *LocalManager) GetVolume(ctx context.Context, name string) (Volume, error) {
+	vol, ok := m.volumes.get(name)
+	if !ok {
+		return Volume{}, fmt.Errorf("%s: %w", name, ErrVolumeNotFound)
+	}
+	return vol, nil
+}
+
+func (m *LocalManager) ListVolumes(ctx context.Context) ([]Volume, error) {
+	return m.volumes.list(), nil
+}
+
+// ResizeVolume grows a volume; shrinking is refused since it would cut off
+// guest data.
+func (m *LocalManager) ResizeVolume(ctx context.Context, name string, sizeBytes int64) (Volume, error) {
+	vol, ok := m.volumes.get(name)
+	if !ok {
+		return Volume{}, fmt.Errorf("%s: %w", name, ErrVolumeNotFound)
+	}
+	if sizeBytes < vol.SizeBytes {
+		return Volume{}, fmt.Errorf("cannot shrink %s from %d to %d bytes", name, vol.SizeBytes, sizeBytes)
+	}
+	if sizeBytes == vol.SizeBytes {
+		return vol, nil
+	}
+	_, b, err := m.pool(vol.Pool)
+	if err != nil {
+		return Volume{}, err
+	}
+	if err := m.checkPool(ctx, vol.Pool, b, 0, sizeBytes-vol.SizeBytes); err != nil {
+		return Volume{}, err
+	}
+	if err := b.Resize(ctx, name, sizeBytes); err != nil {
+		return Volume{}, fmt.Errorf("resize volume: %w", err)
+	}
+	err = m.volumes.update(name, func(v *Volume) error {
+		v.SizeBytes = sizeBytes
+		vol = *v
+		return nil
+	})
+	return vol, err
+}
+
+// CloneVolume copies the volume source into a new volume described by spec,
+// possibly in another pool. spec.Image is ignored; the clone records the
+// image of its source.
+func (m *LocalManager) CloneVolume(ctx context.Context, source string, spec VolumeSpec) (Volume, error) {
+	src, ok := m.volumes.get(source)
+	if !ok {
+		return Volume{}, fmt.Errorf("%s: %w", source, ErrVolumeNotFound)
+	}
+	if err := validVolumeName(spec.Name); err != nil {
+		return Volume{}, err
+	}
+	if spec.Pool == "" {
+		spec.Pool = src.Pool
+	}
+	poolName, b, err := m.pool(spec.Pool)
+	if err != nil {
+		return Volume{}, err
+	}
+	if _, ok := m.volumes.get(spec.Name); ok {
+		return Volume{}, fmt.Errorf("%s: %w", spec.Name, ErrVolumeExists)
+	}
+	if err := m.checkPool(ctx, poolName, b, m.volumeAllocated(ctx, src), max(spec.SizeBytes, src.SizeBytes)); err != nil {
+		return Volume{}, err
+	}
+	vol, err := b.Copy(ctx, spec.Name, src.Path, src.Format, max(spec.SizeBytes, src.SizeBytes))
+	if err != nil {
+		return Volume{}, fmt.Errorf("clone volume: %w", err)
+	}
+	vol.Pool = poolName
+	vol.Image = src.Image
+	if err := m.volumes.put(vol); err != nil {
+		_ = b.Delete(ctx, vol.Name)
+		return Volume{}, err
+	}
+	return vol, nil
+}
+
+// ImportVolume creates a volume holding an independent copy of the disk file
+// at path, such as a disk restored from a backup.
+func (m *LocalManager) ImportVolume(ctx context.Context, spec VolumeSpec, path, format string) (Volume, error) {
+	if err := validVolumeName(spec.Name); err != nil {
+		return Volume{}, err
+	}
+	poolName, b, err := m.pool(spec.Pool)
+	if err != nil {
+		return Volume{}, err
+	}
+	if _, ok := m.volumes.get(spec.Name); ok {
+		return Volume{}, fmt.Errorf("%s: %w", spec.Name, ErrVolumeExists)
+	}
+	written, _ := allocatedBytes(path)
+	size := max(spec.SizeBytes, virtualSize(path))
+	if err := m.checkPool(ctx, poolName, b, written, size); err != nil {
+		return Volume{}, err
+	}
+	vol, err := b.Copy(ctx, spec.Name, path, format, size)
+	if err != nil {
+		return Volume{}, fmt.Errorf("import volume: %w", err)
+	}
+	vol.Pool = poolName
+	vol.Image = spec.Image
+	vol.DeleteWithVM = spec.DeleteWithVM
+	if err := m.volumes.put(vol); err != nil {
+		_ = b.Delete(ctx, vol.Name)
+		return Volume{}, err
+	}
+	return vol, nil
+}
+
+// SetVolumeAttachment records the VM a volume is attached to; an empty vmID detaches it.
+func (m *LocalManager) SetVolumeAttachment(ctx context.Context, name, vmID string) error {
+	return m.volumes.update(name, func(v *Volume) error {
+		if vmID != "" && v.VM != "" && v.VM != vmID {
+			return fmt.Errorf("%s is attached to vm %s: %w", name, v.VM, ErrVolumeInUse)
+		}
+		v.VM = vmID
+		return nil
+	})
+}
+
+func validVolumeName(name string) error {
+	if name == "" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "-") || strings.Contains(name, "..") || strings.ContainsAny(name, "/@ \t\n") {
+		return errors.New("invalid volume name")
+	}
+	return nil
+}
+
+// volumeRegistry remembers the volumes created through the daemon.
+type volumeRegistry struct {
+	mu      sync.Mutex
+	path    string
+	Volumes map[string]Volume `json:"volumes"`
+}
+
+func loadVolumeRegistry(dir string) (*volumeRegistry, error) {
+	r := &volumeRegistry{path: filepath.Join(dir, "volumes.json")}
+	if err := state.Load(r.path, r); err != nil {
+		return nil, err
+	}
+	if r.Volumes == nil {
+		r.Volumes = make(map[string]Volume)
+	}
+	return r, nil
+}
+
+func (r *volumeRegistry) get(name string) (Volume, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.Volumes[name]
+	return v, ok
+}
+
+func (r *volumeRegistry) put(v Volume) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Volumes[v.Name] = v
+	return state.Save(r.path, r)
+}
+
+func (r *volumeRegistry) update(name string, fn func(*Volume) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.Volumes[name]
+	if !ok {
+		return fmt.Errorf("%s: %w", name, ErrVolumeNotFound)
+	}
+	if err := fn(&v); err != nil {
+		return err
+	}
+	r.Volumes[name] = v
+	return state.Save(r.path, r)
+}
+
+func (r *volumeRegistry) remove(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.Volumes, name)
+	return state.Save(r.path, r)
+}
+
+func (r *volumeRegistry) list() []Volume {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Volume, 0, len(r.Volumes))
+	for _, v := range r.Volumes {
+		out = append(out, v)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"strings"
 	"time"
 )
 
@@ -35,7 +36,9 @@ func New(endpoint, token string) (*Client, error) {
 
 func (c *Client) do(ctx context.Context, method, p string, in any, out any) error {
 	u := *c.baseURL
+	p, query, _ := strings.Cut(p, "?")
 	u.Path = path.Join(u.Path, p)
+	u.RawQuery = query
 	var body io.Reader
 	if in != nil {
 		b, err := json.Marshal(in)
@@ -76,6 +79,19 @@ type Image struct {
 	Size   int64  `json:"size_bytes"`
 	Format string `json:"format"`
 	SHA256 string `json:"sha256"`
+
+	Compression  string   `json:"compression,omitempty"`
+	SourceSHA256 string   `json:"source_sha256,omitempty"`
+	Lineage      *Lineage `json:"lineage,omitempty"`
+	UsedBy       int      `json:"used_by"`
+}
+
+// Lineage records the VM and disk an image was captured from.
+type Lineage struct {
+	SourceVM   string    `json:"source_vm"`
+	SourceDisk string    `json:"source_disk"`
+	Parent     string    `json:"parent,omitempty"`
+	CapturedAt time.Time `json:"captured_at"`
 }
 
 func (c *Client) CreateImage(ctx context.Context, name, source string) (Image, error) {
@@ -84,19 +100,76 @@ func (c *Client) CreateImage(ctx context.Context, name, source string) (Image, e
 	return out, err
 }
 
-func (c *Client) DeleteImage(ctx context.Context, name string) error {
-	return c.do(ctx, http.MethodDelete, "/api/v1/images/"+name, nil, nil)
+// TagImage gives the content of image source the additional name.
+func (c *Client) TagImage(ctx context.Context, source, name string) (Image, error) {
+	var out Image
+	err := c.do(ctx, http.MethodPost, "/api/v1/images/"+source+"/tags", map[string]string{"name": name}, &out)
+	return out, err
+}
+
+// CaptureImage stores the boot disk of a VM as a new image. A running VM
+// needs the guest agent so its filesystems can be frozen for the copy.
+func (c *Client) CaptureImage(ctx context.Context, vm, name string, sparsify bool) (Image, error) {
+	var out Image
+	err := c.do(ctx, http.MethodPost, "/api/v1/images/capture", map[string]any{"vm": vm, "name": name, "sparsify": sparsify}, &out)
+	return out, err
+}
+
+// DeleteImage removes an image. Without force it fails while VMs or disks use it.
+func (c *Client) DeleteImage(ctx context.Context, name string, force bool) error {
+	p := "/api/v1/images/" + name
+	if force {
+		p += "?force=true"
+	}
+	return c.do(ctx, http.MethodDelete, p, nil, nil)
 }
 
 // VM APIs
 type VM struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	CPU         int    `json:"cpu"`
-	MemoryBytes int64  `json:"memory_bytes"`
-	DiskBytes   int64  `json:"disk_bytes"`
-	Image       string `json:"image"`
-	Status      string `json:"status"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	CPU         int      `json:"cpu"`
+	MemoryBytes int64    `json:"memory_bytes"`
+	DiskBytes   int64    `json:"disk_bytes"`
+	Image       string   `json:"image"`
+	CDROM       string   `json:"cdrom,omitempty"`
+	BootOrder   []string `json:"boot_order,omitempty"`
+	NICs        []NIC    `json:"nics,omitempty"`
+	Status      string   `json:"status"`
+	// BootTemplate is the script the VM gets when it boots from the network.
+	BootTemplate string `json:"boot_template,omitempty"`
+}
+
+// NIC connects a VM to a managed network or straight to a host bridge.
+type NIC struct {
+	Network string `json:"network,omitempty"`
+	Bridge  string `json:"bridge,omitempty"`
+	// BridgeType is linux or openvswitch; VLAN (access) and Trunk need
+	// openvswitch.
+	BridgeType string `json:"bridge_type,omitempty"`
+	VLAN       int    `json:"vlan,omitempty"`
+	Trunk      []int  `json:"trunk,omitempty"`
+	MAC        string `json:"mac,omitempty"`
+	Model      string `json:"model,omitempty"`
+	LinkState  string `json:"link_state,omitempty"` // up (default) or down
+	// Inbound and Outbound limit the traffic to and from the VM.
+	Inbound  *Bandwidth `json:"inbound,omitempty"`
+	Outbound *Bandwidth `json:"outbound,omitempty"`
+	// IPv4 and IPv6 are the addresses reserved on a managed network.
+	IPv4 string `json:"ipv4,omitempty"`
+	IPv6 string `json:"ipv6,omitempty"`
+	// SecurityGroups filter the NIC's traffic; Device is its tap device
+	// while the VM runs.
+	SecurityGroups []string `json:"security_groups,omitempty"`
+	Device         string   `json:"device,omitempty"`
+}
+
+// Bandwidth is a traffic limit in one direction: Average and Peak in KiB/s,
+// Burst in KiB sent at Peak rate.
+type Bandwidth struct {
+	Average int `json:"average"`
+	Peak    int `json:"peak,omitempty"`
+	Burst   int `json:"burst,omitempty"`
 }
 
 func (c *Client) CreateVM(ctx context.Context, name, image string, cpu int, memory, disk string) (VM, error) {
@@ -114,6 +187,529 @@ func (c *Client) GetVM(ctx context.Context, id string) (VM, error) {
 	return out, err
 }
 
+// InsertMedia puts the ISO image into the CD-ROM drive of a VM.
+func (c *Client) InsertMedia(ctx context.Context, id, image string) error {
+	return c.do(ctx, http.MethodPut, "/api/v1/vms/"+id+"/media", map[string]string{"image": image}, nil)
+}
+
+func (c *Client) EjectMedia(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/vms/"+id+"/media", nil, nil)
+}
+
 func (c *Client) DeleteVM(ctx context.Context, id string) error {
 	return c.do(ctx, http.MethodDelete, "/api/v1/vms/"+id, nil, nil)
 }
+
+// Volume APIs
+type Volume struct {
+	Name         string `json:"name"`
+	Pool         string `json:"pool"`
+	Path         string `json:"path"`
+	Format       string `json:"format"`
+	SizeBytes    int64  `json:"size_bytes"`
+	Block        bool   `json:"block,omitempty"`
+	Image        string `json:"image,omitempty"`
+	VM           string `json:"vm,omitempty"`
+	DeleteWithVM bool   `json:"delete_with_vm,omitempty"`
+}
+
+// CreateVolume allocates a volume of size (like "20GB") in pool, starting
+// from image when it is not empty.
+func (c *Client) CreateVolume(ctx context.Context, name, pool, size, image string) (Volume, error) {
+	var out Volume
+	payload := map[string]string{"name": name, "pool": pool, "size": size, "image": image}
+	err := c.do(ctx, http.MethodPost, "/api/v1/volumes", payload, &out)
+	return out, err
+}
+
+func (c *Client) GetVolume(ctx context.Context, name string) (Volume, error) {
+	var out Volume
+	err := c.do(ctx, http.MethodGet, "/api/v1/volumes/"+name, nil, &out)
+	return out, err
+}
+
+func (c *Client) ListVolumes(ctx context.Context) ([]Volume, error) {
+	var out []Volume
+	err := c.do(ctx, http.MethodGet, "/api/v1/volumes", nil, &out)
+	return out, err
+}
+
+// DeleteVolume removes a volume. It fails while the volume is attached.
+func (c *Client) DeleteVolume(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/volumes/"+name, nil, nil)
+}
+
+func (c *Client) AttachVolume(ctx context.Context, name, vm string) (Volume, error) {
+	var out Volume
+	err := c.do(ctx, http.MethodPost, "/api/v1/volumes/"+name+"/attach", map[string]string{"vm": vm}, &out)
+	return out, err
+}
+
+func (c *Client) DetachVolume(ctx context.Context, name string) (Volume, error) {
+	var out Volume
+	err := c.do(ctx, http.MethodPost, "/api/v1/volumes/"+name+"/detach", nil, &out)
+	return out, err
+}
+
+// ResizeVolume grows a volume to size, like "40GB".
+func (c *Client) ResizeVolume(ctx context.Context, name, size string) (Volume, error) {
+	var out Volume
+	err := c.do(ctx, http.MethodPost, "/api/v1/volumes/"+name+"/resize", map[string]string{"size": size}, &out)
+	return out, err
+}
+
+// CloneVolume copies source into a new volume; pool and size may be empty.
+func (c *Client) CloneVolume(ctx context.Context, source, name, pool, size string) (Volume, error) {
+	var out Volume
+	payload := map[string]string{"name": name, "pool": pool, "size": size}
+	err := c.do(ctx, http.MethodPost, "/api/v1/volumes/"+source+"/clone", payload, &out)
+	return out, err
+}
+
+// Storage jobs
+type Job struct {
+	ID         string     `json:"id"`
+	Kind       string     `json:"kind"`
+	Target     string     `json:"target"`
+	State      string     `json:"state"`
+	Progress   float64    `json:"progress"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// ConvertRequest selects either an image or a detached volume to convert.
+type ConvertRequest struct {
+	Image    string `json:"image,omitempty"`
+	Volume   string `json:"volume,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Format   string `json:"format,omitempty"`
+	Compress bool   `json:"compress,omitempty"`
+}
+
+// Convert starts a conversion job and returns it without waiting.
+func (c *Client) Convert(ctx context.Context, req ConvertRequest) (Job, error) {
+	var out Job
+	err := c.do(ctx, http.MethodPost, "/api/v1/storage/convert", req, &out)
+	return out, err
+}
+
+func (c *Client) GetJob(ctx context.Context, id string) (Job, error) {
+	var out Job
+	err := c.do(ctx, http.MethodGet, "/api/v1/jobs/"+id, nil, &out)
+	return out, err
+}
+
+func (c *Client) ListJobs(ctx context.Context) ([]Job, error) {
+	var out []Job
+	err := c.do(ctx, http.MethodGet, "/api/v1/jobs", nil, &out)
+	return out, err
+}
+
+// Storage usage
+type PoolUsage struct {
+	Name             string  `json:"name"`
+	Type             string  `json:"type"`
+	CapacityBytes    int64   `json:"capacity_bytes"`
+	UsedBytes        int64   `json:"used_bytes"`
+	FreeBytes        int64   `json:"free_bytes"`
+	ProvisionedBytes int64   `json:"provisioned_bytes"`
+	Overcommit       float64 `json:"overcommit"`
+	HighWaterMark    float64 `json:"high_water_mark"`
+	MaxOvercommit    float64 `json:"max_overcommit"`
+	Error            string  `json:"error,omitempty"`
+}
+
+type ImageUsage struct {
+	Name           string `json:"name"`
+	SHA256         string `json:"sha256"`
+	AllocatedBytes int64  `json:"allocated_bytes"`
+	VirtualBytes   int64  `json:"virtual_bytes"`
+}
+
+type VMUsage struct {
+	VM             string   `json:"vm"`
+	Name           string   `json:"name,omitempty"`
+	Volumes        []string `json:"volumes"`
+	AllocatedBytes int64    `json:"allocated_bytes"`
+	VirtualBytes   int64    `json:"virtual_bytes"`
+}
+
+type StorageUsage struct {
+	ImageStore PoolUsage    `json:"image_store"`
+	Pools      []PoolUsage  `json:"pools"`
+	Images     []ImageUsage `json:"images"`
+	VMs        []VMUsage    `json:"vms"`
+}
+
+// StorageUsage reports pool capacity and per-image and per-VM allocation.
+func (c *Client) StorageUsage(ctx context.Context) (StorageUsage, error) {
+	var out StorageUsage
+	err := c.do(ctx, http.MethodGet, "/api/v1/storage/usage", nil, &out)
+	return out, err
+}
+
+// GCItem is an orphaned file, attachment or image reference found by
+// garbage collection.
+type GCItem struct {
+	Kind       string    `json:"kind"`
+	Path       string    `json:"path,omitempty"`
+	Pool       string    `json:"pool,omitempty"`
+	Name       string    `json:"name,omitempty"`
+	SizeBytes  int64     `json:"size_bytes"`
+	ModTime    time.Time `json:"mod_time,omitempty"`
+	Reason     string    `json:"reason"`
+	ReportOnly bool      `json:"report_only,omitempty"`
+	Quarantine string    `json:"quarantine,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+type GCReport struct {
+	DryRun           bool     `json:"dry_run"`
+	Items            []GCItem `json:"items"`
+	ReclaimableBytes int64    `json:"reclaimable_bytes"`
+	Purged           []string `json:"purged,omitempty"`
+}
+
+// GarbageCollect moves orphaned disks, stale transfers and unused images to
+// the daemon's quarantine directory, or only reports them when dryRun is
+// set. A zero minAge keeps the configured minimum age.
+func (c *Client) GarbageCollect(ctx context.Context, dryRun bool, minAge time.Duration) (GCReport, error) {
+	method := http.MethodPost
+	if dryRun {
+		method = http.MethodGet
+	}
+	p := "/api/v1/storage/gc"
+	if minAge > 0 {
+		p += "?min_age=" + url.QueryEscape(minAge.String())
+	}
+	var out GCReport
+	err := c.do(ctx, method, p, nil, &out)
+	return out, err
+}
+
+type BackupFile struct {
+	Name         string `json:"name"`
+	Kind         string `json:"kind"`
+	SizeBytes    int64  `json:"size_bytes"`
+	SHA256       string `json:"sha256"`
+	Volume       string `json:"volume,omitempty"`
+	VirtualBytes int64  `json:"virtual_bytes,omitempty"`
+}
+
+// Backup is a VM backup on a backup target. ID is <target>/<vm>/<timestamp>.
+type Backup struct {
+	ID          string       `json:"id"`
+	Target      string       `json:"target"`
+	Policy      string       `json:"policy,omitempty"`
+	Consistency string       `json:"consistency"`
+	Quiesced    bool         `json:"quiesced"`
+	CreatedAt   time.Time    `json:"created_at"`
+	VM          VM           `json:"vm"`
+	Files       []BackupFile `json:"files"`
+	SizeBytes   int64        `json:"size_bytes"`
+}
+
+// CreateBackup starts a backup job for a VM under a backup policy, which
+// may be empty when the daemon has only one.
+func (c *Client) CreateBackup(ctx context.Context, vm, policy string) (Job, error) {
+	var out Job
+	err := c.do(ctx, http.MethodPost, "/api/v1/backups", map[string]string{"vm": vm, "policy": policy}, &out)
+	return out, err
+}
+
+// ListBackups lists backups, optionally only those on target or of vm.
+func (c *Client) ListBackups(ctx context.Context, target, vm string) ([]Backup, error) {
+	q := url.Values{}
+	if target != "" {
+		q.Set("target", target)
+	}
+	if vm != "" {
+		q.Set("vm", vm)
+	}
+	p := "/api/v1/backups"
+	if len(q) > 0 {
+		p += "?" + q.Encode()
+	}
+	var out []Backup
+	err := c.do(ctx, http.MethodGet, p, nil, &out)
+	return out, err
+}
+
+func (c *Client) GetBackup(ctx context.Context, id string) (Backup, error) {
+	var out Backup
+	err := c.do(ctx, http.MethodGet, "/api/v1/backups/"+id, nil, &out)
+	return out, err
+}
+
+// RestoreBackup starts a job creating a new VM from a backup.
+func (c *Client) RestoreBackup(ctx context.Context, id, name, pool string) (Job, error) {
+	var out Job
+	err := c.do(ctx, http.MethodPost, "/api/v1/backups/"+id+"/restore", map[string]string{"name": name, "pool": pool}, &out)
+	return out, err
+}
+
+func (c *Client) DeleteBackup(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/backups/"+id, nil, nil)
+}
+
+// Network APIs
+type Subnet struct {
+	CIDR      string `json:"cidr"`
+	Gateway   string `json:"gateway,omitempty"`
+	DHCPStart string `json:"dhcp_start,omitempty"`
+	DHCPEnd   string `json:"dhcp_end,omitempty"`
+}
+
+type NetworkSpec struct {
+	Name   string  `json:"name"`
+	Mode   string  `json:"mode"` // nat|isolated|routed
+	Bridge string  `json:"bridge,omitempty"`
+	Uplink string  `json:"uplink,omitempty"`
+	IPv4   *Subnet `json:"ipv4,omitempty"`
+	IPv6   *Subnet `json:"ipv6,omitempty"`
+	// ExternalDNS leaves DNS on the gateway to the daemon's DNS server.
+	ExternalDNS bool `json:"external_dns,omitempty"`
+	// PXE points VMs booting from the network to the daemon's boot server.
+	PXE bool `json:"pxe,omitempty"`
+	// Inbound and Outbound are the limits of NICs without their own.
+	Inbound  *Bandwidth `json:"inbound,omitempty"`
+	Outbound *Bandwidth `json:"outbound,omitempty"`
+}
+
+type Network struct {
+	NetworkSpec
+	UUID   string `json:"uuid"`
+	Active bool   `json:"active"`
+}
+
+func (c *Client) CreateNetwork(ctx context.Context, spec NetworkSpec) (Network, error) {
+	var out Network
+	err := c.do(ctx, http.MethodPost, "/api/v1/networks", spec, &out)
+	return out, err
+}
+
+func (c *Client) GetNetwork(ctx context.Context, name string) (Network, error) {
+	var out Network
+	err := c.do(ctx, http.MethodGet, "/api/v1/networks/"+name, nil, &out)
+	return out, err
+}
+
+func (c *Client) ListNetworks(ctx context.Context) ([]Network, error) {
+	var out []Network
+	err := c.do(ctx, http.MethodGet, "/api/v1/networks", nil, &out)
+	return out, err
+}
+
+// DeleteNetwork fails while VMs have NICs on the network.
+func (c *Client) DeleteNetwork(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/networks/"+name, nil, nil)
+}
+
+// Allocation is an address reserved for a VM NIC on a managed network.
+type Allocation struct {
+	Network   string    `json:"network"`
+	IP        string    `json:"ip"`
+	MAC       string    `json:"mac"`
+	VM        string    `json:"vm"`
+	VMName    string    `json:"vm_name"`
+	Static    bool      `json:"static,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (c *Client) ListAllocations(ctx context.Context, network string) ([]Allocation, error) {
+	var out []Allocation
+	err := c.do(ctx, http.MethodGet, "/api/v1/networks/"+network+"/allocations", nil, &out)
+	return out, err
+}
+
+// Security group APIs
+type SecurityRule struct {
+	Direction string   `json:"direction"` // ingress|egress
+	Protocol  string   `json:"protocol"`  // tcp|udp|icmp|icmpv6|any
+	PortFrom  int      `json:"port_from,omitempty"`
+	PortTo    int      `json:"port_to,omitempty"`
+	CIDRs     []string `json:"cidrs,omitempty"`
+	Groups    []string `json:"groups,omitempty"`
+}
+
+type SecurityGroup struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Rules       []SecurityRule `json:"rules"`
+}
+
+func (c *Client) CreateSecurityGroup(ctx context.Context, g SecurityGroup) (SecurityGroup, error) {
+	var out SecurityGroup
+	err := c.do(ctx, http.MethodPost, "/api/v1/security-groups", g, &out)
+	return out, err
+}
+
+func (c *Client) GetSecurityGroup(ctx context.Context, name string) (SecurityGroup, error) {
+	var out SecurityGroup
+	err := c.do(ctx, http.MethodGet, "/api/v1/security-groups/"+name, nil, &out)
+	return out, err
+}
+
+func (c *Client) ListSecurityGroups(ctx context.Context) ([]SecurityGroup, error) {
+	var out []SecurityGroup
+	err := c.do(ctx, http.MethodGet, "/api/v1/security-groups", nil, &out)
+	return out, err
+}
+
+// UpdateSecurityGroup replaces the description and rules of the group g.Name.
+func (c *Client) UpdateSecurityGroup(ctx context.Context, g SecurityGroup) (SecurityGroup, error) {
+	var out SecurityGroup
+	err := c.do(ctx, http.MethodPut, "/api/v1/security-groups/"+g.Name, g, &out)
+	return out, err
+}
+
+// DeleteSecurityGroup fails while a NIC is in the group or a rule refers to it.
+func (c *Client) DeleteSecurityGroup(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/security-groups/"+name, nil, nil)
+}
+
+// SetNICSecurityGroups replaces the groups of the NIC with the MAC address
+// mac; no groups leaves the NIC unfiltered.
+func (c *Client) SetNICSecurityGroups(ctx context.Context, id, mac string, groups []string) (VM, error) {
+	var out VM
+	err := c.do(ctx, http.MethodPut, "/api/v1/vms/"+id+"/nics/"+mac+"/security-groups", map[string][]string{"groups": groups}, &out)
+	return out, err
+}
+
+// AttachNIC plugs a NIC into a VM, live when it is running.
+func (c *Client) AttachNIC(ctx context.Context, id string, nic NIC) (VM, error) {
+	var out VM
+	err := c.do(ctx, http.MethodPost, "/api/v1/vms/"+id+"/nics", nic, &out)
+	return out, err
+}
+
+// UpdateNIC moves the NIC with the MAC address mac to nic.Network or
+// nic.Bridge, when set, and sets its link state, when set.
+func (c *Client) UpdateNIC(ctx context.Context, id, mac string, nic NIC) (VM, error) {
+	var out VM
+	err := c.do(ctx, http.MethodPut, "/api/v1/vms/"+id+"/nics/"+mac, nic, &out)
+	return out, err
+}
+
+func (c *Client) DetachNIC(ctx context.Context, id, mac string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/vms/"+id+"/nics/"+mac, nil, nil)
+}
+
+// Boot template APIs
+
+// BootTemplate is an iPXE script, rendered for each VM as a Go template.
+type BootTemplate struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Script      string `json:"script"`
+}
+
+func (c *Client) CreateBootTemplate(ctx context.Context, t BootTemplate) (BootTemplate, error) {
+	var out BootTemplate
+	err := c.do(ctx, http.MethodPost, "/api/v1/boot-templates", t, &out)
+	return out, err
+}
+
+func (c *Client) GetBootTemplate(ctx context.Context, name string) (BootTemplate, error) {
+	var out BootTemplate
+	err := c.do(ctx, http.MethodGet, "/api/v1/boot-templates/"+name, nil, &out)
+	return out, err
+}
+
+func (c *Client) ListBootTemplates(ctx context.Context) ([]BootTemplate, error) {
+	var out []BootTemplate
+	err := c.do(ctx, http.MethodGet, "/api/v1/boot-templates", nil, &out)
+	return out, err
+}
+
+// UpdateBootTemplate replaces the description and script of the template
+// t.Name.
+func (c *Client) UpdateBootTemplate(ctx context.Context, t BootTemplate) (BootTemplate, error) {
+	var out BootTemplate
+	err := c.do(ctx, http.MethodPut, "/api/v1/boot-templates/"+t.Name, t, &out)
+	return out, err
+}
+
+// DeleteBootTemplate fails while a VM boots from the template.
+func (c *Client) DeleteBootTemplate(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/boot-templates/"+name, nil, nil)
+}
+
+// SetVMBootTemplate sets the template a VM boots from over the network; an
+// empty name goes back to the default template.
+func (c *Client) SetVMBootTemplate(ctx context.Context, id, name string) (VM, error) {
+	var out VM
+	err := c.do(ctx, http.MethodPut, "/api/v1/vms/"+id+"/boot-template", map[string]string{"template": name}, &out)
+	return out, err
+}
+
+// Port forward APIs
+
+// PortForward sends connections to HostPort of the host, on HostIP or on
+// every host address when it is empty, to Port of a VM on a NAT network.
+type PortForward struct {
+	Name     string `json:"name"`
+	HostIP   string `json:"host_ip,omitempty"`
+	HostPort int    `json:"host_port"`
+	Protocol string `json:"protocol,omitempty"` // tcp (default) or udp
+	VM       string `json:"vm"`                 // id, or name on create
+	VMName   string `json:"vm_name,omitempty"`
+	Network  string `json:"network,omitempty"`
+	Port     int    `json:"port"`
+	Target   string `json:"target,omitempty"`
+	Active   bool   `json:"active,omitempty"`
+}
+
+func (c *Client) CreatePortForward(ctx context.Context, f PortForward) (PortForward, error) {
+	var out PortForward
+	err := c.do(ctx, http.MethodPost, "/api/v1/port-forwards", f, &out)
+	return out, err
+}
+
+func (c *Client) GetPortForward(ctx context.Context, name string) (PortForward, error) {
+	var out PortForward
+	err := c.do(ctx, http.MethodGet, "/api/v1/port-forwards/"+name, nil, &out)
+	return out, err
+}
+
+// ListPortForwards lists the forwards to vm, or all of them when it is empty.
+func (c *Client) ListPortForwards(ctx context.Context, vm string) ([]PortForward, error) {
+	var out []PortForward
+	p := "/api/v1/port-forwards"
+	if vm != "" {
+		p += "?vm=" + url.QueryEscape(vm)
+	}
+	err := c.do(ctx, http.MethodGet, p, nil, &out)
+	return out, err
+}
+
+func (c *Client) DeletePortForward(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/port-forwards/"+name, nil, nil)
+}
+
+// Host APIs
+
+// HostInterface is a network interface of the host: a physical NIC, a
+// bridge, a bond, a VLAN subinterface or another kernel link.
+type HostInterface struct {
+	Name      string   `json:"name"`
+	Index     int      `json:"index"`
+	Kind      string   `json:"kind"`
+	MAC       string   `json:"mac,omitempty"`
+	MTU       int      `json:"mtu"`
+	State     string   `json:"state"`      // up or down
+	OperState string   `json:"oper_state"` // as the kernel reports it
+	Master    string   `json:"master,omitempty"`
+	Ports     []string `json:"ports,omitempty"`
+	Parent    string   `json:"parent,omitempty"`
+	VLAN      int      `json:"vlan,omitempty"`
+	BondMode  string   `json:"bond_mode,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+func (c *Client) ListHostInterfaces(ctx context.Context) ([]HostInterface, error) {
+	var out []HostInterface
+	err := c.do(ctx, http.MethodGet, "/api/v1/host/interfaces", nil, &out)
+	return out, err
+}
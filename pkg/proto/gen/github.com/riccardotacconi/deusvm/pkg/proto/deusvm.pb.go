@@ -65,7 +65,11 @@ type VM struct {
 	MemoryBytes   int64                  `protobuf:"varint,4,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`
 	DiskBytes     int64                  `protobuf:"varint,5,opt,name=disk_bytes,json=diskBytes,proto3" json:"disk_bytes,omitempty"`
 	Image         string                 `protobuf:"bytes,6,opt,name=image,proto3" json:"image,omitempty"`
-	Status        string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"` // running|stopped|unknown
+	Status        string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`                        // running|stopped|unknown
+	Cdrom         string                 `protobuf:"bytes,8,opt,name=cdrom,proto3" json:"cdrom,omitempty"`                          // ISO in the CD-ROM drive, if any
+	BootOrder     []string               `protobuf:"bytes,9,rep,name=boot_order,json=bootOrder,proto3" json:"boot_order,omitempty"` // hd|cdrom|network
+	Nics          []*NIC                 `protobuf:"bytes,10,rep,name=nics,proto3" json:"nics,omitempty"`
+	BootTemplate  string                 `protobuf:"bytes,11,opt,name=boot_template,json=bootTemplate,proto3" json:"boot_template,omitempty"` // iPXE template for network boots; default when empty
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -149,32 +153,4383 @@ func (x *VM) GetStatus() string {
 	return ""
 }
 
+func (x *VM) GetCdrom() string {
+	if x != nil {
+		return x.Cdrom
+	}
+	return ""
+}
+
+func (x *VM) GetBootOrder() []string {
+	if x != nil {
+		return x.BootOrder
+	}
+	return nil
+}
+
+func (x *VM) GetNics() []*NIC {
+	if x != nil {
+		return x.Nics
+	}
+	return nil
+}
+
+func (x *VM) GetBootTemplate() string {
+	if x != nil {
+		return x.BootTemplate
+	}
+	return ""
+}
+
+// NIC connects a VM to a managed network or straight to a host bridge.
+type NIC struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Network string                 `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
+	Bridge  string                 `protobuf:"bytes,2,opt,name=bridge,proto3" json:"bridge,omitempty"`
+	Mac     string                 `protobuf:"bytes,3,opt,name=mac,proto3" json:"mac,omitempty"`     // generated when empty
+	Model   string                 `protobuf:"bytes,4,opt,name=model,proto3" json:"model,omitempty"` // virtio when empty
+	// Fixed addresses on a managed network; on VMs, the reserved addresses.
+	Ipv4           string   `protobuf:"bytes,5,opt,name=ipv4,proto3" json:"ipv4,omitempty"`
+	Ipv6           string   `protobuf:"bytes,6,opt,name=ipv6,proto3" json:"ipv6,omitempty"`
+	SecurityGroups []string `protobuf:"bytes,7,rep,name=security_groups,json=securityGroups,proto3" json:"security_groups,omitempty"` // unfiltered when empty
+	Device         string   `protobuf:"bytes,8,opt,name=device,proto3" json:"device,omitempty"`                                       // host tap device while the VM runs
+	BridgeType     string   `protobuf:"bytes,9,opt,name=bridge_type,json=bridgeType,proto3" json:"bridge_type,omitempty"`             // linux or openvswitch, for host bridges
+	Vlan           int32    `protobuf:"varint,10,opt,name=vlan,proto3" json:"vlan,omitempty"`                                         // access VLAN, openvswitch only
+	Trunk          []int32  `protobuf:"varint,11,rep,packed,name=trunk,proto3" json:"trunk,omitempty"`                                // trunked VLANs, openvswitch only
+	LinkState      string   `protobuf:"bytes,12,opt,name=link_state,json=linkState,proto3" json:"link_state,omitempty"`               // up or down; up when empty
+	// Limits of the traffic to and from the VM; NICs on a managed network
+	// default to its limits.
+	Inbound       *Bandwidth `protobuf:"bytes,13,opt,name=inbound,proto3" json:"inbound,omitempty"`
+	Outbound      *Bandwidth `protobuf:"bytes,14,opt,name=outbound,proto3" json:"outbound,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NIC) Reset() {
+	*x = NIC{}
+	mi := &file_deusvm_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NIC) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NIC) ProtoMessage() {}
+
+func (x *NIC) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NIC.ProtoReflect.Descriptor instead.
+func (*NIC) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *NIC) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *NIC) GetBridge() string {
+	if x != nil {
+		return x.Bridge
+	}
+	return ""
+}
+
+func (x *NIC) GetMac() string {
+	if x != nil {
+		return x.Mac
+	}
+	return ""
+}
+
+func (x *NIC) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *NIC) GetIpv4() string {
+	if x != nil {
+		return x.Ipv4
+	}
+	return ""
+}
+
+func (x *NIC) GetIpv6() string {
+	if x != nil {
+		return x.Ipv6
+	}
+	return ""
+}
+
+func (x *NIC) GetSecurityGroups() []string {
+	if x != nil {
+		return x.SecurityGroups
+	}
+	return nil
+}
+
+func (x *NIC) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+func (x *NIC) GetBridgeType() string {
+	if x != nil {
+		return x.BridgeType
+	}
+	return ""
+}
+
+func (x *NIC) GetVlan() int32 {
+	if x != nil {
+		return x.Vlan
+	}
+	return 0
+}
+
+func (x *NIC) GetTrunk() []int32 {
+	if x != nil {
+		return x.Trunk
+	}
+	return nil
+}
+
+func (x *NIC) GetLinkState() string {
+	if x != nil {
+		return x.LinkState
+	}
+	return ""
+}
+
+func (x *NIC) GetInbound() *Bandwidth {
+	if x != nil {
+		return x.Inbound
+	}
+	return nil
+}
+
+func (x *NIC) GetOutbound() *Bandwidth {
+	if x != nil {
+		return x.Outbound
+	}
+	return nil
+}
+
+// Bandwidth is a traffic limit in one direction: average and peak in KiB/s,
+// burst in KiB sent at peak rate. A zero average lifts the limit.
+type Bandwidth struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Average       uint32                 `protobuf:"varint,1,opt,name=average,proto3" json:"average,omitempty"`
+	Peak          uint32                 `protobuf:"varint,2,opt,name=peak,proto3" json:"peak,omitempty"`
+	Burst         uint32                 `protobuf:"varint,3,opt,name=burst,proto3" json:"burst,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Bandwidth) Reset() {
+	*x = Bandwidth{}
+	mi := &file_deusvm_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Bandwidth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Bandwidth) ProtoMessage() {}
+
+func (x *Bandwidth) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Bandwidth.ProtoReflect.Descriptor instead.
+func (*Bandwidth) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Bandwidth) GetAverage() uint32 {
+	if x != nil {
+		return x.Average
+	}
+	return 0
+}
+
+func (x *Bandwidth) GetPeak() uint32 {
+	if x != nil {
+		return x.Peak
+	}
+	return 0
+}
+
+func (x *Bandwidth) GetBurst() uint32 {
+	if x != nil {
+		return x.Burst
+	}
+	return 0
+}
+
 type CreateVMRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Name        string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Image       string                 `protobuf:"bytes,2,opt,name=image,proto3" json:"image,omitempty"`
+	Cpu         int32                  `protobuf:"varint,3,opt,name=cpu,proto3" json:"cpu,omitempty"`
+	MemoryBytes int64                  `protobuf:"varint,4,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`
+	DiskBytes   int64                  `protobuf:"varint,5,opt,name=disk_bytes,json=diskBytes,proto3" json:"disk_bytes,omitempty"`
+	// storage pool for the root volume; the configured default when empty
+	Pool string `protobuf:"bytes,6,opt,name=pool,proto3" json:"pool,omitempty"`
+	// ISO image to boot an installer from; the root volume is then a blank
+	// disk of disk_bytes instead of a clone of image
+	Iso       string   `protobuf:"bytes,7,opt,name=iso,proto3" json:"iso,omitempty"`
+	BootOrder []string `protobuf:"bytes,8,rep,name=boot_order,json=bootOrder,proto3" json:"boot_order,omitempty"` // hd|cdrom|network; cdrom first with an iso
+	// one NIC on the configured bridge when empty
+	Nics []*NIC `protobuf:"bytes,9,rep,name=nics,proto3" json:"nics,omitempty"`
+	// network boots from the NICs first, then from the root volume, which is
+	// blank unless image is set; exclusive with boot_order
+	Boot          string `protobuf:"bytes,10,opt,name=boot,proto3" json:"boot,omitempty"`
+	BootTemplate  string `protobuf:"bytes,11,opt,name=boot_template,json=bootTemplate,proto3" json:"boot_template,omitempty"` // iPXE template for network boots
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateVMRequest) Reset() {
+	*x = CreateVMRequest{}
+	mi := &file_deusvm_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateVMRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateVMRequest) ProtoMessage() {}
+
+func (x *CreateVMRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateVMRequest.ProtoReflect.Descriptor instead.
+func (*CreateVMRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateVMRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateVMRequest) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *CreateVMRequest) GetCpu() int32 {
+	if x != nil {
+		return x.Cpu
+	}
+	return 0
+}
+
+func (x *CreateVMRequest) GetMemoryBytes() int64 {
+	if x != nil {
+		return x.MemoryBytes
+	}
+	return 0
+}
+
+func (x *CreateVMRequest) GetDiskBytes() int64 {
+	if x != nil {
+		return x.DiskBytes
+	}
+	return 0
+}
+
+func (x *CreateVMRequest) GetPool() string {
+	if x != nil {
+		return x.Pool
+	}
+	return ""
+}
+
+func (x *CreateVMRequest) GetIso() string {
+	if x != nil {
+		return x.Iso
+	}
+	return ""
+}
+
+func (x *CreateVMRequest) GetBootOrder() []string {
+	if x != nil {
+		return x.BootOrder
+	}
+	return nil
+}
+
+func (x *CreateVMRequest) GetNics() []*NIC {
+	if x != nil {
+		return x.Nics
+	}
+	return nil
+}
+
+func (x *CreateVMRequest) GetBoot() string {
+	if x != nil {
+		return x.Boot
+	}
+	return ""
+}
+
+func (x *CreateVMRequest) GetBootTemplate() string {
+	if x != nil {
+		return x.BootTemplate
+	}
+	return ""
+}
+
+type VMIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // allow either id or name for convenience
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VMIDRequest) Reset() {
+	*x = VMIDRequest{}
+	mi := &file_deusvm_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VMIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VMIDRequest) ProtoMessage() {}
+
+func (x *VMIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VMIDRequest.ProtoReflect.Descriptor instead.
+func (*VMIDRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *VMIDRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type InsertMediaRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`       // VM id or name
+	Image         string                 `protobuf:"bytes,2,opt,name=image,proto3" json:"image,omitempty"` // ISO image name
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InsertMediaRequest) Reset() {
+	*x = InsertMediaRequest{}
+	mi := &file_deusvm_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InsertMediaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InsertMediaRequest) ProtoMessage() {}
+
+func (x *InsertMediaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InsertMediaRequest.ProtoReflect.Descriptor instead.
+func (*InsertMediaRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *InsertMediaRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *InsertMediaRequest) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+type AttachInterfaceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // VM id or name
+	Nic           *NIC                   `protobuf:"bytes,2,opt,name=nic,proto3" json:"nic,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachInterfaceRequest) Reset() {
+	*x = AttachInterfaceRequest{}
+	mi := &file_deusvm_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachInterfaceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachInterfaceRequest) ProtoMessage() {}
+
+func (x *AttachInterfaceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachInterfaceRequest.ProtoReflect.Descriptor instead.
+func (*AttachInterfaceRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AttachInterfaceRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AttachInterfaceRequest) GetNic() *NIC {
+	if x != nil {
+		return x.Nic
+	}
+	return nil
+}
+
+type DetachInterfaceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // VM id or name
+	Mac           string                 `protobuf:"bytes,2,opt,name=mac,proto3" json:"mac,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DetachInterfaceRequest) Reset() {
+	*x = DetachInterfaceRequest{}
+	mi := &file_deusvm_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DetachInterfaceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetachInterfaceRequest) ProtoMessage() {}
+
+func (x *DetachInterfaceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetachInterfaceRequest.ProtoReflect.Descriptor instead.
+func (*DetachInterfaceRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DetachInterfaceRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DetachInterfaceRequest) GetMac() string {
+	if x != nil {
+		return x.Mac
+	}
+	return ""
+}
+
+// UpdateInterfaceRequest moves the NIC with nic.mac to nic.network or
+// nic.bridge, when set, and sets its link state, when set.
+type UpdateInterfaceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // VM id or name
+	Nic           *NIC                   `protobuf:"bytes,2,opt,name=nic,proto3" json:"nic,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateInterfaceRequest) Reset() {
+	*x = UpdateInterfaceRequest{}
+	mi := &file_deusvm_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateInterfaceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateInterfaceRequest) ProtoMessage() {}
+
+func (x *UpdateInterfaceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateInterfaceRequest.ProtoReflect.Descriptor instead.
+func (*UpdateInterfaceRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateInterfaceRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateInterfaceRequest) GetNic() *NIC {
+	if x != nil {
+		return x.Nic
+	}
+	return nil
+}
+
+type ListVMsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Vms           []*VM                  `protobuf:"bytes,1,rep,name=vms,proto3" json:"vms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVMsResponse) Reset() {
+	*x = ListVMsResponse{}
+	mi := &file_deusvm_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVMsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVMsResponse) ProtoMessage() {}
+
+func (x *ListVMsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVMsResponse.ProtoReflect.Descriptor instead.
+func (*ListVMsResponse) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListVMsResponse) GetVms() []*VM {
+	if x != nil {
+		return x.Vms
+	}
+	return nil
+}
+
+// VMArchiveChunk carries part of a zstd-compressed tar archive of a VM.
+type VMArchiveChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chunk         []byte                 `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VMArchiveChunk) Reset() {
+	*x = VMArchiveChunk{}
+	mi := &file_deusvm_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VMArchiveChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VMArchiveChunk) ProtoMessage() {}
+
+func (x *VMArchiveChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VMArchiveChunk.ProtoReflect.Descriptor instead.
+func (*VMArchiveChunk) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *VMArchiveChunk) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+type ImportVMInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // name of the new VM; the archived name if empty
+	Pool          string                 `protobuf:"bytes,2,opt,name=pool,proto3" json:"pool,omitempty"` // storage pool for its disks
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportVMInfo) Reset() {
+	*x = ImportVMInfo{}
+	mi := &file_deusvm_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportVMInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportVMInfo) ProtoMessage() {}
+
+func (x *ImportVMInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportVMInfo.ProtoReflect.Descriptor instead.
+func (*ImportVMInfo) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ImportVMInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ImportVMInfo) GetPool() string {
+	if x != nil {
+		return x.Pool
+	}
+	return ""
+}
+
+// ImportVMRequest is sent as a stream: info first, then archive chunks.
+type ImportVMRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*ImportVMRequest_Info
+	//	*ImportVMRequest_Chunk
+	Payload       isImportVMRequest_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportVMRequest) Reset() {
+	*x = ImportVMRequest{}
+	mi := &file_deusvm_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportVMRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportVMRequest) ProtoMessage() {}
+
+func (x *ImportVMRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportVMRequest.ProtoReflect.Descriptor instead.
+func (*ImportVMRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ImportVMRequest) GetPayload() isImportVMRequest_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *ImportVMRequest) GetInfo() *ImportVMInfo {
+	if x != nil {
+		if x, ok := x.Payload.(*ImportVMRequest_Info); ok {
+			return x.Info
+		}
+	}
+	return nil
+}
+
+func (x *ImportVMRequest) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*ImportVMRequest_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+type isImportVMRequest_Payload interface {
+	isImportVMRequest_Payload()
+}
+
+type ImportVMRequest_Info struct {
+	Info *ImportVMInfo `protobuf:"bytes,1,opt,name=info,proto3,oneof"`
+}
+
+type ImportVMRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*ImportVMRequest_Info) isImportVMRequest_Payload() {}
+
+func (*ImportVMRequest_Chunk) isImportVMRequest_Payload() {}
+
+type Image struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	SizeBytes     int64                  `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	Format        string                 `protobuf:"bytes,4,opt,name=format,proto3" json:"format,omitempty"`                                 // qcow2|raw|vmdk|vhdx|iso|unknown
+	Sha256        string                 `protobuf:"bytes,5,opt,name=sha256,proto3" json:"sha256,omitempty"`                                 // of the stored (decompressed) content
+	Compression   string                 `protobuf:"bytes,6,opt,name=compression,proto3" json:"compression,omitempty"`                       // gzip|xz|zstd|bzip2 when decompressed on import
+	SourceSha256  string                 `protobuf:"bytes,7,opt,name=source_sha256,json=sourceSha256,proto3" json:"source_sha256,omitempty"` // of the transferred stream, when compressed
+	UsedBy        int32                  `protobuf:"varint,8,opt,name=used_by,json=usedBy,proto3" json:"used_by,omitempty"`                  // number of VMs and disks depending on the image
+	Lineage       *Lineage               `protobuf:"bytes,9,opt,name=lineage,proto3" json:"lineage,omitempty"`                               // set for images captured from a VM
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Image) Reset() {
+	*x = Image{}
+	mi := &file_deusvm_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Image) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Image) ProtoMessage() {}
+
+func (x *Image) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Image.ProtoReflect.Descriptor instead.
+func (*Image) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Image) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Image) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Image) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *Image) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *Image) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *Image) GetCompression() string {
+	if x != nil {
+		return x.Compression
+	}
+	return ""
+}
+
+func (x *Image) GetSourceSha256() string {
+	if x != nil {
+		return x.SourceSha256
+	}
+	return ""
+}
+
+func (x *Image) GetUsedBy() int32 {
+	if x != nil {
+		return x.UsedBy
+	}
+	return 0
+}
+
+func (x *Image) GetLineage() *Lineage {
+	if x != nil {
+		return x.Lineage
+	}
+	return nil
+}
+
+type Lineage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SourceVm      string                 `protobuf:"bytes,1,opt,name=source_vm,json=sourceVm,proto3" json:"source_vm,omitempty"`
+	SourceDisk    string                 `protobuf:"bytes,2,opt,name=source_disk,json=sourceDisk,proto3" json:"source_disk,omitempty"` // volume name, or path for disks that are not volumes
+	Parent        string                 `protobuf:"bytes,3,opt,name=parent,proto3" json:"parent,omitempty"`                           // image the captured disk was cloned from
+	CapturedAt    string                 `protobuf:"bytes,4,opt,name=captured_at,json=capturedAt,proto3" json:"captured_at,omitempty"` // RFC 3339
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Lineage) Reset() {
+	*x = Lineage{}
+	mi := &file_deusvm_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Lineage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Lineage) ProtoMessage() {}
+
+func (x *Lineage) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Lineage.ProtoReflect.Descriptor instead.
+func (*Lineage) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Lineage) GetSourceVm() string {
+	if x != nil {
+		return x.SourceVm
+	}
+	return ""
+}
+
+func (x *Lineage) GetSourceDisk() string {
+	if x != nil {
+		return x.SourceDisk
+	}
+	return ""
+}
+
+func (x *Lineage) GetParent() string {
+	if x != nil {
+		return x.Parent
+	}
+	return ""
+}
+
+func (x *Lineage) GetCapturedAt() string {
+	if x != nil {
+		return x.CapturedAt
+	}
+	return ""
+}
+
+type CreateImageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Source        string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"` // URL
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateImageRequest) Reset() {
+	*x = CreateImageRequest{}
+	mi := &file_deusvm_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateImageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateImageRequest) ProtoMessage() {}
+
+func (x *CreateImageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateImageRequest.ProtoReflect.Descriptor instead.
+func (*CreateImageRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CreateImageRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateImageRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+type ImageProgress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BytesDone     int64                  `protobuf:"varint,1,opt,name=bytes_done,json=bytesDone,proto3" json:"bytes_done,omitempty"`
+	BytesTotal    int64                  `protobuf:"varint,2,opt,name=bytes_total,json=bytesTotal,proto3" json:"bytes_total,omitempty"` // -1 when the source does not report a size
+	Image         *Image                 `protobuf:"bytes,3,opt,name=image,proto3" json:"image,omitempty"`                              // set on the last message, once the image is stored
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImageProgress) Reset() {
+	*x = ImageProgress{}
+	mi := &file_deusvm_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImageProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImageProgress) ProtoMessage() {}
+
+func (x *ImageProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImageProgress.ProtoReflect.Descriptor instead.
+func (*ImageProgress) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ImageProgress) GetBytesDone() int64 {
+	if x != nil {
+		return x.BytesDone
+	}
+	return 0
+}
+
+func (x *ImageProgress) GetBytesTotal() int64 {
+	if x != nil {
+		return x.BytesTotal
+	}
+	return 0
+}
+
+func (x *ImageProgress) GetImage() *Image {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+type UploadImageInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	SizeBytes     int64                  `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"` // optional, informational
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadImageInfo) Reset() {
+	*x = UploadImageInfo{}
+	mi := &file_deusvm_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadImageInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadImageInfo) ProtoMessage() {}
+
+func (x *UploadImageInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadImageInfo.ProtoReflect.Descriptor instead.
+func (*UploadImageInfo) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *UploadImageInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UploadImageInfo) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+// UploadImageRequest is sent as a stream: info first, then data chunks, then
+// optionally the sha256 of the whole content, which is checked before the
+// image is stored.
+type UploadImageRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*UploadImageRequest_Info
+	//	*UploadImageRequest_Chunk
+	//	*UploadImageRequest_Sha256
+	Payload       isUploadImageRequest_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadImageRequest) Reset() {
+	*x = UploadImageRequest{}
+	mi := &file_deusvm_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadImageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadImageRequest) ProtoMessage() {}
+
+func (x *UploadImageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadImageRequest.ProtoReflect.Descriptor instead.
+func (*UploadImageRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *UploadImageRequest) GetPayload() isUploadImageRequest_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *UploadImageRequest) GetInfo() *UploadImageInfo {
+	if x != nil {
+		if x, ok := x.Payload.(*UploadImageRequest_Info); ok {
+			return x.Info
+		}
+	}
+	return nil
+}
+
+func (x *UploadImageRequest) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*UploadImageRequest_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+func (x *UploadImageRequest) GetSha256() string {
+	if x != nil {
+		if x, ok := x.Payload.(*UploadImageRequest_Sha256); ok {
+			return x.Sha256
+		}
+	}
+	return ""
+}
+
+type isUploadImageRequest_Payload interface {
+	isUploadImageRequest_Payload()
+}
+
+type UploadImageRequest_Info struct {
+	Info *UploadImageInfo `protobuf:"bytes,1,opt,name=info,proto3,oneof"`
+}
+
+type UploadImageRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+type UploadImageRequest_Sha256 struct {
+	Sha256 string `protobuf:"bytes,3,opt,name=sha256,proto3,oneof"`
+}
+
+func (*UploadImageRequest_Info) isUploadImageRequest_Payload() {}
+
+func (*UploadImageRequest_Chunk) isUploadImageRequest_Payload() {}
+
+func (*UploadImageRequest_Sha256) isUploadImageRequest_Payload() {}
+
+type ImageNameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Force         bool                   `protobuf:"varint,2,opt,name=force,proto3" json:"force,omitempty"` // delete even if VMs or disks still use the image
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImageNameRequest) Reset() {
+	*x = ImageNameRequest{}
+	mi := &file_deusvm_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImageNameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImageNameRequest) ProtoMessage() {}
+
+func (x *ImageNameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImageNameRequest.ProtoReflect.Descriptor instead.
+func (*ImageNameRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ImageNameRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ImageNameRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type TagImageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"` // existing image name
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`     // additional name for the same content
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagImageRequest) Reset() {
+	*x = TagImageRequest{}
+	mi := &file_deusvm_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagImageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagImageRequest) ProtoMessage() {}
+
+func (x *TagImageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagImageRequest.ProtoReflect.Descriptor instead.
+func (*TagImageRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *TagImageRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *TagImageRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type CaptureImageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VmId          string                 `protobuf:"bytes,1,opt,name=vm_id,json=vmId,proto3" json:"vm_id,omitempty"` // VM id or name; its boot disk is captured
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`             // name of the new image
+	Sparsify      bool                   `protobuf:"varint,3,opt,name=sparsify,proto3" json:"sparsify,omitempty"`    // drop blocks unused by the guest filesystems
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureImageRequest) Reset() {
+	*x = CaptureImageRequest{}
+	mi := &file_deusvm_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureImageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureImageRequest) ProtoMessage() {}
+
+func (x *CaptureImageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureImageRequest.ProtoReflect.Descriptor instead.
+func (*CaptureImageRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CaptureImageRequest) GetVmId() string {
+	if x != nil {
+		return x.VmId
+	}
+	return ""
+}
+
+func (x *CaptureImageRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CaptureImageRequest) GetSparsify() bool {
+	if x != nil {
+		return x.Sparsify
+	}
+	return false
+}
+
+type ListImagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Images        []*Image               `protobuf:"bytes,1,rep,name=images,proto3" json:"images,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListImagesResponse) Reset() {
+	*x = ListImagesResponse{}
+	mi := &file_deusvm_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListImagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListImagesResponse) ProtoMessage() {}
+
+func (x *ListImagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListImagesResponse.ProtoReflect.Descriptor instead.
+func (*ListImagesResponse) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ListImagesResponse) GetImages() []*Image {
+	if x != nil {
+		return x.Images
+	}
+	return nil
+}
+
+type Volume struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Pool          string                 `protobuf:"bytes,2,opt,name=pool,proto3" json:"pool,omitempty"`
+	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	Format        string                 `protobuf:"bytes,4,opt,name=format,proto3" json:"format,omitempty"` // qcow2|raw
+	SizeBytes     int64                  `protobuf:"varint,5,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	VmId          string                 `protobuf:"bytes,6,opt,name=vm_id,json=vmId,proto3" json:"vm_id,omitempty"`                            // VM the volume is attached to, empty when detached
+	Image         string                 `protobuf:"bytes,7,opt,name=image,proto3" json:"image,omitempty"`                                      // image the volume was cloned from, if any
+	DeleteWithVm  bool                   `protobuf:"varint,8,opt,name=delete_with_vm,json=deleteWithVm,proto3" json:"delete_with_vm,omitempty"` // created with a VM and deleted along with it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Volume) Reset() {
+	*x = Volume{}
+	mi := &file_deusvm_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Volume) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Volume) ProtoMessage() {}
+
+func (x *Volume) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Volume.ProtoReflect.Descriptor instead.
+func (*Volume) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *Volume) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Volume) GetPool() string {
+	if x != nil {
+		return x.Pool
+	}
+	return ""
+}
+
+func (x *Volume) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Volume) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *Volume) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *Volume) GetVmId() string {
+	if x != nil {
+		return x.VmId
+	}
+	return ""
+}
+
+func (x *Volume) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *Volume) GetDeleteWithVm() bool {
+	if x != nil {
+		return x.DeleteWithVm
+	}
+	return false
+}
+
+type CreateVolumeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Pool          string                 `protobuf:"bytes,2,opt,name=pool,proto3" json:"pool,omitempty"`                             // default pool when empty
+	SizeBytes     int64                  `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"` // may be 0 when cloning an image, to keep its size
+	Format        string                 `protobuf:"bytes,4,opt,name=format,proto3" json:"format,omitempty"`                         // qcow2 or raw for directory pools
+	Image         string                 `protobuf:"bytes,5,opt,name=image,proto3" json:"image,omitempty"`                           // optional image name or path to start from
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateVolumeRequest) Reset() {
+	*x = CreateVolumeRequest{}
+	mi := &file_deusvm_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateVolumeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateVolumeRequest) ProtoMessage() {}
+
+func (x *CreateVolumeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateVolumeRequest.ProtoReflect.Descriptor instead.
+func (*CreateVolumeRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *CreateVolumeRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateVolumeRequest) GetPool() string {
+	if x != nil {
+		return x.Pool
+	}
+	return ""
+}
+
+func (x *CreateVolumeRequest) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *CreateVolumeRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *CreateVolumeRequest) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+type VolumeNameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VolumeNameRequest) Reset() {
+	*x = VolumeNameRequest{}
+	mi := &file_deusvm_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VolumeNameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VolumeNameRequest) ProtoMessage() {}
+
+func (x *VolumeNameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VolumeNameRequest.ProtoReflect.Descriptor instead.
+func (*VolumeNameRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *VolumeNameRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type AttachVolumeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	VmId          string                 `protobuf:"bytes,2,opt,name=vm_id,json=vmId,proto3" json:"vm_id,omitempty"` // VM id or name
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachVolumeRequest) Reset() {
+	*x = AttachVolumeRequest{}
+	mi := &file_deusvm_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachVolumeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachVolumeRequest) ProtoMessage() {}
+
+func (x *AttachVolumeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachVolumeRequest.ProtoReflect.Descriptor instead.
+func (*AttachVolumeRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *AttachVolumeRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AttachVolumeRequest) GetVmId() string {
+	if x != nil {
+		return x.VmId
+	}
+	return ""
+}
+
+type ResizeVolumeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	SizeBytes     int64                  `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"` // new size, volumes only grow
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResizeVolumeRequest) Reset() {
+	*x = ResizeVolumeRequest{}
+	mi := &file_deusvm_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResizeVolumeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResizeVolumeRequest) ProtoMessage() {}
+
+func (x *ResizeVolumeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResizeVolumeRequest.ProtoReflect.Descriptor instead.
+func (*ResizeVolumeRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ResizeVolumeRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ResizeVolumeRequest) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+type CloneVolumeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Pool          string                 `protobuf:"bytes,3,opt,name=pool,proto3" json:"pool,omitempty"`                             // pool of the source when empty
+	SizeBytes     int64                  `protobuf:"varint,4,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"` // optional, to grow the clone
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloneVolumeRequest) Reset() {
+	*x = CloneVolumeRequest{}
+	mi := &file_deusvm_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloneVolumeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloneVolumeRequest) ProtoMessage() {}
+
+func (x *CloneVolumeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloneVolumeRequest.ProtoReflect.Descriptor instead.
+func (*CloneVolumeRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *CloneVolumeRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *CloneVolumeRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CloneVolumeRequest) GetPool() string {
+	if x != nil {
+		return x.Pool
+	}
+	return ""
+}
+
+func (x *CloneVolumeRequest) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+type ListVolumesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Volumes       []*Volume              `protobuf:"bytes,1,rep,name=volumes,proto3" json:"volumes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVolumesResponse) Reset() {
+	*x = ListVolumesResponse{}
+	mi := &file_deusvm_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVolumesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVolumesResponse) ProtoMessage() {}
+
+func (x *ListVolumesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVolumesResponse.ProtoReflect.Descriptor instead.
+func (*ListVolumesResponse) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ListVolumesResponse) GetVolumes() []*Volume {
+	if x != nil {
+		return x.Volumes
+	}
+	return nil
+}
+
+type ConvertRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// exactly one of image and volume
+	Image         string `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	Volume        string `protobuf:"bytes,2,opt,name=volume,proto3" json:"volume,omitempty"`
+	Name          string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`          // new image name; the image is replaced when empty
+	Format        string `protobuf:"bytes,4,opt,name=format,proto3" json:"format,omitempty"`      // raw|qcow2|vmdk; empty keeps the format and only drops zeroed regions
+	Compress      bool   `protobuf:"varint,5,opt,name=compress,proto3" json:"compress,omitempty"` // compressed qcow2
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConvertRequest) Reset() {
+	*x = ConvertRequest{}
+	mi := &file_deusvm_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertRequest) ProtoMessage() {}
+
+func (x *ConvertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertRequest.ProtoReflect.Descriptor instead.
+func (*ConvertRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ConvertRequest) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *ConvertRequest) GetVolume() string {
+	if x != nil {
+		return x.Volume
+	}
+	return ""
+}
+
+func (x *ConvertRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ConvertRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ConvertRequest) GetCompress() bool {
+	if x != nil {
+		return x.Compress
+	}
+	return false
+}
+
+type Job struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`           // e.g. convert
+	Target        string                 `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`       // image/<name> or volume/<name>
+	State         string                 `protobuf:"bytes,4,opt,name=state,proto3" json:"state,omitempty"`         // running|succeeded|failed
+	Progress      float64                `protobuf:"fixed64,5,opt,name=progress,proto3" json:"progress,omitempty"` // percent
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`    // RFC 3339
+	FinishedAt    string                 `protobuf:"bytes,8,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"` // RFC 3339, empty while running
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	mi := &file_deusvm_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *Job) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Job) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Job) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *Job) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Job) GetProgress() float64 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+func (x *Job) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Job) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Job) GetFinishedAt() string {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return ""
+}
+
+type JobIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobIDRequest) Reset() {
+	*x = JobIDRequest{}
+	mi := &file_deusvm_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobIDRequest) ProtoMessage() {}
+
+func (x *JobIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobIDRequest.ProtoReflect.Descriptor instead.
+func (*JobIDRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *JobIDRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListJobsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jobs          []*Job                 `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsResponse) Reset() {
+	*x = ListJobsResponse{}
+	mi := &file_deusvm_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsResponse) ProtoMessage() {}
+
+func (x *ListJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsResponse.ProtoReflect.Descriptor instead.
+func (*ListJobsResponse) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ListJobsResponse) GetJobs() []*Job {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+type PoolUsage struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Name             string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // "images" for the filesystem holding images
+	Type             string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"` // dir|lvm-thin|zfs
+	CapacityBytes    int64                  `protobuf:"varint,3,opt,name=capacity_bytes,json=capacityBytes,proto3" json:"capacity_bytes,omitempty"`
+	UsedBytes        int64                  `protobuf:"varint,4,opt,name=used_bytes,json=usedBytes,proto3" json:"used_bytes,omitempty"`
+	FreeBytes        int64                  `protobuf:"varint,5,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"`
+	ProvisionedBytes int64                  `protobuf:"varint,6,opt,name=provisioned_bytes,json=provisionedBytes,proto3" json:"provisioned_bytes,omitempty"` // virtual size of all volumes in the pool
+	Overcommit       float64                `protobuf:"fixed64,7,opt,name=overcommit,proto3" json:"overcommit,omitempty"`                                    // provisioned_bytes / capacity_bytes
+	HighWaterMark    float64                `protobuf:"fixed64,8,opt,name=high_water_mark,json=highWaterMark,proto3" json:"high_water_mark,omitempty"`       // percent of capacity, 0 when not enforced
+	MaxOvercommit    float64                `protobuf:"fixed64,9,opt,name=max_overcommit,json=maxOvercommit,proto3" json:"max_overcommit,omitempty"`         // 0 when not enforced
+	Error            string                 `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`                                               // set when the pool could not be queried
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *PoolUsage) Reset() {
+	*x = PoolUsage{}
+	mi := &file_deusvm_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PoolUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PoolUsage) ProtoMessage() {}
+
+func (x *PoolUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PoolUsage.ProtoReflect.Descriptor instead.
+func (*PoolUsage) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *PoolUsage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PoolUsage) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *PoolUsage) GetCapacityBytes() int64 {
+	if x != nil {
+		return x.CapacityBytes
+	}
+	return 0
+}
+
+func (x *PoolUsage) GetUsedBytes() int64 {
+	if x != nil {
+		return x.UsedBytes
+	}
+	return 0
+}
+
+func (x *PoolUsage) GetFreeBytes() int64 {
+	if x != nil {
+		return x.FreeBytes
+	}
+	return 0
+}
+
+func (x *PoolUsage) GetProvisionedBytes() int64 {
+	if x != nil {
+		return x.ProvisionedBytes
+	}
+	return 0
+}
+
+func (x *PoolUsage) GetOvercommit() float64 {
+	if x != nil {
+		return x.Overcommit
+	}
+	return 0
+}
+
+func (x *PoolUsage) GetHighWaterMark() float64 {
+	if x != nil {
+		return x.HighWaterMark
+	}
+	return 0
+}
+
+func (x *PoolUsage) GetMaxOvercommit() float64 {
+	if x != nil {
+		return x.MaxOvercommit
+	}
+	return 0
+}
+
+func (x *PoolUsage) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ImageUsage struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Name           string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Sha256         string                 `protobuf:"bytes,2,opt,name=sha256,proto3" json:"sha256,omitempty"` // names with the same content share one allocation
+	AllocatedBytes int64                  `protobuf:"varint,3,opt,name=allocated_bytes,json=allocatedBytes,proto3" json:"allocated_bytes,omitempty"`
+	VirtualBytes   int64                  `protobuf:"varint,4,opt,name=virtual_bytes,json=virtualBytes,proto3" json:"virtual_bytes,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ImageUsage) Reset() {
+	*x = ImageUsage{}
+	mi := &file_deusvm_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImageUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImageUsage) ProtoMessage() {}
+
+func (x *ImageUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImageUsage.ProtoReflect.Descriptor instead.
+func (*ImageUsage) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ImageUsage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ImageUsage) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *ImageUsage) GetAllocatedBytes() int64 {
+	if x != nil {
+		return x.AllocatedBytes
+	}
+	return 0
+}
+
+func (x *ImageUsage) GetVirtualBytes() int64 {
+	if x != nil {
+		return x.VirtualBytes
+	}
+	return 0
+}
+
+type VMUsage struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	VmId           string                 `protobuf:"bytes,1,opt,name=vm_id,json=vmId,proto3" json:"vm_id,omitempty"`
+	Name           string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Volumes        []string               `protobuf:"bytes,3,rep,name=volumes,proto3" json:"volumes,omitempty"`
+	AllocatedBytes int64                  `protobuf:"varint,4,opt,name=allocated_bytes,json=allocatedBytes,proto3" json:"allocated_bytes,omitempty"`
+	VirtualBytes   int64                  `protobuf:"varint,5,opt,name=virtual_bytes,json=virtualBytes,proto3" json:"virtual_bytes,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *VMUsage) Reset() {
+	*x = VMUsage{}
+	mi := &file_deusvm_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VMUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VMUsage) ProtoMessage() {}
+
+func (x *VMUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VMUsage.ProtoReflect.Descriptor instead.
+func (*VMUsage) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *VMUsage) GetVmId() string {
+	if x != nil {
+		return x.VmId
+	}
+	return ""
+}
+
+func (x *VMUsage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *VMUsage) GetVolumes() []string {
+	if x != nil {
+		return x.Volumes
+	}
+	return nil
+}
+
+func (x *VMUsage) GetAllocatedBytes() int64 {
+	if x != nil {
+		return x.AllocatedBytes
+	}
+	return 0
+}
+
+func (x *VMUsage) GetVirtualBytes() int64 {
+	if x != nil {
+		return x.VirtualBytes
+	}
+	return 0
+}
+
+type StorageUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ImageStore    *PoolUsage             `protobuf:"bytes,1,opt,name=image_store,json=imageStore,proto3" json:"image_store,omitempty"`
+	Pools         []*PoolUsage           `protobuf:"bytes,2,rep,name=pools,proto3" json:"pools,omitempty"`
+	Images        []*ImageUsage          `protobuf:"bytes,3,rep,name=images,proto3" json:"images,omitempty"`
+	Vms           []*VMUsage             `protobuf:"bytes,4,rep,name=vms,proto3" json:"vms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StorageUsage) Reset() {
+	*x = StorageUsage{}
+	mi := &file_deusvm_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StorageUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageUsage) ProtoMessage() {}
+
+func (x *StorageUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageUsage.ProtoReflect.Descriptor instead.
+func (*StorageUsage) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *StorageUsage) GetImageStore() *PoolUsage {
+	if x != nil {
+		return x.ImageStore
+	}
+	return nil
+}
+
+func (x *StorageUsage) GetPools() []*PoolUsage {
+	if x != nil {
+		return x.Pools
+	}
+	return nil
+}
+
+func (x *StorageUsage) GetImages() []*ImageUsage {
+	if x != nil {
+		return x.Images
+	}
+	return nil
+}
+
+func (x *StorageUsage) GetVms() []*VMUsage {
+	if x != nil {
+		return x.Vms
+	}
+	return nil
+}
+
+type GCRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// dry_run only reports what would be collected.
+	DryRun bool `protobuf:"varint,1,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// min_age_seconds overrides the configured minimum age when set.
+	MinAgeSeconds int64 `protobuf:"varint,2,opt,name=min_age_seconds,json=minAgeSeconds,proto3" json:"min_age_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GCRequest) Reset() {
+	*x = GCRequest{}
+	mi := &file_deusvm_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GCRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GCRequest) ProtoMessage() {}
+
+func (x *GCRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GCRequest.ProtoReflect.Descriptor instead.
+func (*GCRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *GCRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *GCRequest) GetMinAgeSeconds() int64 {
+	if x != nil {
+		return x.MinAgeSeconds
+	}
+	return 0
+}
+
+type GCItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"` // part|blob|disk|temp|volume|attachment|ref
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Pool          string                 `protobuf:"bytes,3,opt,name=pool,proto3" json:"pool,omitempty"`
+	Name          string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	SizeBytes     int64                  `protobuf:"varint,5,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	ModTime       string                 `protobuf:"bytes,6,opt,name=mod_time,json=modTime,proto3" json:"mod_time,omitempty"` // RFC 3339
+	Reason        string                 `protobuf:"bytes,7,opt,name=reason,proto3" json:"reason,omitempty"`
+	ReportOnly    bool                   `protobuf:"varint,8,opt,name=report_only,json=reportOnly,proto3" json:"report_only,omitempty"`
+	Quarantine    string                 `protobuf:"bytes,9,opt,name=quarantine,proto3" json:"quarantine,omitempty"`
+	Error         string                 `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GCItem) Reset() {
+	*x = GCItem{}
+	mi := &file_deusvm_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GCItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GCItem) ProtoMessage() {}
+
+func (x *GCItem) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GCItem.ProtoReflect.Descriptor instead.
+func (*GCItem) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *GCItem) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *GCItem) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *GCItem) GetPool() string {
+	if x != nil {
+		return x.Pool
+	}
+	return ""
+}
+
+func (x *GCItem) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *GCItem) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *GCItem) GetModTime() string {
+	if x != nil {
+		return x.ModTime
+	}
+	return ""
+}
+
+func (x *GCItem) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *GCItem) GetReportOnly() bool {
+	if x != nil {
+		return x.ReportOnly
+	}
+	return false
+}
+
+func (x *GCItem) GetQuarantine() string {
+	if x != nil {
+		return x.Quarantine
+	}
+	return ""
+}
+
+func (x *GCItem) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GCReport struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	DryRun           bool                   `protobuf:"varint,1,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Items            []*GCItem              `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	ReclaimableBytes int64                  `protobuf:"varint,3,opt,name=reclaimable_bytes,json=reclaimableBytes,proto3" json:"reclaimable_bytes,omitempty"`
+	Purged           []string               `protobuf:"bytes,4,rep,name=purged,proto3" json:"purged,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *GCReport) Reset() {
+	*x = GCReport{}
+	mi := &file_deusvm_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GCReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GCReport) ProtoMessage() {}
+
+func (x *GCReport) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GCReport.ProtoReflect.Descriptor instead.
+func (*GCReport) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *GCReport) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *GCReport) GetItems() []*GCItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *GCReport) GetReclaimableBytes() int64 {
+	if x != nil {
+		return x.ReclaimableBytes
+	}
+	return 0
+}
+
+func (x *GCReport) GetPurged() []string {
+	if x != nil {
+		return x.Purged
+	}
+	return nil
+}
+
+type BackupFile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"` // disk|domain
+	SizeBytes     int64                  `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	Sha256        string                 `protobuf:"bytes,4,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	Volume        string                 `protobuf:"bytes,5,opt,name=volume,proto3" json:"volume,omitempty"`
+	VirtualBytes  int64                  `protobuf:"varint,6,opt,name=virtual_bytes,json=virtualBytes,proto3" json:"virtual_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupFile) Reset() {
+	*x = BackupFile{}
+	mi := &file_deusvm_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupFile) ProtoMessage() {}
+
+func (x *BackupFile) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupFile.ProtoReflect.Descriptor instead.
+func (*BackupFile) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *BackupFile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *BackupFile) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *BackupFile) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *BackupFile) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *BackupFile) GetVolume() string {
+	if x != nil {
+		return x.Volume
+	}
+	return ""
+}
+
+func (x *BackupFile) GetVirtualBytes() int64 {
+	if x != nil {
+		return x.VirtualBytes
+	}
+	return 0
+}
+
+type Backup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // target/vm/timestamp
+	Target        string                 `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	VmId          string                 `protobuf:"bytes,3,opt,name=vm_id,json=vmId,proto3" json:"vm_id,omitempty"`
+	VmName        string                 `protobuf:"bytes,4,opt,name=vm_name,json=vmName,proto3" json:"vm_name,omitempty"`
+	Policy        string                 `protobuf:"bytes,5,opt,name=policy,proto3" json:"policy,omitempty"`
+	Consistency   string                 `protobuf:"bytes,6,opt,name=consistency,proto3" json:"consistency,omitempty"` // none|freeze|snapshot
+	Quiesced      bool                   `protobuf:"varint,7,opt,name=quiesced,proto3" json:"quiesced,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // RFC 3339
+	SizeBytes     int64                  `protobuf:"varint,9,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	Files         []*BackupFile          `protobuf:"bytes,10,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Backup) Reset() {
+	*x = Backup{}
+	mi := &file_deusvm_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Backup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Backup) ProtoMessage() {}
+
+func (x *Backup) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Backup.ProtoReflect.Descriptor instead.
+func (*Backup) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *Backup) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Backup) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *Backup) GetVmId() string {
+	if x != nil {
+		return x.VmId
+	}
+	return ""
+}
+
+func (x *Backup) GetVmName() string {
+	if x != nil {
+		return x.VmName
+	}
+	return ""
+}
+
+func (x *Backup) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *Backup) GetConsistency() string {
+	if x != nil {
+		return x.Consistency
+	}
+	return ""
+}
+
+func (x *Backup) GetQuiesced() bool {
+	if x != nil {
+		return x.Quiesced
+	}
+	return false
+}
+
+func (x *Backup) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Backup) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *Backup) GetFiles() []*BackupFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type CreateBackupRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	VmId  string                 `protobuf:"bytes,1,opt,name=vm_id,json=vmId,proto3" json:"vm_id,omitempty"`
+	// policy may be empty when only one is configured.
+	Policy        string `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateBackupRequest) Reset() {
+	*x = CreateBackupRequest{}
+	mi := &file_deusvm_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateBackupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateBackupRequest) ProtoMessage() {}
+
+func (x *CreateBackupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateBackupRequest.ProtoReflect.Descriptor instead.
+func (*CreateBackupRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *CreateBackupRequest) GetVmId() string {
+	if x != nil {
+		return x.VmId
+	}
+	return ""
+}
+
+func (x *CreateBackupRequest) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+type ListBackupsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Target        string                 `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	VmName        string                 `protobuf:"bytes,2,opt,name=vm_name,json=vmName,proto3" json:"vm_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBackupsRequest) Reset() {
+	*x = ListBackupsRequest{}
+	mi := &file_deusvm_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBackupsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBackupsRequest) ProtoMessage() {}
+
+func (x *ListBackupsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBackupsRequest.ProtoReflect.Descriptor instead.
+func (*ListBackupsRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *ListBackupsRequest) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *ListBackupsRequest) GetVmName() string {
+	if x != nil {
+		return x.VmName
+	}
+	return ""
+}
+
+type ListBackupsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Backups       []*Backup              `protobuf:"bytes,1,rep,name=backups,proto3" json:"backups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBackupsResponse) Reset() {
+	*x = ListBackupsResponse{}
+	mi := &file_deusvm_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBackupsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBackupsResponse) ProtoMessage() {}
+
+func (x *ListBackupsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBackupsResponse.ProtoReflect.Descriptor instead.
+func (*ListBackupsResponse) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *ListBackupsResponse) GetBackups() []*Backup {
+	if x != nil {
+		return x.Backups
+	}
+	return nil
+}
+
+type BackupIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupIDRequest) Reset() {
+	*x = BackupIDRequest{}
+	mi := &file_deusvm_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupIDRequest) ProtoMessage() {}
+
+func (x *BackupIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupIDRequest.ProtoReflect.Descriptor instead.
+func (*BackupIDRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *BackupIDRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RestoreBackupRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// name of the new VM, <vm>-restored when empty.
+	Name          string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Pool          string `protobuf:"bytes,3,opt,name=pool,proto3" json:"pool,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreBackupRequest) Reset() {
+	*x = RestoreBackupRequest{}
+	mi := &file_deusvm_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreBackupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreBackupRequest) ProtoMessage() {}
+
+func (x *RestoreBackupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreBackupRequest.ProtoReflect.Descriptor instead.
+func (*RestoreBackupRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *RestoreBackupRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RestoreBackupRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RestoreBackupRequest) GetPool() string {
+	if x != nil {
+		return x.Pool
+	}
+	return ""
+}
+
+// Subnet is an IPv4 or IPv6 subnet of a managed network. The gateway is the
+// host's address, by default the first of the cidr; DHCP is served when both
+// ends of the range are set.
+type Subnet struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cidr          string                 `protobuf:"bytes,1,opt,name=cidr,proto3" json:"cidr,omitempty"`
+	Gateway       string                 `protobuf:"bytes,2,opt,name=gateway,proto3" json:"gateway,omitempty"`
+	DhcpStart     string                 `protobuf:"bytes,3,opt,name=dhcp_start,json=dhcpStart,proto3" json:"dhcp_start,omitempty"`
+	DhcpEnd       string                 `protobuf:"bytes,4,opt,name=dhcp_end,json=dhcpEnd,proto3" json:"dhcp_end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Subnet) Reset() {
+	*x = Subnet{}
+	mi := &file_deusvm_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Subnet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Subnet) ProtoMessage() {}
+
+func (x *Subnet) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Subnet.ProtoReflect.Descriptor instead.
+func (*Subnet) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *Subnet) GetCidr() string {
+	if x != nil {
+		return x.Cidr
+	}
+	return ""
+}
+
+func (x *Subnet) GetGateway() string {
+	if x != nil {
+		return x.Gateway
+	}
+	return ""
+}
+
+func (x *Subnet) GetDhcpStart() string {
+	if x != nil {
+		return x.DhcpStart
+	}
+	return ""
+}
+
+func (x *Subnet) GetDhcpEnd() string {
+	if x != nil {
+		return x.DhcpEnd
+	}
+	return ""
+}
+
+type Network struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Mode          string                 `protobuf:"bytes,2,opt,name=mode,proto3" json:"mode,omitempty"` // nat|isolated|routed
+	Bridge        string                 `protobuf:"bytes,3,opt,name=bridge,proto3" json:"bridge,omitempty"`
+	Uplink        string                 `protobuf:"bytes,4,opt,name=uplink,proto3" json:"uplink,omitempty"`
+	Ipv4          *Subnet                `protobuf:"bytes,5,opt,name=ipv4,proto3" json:"ipv4,omitempty"`
+	Ipv6          *Subnet                `protobuf:"bytes,6,opt,name=ipv6,proto3" json:"ipv6,omitempty"`
+	Uuid          string                 `protobuf:"bytes,7,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Active        bool                   `protobuf:"varint,8,opt,name=active,proto3" json:"active,omitempty"`
+	ExternalDns   bool                   `protobuf:"varint,9,opt,name=external_dns,json=externalDns,proto3" json:"external_dns,omitempty"`
+	Inbound       *Bandwidth             `protobuf:"bytes,10,opt,name=inbound,proto3" json:"inbound,omitempty"` // default limits of NICs on the network
+	Outbound      *Bandwidth             `protobuf:"bytes,11,opt,name=outbound,proto3" json:"outbound,omitempty"`
+	Pxe           bool                   `protobuf:"varint,12,opt,name=pxe,proto3" json:"pxe,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Network) Reset() {
+	*x = Network{}
+	mi := &file_deusvm_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Network) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Network) ProtoMessage() {}
+
+func (x *Network) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Network.ProtoReflect.Descriptor instead.
+func (*Network) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *Network) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Network) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *Network) GetBridge() string {
+	if x != nil {
+		return x.Bridge
+	}
+	return ""
+}
+
+func (x *Network) GetUplink() string {
+	if x != nil {
+		return x.Uplink
+	}
+	return ""
+}
+
+func (x *Network) GetIpv4() *Subnet {
+	if x != nil {
+		return x.Ipv4
+	}
+	return nil
+}
+
+func (x *Network) GetIpv6() *Subnet {
+	if x != nil {
+		return x.Ipv6
+	}
+	return nil
+}
+
+func (x *Network) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *Network) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *Network) GetExternalDns() bool {
+	if x != nil {
+		return x.ExternalDns
+	}
+	return false
+}
+
+func (x *Network) GetInbound() *Bandwidth {
+	if x != nil {
+		return x.Inbound
+	}
+	return nil
+}
+
+func (x *Network) GetOutbound() *Bandwidth {
+	if x != nil {
+		return x.Outbound
+	}
+	return nil
+}
+
+func (x *Network) GetPxe() bool {
+	if x != nil {
+		return x.Pxe
+	}
+	return false
+}
+
+type CreateNetworkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Mode          string                 `protobuf:"bytes,2,opt,name=mode,proto3" json:"mode,omitempty"`     // nat|isolated|routed; nat when empty
+	Bridge        string                 `protobuf:"bytes,3,opt,name=bridge,proto3" json:"bridge,omitempty"` // picked by libvirt when empty
+	Uplink        string                 `protobuf:"bytes,4,opt,name=uplink,proto3" json:"uplink,omitempty"` // host interface for nat and routed traffic
+	Ipv4          *Subnet                `protobuf:"bytes,5,opt,name=ipv4,proto3" json:"ipv4,omitempty"`
+	Ipv6          *Subnet                `protobuf:"bytes,6,opt,name=ipv6,proto3" json:"ipv6,omitempty"`
+	ExternalDns   bool                   `protobuf:"varint,7,opt,name=external_dns,json=externalDns,proto3" json:"external_dns,omitempty"` // leave DNS on the gateway to the daemon's DNS server
+	Inbound       *Bandwidth             `protobuf:"bytes,8,opt,name=inbound,proto3" json:"inbound,omitempty"`                             // default limits of NICs on the network
+	Outbound      *Bandwidth             `protobuf:"bytes,9,opt,name=outbound,proto3" json:"outbound,omitempty"`
+	Pxe           bool                   `protobuf:"varint,10,opt,name=pxe,proto3" json:"pxe,omitempty"` // send network boots to the daemon's boot server
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateNetworkRequest) Reset() {
+	*x = CreateNetworkRequest{}
+	mi := &file_deusvm_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateNetworkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateNetworkRequest) ProtoMessage() {}
+
+func (x *CreateNetworkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateNetworkRequest.ProtoReflect.Descriptor instead.
+func (*CreateNetworkRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *CreateNetworkRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateNetworkRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *CreateNetworkRequest) GetBridge() string {
+	if x != nil {
+		return x.Bridge
+	}
+	return ""
+}
+
+func (x *CreateNetworkRequest) GetUplink() string {
+	if x != nil {
+		return x.Uplink
+	}
+	return ""
+}
+
+func (x *CreateNetworkRequest) GetIpv4() *Subnet {
+	if x != nil {
+		return x.Ipv4
+	}
+	return nil
+}
+
+func (x *CreateNetworkRequest) GetIpv6() *Subnet {
+	if x != nil {
+		return x.Ipv6
+	}
+	return nil
+}
+
+func (x *CreateNetworkRequest) GetExternalDns() bool {
+	if x != nil {
+		return x.ExternalDns
+	}
+	return false
+}
+
+func (x *CreateNetworkRequest) GetInbound() *Bandwidth {
+	if x != nil {
+		return x.Inbound
+	}
+	return nil
+}
+
+func (x *CreateNetworkRequest) GetOutbound() *Bandwidth {
+	if x != nil {
+		return x.Outbound
+	}
+	return nil
+}
+
+func (x *CreateNetworkRequest) GetPxe() bool {
+	if x != nil {
+		return x.Pxe
+	}
+	return false
+}
+
+type NetworkNameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkNameRequest) Reset() {
+	*x = NetworkNameRequest{}
+	mi := &file_deusvm_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkNameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkNameRequest) ProtoMessage() {}
+
+func (x *NetworkNameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkNameRequest.ProtoReflect.Descriptor instead.
+func (*NetworkNameRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *NetworkNameRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ListNetworksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Networks      []*Network             `protobuf:"bytes,1,rep,name=networks,proto3" json:"networks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNetworksResponse) Reset() {
+	*x = ListNetworksResponse{}
+	mi := &file_deusvm_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNetworksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNetworksResponse) ProtoMessage() {}
+
+func (x *ListNetworksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNetworksResponse.ProtoReflect.Descriptor instead.
+func (*ListNetworksResponse) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *ListNetworksResponse) GetNetworks() []*Network {
+	if x != nil {
+		return x.Networks
+	}
+	return nil
+}
+
+// Allocation is an address reserved for a VM NIC on a managed network.
+type Allocation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Network       string                 `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
+	Ip            string                 `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
+	Mac           string                 `protobuf:"bytes,3,opt,name=mac,proto3" json:"mac,omitempty"`
+	VmId          string                 `protobuf:"bytes,4,opt,name=vm_id,json=vmId,proto3" json:"vm_id,omitempty"`
+	VmName        string                 `protobuf:"bytes,5,opt,name=vm_name,json=vmName,proto3" json:"vm_name,omitempty"`
+	Static        bool                   `protobuf:"varint,6,opt,name=static,proto3" json:"static,omitempty"`                       // requested by address rather than picked
+	CreatedAt     string                 `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // RFC 3339
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Allocation) Reset() {
+	*x = Allocation{}
+	mi := &file_deusvm_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Allocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Allocation) ProtoMessage() {}
+
+func (x *Allocation) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Allocation.ProtoReflect.Descriptor instead.
+func (*Allocation) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *Allocation) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *Allocation) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *Allocation) GetMac() string {
+	if x != nil {
+		return x.Mac
+	}
+	return ""
+}
+
+func (x *Allocation) GetVmId() string {
+	if x != nil {
+		return x.VmId
+	}
+	return ""
+}
+
+func (x *Allocation) GetVmName() string {
+	if x != nil {
+		return x.VmName
+	}
+	return ""
+}
+
+func (x *Allocation) GetStatic() bool {
+	if x != nil {
+		return x.Static
+	}
+	return false
+}
+
+func (x *Allocation) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type ListAllocationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Network       string                 `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"` // every network when empty
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAllocationsRequest) Reset() {
+	*x = ListAllocationsRequest{}
+	mi := &file_deusvm_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAllocationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAllocationsRequest) ProtoMessage() {}
+
+func (x *ListAllocationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAllocationsRequest.ProtoReflect.Descriptor instead.
+func (*ListAllocationsRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *ListAllocationsRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+type ListAllocationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Allocations   []*Allocation          `protobuf:"bytes,1,rep,name=allocations,proto3" json:"allocations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAllocationsResponse) Reset() {
+	*x = ListAllocationsResponse{}
+	mi := &file_deusvm_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAllocationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAllocationsResponse) ProtoMessage() {}
+
+func (x *ListAllocationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAllocationsResponse.ProtoReflect.Descriptor instead.
+func (*ListAllocationsResponse) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *ListAllocationsResponse) GetAllocations() []*Allocation {
+	if x != nil {
+		return x.Allocations
+	}
+	return nil
+}
+
+// SecurityRule allows traffic to (ingress) or from (egress) a NIC. Ports
+// apply to tcp and udp; peers are cidrs and the NICs of groups, any address
+// when both are empty.
+type SecurityRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Direction     string                 `protobuf:"bytes,1,opt,name=direction,proto3" json:"direction,omitempty"` // ingress|egress
+	Protocol      string                 `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`   // tcp|udp|icmp|icmpv6|any
+	PortFrom      int32                  `protobuf:"varint,3,opt,name=port_from,json=portFrom,proto3" json:"port_from,omitempty"`
+	PortTo        int32                  `protobuf:"varint,4,opt,name=port_to,json=portTo,proto3" json:"port_to,omitempty"`
+	Cidrs         []string               `protobuf:"bytes,5,rep,name=cidrs,proto3" json:"cidrs,omitempty"`
+	Groups        []string               `protobuf:"bytes,6,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SecurityRule) Reset() {
+	*x = SecurityRule{}
+	mi := &file_deusvm_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SecurityRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecurityRule) ProtoMessage() {}
+
+func (x *SecurityRule) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecurityRule.ProtoReflect.Descriptor instead.
+func (*SecurityRule) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *SecurityRule) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *SecurityRule) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *SecurityRule) GetPortFrom() int32 {
+	if x != nil {
+		return x.PortFrom
+	}
+	return 0
+}
+
+func (x *SecurityRule) GetPortTo() int32 {
+	if x != nil {
+		return x.PortTo
+	}
+	return 0
+}
+
+func (x *SecurityRule) GetCidrs() []string {
+	if x != nil {
+		return x.Cidrs
+	}
+	return nil
+}
+
+func (x *SecurityRule) GetGroups() []string {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type SecurityGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Rules         []*SecurityRule        `protobuf:"bytes,3,rep,name=rules,proto3" json:"rules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SecurityGroup) Reset() {
+	*x = SecurityGroup{}
+	mi := &file_deusvm_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SecurityGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecurityGroup) ProtoMessage() {}
+
+func (x *SecurityGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecurityGroup.ProtoReflect.Descriptor instead.
+func (*SecurityGroup) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *SecurityGroup) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SecurityGroup) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *SecurityGroup) GetRules() []*SecurityRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type SecurityGroupNameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SecurityGroupNameRequest) Reset() {
+	*x = SecurityGroupNameRequest{}
+	mi := &file_deusvm_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SecurityGroupNameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecurityGroupNameRequest) ProtoMessage() {}
+
+func (x *SecurityGroupNameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecurityGroupNameRequest.ProtoReflect.Descriptor instead.
+func (*SecurityGroupNameRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *SecurityGroupNameRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ListSecurityGroupsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Groups        []*SecurityGroup       `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSecurityGroupsResponse) Reset() {
+	*x = ListSecurityGroupsResponse{}
+	mi := &file_deusvm_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSecurityGroupsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSecurityGroupsResponse) ProtoMessage() {}
+
+func (x *ListSecurityGroupsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSecurityGroupsResponse.ProtoReflect.Descriptor instead.
+func (*ListSecurityGroupsResponse) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *ListSecurityGroupsResponse) GetGroups() []*SecurityGroup {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type SetNICSecurityGroupsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VmId          string                 `protobuf:"bytes,1,opt,name=vm_id,json=vmId,proto3" json:"vm_id,omitempty"`
+	Mac           string                 `protobuf:"bytes,2,opt,name=mac,proto3" json:"mac,omitempty"`
+	Groups        []string               `protobuf:"bytes,3,rep,name=groups,proto3" json:"groups,omitempty"` // empty leaves the NIC unfiltered
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetNICSecurityGroupsRequest) Reset() {
+	*x = SetNICSecurityGroupsRequest{}
+	mi := &file_deusvm_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetNICSecurityGroupsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetNICSecurityGroupsRequest) ProtoMessage() {}
+
+func (x *SetNICSecurityGroupsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetNICSecurityGroupsRequest.ProtoReflect.Descriptor instead.
+func (*SetNICSecurityGroupsRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *SetNICSecurityGroupsRequest) GetVmId() string {
+	if x != nil {
+		return x.VmId
+	}
+	return ""
+}
+
+func (x *SetNICSecurityGroupsRequest) GetMac() string {
+	if x != nil {
+		return x.Mac
+	}
+	return ""
+}
+
+func (x *SetNICSecurityGroupsRequest) GetGroups() []string {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type PortForward struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	HostIp        string                 `protobuf:"bytes,2,opt,name=host_ip,json=hostIp,proto3" json:"host_ip,omitempty"` // every host address if empty
+	HostPort      int32                  `protobuf:"varint,3,opt,name=host_port,json=hostPort,proto3" json:"host_port,omitempty"`
+	Protocol      string                 `protobuf:"bytes,4,opt,name=protocol,proto3" json:"protocol,omitempty"` // tcp (default) or udp
+	Vm            string                 `protobuf:"bytes,5,opt,name=vm,proto3" json:"vm,omitempty"`             // id, or name on create
+	VmName        string                 `protobuf:"bytes,6,opt,name=vm_name,json=vmName,proto3" json:"vm_name,omitempty"`
+	Network       string                 `protobuf:"bytes,7,opt,name=network,proto3" json:"network,omitempty"` // nat network of the target NIC, the first one if empty
+	Port          int32                  `protobuf:"varint,8,opt,name=port,proto3" json:"port,omitempty"`
+	Target        string                 `protobuf:"bytes,9,opt,name=target,proto3" json:"target,omitempty"`   // VM address connections go to
+	Active        bool                   `protobuf:"varint,10,opt,name=active,proto3" json:"active,omitempty"` // rules are in place while the VM runs
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PortForward) Reset() {
+	*x = PortForward{}
+	mi := &file_deusvm_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PortForward) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortForward) ProtoMessage() {}
+
+func (x *PortForward) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortForward.ProtoReflect.Descriptor instead.
+func (*PortForward) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *PortForward) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PortForward) GetHostIp() string {
+	if x != nil {
+		return x.HostIp
+	}
+	return ""
+}
+
+func (x *PortForward) GetHostPort() int32 {
+	if x != nil {
+		return x.HostPort
+	}
+	return 0
+}
+
+func (x *PortForward) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *PortForward) GetVm() string {
+	if x != nil {
+		return x.Vm
+	}
+	return ""
+}
+
+func (x *PortForward) GetVmName() string {
+	if x != nil {
+		return x.VmName
+	}
+	return ""
+}
+
+func (x *PortForward) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *PortForward) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *PortForward) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *PortForward) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+type PortForwardNameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PortForwardNameRequest) Reset() {
+	*x = PortForwardNameRequest{}
+	mi := &file_deusvm_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PortForwardNameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortForwardNameRequest) ProtoMessage() {}
+
+func (x *PortForwardNameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortForwardNameRequest.ProtoReflect.Descriptor instead.
+func (*PortForwardNameRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *PortForwardNameRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ListPortForwardsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Vm            string                 `protobuf:"bytes,1,opt,name=vm,proto3" json:"vm,omitempty"` // id or name, every VM if empty
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPortForwardsRequest) Reset() {
+	*x = ListPortForwardsRequest{}
+	mi := &file_deusvm_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPortForwardsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPortForwardsRequest) ProtoMessage() {}
+
+func (x *ListPortForwardsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPortForwardsRequest.ProtoReflect.Descriptor instead.
+func (*ListPortForwardsRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *ListPortForwardsRequest) GetVm() string {
+	if x != nil {
+		return x.Vm
+	}
+	return ""
+}
+
+type ListPortForwardsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Forwards      []*PortForward         `protobuf:"bytes,1,rep,name=forwards,proto3" json:"forwards,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPortForwardsResponse) Reset() {
+	*x = ListPortForwardsResponse{}
+	mi := &file_deusvm_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPortForwardsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPortForwardsResponse) ProtoMessage() {}
+
+func (x *ListPortForwardsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPortForwardsResponse.ProtoReflect.Descriptor instead.
+func (*ListPortForwardsResponse) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *ListPortForwardsResponse) GetForwards() []*PortForward {
+	if x != nil {
+		return x.Forwards
+	}
+	return nil
+}
+
+// BootTemplate is an iPXE script rendered with Go's text/template for each VM
+// booting from it.
+type BootTemplate struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Image         string                 `protobuf:"bytes,2,opt,name=image,proto3" json:"image,omitempty"`
-	Cpu           int32                  `protobuf:"varint,3,opt,name=cpu,proto3" json:"cpu,omitempty"`
-	MemoryBytes   int64                  `protobuf:"varint,4,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`
-	DiskBytes     int64                  `protobuf:"varint,5,opt,name=disk_bytes,json=diskBytes,proto3" json:"disk_bytes,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Script        string                 `protobuf:"bytes,3,opt,name=script,proto3" json:"script,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateVMRequest) Reset() {
-	*x = CreateVMRequest{}
-	mi := &file_deusvm_proto_msgTypes[2]
+func (x *BootTemplate) Reset() {
+	*x = BootTemplate{}
+	mi := &file_deusvm_proto_msgTypes[66]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateVMRequest) String() string {
+func (x *BootTemplate) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateVMRequest) ProtoMessage() {}
+func (*BootTemplate) ProtoMessage() {}
 
-func (x *CreateVMRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_deusvm_proto_msgTypes[2]
+func (x *BootTemplate) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[66]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -185,68 +4540,54 @@ func (x *CreateVMRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateVMRequest.ProtoReflect.Descriptor instead.
-func (*CreateVMRequest) Descriptor() ([]byte, []int) {
-	return file_deusvm_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use BootTemplate.ProtoReflect.Descriptor instead.
+func (*BootTemplate) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{66}
 }
 
-func (x *CreateVMRequest) GetName() string {
+func (x *BootTemplate) GetName() string {
 	if x != nil {
 		return x.Name
 	}
 	return ""
 }
 
-func (x *CreateVMRequest) GetImage() string {
+func (x *BootTemplate) GetDescription() string {
 	if x != nil {
-		return x.Image
+		return x.Description
 	}
 	return ""
 }
 
-func (x *CreateVMRequest) GetCpu() int32 {
-	if x != nil {
-		return x.Cpu
-	}
-	return 0
-}
-
-func (x *CreateVMRequest) GetMemoryBytes() int64 {
+func (x *BootTemplate) GetScript() string {
 	if x != nil {
-		return x.MemoryBytes
-	}
-	return 0
-}
-
-func (x *CreateVMRequest) GetDiskBytes() int64 {
-	if x != nil {
-		return x.DiskBytes
+		return x.Script
 	}
-	return 0
+	return ""
 }
 
-type VMIDRequest struct {
+type BootTemplateNameRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // allow either id or name for convenience
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *VMIDRequest) Reset() {
-	*x = VMIDRequest{}
-	mi := &file_deusvm_proto_msgTypes[3]
+func (x *BootTemplateNameRequest) Reset() {
+	*x = BootTemplateNameRequest{}
+	mi := &file_deusvm_proto_msgTypes[67]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *VMIDRequest) String() string {
+func (x *BootTemplateNameRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*VMIDRequest) ProtoMessage() {}
+func (*BootTemplateNameRequest) ProtoMessage() {}
 
-func (x *VMIDRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_deusvm_proto_msgTypes[3]
+func (x *BootTemplateNameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[67]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -257,40 +4598,40 @@ func (x *VMIDRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use VMIDRequest.ProtoReflect.Descriptor instead.
-func (*VMIDRequest) Descriptor() ([]byte, []int) {
-	return file_deusvm_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use BootTemplateNameRequest.ProtoReflect.Descriptor instead.
+func (*BootTemplateNameRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{67}
 }
 
-func (x *VMIDRequest) GetId() string {
+func (x *BootTemplateNameRequest) GetName() string {
 	if x != nil {
-		return x.Id
+		return x.Name
 	}
 	return ""
 }
 
-type ListVMsResponse struct {
+type ListBootTemplatesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Vms           []*VM                  `protobuf:"bytes,1,rep,name=vms,proto3" json:"vms,omitempty"`
+	Templates     []*BootTemplate        `protobuf:"bytes,1,rep,name=templates,proto3" json:"templates,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListVMsResponse) Reset() {
-	*x = ListVMsResponse{}
-	mi := &file_deusvm_proto_msgTypes[4]
+func (x *ListBootTemplatesResponse) Reset() {
+	*x = ListBootTemplatesResponse{}
+	mi := &file_deusvm_proto_msgTypes[68]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListVMsResponse) String() string {
+func (x *ListBootTemplatesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListVMsResponse) ProtoMessage() {}
+func (*ListBootTemplatesResponse) ProtoMessage() {}
 
-func (x *ListVMsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_deusvm_proto_msgTypes[4]
+func (x *ListBootTemplatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[68]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -301,44 +4642,41 @@ func (x *ListVMsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListVMsResponse.ProtoReflect.Descriptor instead.
-func (*ListVMsResponse) Descriptor() ([]byte, []int) {
-	return file_deusvm_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use ListBootTemplatesResponse.ProtoReflect.Descriptor instead.
+func (*ListBootTemplatesResponse) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{68}
 }
 
-func (x *ListVMsResponse) GetVms() []*VM {
+func (x *ListBootTemplatesResponse) GetTemplates() []*BootTemplate {
 	if x != nil {
-		return x.Vms
+		return x.Templates
 	}
 	return nil
 }
 
-type Image struct {
+type SetVMBootTemplateRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
-	SizeBytes     int64                  `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
-	Format        string                 `protobuf:"bytes,4,opt,name=format,proto3" json:"format,omitempty"` // qcow2|raw|unknown
-	Sha256        string                 `protobuf:"bytes,5,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`             // VM id or name
+	Template      string                 `protobuf:"bytes,2,opt,name=template,proto3" json:"template,omitempty"` // the default template when empty
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Image) Reset() {
-	*x = Image{}
-	mi := &file_deusvm_proto_msgTypes[5]
+func (x *SetVMBootTemplateRequest) Reset() {
+	*x = SetVMBootTemplateRequest{}
+	mi := &file_deusvm_proto_msgTypes[69]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Image) String() string {
+func (x *SetVMBootTemplateRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Image) ProtoMessage() {}
+func (*SetVMBootTemplateRequest) ProtoMessage() {}
 
-func (x *Image) ProtoReflect() protoreflect.Message {
-	mi := &file_deusvm_proto_msgTypes[5]
+func (x *SetVMBootTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[69]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -349,69 +4687,60 @@ func (x *Image) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Image.ProtoReflect.Descriptor instead.
-func (*Image) Descriptor() ([]byte, []int) {
-	return file_deusvm_proto_rawDescGZIP(), []int{5}
-}
-
-func (x *Image) GetName() string {
-	if x != nil {
-		return x.Name
-	}
-	return ""
-}
-
-func (x *Image) GetPath() string {
-	if x != nil {
-		return x.Path
-	}
-	return ""
-}
-
-func (x *Image) GetSizeBytes() int64 {
-	if x != nil {
-		return x.SizeBytes
-	}
-	return 0
+// Deprecated: Use SetVMBootTemplateRequest.ProtoReflect.Descriptor instead.
+func (*SetVMBootTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{69}
 }
 
-func (x *Image) GetFormat() string {
+func (x *SetVMBootTemplateRequest) GetId() string {
 	if x != nil {
-		return x.Format
+		return x.Id
 	}
 	return ""
 }
 
-func (x *Image) GetSha256() string {
+func (x *SetVMBootTemplateRequest) GetTemplate() string {
 	if x != nil {
-		return x.Sha256
+		return x.Template
 	}
 	return ""
 }
 
-type CreateImageRequest struct {
+// HostInterface is a network interface of the host.
+type HostInterface struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Source        string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"` // URL
+	Index         int32                  `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	Kind          string                 `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"` // physical, loopback, bridge, openvswitch, bond, vlan or the kernel link type
+	Mac           string                 `protobuf:"bytes,4,opt,name=mac,proto3" json:"mac,omitempty"`
+	Mtu           int32                  `protobuf:"varint,5,opt,name=mtu,proto3" json:"mtu,omitempty"`
+	State         string                 `protobuf:"bytes,6,opt,name=state,proto3" json:"state,omitempty"`                          // up or down
+	OperState     string                 `protobuf:"bytes,7,opt,name=oper_state,json=operState,proto3" json:"oper_state,omitempty"` // as the kernel reports it
+	Master        string                 `protobuf:"bytes,8,opt,name=master,proto3" json:"master,omitempty"`                        // bridge or bond the interface is enslaved to
+	Ports         []string               `protobuf:"bytes,9,rep,name=ports,proto3" json:"ports,omitempty"`
+	Parent        string                 `protobuf:"bytes,10,opt,name=parent,proto3" json:"parent,omitempty"` // of VLAN subinterfaces
+	Vlan          int32                  `protobuf:"varint,11,opt,name=vlan,proto3" json:"vlan,omitempty"`
+	BondMode      string                 `protobuf:"bytes,12,opt,name=bond_mode,json=bondMode,proto3" json:"bond_mode,omitempty"`
+	Addresses     []string               `protobuf:"bytes,13,rep,name=addresses,proto3" json:"addresses,omitempty"` // CIDR
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateImageRequest) Reset() {
-	*x = CreateImageRequest{}
-	mi := &file_deusvm_proto_msgTypes[6]
+func (x *HostInterface) Reset() {
+	*x = HostInterface{}
+	mi := &file_deusvm_proto_msgTypes[70]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateImageRequest) String() string {
+func (x *HostInterface) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateImageRequest) ProtoMessage() {}
+func (*HostInterface) ProtoMessage() {}
 
-func (x *CreateImageRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_deusvm_proto_msgTypes[6]
+func (x *HostInterface) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[70]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -422,91 +4751,124 @@ func (x *CreateImageRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateImageRequest.ProtoReflect.Descriptor instead.
-func (*CreateImageRequest) Descriptor() ([]byte, []int) {
-	return file_deusvm_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use HostInterface.ProtoReflect.Descriptor instead.
+func (*HostInterface) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{70}
 }
 
-func (x *CreateImageRequest) GetName() string {
+func (x *HostInterface) GetName() string {
 	if x != nil {
 		return x.Name
 	}
 	return ""
 }
 
-func (x *CreateImageRequest) GetSource() string {
+func (x *HostInterface) GetIndex() int32 {
 	if x != nil {
-		return x.Source
+		return x.Index
+	}
+	return 0
+}
+
+func (x *HostInterface) GetKind() string {
+	if x != nil {
+		return x.Kind
 	}
 	return ""
 }
 
-type ImageNameRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *HostInterface) GetMac() string {
+	if x != nil {
+		return x.Mac
+	}
+	return ""
 }
 
-func (x *ImageNameRequest) Reset() {
-	*x = ImageNameRequest{}
-	mi := &file_deusvm_proto_msgTypes[7]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *HostInterface) GetMtu() int32 {
+	if x != nil {
+		return x.Mtu
+	}
+	return 0
 }
 
-func (x *ImageNameRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *HostInterface) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
 }
 
-func (*ImageNameRequest) ProtoMessage() {}
+func (x *HostInterface) GetOperState() string {
+	if x != nil {
+		return x.OperState
+	}
+	return ""
+}
 
-func (x *ImageNameRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_deusvm_proto_msgTypes[7]
+func (x *HostInterface) GetMaster() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Master
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use ImageNameRequest.ProtoReflect.Descriptor instead.
-func (*ImageNameRequest) Descriptor() ([]byte, []int) {
-	return file_deusvm_proto_rawDescGZIP(), []int{7}
+func (x *HostInterface) GetPorts() []string {
+	if x != nil {
+		return x.Ports
+	}
+	return nil
 }
 
-func (x *ImageNameRequest) GetName() string {
+func (x *HostInterface) GetParent() string {
 	if x != nil {
-		return x.Name
+		return x.Parent
 	}
 	return ""
 }
 
-type ListImagesResponse struct {
+func (x *HostInterface) GetVlan() int32 {
+	if x != nil {
+		return x.Vlan
+	}
+	return 0
+}
+
+func (x *HostInterface) GetBondMode() string {
+	if x != nil {
+		return x.BondMode
+	}
+	return ""
+}
+
+func (x *HostInterface) GetAddresses() []string {
+	if x != nil {
+		return x.Addresses
+	}
+	return nil
+}
+
+type ListNetworkInterfacesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Images        []*Image               `protobuf:"bytes,1,rep,name=images,proto3" json:"images,omitempty"`
+	Interfaces    []*HostInterface       `protobuf:"bytes,1,rep,name=interfaces,proto3" json:"interfaces,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListImagesResponse) Reset() {
-	*x = ListImagesResponse{}
-	mi := &file_deusvm_proto_msgTypes[8]
+func (x *ListNetworkInterfacesResponse) Reset() {
+	*x = ListNetworkInterfacesResponse{}
+	mi := &file_deusvm_proto_msgTypes[71]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListImagesResponse) String() string {
+func (x *ListNetworkInterfacesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListImagesResponse) ProtoMessage() {}
+func (*ListNetworkInterfacesResponse) ProtoMessage() {}
 
-func (x *ListImagesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_deusvm_proto_msgTypes[8]
+func (x *ListNetworkInterfacesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deusvm_proto_msgTypes[71]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -517,14 +4879,14 @@ func (x *ListImagesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListImagesResponse.ProtoReflect.Descriptor instead.
-func (*ListImagesResponse) Descriptor() ([]byte, []int) {
-	return file_deusvm_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use ListNetworkInterfacesResponse.ProtoReflect.Descriptor instead.
+func (*ListNetworkInterfacesResponse) Descriptor() ([]byte, []int) {
+	return file_deusvm_proto_rawDescGZIP(), []int{71}
 }
 
-func (x *ListImagesResponse) GetImages() []*Image {
+func (x *ListNetworkInterfacesResponse) GetInterfaces() []*HostInterface {
 	if x != nil {
-		return x.Images
+		return x.Interfaces
 	}
 	return nil
 }
@@ -534,7 +4896,7 @@ var File_deusvm_proto protoreflect.FileDescriptor
 const file_deusvm_proto_rawDesc = "" +
 	"\n" +
 	"\fdeusvm.proto\x12\tdeusvm.v1\"\a\n" +
-	"\x05Empty\"\xaa\x01\n" +
+	"\x05Empty\"\xa8\x02\n" +
 	"\x02VM\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x10\n" +
@@ -543,43 +4905,462 @@ const file_deusvm_proto_rawDesc = "" +
 	"\n" +
 	"disk_bytes\x18\x05 \x01(\x03R\tdiskBytes\x12\x14\n" +
 	"\x05image\x18\x06 \x01(\tR\x05image\x12\x16\n" +
-	"\x06status\x18\a \x01(\tR\x06status\"\x8f\x01\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12\x14\n" +
+	"\x05cdrom\x18\b \x01(\tR\x05cdrom\x12\x1d\n" +
+	"\n" +
+	"boot_order\x18\t \x03(\tR\tbootOrder\x12\"\n" +
+	"\x04nics\x18\n" +
+	" \x03(\v2\x0e.deusvm.v1.NICR\x04nics\x12#\n" +
+	"\rboot_template\x18\v \x01(\tR\fbootTemplate\"\x94\x03\n" +
+	"\x03NIC\x12\x18\n" +
+	"\anetwork\x18\x01 \x01(\tR\anetwork\x12\x16\n" +
+	"\x06bridge\x18\x02 \x01(\tR\x06bridge\x12\x10\n" +
+	"\x03mac\x18\x03 \x01(\tR\x03mac\x12\x14\n" +
+	"\x05model\x18\x04 \x01(\tR\x05model\x12\x12\n" +
+	"\x04ipv4\x18\x05 \x01(\tR\x04ipv4\x12\x12\n" +
+	"\x04ipv6\x18\x06 \x01(\tR\x04ipv6\x12'\n" +
+	"\x0fsecurity_groups\x18\a \x03(\tR\x0esecurityGroups\x12\x16\n" +
+	"\x06device\x18\b \x01(\tR\x06device\x12\x1f\n" +
+	"\vbridge_type\x18\t \x01(\tR\n" +
+	"bridgeType\x12\x12\n" +
+	"\x04vlan\x18\n" +
+	" \x01(\x05R\x04vlan\x12\x14\n" +
+	"\x05trunk\x18\v \x03(\x05R\x05trunk\x12\x1d\n" +
+	"\n" +
+	"link_state\x18\f \x01(\tR\tlinkState\x12.\n" +
+	"\ainbound\x18\r \x01(\v2\x14.deusvm.v1.BandwidthR\ainbound\x120\n" +
+	"\boutbound\x18\x0e \x01(\v2\x14.deusvm.v1.BandwidthR\boutbound\"O\n" +
+	"\tBandwidth\x12\x18\n" +
+	"\aaverage\x18\x01 \x01(\rR\aaverage\x12\x12\n" +
+	"\x04peak\x18\x02 \x01(\rR\x04peak\x12\x14\n" +
+	"\x05burst\x18\x03 \x01(\rR\x05burst\"\xb1\x02\n" +
 	"\x0fCreateVMRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
 	"\x05image\x18\x02 \x01(\tR\x05image\x12\x10\n" +
 	"\x03cpu\x18\x03 \x01(\x05R\x03cpu\x12!\n" +
 	"\fmemory_bytes\x18\x04 \x01(\x03R\vmemoryBytes\x12\x1d\n" +
 	"\n" +
-	"disk_bytes\x18\x05 \x01(\x03R\tdiskBytes\"\x1d\n" +
+	"disk_bytes\x18\x05 \x01(\x03R\tdiskBytes\x12\x12\n" +
+	"\x04pool\x18\x06 \x01(\tR\x04pool\x12\x10\n" +
+	"\x03iso\x18\a \x01(\tR\x03iso\x12\x1d\n" +
+	"\n" +
+	"boot_order\x18\b \x03(\tR\tbootOrder\x12\"\n" +
+	"\x04nics\x18\t \x03(\v2\x0e.deusvm.v1.NICR\x04nics\x12\x12\n" +
+	"\x04boot\x18\n" +
+	" \x01(\tR\x04boot\x12#\n" +
+	"\rboot_template\x18\v \x01(\tR\fbootTemplate\"\x1d\n" +
 	"\vVMIDRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"2\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\":\n" +
+	"\x12InsertMediaRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05image\x18\x02 \x01(\tR\x05image\"J\n" +
+	"\x16AttachInterfaceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12 \n" +
+	"\x03nic\x18\x02 \x01(\v2\x0e.deusvm.v1.NICR\x03nic\":\n" +
+	"\x16DetachInterfaceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
+	"\x03mac\x18\x02 \x01(\tR\x03mac\"J\n" +
+	"\x16UpdateInterfaceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12 \n" +
+	"\x03nic\x18\x02 \x01(\v2\x0e.deusvm.v1.NICR\x03nic\"2\n" +
 	"\x0fListVMsResponse\x12\x1f\n" +
-	"\x03vms\x18\x01 \x03(\v2\r.deusvm.v1.VMR\x03vms\"~\n" +
+	"\x03vms\x18\x01 \x03(\v2\r.deusvm.v1.VMR\x03vms\"&\n" +
+	"\x0eVMArchiveChunk\x12\x14\n" +
+	"\x05chunk\x18\x01 \x01(\fR\x05chunk\"6\n" +
+	"\fImportVMInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04pool\x18\x02 \x01(\tR\x04pool\"c\n" +
+	"\x0fImportVMRequest\x12-\n" +
+	"\x04info\x18\x01 \x01(\v2\x17.deusvm.v1.ImportVMInfoH\x00R\x04info\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\t\n" +
+	"\apayload\"\x8c\x02\n" +
 	"\x05Image\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
 	"\x04path\x18\x02 \x01(\tR\x04path\x12\x1d\n" +
 	"\n" +
 	"size_bytes\x18\x03 \x01(\x03R\tsizeBytes\x12\x16\n" +
 	"\x06format\x18\x04 \x01(\tR\x06format\x12\x16\n" +
-	"\x06sha256\x18\x05 \x01(\tR\x06sha256\"@\n" +
+	"\x06sha256\x18\x05 \x01(\tR\x06sha256\x12 \n" +
+	"\vcompression\x18\x06 \x01(\tR\vcompression\x12#\n" +
+	"\rsource_sha256\x18\a \x01(\tR\fsourceSha256\x12\x17\n" +
+	"\aused_by\x18\b \x01(\x05R\x06usedBy\x12,\n" +
+	"\alineage\x18\t \x01(\v2\x12.deusvm.v1.LineageR\alineage\"\x80\x01\n" +
+	"\aLineage\x12\x1b\n" +
+	"\tsource_vm\x18\x01 \x01(\tR\bsourceVm\x12\x1f\n" +
+	"\vsource_disk\x18\x02 \x01(\tR\n" +
+	"sourceDisk\x12\x16\n" +
+	"\x06parent\x18\x03 \x01(\tR\x06parent\x12\x1f\n" +
+	"\vcaptured_at\x18\x04 \x01(\tR\n" +
+	"capturedAt\"@\n" +
 	"\x12CreateImageRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
-	"\x06source\x18\x02 \x01(\tR\x06source\"&\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\"w\n" +
+	"\rImageProgress\x12\x1d\n" +
+	"\n" +
+	"bytes_done\x18\x01 \x01(\x03R\tbytesDone\x12\x1f\n" +
+	"\vbytes_total\x18\x02 \x01(\x03R\n" +
+	"bytesTotal\x12&\n" +
+	"\x05image\x18\x03 \x01(\v2\x10.deusvm.v1.ImageR\x05image\"D\n" +
+	"\x0fUploadImageInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x02 \x01(\x03R\tsizeBytes\"\x83\x01\n" +
+	"\x12UploadImageRequest\x120\n" +
+	"\x04info\x18\x01 \x01(\v2\x1a.deusvm.v1.UploadImageInfoH\x00R\x04info\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunk\x12\x18\n" +
+	"\x06sha256\x18\x03 \x01(\tH\x00R\x06sha256B\t\n" +
+	"\apayload\"<\n" +
 	"\x10ImageNameRequest\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\">\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05force\x18\x02 \x01(\bR\x05force\"=\n" +
+	"\x0fTagImageRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"Z\n" +
+	"\x13CaptureImageRequest\x12\x13\n" +
+	"\x05vm_id\x18\x01 \x01(\tR\x04vmId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
+	"\bsparsify\x18\x03 \x01(\bR\bsparsify\">\n" +
 	"\x12ListImagesResponse\x12(\n" +
-	"\x06images\x18\x01 \x03(\v2\x10.deusvm.v1.ImageR\x06images2\xbd\x02\n" +
+	"\x06images\x18\x01 \x03(\v2\x10.deusvm.v1.ImageR\x06images\"\xcc\x01\n" +
+	"\x06Volume\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04pool\x18\x02 \x01(\tR\x04pool\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\x12\x16\n" +
+	"\x06format\x18\x04 \x01(\tR\x06format\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x05 \x01(\x03R\tsizeBytes\x12\x13\n" +
+	"\x05vm_id\x18\x06 \x01(\tR\x04vmId\x12\x14\n" +
+	"\x05image\x18\a \x01(\tR\x05image\x12$\n" +
+	"\x0edelete_with_vm\x18\b \x01(\bR\fdeleteWithVm\"\x8a\x01\n" +
+	"\x13CreateVolumeRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04pool\x18\x02 \x01(\tR\x04pool\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x03 \x01(\x03R\tsizeBytes\x12\x16\n" +
+	"\x06format\x18\x04 \x01(\tR\x06format\x12\x14\n" +
+	"\x05image\x18\x05 \x01(\tR\x05image\"'\n" +
+	"\x11VolumeNameRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\">\n" +
+	"\x13AttachVolumeRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x13\n" +
+	"\x05vm_id\x18\x02 \x01(\tR\x04vmId\"H\n" +
+	"\x13ResizeVolumeRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x02 \x01(\x03R\tsizeBytes\"s\n" +
+	"\x12CloneVolumeRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04pool\x18\x03 \x01(\tR\x04pool\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x04 \x01(\x03R\tsizeBytes\"B\n" +
+	"\x13ListVolumesResponse\x12+\n" +
+	"\avolumes\x18\x01 \x03(\v2\x11.deusvm.v1.VolumeR\avolumes\"\x86\x01\n" +
+	"\x0eConvertRequest\x12\x14\n" +
+	"\x05image\x18\x01 \x01(\tR\x05image\x12\x16\n" +
+	"\x06volume\x18\x02 \x01(\tR\x06volume\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x16\n" +
+	"\x06format\x18\x04 \x01(\tR\x06format\x12\x1a\n" +
+	"\bcompress\x18\x05 \x01(\bR\bcompress\"\xc9\x01\n" +
+	"\x03Job\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x16\n" +
+	"\x06target\x18\x03 \x01(\tR\x06target\x12\x14\n" +
+	"\x05state\x18\x04 \x01(\tR\x05state\x12\x1a\n" +
+	"\bprogress\x18\x05 \x01(\x01R\bprogress\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\tR\tcreatedAt\x12\x1f\n" +
+	"\vfinished_at\x18\b \x01(\tR\n" +
+	"finishedAt\"\x1e\n" +
+	"\fJobIDRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"6\n" +
+	"\x10ListJobsResponse\x12\"\n" +
+	"\x04jobs\x18\x01 \x03(\v2\x0e.deusvm.v1.JobR\x04jobs\"\xca\x02\n" +
+	"\tPoolUsage\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12%\n" +
+	"\x0ecapacity_bytes\x18\x03 \x01(\x03R\rcapacityBytes\x12\x1d\n" +
+	"\n" +
+	"used_bytes\x18\x04 \x01(\x03R\tusedBytes\x12\x1d\n" +
+	"\n" +
+	"free_bytes\x18\x05 \x01(\x03R\tfreeBytes\x12+\n" +
+	"\x11provisioned_bytes\x18\x06 \x01(\x03R\x10provisionedBytes\x12\x1e\n" +
+	"\n" +
+	"overcommit\x18\a \x01(\x01R\n" +
+	"overcommit\x12&\n" +
+	"\x0fhigh_water_mark\x18\b \x01(\x01R\rhighWaterMark\x12%\n" +
+	"\x0emax_overcommit\x18\t \x01(\x01R\rmaxOvercommit\x12\x14\n" +
+	"\x05error\x18\n" +
+	" \x01(\tR\x05error\"\x86\x01\n" +
+	"\n" +
+	"ImageUsage\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06sha256\x18\x02 \x01(\tR\x06sha256\x12'\n" +
+	"\x0fallocated_bytes\x18\x03 \x01(\x03R\x0eallocatedBytes\x12#\n" +
+	"\rvirtual_bytes\x18\x04 \x01(\x03R\fvirtualBytes\"\x9a\x01\n" +
+	"\aVMUsage\x12\x13\n" +
+	"\x05vm_id\x18\x01 \x01(\tR\x04vmId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\avolumes\x18\x03 \x03(\tR\avolumes\x12'\n" +
+	"\x0fallocated_bytes\x18\x04 \x01(\x03R\x0eallocatedBytes\x12#\n" +
+	"\rvirtual_bytes\x18\x05 \x01(\x03R\fvirtualBytes\"\xc6\x01\n" +
+	"\fStorageUsage\x125\n" +
+	"\vimage_store\x18\x01 \x01(\v2\x14.deusvm.v1.PoolUsageR\n" +
+	"imageStore\x12*\n" +
+	"\x05pools\x18\x02 \x03(\v2\x14.deusvm.v1.PoolUsageR\x05pools\x12-\n" +
+	"\x06images\x18\x03 \x03(\v2\x15.deusvm.v1.ImageUsageR\x06images\x12$\n" +
+	"\x03vms\x18\x04 \x03(\v2\x12.deusvm.v1.VMUsageR\x03vms\"L\n" +
+	"\tGCRequest\x12\x17\n" +
+	"\adry_run\x18\x01 \x01(\bR\x06dryRun\x12&\n" +
+	"\x0fmin_age_seconds\x18\x02 \x01(\x03R\rminAgeSeconds\"\x81\x02\n" +
+	"\x06GCItem\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x12\n" +
+	"\x04pool\x18\x03 \x01(\tR\x04pool\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x05 \x01(\x03R\tsizeBytes\x12\x19\n" +
+	"\bmod_time\x18\x06 \x01(\tR\amodTime\x12\x16\n" +
+	"\x06reason\x18\a \x01(\tR\x06reason\x12\x1f\n" +
+	"\vreport_only\x18\b \x01(\bR\n" +
+	"reportOnly\x12\x1e\n" +
+	"\n" +
+	"quarantine\x18\t \x01(\tR\n" +
+	"quarantine\x12\x14\n" +
+	"\x05error\x18\n" +
+	" \x01(\tR\x05error\"\x91\x01\n" +
+	"\bGCReport\x12\x17\n" +
+	"\adry_run\x18\x01 \x01(\bR\x06dryRun\x12'\n" +
+	"\x05items\x18\x02 \x03(\v2\x11.deusvm.v1.GCItemR\x05items\x12+\n" +
+	"\x11reclaimable_bytes\x18\x03 \x01(\x03R\x10reclaimableBytes\x12\x16\n" +
+	"\x06purged\x18\x04 \x03(\tR\x06purged\"\xa8\x01\n" +
+	"\n" +
+	"BackupFile\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x03 \x01(\x03R\tsizeBytes\x12\x16\n" +
+	"\x06sha256\x18\x04 \x01(\tR\x06sha256\x12\x16\n" +
+	"\x06volume\x18\x05 \x01(\tR\x06volume\x12#\n" +
+	"\rvirtual_bytes\x18\x06 \x01(\x03R\fvirtualBytes\"\x9f\x02\n" +
+	"\x06Backup\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\tR\x06target\x12\x13\n" +
+	"\x05vm_id\x18\x03 \x01(\tR\x04vmId\x12\x17\n" +
+	"\avm_name\x18\x04 \x01(\tR\x06vmName\x12\x16\n" +
+	"\x06policy\x18\x05 \x01(\tR\x06policy\x12 \n" +
+	"\vconsistency\x18\x06 \x01(\tR\vconsistency\x12\x1a\n" +
+	"\bquiesced\x18\a \x01(\bR\bquiesced\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\b \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\t \x01(\x03R\tsizeBytes\x12+\n" +
+	"\x05files\x18\n" +
+	" \x03(\v2\x15.deusvm.v1.BackupFileR\x05files\"B\n" +
+	"\x13CreateBackupRequest\x12\x13\n" +
+	"\x05vm_id\x18\x01 \x01(\tR\x04vmId\x12\x16\n" +
+	"\x06policy\x18\x02 \x01(\tR\x06policy\"E\n" +
+	"\x12ListBackupsRequest\x12\x16\n" +
+	"\x06target\x18\x01 \x01(\tR\x06target\x12\x17\n" +
+	"\avm_name\x18\x02 \x01(\tR\x06vmName\"B\n" +
+	"\x13ListBackupsResponse\x12+\n" +
+	"\abackups\x18\x01 \x03(\v2\x11.deusvm.v1.BackupR\abackups\"!\n" +
+	"\x0fBackupIDRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"N\n" +
+	"\x14RestoreBackupRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04pool\x18\x03 \x01(\tR\x04pool\"p\n" +
+	"\x06Subnet\x12\x12\n" +
+	"\x04cidr\x18\x01 \x01(\tR\x04cidr\x12\x18\n" +
+	"\agateway\x18\x02 \x01(\tR\agateway\x12\x1d\n" +
+	"\n" +
+	"dhcp_start\x18\x03 \x01(\tR\tdhcpStart\x12\x19\n" +
+	"\bdhcp_end\x18\x04 \x01(\tR\adhcpEnd\"\xf2\x02\n" +
+	"\aNetwork\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04mode\x18\x02 \x01(\tR\x04mode\x12\x16\n" +
+	"\x06bridge\x18\x03 \x01(\tR\x06bridge\x12\x16\n" +
+	"\x06uplink\x18\x04 \x01(\tR\x06uplink\x12%\n" +
+	"\x04ipv4\x18\x05 \x01(\v2\x11.deusvm.v1.SubnetR\x04ipv4\x12%\n" +
+	"\x04ipv6\x18\x06 \x01(\v2\x11.deusvm.v1.SubnetR\x04ipv6\x12\x12\n" +
+	"\x04uuid\x18\a \x01(\tR\x04uuid\x12\x16\n" +
+	"\x06active\x18\b \x01(\bR\x06active\x12!\n" +
+	"\fexternal_dns\x18\t \x01(\bR\vexternalDns\x12.\n" +
+	"\ainbound\x18\n" +
+	" \x01(\v2\x14.deusvm.v1.BandwidthR\ainbound\x120\n" +
+	"\boutbound\x18\v \x01(\v2\x14.deusvm.v1.BandwidthR\boutbound\x12\x10\n" +
+	"\x03pxe\x18\f \x01(\bR\x03pxe\"\xd3\x02\n" +
+	"\x14CreateNetworkRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04mode\x18\x02 \x01(\tR\x04mode\x12\x16\n" +
+	"\x06bridge\x18\x03 \x01(\tR\x06bridge\x12\x16\n" +
+	"\x06uplink\x18\x04 \x01(\tR\x06uplink\x12%\n" +
+	"\x04ipv4\x18\x05 \x01(\v2\x11.deusvm.v1.SubnetR\x04ipv4\x12%\n" +
+	"\x04ipv6\x18\x06 \x01(\v2\x11.deusvm.v1.SubnetR\x04ipv6\x12!\n" +
+	"\fexternal_dns\x18\a \x01(\bR\vexternalDns\x12.\n" +
+	"\ainbound\x18\b \x01(\v2\x14.deusvm.v1.BandwidthR\ainbound\x120\n" +
+	"\boutbound\x18\t \x01(\v2\x14.deusvm.v1.BandwidthR\boutbound\x12\x10\n" +
+	"\x03pxe\x18\n" +
+	" \x01(\bR\x03pxe\"(\n" +
+	"\x12NetworkNameRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"F\n" +
+	"\x14ListNetworksResponse\x12.\n" +
+	"\bnetworks\x18\x01 \x03(\v2\x12.deusvm.v1.NetworkR\bnetworks\"\xad\x01\n" +
+	"\n" +
+	"Allocation\x12\x18\n" +
+	"\anetwork\x18\x01 \x01(\tR\anetwork\x12\x0e\n" +
+	"\x02ip\x18\x02 \x01(\tR\x02ip\x12\x10\n" +
+	"\x03mac\x18\x03 \x01(\tR\x03mac\x12\x13\n" +
+	"\x05vm_id\x18\x04 \x01(\tR\x04vmId\x12\x17\n" +
+	"\avm_name\x18\x05 \x01(\tR\x06vmName\x12\x16\n" +
+	"\x06static\x18\x06 \x01(\bR\x06static\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\tR\tcreatedAt\"2\n" +
+	"\x16ListAllocationsRequest\x12\x18\n" +
+	"\anetwork\x18\x01 \x01(\tR\anetwork\"R\n" +
+	"\x17ListAllocationsResponse\x127\n" +
+	"\vallocations\x18\x01 \x03(\v2\x15.deusvm.v1.AllocationR\vallocations\"\xac\x01\n" +
+	"\fSecurityRule\x12\x1c\n" +
+	"\tdirection\x18\x01 \x01(\tR\tdirection\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x1b\n" +
+	"\tport_from\x18\x03 \x01(\x05R\bportFrom\x12\x17\n" +
+	"\aport_to\x18\x04 \x01(\x05R\x06portTo\x12\x14\n" +
+	"\x05cidrs\x18\x05 \x03(\tR\x05cidrs\x12\x16\n" +
+	"\x06groups\x18\x06 \x03(\tR\x06groups\"t\n" +
+	"\rSecurityGroup\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12-\n" +
+	"\x05rules\x18\x03 \x03(\v2\x17.deusvm.v1.SecurityRuleR\x05rules\".\n" +
+	"\x18SecurityGroupNameRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"N\n" +
+	"\x1aListSecurityGroupsResponse\x120\n" +
+	"\x06groups\x18\x01 \x03(\v2\x18.deusvm.v1.SecurityGroupR\x06groups\"\\\n" +
+	"\x1bSetNICSecurityGroupsRequest\x12\x13\n" +
+	"\x05vm_id\x18\x01 \x01(\tR\x04vmId\x12\x10\n" +
+	"\x03mac\x18\x02 \x01(\tR\x03mac\x12\x16\n" +
+	"\x06groups\x18\x03 \x03(\tR\x06groups\"\xfa\x01\n" +
+	"\vPortForward\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x17\n" +
+	"\ahost_ip\x18\x02 \x01(\tR\x06hostIp\x12\x1b\n" +
+	"\thost_port\x18\x03 \x01(\x05R\bhostPort\x12\x1a\n" +
+	"\bprotocol\x18\x04 \x01(\tR\bprotocol\x12\x0e\n" +
+	"\x02vm\x18\x05 \x01(\tR\x02vm\x12\x17\n" +
+	"\avm_name\x18\x06 \x01(\tR\x06vmName\x12\x18\n" +
+	"\anetwork\x18\a \x01(\tR\anetwork\x12\x12\n" +
+	"\x04port\x18\b \x01(\x05R\x04port\x12\x16\n" +
+	"\x06target\x18\t \x01(\tR\x06target\x12\x16\n" +
+	"\x06active\x18\n" +
+	" \x01(\bR\x06active\",\n" +
+	"\x16PortForwardNameRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\")\n" +
+	"\x17ListPortForwardsRequest\x12\x0e\n" +
+	"\x02vm\x18\x01 \x01(\tR\x02vm\"N\n" +
+	"\x18ListPortForwardsResponse\x122\n" +
+	"\bforwards\x18\x01 \x03(\v2\x16.deusvm.v1.PortForwardR\bforwards\"\\\n" +
+	"\fBootTemplate\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06script\x18\x03 \x01(\tR\x06script\"-\n" +
+	"\x17BootTemplateNameRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"R\n" +
+	"\x19ListBootTemplatesResponse\x125\n" +
+	"\ttemplates\x18\x01 \x03(\v2\x17.deusvm.v1.BootTemplateR\ttemplates\"F\n" +
+	"\x18SetVMBootTemplateRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\btemplate\x18\x02 \x01(\tR\btemplate\"\xbb\x02\n" +
+	"\rHostInterface\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05index\x18\x02 \x01(\x05R\x05index\x12\x12\n" +
+	"\x04kind\x18\x03 \x01(\tR\x04kind\x12\x10\n" +
+	"\x03mac\x18\x04 \x01(\tR\x03mac\x12\x10\n" +
+	"\x03mtu\x18\x05 \x01(\x05R\x03mtu\x12\x14\n" +
+	"\x05state\x18\x06 \x01(\tR\x05state\x12\x1d\n" +
+	"\n" +
+	"oper_state\x18\a \x01(\tR\toperState\x12\x16\n" +
+	"\x06master\x18\b \x01(\tR\x06master\x12\x14\n" +
+	"\x05ports\x18\t \x03(\tR\x05ports\x12\x16\n" +
+	"\x06parent\x18\n" +
+	" \x01(\tR\x06parent\x12\x12\n" +
+	"\x04vlan\x18\v \x01(\x05R\x04vlan\x12\x1b\n" +
+	"\tbond_mode\x18\f \x01(\tR\bbondMode\x12\x1c\n" +
+	"\taddresses\x18\r \x03(\tR\taddresses\"Y\n" +
+	"\x1dListNetworkInterfacesResponse\x128\n" +
+	"\n" +
+	"interfaces\x18\x01 \x03(\v2\x18.deusvm.v1.HostInterfaceR\n" +
+	"interfaces2\xfa\x05\n" +
 	"\tVMService\x123\n" +
 	"\x06Create\x12\x1a.deusvm.v1.CreateVMRequest\x1a\r.deusvm.v1.VM\x122\n" +
 	"\x06Delete\x12\x16.deusvm.v1.VMIDRequest\x1a\x10.deusvm.v1.Empty\x121\n" +
 	"\x05Start\x12\x16.deusvm.v1.VMIDRequest\x1a\x10.deusvm.v1.Empty\x120\n" +
 	"\x04Stop\x12\x16.deusvm.v1.VMIDRequest\x1a\x10.deusvm.v1.Empty\x12,\n" +
 	"\x03Get\x12\x16.deusvm.v1.VMIDRequest\x1a\r.deusvm.v1.VM\x124\n" +
-	"\x04List\x12\x10.deusvm.v1.Empty\x1a\x1a.deusvm.v1.ListVMsResponse2\xbb\x01\n" +
+	"\x04List\x12\x10.deusvm.v1.Empty\x1a\x1a.deusvm.v1.ListVMsResponse\x12>\n" +
+	"\vInsertMedia\x12\x1d.deusvm.v1.InsertMediaRequest\x1a\x10.deusvm.v1.Empty\x126\n" +
+	"\n" +
+	"EjectMedia\x12\x16.deusvm.v1.VMIDRequest\x1a\x10.deusvm.v1.Empty\x12C\n" +
+	"\x0fAttachInterface\x12!.deusvm.v1.AttachInterfaceRequest\x1a\r.deusvm.v1.VM\x12C\n" +
+	"\x0fDetachInterface\x12!.deusvm.v1.DetachInterfaceRequest\x1a\r.deusvm.v1.VM\x12C\n" +
+	"\x0fUpdateInterface\x12!.deusvm.v1.UpdateInterfaceRequest\x1a\r.deusvm.v1.VM\x12=\n" +
+	"\x06Export\x12\x16.deusvm.v1.VMIDRequest\x1a\x19.deusvm.v1.VMArchiveChunk0\x01\x125\n" +
+	"\x06Import\x12\x1a.deusvm.v1.ImportVMRequest\x1a\r.deusvm.v1.VM(\x012\xbb\x03\n" +
 	"\fImageService\x129\n" +
-	"\x06Create\x12\x1d.deusvm.v1.CreateImageRequest\x1a\x10.deusvm.v1.Image\x127\n" +
+	"\x06Create\x12\x1d.deusvm.v1.CreateImageRequest\x1a\x10.deusvm.v1.Image\x12I\n" +
+	"\fCreateStream\x12\x1d.deusvm.v1.CreateImageRequest\x1a\x18.deusvm.v1.ImageProgress0\x01\x12;\n" +
+	"\x06Upload\x12\x1d.deusvm.v1.UploadImageRequest\x1a\x10.deusvm.v1.Image(\x01\x123\n" +
+	"\x03Tag\x12\x1a.deusvm.v1.TagImageRequest\x1a\x10.deusvm.v1.Image\x12A\n" +
+	"\rCaptureFromVM\x12\x1e.deusvm.v1.CaptureImageRequest\x1a\x10.deusvm.v1.Image\x127\n" +
 	"\x06Delete\x12\x1b.deusvm.v1.ImageNameRequest\x1a\x10.deusvm.v1.Empty\x127\n" +
-	"\x04List\x12\x10.deusvm.v1.Empty\x1a\x1d.deusvm.v1.ListImagesResponseB9Z7github.com/riccardotacconi/deusvm/pkg/proto;deusvmprotob\x06proto3"
+	"\x04List\x12\x10.deusvm.v1.Empty\x1a\x1d.deusvm.v1.ListImagesResponse2\xe8\x03\n" +
+	"\rVolumeService\x12;\n" +
+	"\x06Create\x12\x1e.deusvm.v1.CreateVolumeRequest\x1a\x11.deusvm.v1.Volume\x126\n" +
+	"\x03Get\x12\x1c.deusvm.v1.VolumeNameRequest\x1a\x11.deusvm.v1.Volume\x128\n" +
+	"\x04List\x12\x10.deusvm.v1.Empty\x1a\x1e.deusvm.v1.ListVolumesResponse\x128\n" +
+	"\x06Delete\x12\x1c.deusvm.v1.VolumeNameRequest\x1a\x10.deusvm.v1.Empty\x12;\n" +
+	"\x06Attach\x12\x1e.deusvm.v1.AttachVolumeRequest\x1a\x11.deusvm.v1.Volume\x129\n" +
+	"\x06Detach\x12\x1c.deusvm.v1.VolumeNameRequest\x1a\x11.deusvm.v1.Volume\x12;\n" +
+	"\x06Resize\x12\x1e.deusvm.v1.ResizeVolumeRequest\x1a\x11.deusvm.v1.Volume\x129\n" +
+	"\x05Clone\x12\x1d.deusvm.v1.CloneVolumeRequest\x1a\x11.deusvm.v1.Volume2\xa5\x02\n" +
+	"\x0eStorageService\x124\n" +
+	"\aConvert\x12\x19.deusvm.v1.ConvertRequest\x1a\x0e.deusvm.v1.Job\x121\n" +
+	"\x06GetJob\x12\x17.deusvm.v1.JobIDRequest\x1a\x0e.deusvm.v1.Job\x129\n" +
+	"\bListJobs\x12\x10.deusvm.v1.Empty\x1a\x1b.deusvm.v1.ListJobsResponse\x122\n" +
+	"\x05Usage\x12\x10.deusvm.v1.Empty\x1a\x17.deusvm.v1.StorageUsage\x12;\n" +
+	"\x0eGarbageCollect\x12\x14.deusvm.v1.GCRequest\x1a\x13.deusvm.v1.GCReport2\xba\x02\n" +
+	"\rBackupService\x128\n" +
+	"\x06Create\x12\x1e.deusvm.v1.CreateBackupRequest\x1a\x0e.deusvm.v1.Job\x12E\n" +
+	"\x04List\x12\x1d.deusvm.v1.ListBackupsRequest\x1a\x1e.deusvm.v1.ListBackupsResponse\x124\n" +
+	"\x03Get\x12\x1a.deusvm.v1.BackupIDRequest\x1a\x11.deusvm.v1.Backup\x12:\n" +
+	"\aRestore\x12\x1f.deusvm.v1.RestoreBackupRequest\x1a\x0e.deusvm.v1.Job\x126\n" +
+	"\x06Delete\x12\x1a.deusvm.v1.BackupIDRequest\x1a\x10.deusvm.v1.Empty2\xd9\x02\n" +
+	"\x0eNetworkService\x12=\n" +
+	"\x06Create\x12\x1f.deusvm.v1.CreateNetworkRequest\x1a\x12.deusvm.v1.Network\x128\n" +
+	"\x03Get\x12\x1d.deusvm.v1.NetworkNameRequest\x1a\x12.deusvm.v1.Network\x129\n" +
+	"\x04List\x12\x10.deusvm.v1.Empty\x1a\x1f.deusvm.v1.ListNetworksResponse\x129\n" +
+	"\x06Delete\x12\x1d.deusvm.v1.NetworkNameRequest\x1a\x10.deusvm.v1.Empty\x12X\n" +
+	"\x0fListAllocations\x12!.deusvm.v1.ListAllocationsRequest\x1a\".deusvm.v1.ListAllocationsResponse2\xa1\x03\n" +
+	"\x14SecurityGroupService\x12<\n" +
+	"\x06Create\x12\x18.deusvm.v1.SecurityGroup\x1a\x18.deusvm.v1.SecurityGroup\x12D\n" +
+	"\x03Get\x12#.deusvm.v1.SecurityGroupNameRequest\x1a\x18.deusvm.v1.SecurityGroup\x12?\n" +
+	"\x04List\x12\x10.deusvm.v1.Empty\x1a%.deusvm.v1.ListSecurityGroupsResponse\x12<\n" +
+	"\x06Update\x12\x18.deusvm.v1.SecurityGroup\x1a\x18.deusvm.v1.SecurityGroup\x12?\n" +
+	"\x06Delete\x12#.deusvm.v1.SecurityGroupNameRequest\x1a\x10.deusvm.v1.Empty\x12E\n" +
+	"\fSetNICGroups\x12&.deusvm.v1.SetNICSecurityGroupsRequest\x1a\r.deusvm.v1.VM2\xa0\x02\n" +
+	"\x12PortForwardService\x128\n" +
+	"\x06Create\x12\x16.deusvm.v1.PortForward\x1a\x16.deusvm.v1.PortForward\x12@\n" +
+	"\x03Get\x12!.deusvm.v1.PortForwardNameRequest\x1a\x16.deusvm.v1.PortForward\x12O\n" +
+	"\x04List\x12\".deusvm.v1.ListPortForwardsRequest\x1a#.deusvm.v1.ListPortForwardsResponse\x12=\n" +
+	"\x06Delete\x12!.deusvm.v1.PortForwardNameRequest\x1a\x10.deusvm.v1.Empty2\xb7\x03\n" +
+	"\vBootService\x12B\n" +
+	"\x0eCreateTemplate\x12\x17.deusvm.v1.BootTemplate\x1a\x17.deusvm.v1.BootTemplate\x12J\n" +
+	"\vGetTemplate\x12\".deusvm.v1.BootTemplateNameRequest\x1a\x17.deusvm.v1.BootTemplate\x12G\n" +
+	"\rListTemplates\x12\x10.deusvm.v1.Empty\x1a$.deusvm.v1.ListBootTemplatesResponse\x12B\n" +
+	"\x0eUpdateTemplate\x12\x17.deusvm.v1.BootTemplate\x1a\x17.deusvm.v1.BootTemplate\x12F\n" +
+	"\x0eDeleteTemplate\x12\".deusvm.v1.BootTemplateNameRequest\x1a\x10.deusvm.v1.Empty\x12C\n" +
+	"\rSetVMTemplate\x12#.deusvm.v1.SetVMBootTemplateRequest\x1a\r.deusvm.v1.VM2b\n" +
+	"\vHostService\x12S\n" +
+	"\x15ListNetworkInterfaces\x12\x10.deusvm.v1.Empty\x1a(.deusvm.v1.ListNetworkInterfacesResponseB9Z7github.com/riccardotacconi/deusvm/pkg/proto;deusvmprotob\x06proto3"
 
 var (
 	file_deusvm_proto_rawDescOnce sync.Once
@@ -593,44 +5374,243 @@ func file_deusvm_proto_rawDescGZIP() []byte {
 	return file_deusvm_proto_rawDescData
 }
 
-var file_deusvm_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_deusvm_proto_msgTypes = make([]protoimpl.MessageInfo, 72)
 var file_deusvm_proto_goTypes = []any{
-	(*Empty)(nil),              // 0: deusvm.v1.Empty
-	(*VM)(nil),                 // 1: deusvm.v1.VM
-	(*CreateVMRequest)(nil),    // 2: deusvm.v1.CreateVMRequest
-	(*VMIDRequest)(nil),        // 3: deusvm.v1.VMIDRequest
-	(*ListVMsResponse)(nil),    // 4: deusvm.v1.ListVMsResponse
-	(*Image)(nil),              // 5: deusvm.v1.Image
-	(*CreateImageRequest)(nil), // 6: deusvm.v1.CreateImageRequest
-	(*ImageNameRequest)(nil),   // 7: deusvm.v1.ImageNameRequest
-	(*ListImagesResponse)(nil), // 8: deusvm.v1.ListImagesResponse
+	(*Empty)(nil),                         // 0: deusvm.v1.Empty
+	(*VM)(nil),                            // 1: deusvm.v1.VM
+	(*NIC)(nil),                           // 2: deusvm.v1.NIC
+	(*Bandwidth)(nil),                     // 3: deusvm.v1.Bandwidth
+	(*CreateVMRequest)(nil),               // 4: deusvm.v1.CreateVMRequest
+	(*VMIDRequest)(nil),                   // 5: deusvm.v1.VMIDRequest
+	(*InsertMediaRequest)(nil),            // 6: deusvm.v1.InsertMediaRequest
+	(*AttachInterfaceRequest)(nil),        // 7: deusvm.v1.AttachInterfaceRequest
+	(*DetachInterfaceRequest)(nil),        // 8: deusvm.v1.DetachInterfaceRequest
+	(*UpdateInterfaceRequest)(nil),        // 9: deusvm.v1.UpdateInterfaceRequest
+	(*ListVMsResponse)(nil),               // 10: deusvm.v1.ListVMsResponse
+	(*VMArchiveChunk)(nil),                // 11: deusvm.v1.VMArchiveChunk
+	(*ImportVMInfo)(nil),                  // 12: deusvm.v1.ImportVMInfo
+	(*ImportVMRequest)(nil),               // 13: deusvm.v1.ImportVMRequest
+	(*Image)(nil),                         // 14: deusvm.v1.Image
+	(*Lineage)(nil),                       // 15: deusvm.v1.Lineage
+	(*CreateImageRequest)(nil),            // 16: deusvm.v1.CreateImageRequest
+	(*ImageProgress)(nil),                 // 17: deusvm.v1.ImageProgress
+	(*UploadImageInfo)(nil),               // 18: deusvm.v1.UploadImageInfo
+	(*UploadImageRequest)(nil),            // 19: deusvm.v1.UploadImageRequest
+	(*ImageNameRequest)(nil),              // 20: deusvm.v1.ImageNameRequest
+	(*TagImageRequest)(nil),               // 21: deusvm.v1.TagImageRequest
+	(*CaptureImageRequest)(nil),           // 22: deusvm.v1.CaptureImageRequest
+	(*ListImagesResponse)(nil),            // 23: deusvm.v1.ListImagesResponse
+	(*Volume)(nil),                        // 24: deusvm.v1.Volume
+	(*CreateVolumeRequest)(nil),           // 25: deusvm.v1.CreateVolumeRequest
+	(*VolumeNameRequest)(nil),             // 26: deusvm.v1.VolumeNameRequest
+	(*AttachVolumeRequest)(nil),           // 27: deusvm.v1.AttachVolumeRequest
+	(*ResizeVolumeRequest)(nil),           // 28: deusvm.v1.ResizeVolumeRequest
+	(*CloneVolumeRequest)(nil),            // 29: deusvm.v1.CloneVolumeRequest
+	(*ListVolumesResponse)(nil),           // 30: deusvm.v1.ListVolumesResponse
+	(*ConvertRequest)(nil),                // 31: deusvm.v1.ConvertRequest
+	(*Job)(nil),                           // 32: deusvm.v1.Job
+	(*JobIDRequest)(nil),                  // 33: deusvm.v1.JobIDRequest
+	(*ListJobsResponse)(nil),              // 34: deusvm.v1.ListJobsResponse
+	(*PoolUsage)(nil),                     // 35: deusvm.v1.PoolUsage
+	(*ImageUsage)(nil),                    // 36: deusvm.v1.ImageUsage
+	(*VMUsage)(nil),                       // 37: deusvm.v1.VMUsage
+	(*StorageUsage)(nil),                  // 38: deusvm.v1.StorageUsage
+	(*GCRequest)(nil),                     // 39: deusvm.v1.GCRequest
+	(*GCItem)(nil),                        // 40: deusvm.v1.GCItem
+	(*GCReport)(nil),                      // 41: deusvm.v1.GCReport
+	(*BackupFile)(nil),                    // 42: deusvm.v1.BackupFile
+	(*Backup)(nil),                        // 43: deusvm.v1.Backup
+	(*CreateBackupRequest)(nil),           // 44: deusvm.v1.CreateBackupRequest
+	(*ListBackupsRequest)(nil),            // 45: deusvm.v1.ListBackupsRequest
+	(*ListBackupsResponse)(nil),           // 46: deusvm.v1.ListBackupsResponse
+	(*BackupIDRequest)(nil),               // 47: deusvm.v1.BackupIDRequest
+	(*RestoreBackupRequest)(nil),          // 48: deusvm.v1.RestoreBackupRequest
+	(*Subnet)(nil),                        // 49: deusvm.v1.Subnet
+	(*Network)(nil),                       // 50: deusvm.v1.Network
+	(*CreateNetworkRequest)(nil),          // 51: deusvm.v1.CreateNetworkRequest
+	(*NetworkNameRequest)(nil),            // 52: deusvm.v1.NetworkNameRequest
+	(*ListNetworksResponse)(nil),          // 53: deusvm.v1.ListNetworksResponse
+	(*Allocation)(nil),                    // 54: deusvm.v1.Allocation
+	(*ListAllocationsRequest)(nil),        // 55: deusvm.v1.ListAllocationsRequest
+	(*ListAllocationsResponse)(nil),       // 56: deusvm.v1.ListAllocationsResponse
+	(*SecurityRule)(nil),                  // 57: deusvm.v1.SecurityRule
+	(*SecurityGroup)(nil),                 // 58: deusvm.v1.SecurityGroup
+	(*SecurityGroupNameRequest)(nil),      // 59: deusvm.v1.SecurityGroupNameRequest
+	(*ListSecurityGroupsResponse)(nil),    // 60: deusvm.v1.ListSecurityGroupsResponse
+	(*SetNICSecurityGroupsRequest)(nil),   // 61: deusvm.v1.SetNICSecurityGroupsRequest
+	(*PortForward)(nil),                   // 62: deusvm.v1.PortForward
+	(*PortForwardNameRequest)(nil),        // 63: deusvm.v1.PortForwardNameRequest
+	(*ListPortForwardsRequest)(nil),       // 64: deusvm.v1.ListPortForwardsRequest
+	(*ListPortForwardsResponse)(nil),      // 65: deusvm.v1.ListPortForwardsResponse
+	(*BootTemplate)(nil),                  // 66: deusvm.v1.BootTemplate
+	(*BootTemplateNameRequest)(nil),       // 67: deusvm.v1.BootTemplateNameRequest
+	(*ListBootTemplatesResponse)(nil),     // 68: deusvm.v1.ListBootTemplatesResponse
+	(*SetVMBootTemplateRequest)(nil),      // 69: deusvm.v1.SetVMBootTemplateRequest
+	(*HostInterface)(nil),                 // 70: deusvm.v1.HostInterface
+	(*ListNetworkInterfacesResponse)(nil), // 71: deusvm.v1.ListNetworkInterfacesResponse
 }
 var file_deusvm_proto_depIdxs = []int32{
-	1,  // 0: deusvm.v1.ListVMsResponse.vms:type_name -> deusvm.v1.VM
-	5,  // 1: deusvm.v1.ListImagesResponse.images:type_name -> deusvm.v1.Image
-	2,  // 2: deusvm.v1.VMService.Create:input_type -> deusvm.v1.CreateVMRequest
-	3,  // 3: deusvm.v1.VMService.Delete:input_type -> deusvm.v1.VMIDRequest
-	3,  // 4: deusvm.v1.VMService.Start:input_type -> deusvm.v1.VMIDRequest
-	3,  // 5: deusvm.v1.VMService.Stop:input_type -> deusvm.v1.VMIDRequest
-	3,  // 6: deusvm.v1.VMService.Get:input_type -> deusvm.v1.VMIDRequest
-	0,  // 7: deusvm.v1.VMService.List:input_type -> deusvm.v1.Empty
-	6,  // 8: deusvm.v1.ImageService.Create:input_type -> deusvm.v1.CreateImageRequest
-	7,  // 9: deusvm.v1.ImageService.Delete:input_type -> deusvm.v1.ImageNameRequest
-	0,  // 10: deusvm.v1.ImageService.List:input_type -> deusvm.v1.Empty
-	1,  // 11: deusvm.v1.VMService.Create:output_type -> deusvm.v1.VM
-	0,  // 12: deusvm.v1.VMService.Delete:output_type -> deusvm.v1.Empty
-	0,  // 13: deusvm.v1.VMService.Start:output_type -> deusvm.v1.Empty
-	0,  // 14: deusvm.v1.VMService.Stop:output_type -> deusvm.v1.Empty
-	1,  // 15: deusvm.v1.VMService.Get:output_type -> deusvm.v1.VM
-	4,  // 16: deusvm.v1.VMService.List:output_type -> deusvm.v1.ListVMsResponse
-	5,  // 17: deusvm.v1.ImageService.Create:output_type -> deusvm.v1.Image
-	0,  // 18: deusvm.v1.ImageService.Delete:output_type -> deusvm.v1.Empty
-	8,  // 19: deusvm.v1.ImageService.List:output_type -> deusvm.v1.ListImagesResponse
-	11, // [11:20] is the sub-list for method output_type
-	2,  // [2:11] is the sub-list for method input_type
-	2,  // [2:2] is the sub-list for extension type_name
-	2,  // [2:2] is the sub-list for extension extendee
-	0,  // [0:2] is the sub-list for field type_name
+	2,  // 0: deusvm.v1.VM.nics:type_name -> deusvm.v1.NIC
+	3,  // 1: deusvm.v1.NIC.inbound:type_name -> deusvm.v1.Bandwidth
+	3,  // 2: deusvm.v1.NIC.outbound:type_name -> deusvm.v1.Bandwidth
+	2,  // 3: deusvm.v1.CreateVMRequest.nics:type_name -> deusvm.v1.NIC
+	2,  // 4: deusvm.v1.AttachInterfaceRequest.nic:type_name -> deusvm.v1.NIC
+	2,  // 5: deusvm.v1.UpdateInterfaceRequest.nic:type_name -> deusvm.v1.NIC
+	1,  // 6: deusvm.v1.ListVMsResponse.vms:type_name -> deusvm.v1.VM
+	12, // 7: deusvm.v1.ImportVMRequest.info:type_name -> deusvm.v1.ImportVMInfo
+	15, // 8: deusvm.v1.Image.lineage:type_name -> deusvm.v1.Lineage
+	14, // 9: deusvm.v1.ImageProgress.image:type_name -> deusvm.v1.Image
+	18, // 10: deusvm.v1.UploadImageRequest.info:type_name -> deusvm.v1.UploadImageInfo
+	14, // 11: deusvm.v1.ListImagesResponse.images:type_name -> deusvm.v1.Image
+	24, // 12: deusvm.v1.ListVolumesResponse.volumes:type_name -> deusvm.v1.Volume
+	32, // 13: deusvm.v1.ListJobsResponse.jobs:type_name -> deusvm.v1.Job
+	35, // 14: deusvm.v1.StorageUsage.image_store:type_name -> deusvm.v1.PoolUsage
+	35, // 15: deusvm.v1.StorageUsage.pools:type_name -> deusvm.v1.PoolUsage
+	36, // 16: deusvm.v1.StorageUsage.images:type_name -> deusvm.v1.ImageUsage
+	37, // 17: deusvm.v1.StorageUsage.vms:type_name -> deusvm.v1.VMUsage
+	40, // 18: deusvm.v1.GCReport.items:type_name -> deusvm.v1.GCItem
+	42, // 19: deusvm.v1.Backup.files:type_name -> deusvm.v1.BackupFile
+	43, // 20: deusvm.v1.ListBackupsResponse.backups:type_name -> deusvm.v1.Backup
+	49, // 21: deusvm.v1.Network.ipv4:type_name -> deusvm.v1.Subnet
+	49, // 22: deusvm.v1.Network.ipv6:type_name -> deusvm.v1.Subnet
+	3,  // 23: deusvm.v1.Network.inbound:type_name -> deusvm.v1.Bandwidth
+	3,  // 24: deusvm.v1.Network.outbound:type_name -> deusvm.v1.Bandwidth
+	49, // 25: deusvm.v1.CreateNetworkRequest.ipv4:type_name -> deusvm.v1.Subnet
+	49, // 26: deusvm.v1.CreateNetworkRequest.ipv6:type_name -> deusvm.v1.Subnet
+	3,  // 27: deusvm.v1.CreateNetworkRequest.inbound:type_name -> deusvm.v1.Bandwidth
+	3,  // 28: deusvm.v1.CreateNetworkRequest.outbound:type_name -> deusvm.v1.Bandwidth
+	50, // 29: deusvm.v1.ListNetworksResponse.networks:type_name -> deusvm.v1.Network
+	54, // 30: deusvm.v1.ListAllocationsResponse.allocations:type_name -> deusvm.v1.Allocation
+	57, // 31: deusvm.v1.SecurityGroup.rules:type_name -> deusvm.v1.SecurityRule
+	58, // 32: deusvm.v1.ListSecurityGroupsResponse.groups:type_name -> deusvm.v1.SecurityGroup
+	62, // 33: deusvm.v1.ListPortForwardsResponse.forwards:type_name -> deusvm.v1.PortForward
+	66, // 34: deusvm.v1.ListBootTemplatesResponse.templates:type_name -> deusvm.v1.BootTemplate
+	70, // 35: deusvm.v1.ListNetworkInterfacesResponse.interfaces:type_name -> deusvm.v1.HostInterface
+	4,  // 36: deusvm.v1.VMService.Create:input_type -> deusvm.v1.CreateVMRequest
+	5,  // 37: deusvm.v1.VMService.Delete:input_type -> deusvm.v1.VMIDRequest
+	5,  // 38: deusvm.v1.VMService.Start:input_type -> deusvm.v1.VMIDRequest
+	5,  // 39: deusvm.v1.VMService.Stop:input_type -> deusvm.v1.VMIDRequest
+	5,  // 40: deusvm.v1.VMService.Get:input_type -> deusvm.v1.VMIDRequest
+	0,  // 41: deusvm.v1.VMService.List:input_type -> deusvm.v1.Empty
+	6,  // 42: deusvm.v1.VMService.InsertMedia:input_type -> deusvm.v1.InsertMediaRequest
+	5,  // 43: deusvm.v1.VMService.EjectMedia:input_type -> deusvm.v1.VMIDRequest
+	7,  // 44: deusvm.v1.VMService.AttachInterface:input_type -> deusvm.v1.AttachInterfaceRequest
+	8,  // 45: deusvm.v1.VMService.DetachInterface:input_type -> deusvm.v1.DetachInterfaceRequest
+	9,  // 46: deusvm.v1.VMService.UpdateInterface:input_type -> deusvm.v1.UpdateInterfaceRequest
+	5,  // 47: deusvm.v1.VMService.Export:input_type -> deusvm.v1.VMIDRequest
+	13, // 48: deusvm.v1.VMService.Import:input_type -> deusvm.v1.ImportVMRequest
+	16, // 49: deusvm.v1.ImageService.Create:input_type -> deusvm.v1.CreateImageRequest
+	16, // 50: deusvm.v1.ImageService.CreateStream:input_type -> deusvm.v1.CreateImageRequest
+	19, // 51: deusvm.v1.ImageService.Upload:input_type -> deusvm.v1.UploadImageRequest
+	21, // 52: deusvm.v1.ImageService.Tag:input_type -> deusvm.v1.TagImageRequest
+	22, // 53: deusvm.v1.ImageService.CaptureFromVM:input_type -> deusvm.v1.CaptureImageRequest
+	20, // 54: deusvm.v1.ImageService.Delete:input_type -> deusvm.v1.ImageNameRequest
+	0,  // 55: deusvm.v1.ImageService.List:input_type -> deusvm.v1.Empty
+	25, // 56: deusvm.v1.VolumeService.Create:input_type -> deusvm.v1.CreateVolumeRequest
+	26, // 57: deusvm.v1.VolumeService.Get:input_type -> deusvm.v1.VolumeNameRequest
+	0,  // 58: deusvm.v1.VolumeService.List:input_type -> deusvm.v1.Empty
+	26, // 59: deusvm.v1.VolumeService.Delete:input_type -> deusvm.v1.VolumeNameRequest
+	27, // 60: deusvm.v1.VolumeService.Attach:input_type -> deusvm.v1.AttachVolumeRequest
+	26, // 61: deusvm.v1.VolumeService.Detach:input_type -> deusvm.v1.VolumeNameRequest
+	28, // 62: deusvm.v1.VolumeService.Resize:input_type -> deusvm.v1.ResizeVolumeRequest
+	29, // 63: deusvm.v1.VolumeService.Clone:input_type -> deusvm.v1.CloneVolumeRequest
+	31, // 64: deusvm.v1.StorageService.Convert:input_type -> deusvm.v1.ConvertRequest
+	33, // 65: deusvm.v1.StorageService.GetJob:input_type -> deusvm.v1.JobIDRequest
+	0,  // 66: deusvm.v1.StorageService.ListJobs:input_type -> deusvm.v1.Empty
+	0,  // 67: deusvm.v1.StorageService.Usage:input_type -> deusvm.v1.Empty
+	39, // 68: deusvm.v1.StorageService.GarbageCollect:input_type -> deusvm.v1.GCRequest
+	44, // 69: deusvm.v1.BackupService.Create:input_type -> deusvm.v1.CreateBackupRequest
+	45, // 70: deusvm.v1.BackupService.List:input_type -> deusvm.v1.ListBackupsRequest
+	47, // 71: deusvm.v1.BackupService.Get:input_type -> deusvm.v1.BackupIDRequest
+	48, // 72: deusvm.v1.BackupService.Restore:input_type -> deusvm.v1.RestoreBackupRequest
+	47, // 73: deusvm.v1.BackupService.Delete:input_type -> deusvm.v1.BackupIDRequest
+	51, // 74: deusvm.v1.NetworkService.Create:input_type -> deusvm.v1.CreateNetworkRequest
+	52, // 75: deusvm.v1.NetworkService.Get:input_type -> deusvm.v1.NetworkNameRequest
+	0,  // 76: deusvm.v1.NetworkService.List:input_type -> deusvm.v1.Empty
+	52, // 77: deusvm.v1.NetworkService.Delete:input_type -> deusvm.v1.NetworkNameRequest
+	55, // 78: deusvm.v1.NetworkService.ListAllocations:input_type -> deusvm.v1.ListAllocationsRequest
+	58, // 79: deusvm.v1.SecurityGroupService.Create:input_type -> deusvm.v1.SecurityGroup
+	59, // 80: deusvm.v1.SecurityGroupService.Get:input_type -> deusvm.v1.SecurityGroupNameRequest
+	0,  // 81: deusvm.v1.SecurityGroupService.List:input_type -> deusvm.v1.Empty
+	58, // 82: deusvm.v1.SecurityGroupService.Update:input_type -> deusvm.v1.SecurityGroup
+	59, // 83: deusvm.v1.SecurityGroupService.Delete:input_type -> deusvm.v1.SecurityGroupNameRequest
+	61, // 84: deusvm.v1.SecurityGroupService.SetNICGroups:input_type -> deusvm.v1.SetNICSecurityGroupsRequest
+	62, // 85: deusvm.v1.PortForwardService.Create:input_type -> deusvm.v1.PortForward
+	63, // 86: deusvm.v1.PortForwardService.Get:input_type -> deusvm.v1.PortForwardNameRequest
+	64, // 87: deusvm.v1.PortForwardService.List:input_type -> deusvm.v1.ListPortForwardsRequest
+	63, // 88: deusvm.v1.PortForwardService.Delete:input_type -> deusvm.v1.PortForwardNameRequest
+	66, // 89: deusvm.v1.BootService.CreateTemplate:input_type -> deusvm.v1.BootTemplate
+	67, // 90: deusvm.v1.BootService.GetTemplate:input_type -> deusvm.v1.BootTemplateNameRequest
+	0,  // 91: deusvm.v1.BootService.ListTemplates:input_type -> deusvm.v1.Empty
+	66, // 92: deusvm.v1.BootService.UpdateTemplate:input_type -> deusvm.v1.BootTemplate
+	67, // 93: deusvm.v1.BootService.DeleteTemplate:input_type -> deusvm.v1.BootTemplateNameRequest
+	69, // 94: deusvm.v1.BootService.SetVMTemplate:input_type -> deusvm.v1.SetVMBootTemplateRequest
+	0,  // 95: deusvm.v1.HostService.ListNetworkInterfaces:input_type -> deusvm.v1.Empty
+	1,  // 96: deusvm.v1.VMService.Create:output_type -> deusvm.v1.VM
+	0,  // 97: deusvm.v1.VMService.Delete:output_type -> deusvm.v1.Empty
+	0,  // 98: deusvm.v1.VMService.Start:output_type -> deusvm.v1.Empty
+	0,  // 99: deusvm.v1.VMService.Stop:output_type -> deusvm.v1.Empty
+	1,  // 100: deusvm.v1.VMService.Get:output_type -> deusvm.v1.VM
+	10, // 101: deusvm.v1.VMService.List:output_type -> deusvm.v1.ListVMsResponse
+	0,  // 102: deusvm.v1.VMService.InsertMedia:output_type -> deusvm.v1.Empty
+	0,  // 103: deusvm.v1.VMService.EjectMedia:output_type -> deusvm.v1.Empty
+	1,  // 104: deusvm.v1.VMService.AttachInterface:output_type -> deusvm.v1.VM
+	1,  // 105: deusvm.v1.VMService.DetachInterface:output_type -> deusvm.v1.VM
+	1,  // 106: deusvm.v1.VMService.UpdateInterface:output_type -> deusvm.v1.VM
+	11, // 107: deusvm.v1.VMService.Export:output_type -> deusvm.v1.VMArchiveChunk
+	1,  // 108: deusvm.v1.VMService.Import:output_type -> deusvm.v1.VM
+	14, // 109: deusvm.v1.ImageService.Create:output_type -> deusvm.v1.Image
+	17, // 110: deusvm.v1.ImageService.CreateStream:output_type -> deusvm.v1.ImageProgress
+	14, // 111: deusvm.v1.ImageService.Upload:output_type -> deusvm.v1.Image
+	14, // 112: deusvm.v1.ImageService.Tag:output_type -> deusvm.v1.Image
+	14, // 113: deusvm.v1.ImageService.CaptureFromVM:output_type -> deusvm.v1.Image
+	0,  // 114: deusvm.v1.ImageService.Delete:output_type -> deusvm.v1.Empty
+	23, // 115: deusvm.v1.ImageService.List:output_type -> deusvm.v1.ListImagesResponse
+	24, // 116: deusvm.v1.VolumeService.Create:output_type -> deusvm.v1.Volume
+	24, // 117: deusvm.v1.VolumeService.Get:output_type -> deusvm.v1.Volume
+	30, // 118: deusvm.v1.VolumeService.List:output_type -> deusvm.v1.ListVolumesResponse
+	0,  // 119: deusvm.v1.VolumeService.Delete:output_type -> deusvm.v1.Empty
+	24, // 120: deusvm.v1.VolumeService.Attach:output_type -> deusvm.v1.Volume
+	24, // 121: deusvm.v1.VolumeService.Detach:output_type -> deusvm.v1.Volume
+	24, // 122: deusvm.v1.VolumeService.Resize:output_type -> deusvm.v1.Volume
+	24, // 123: deusvm.v1.VolumeService.Clone:output_type -> deusvm.v1.Volume
+	32, // 124: deusvm.v1.StorageService.Convert:output_type -> deusvm.v1.Job
+	32, // 125: deusvm.v1.StorageService.GetJob:output_type -> deusvm.v1.Job
+	34, // 126: deusvm.v1.StorageService.ListJobs:output_type -> deusvm.v1.ListJobsResponse
+	38, // 127: deusvm.v1.StorageService.Usage:output_type -> deusvm.v1.StorageUsage
+	41, // 128: deusvm.v1.StorageService.GarbageCollect:output_type -> deusvm.v1.GCReport
+	32, // 129: deusvm.v1.BackupService.Create:output_type -> deusvm.v1.Job
+	46, // 130: deusvm.v1.BackupService.List:output_type -> deusvm.v1.ListBackupsResponse
+	43, // 131: deusvm.v1.BackupService.Get:output_type -> deusvm.v1.Backup
+	32, // 132: deusvm.v1.BackupService.Restore:output_type -> deusvm.v1.Job
+	0,  // 133: deusvm.v1.BackupService.Delete:output_type -> deusvm.v1.Empty
+	50, // 134: deusvm.v1.NetworkService.Create:output_type -> deusvm.v1.Network
+	50, // 135: deusvm.v1.NetworkService.Get:output_type -> deusvm.v1.Network
+	53, // 136: deusvm.v1.NetworkService.List:output_type -> deusvm.v1.ListNetworksResponse
+	0,  // 137: deusvm.v1.NetworkService.Delete:output_type -> deusvm.v1.Empty
+	56, // 138: deusvm.v1.NetworkService.ListAllocations:output_type -> deusvm.v1.ListAllocationsResponse
+	58, // 139: deusvm.v1.SecurityGroupService.Create:output_type -> deusvm.v1.SecurityGroup
+	58, // 140: deusvm.v1.SecurityGroupService.Get:output_type -> deusvm.v1.SecurityGroup
+	60, // 141: deusvm.v1.SecurityGroupService.List:output_type -> deusvm.v1.ListSecurityGroupsResponse
+	58, // 142: deusvm.v1.SecurityGroupService.Update:output_type -> deusvm.v1.SecurityGroup
+	0,  // 143: deusvm.v1.SecurityGroupService.Delete:output_type -> deusvm.v1.Empty
+	1,  // 144: deusvm.v1.SecurityGroupService.SetNICGroups:output_type -> deusvm.v1.VM
+	62, // 145: deusvm.v1.PortForwardService.Create:output_type -> deusvm.v1.PortForward
+	62, // 146: deusvm.v1.PortForwardService.Get:output_type -> deusvm.v1.PortForward
+	65, // 147: deusvm.v1.PortForwardService.List:output_type -> deusvm.v1.ListPortForwardsResponse
+	0,  // 148: deusvm.v1.PortForwardService.Delete:output_type -> deusvm.v1.Empty
+	66, // 149: deusvm.v1.BootService.CreateTemplate:output_type -> deusvm.v1.BootTemplate
+	66, // 150: deusvm.v1.BootService.GetTemplate:output_type -> deusvm.v1.BootTemplate
+	68, // 151: deusvm.v1.BootService.ListTemplates:output_type -> deusvm.v1.ListBootTemplatesResponse
+	66, // 152: deusvm.v1.BootService.UpdateTemplate:output_type -> deusvm.v1.BootTemplate
+	0,  // 153: deusvm.v1.BootService.DeleteTemplate:output_type -> deusvm.v1.Empty
+	1,  // 154: deusvm.v1.BootService.SetVMTemplate:output_type -> deusvm.v1.VM
+	71, // 155: deusvm.v1.HostService.ListNetworkInterfaces:output_type -> deusvm.v1.ListNetworkInterfacesResponse
+	96, // [96:156] is the sub-list for method output_type
+	36, // [36:96] is the sub-list for method input_type
+	36, // [36:36] is the sub-list for extension type_name
+	36, // [36:36] is the sub-list for extension extendee
+	0,  // [0:36] is the sub-list for field type_name
 }
 
 func init() { file_deusvm_proto_init() }
@@ -638,15 +5618,24 @@ func file_deusvm_proto_init() {
 	if File_deusvm_proto != nil {
 		return
 	}
+	file_deusvm_proto_msgTypes[13].OneofWrappers = []any{
+		(*ImportVMRequest_Info)(nil),
+		(*ImportVMRequest_Chunk)(nil),
+	}
+	file_deusvm_proto_msgTypes[19].OneofWrappers = []any{
+		(*UploadImageRequest_Info)(nil),
+		(*UploadImageRequest_Chunk)(nil),
+		(*UploadImageRequest_Sha256)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_deusvm_proto_rawDesc), len(file_deusvm_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   72,
 			NumExtensions: 0,
-			NumServices:   2,
+			NumServices:   10,
 		},
 		GoTypes:           file_deusvm_proto_goTypes,
 		DependencyIndexes: file_deusvm_proto_depIdxs,
@@ -19,12 +19,19 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	VMService_Create_FullMethodName = "/deusvm.v1.VMService/Create"
-	VMService_Delete_FullMethodName = "/deusvm.v1.VMService/Delete"
-	VMService_Start_FullMethodName  = "/deusvm.v1.VMService/Start"
-	VMService_Stop_FullMethodName   = "/deusvm.v1.VMService/Stop"
-	VMService_Get_FullMethodName    = "/deusvm.v1.VMService/Get"
-	VMService_List_FullMethodName   = "/deusvm.v1.VMService/List"
+	VMService_Create_FullMethodName          = "/deusvm.v1.VMService/Create"
+	VMService_Delete_FullMethodName          = "/deusvm.v1.VMService/Delete"
+	VMService_Start_FullMethodName           = "/deusvm.v1.VMService/Start"
+	VMService_Stop_FullMethodName            = "/deusvm.v1.VMService/Stop"
+	VMService_Get_FullMethodName             = "/deusvm.v1.VMService/Get"
+	VMService_List_FullMethodName            = "/deusvm.v1.VMService/List"
+	VMService_InsertMedia_FullMethodName     = "/deusvm.v1.VMService/InsertMedia"
+	VMService_EjectMedia_FullMethodName      = "/deusvm.v1.VMService/EjectMedia"
+	VMService_AttachInterface_FullMethodName = "/deusvm.v1.VMService/AttachInterface"
+	VMService_DetachInterface_FullMethodName = "/deusvm.v1.VMService/DetachInterface"
+	VMService_UpdateInterface_FullMethodName = "/deusvm.v1.VMService/UpdateInterface"
+	VMService_Export_FullMethodName          = "/deusvm.v1.VMService/Export"
+	VMService_Import_FullMethodName          = "/deusvm.v1.VMService/Import"
 )
 
 // VMServiceClient is the client API for VMService service.
@@ -37,6 +44,13 @@ type VMServiceClient interface {
 	Stop(ctx context.Context, in *VMIDRequest, opts ...grpc.CallOption) (*Empty, error)
 	Get(ctx context.Context, in *VMIDRequest, opts ...grpc.CallOption) (*VM, error)
 	List(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListVMsResponse, error)
+	InsertMedia(ctx context.Context, in *InsertMediaRequest, opts ...grpc.CallOption) (*Empty, error)
+	EjectMedia(ctx context.Context, in *VMIDRequest, opts ...grpc.CallOption) (*Empty, error)
+	AttachInterface(ctx context.Context, in *AttachInterfaceRequest, opts ...grpc.CallOption) (*VM, error)
+	DetachInterface(ctx context.Context, in *DetachInterfaceRequest, opts ...grpc.CallOption) (*VM, error)
+	UpdateInterface(ctx context.Context, in *UpdateInterfaceRequest, opts ...grpc.CallOption) (*VM, error)
+	Export(ctx context.Context, in *VMIDRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[VMArchiveChunk], error)
+	Import(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportVMRequest, VM], error)
 }
 
 type vMServiceClient struct {
@@ -107,6 +121,88 @@ func (c *vMServiceClient) List(ctx context.Context, in *Empty, opts ...grpc.Call
 	return out, nil
 }
 
+func (c *vMServiceClient) InsertMedia(ctx context.Context, in *InsertMediaRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, VMService_InsertMedia_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vMServiceClient) EjectMedia(ctx context.Context, in *VMIDRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, VMService_EjectMedia_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vMServiceClient) AttachInterface(ctx context.Context, in *AttachInterfaceRequest, opts ...grpc.CallOption) (*VM, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VM)
+	err := c.cc.Invoke(ctx, VMService_AttachInterface_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vMServiceClient) DetachInterface(ctx context.Context, in *DetachInterfaceRequest, opts ...grpc.CallOption) (*VM, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VM)
+	err := c.cc.Invoke(ctx, VMService_DetachInterface_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vMServiceClient) UpdateInterface(ctx context.Context, in *UpdateInterfaceRequest, opts ...grpc.CallOption) (*VM, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VM)
+	err := c.cc.Invoke(ctx, VMService_UpdateInterface_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vMServiceClient) Export(ctx context.Context, in *VMIDRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[VMArchiveChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &VMService_ServiceDesc.Streams[0], VMService_Export_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[VMIDRequest, VMArchiveChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VMService_ExportClient = grpc.ServerStreamingClient[VMArchiveChunk]
+
+func (c *vMServiceClient) Import(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportVMRequest, VM], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &VMService_ServiceDesc.Streams[1], VMService_Import_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportVMRequest, VM]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VMService_ImportClient = grpc.ClientStreamingClient[ImportVMRequest, VM]
+
 // VMServiceServer is the server API for VMService service.
 // All implementations must embed UnimplementedVMServiceServer
 // for forward compatibility.
@@ -117,6 +213,13 @@ type VMServiceServer interface {
 	Stop(context.Context, *VMIDRequest) (*Empty, error)
 	Get(context.Context, *VMIDRequest) (*VM, error)
 	List(context.Context, *Empty) (*ListVMsResponse, error)
+	InsertMedia(context.Context, *InsertMediaRequest) (*Empty, error)
+	EjectMedia(context.Context, *VMIDRequest) (*Empty, error)
+	AttachInterface(context.Context, *AttachInterfaceRequest) (*VM, error)
+	DetachInterface(context.Context, *DetachInterfaceRequest) (*VM, error)
+	UpdateInterface(context.Context, *UpdateInterfaceRequest) (*VM, error)
+	Export(*VMIDRequest, grpc.ServerStreamingServer[VMArchiveChunk]) error
+	Import(grpc.ClientStreamingServer[ImportVMRequest, VM]) error
 	mustEmbedUnimplementedVMServiceServer()
 }
 
@@ -145,6 +248,27 @@ func (UnimplementedVMServiceServer) Get(context.Context, *VMIDRequest) (*VM, err
 func (UnimplementedVMServiceServer) List(context.Context, *Empty) (*ListVMsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
 }
+func (UnimplementedVMServiceServer) InsertMedia(context.Context, *InsertMediaRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InsertMedia not implemented")
+}
+func (UnimplementedVMServiceServer) EjectMedia(context.Context, *VMIDRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EjectMedia not implemented")
+}
+func (UnimplementedVMServiceServer) AttachInterface(context.Context, *AttachInterfaceRequest) (*VM, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AttachInterface not implemented")
+}
+func (UnimplementedVMServiceServer) DetachInterface(context.Context, *DetachInterfaceRequest) (*VM, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DetachInterface not implemented")
+}
+func (UnimplementedVMServiceServer) UpdateInterface(context.Context, *UpdateInterfaceRequest) (*VM, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateInterface not implemented")
+}
+func (UnimplementedVMServiceServer) Export(*VMIDRequest, grpc.ServerStreamingServer[VMArchiveChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+func (UnimplementedVMServiceServer) Import(grpc.ClientStreamingServer[ImportVMRequest, VM]) error {
+	return status.Errorf(codes.Unimplemented, "method Import not implemented")
+}
 func (UnimplementedVMServiceServer) mustEmbedUnimplementedVMServiceServer() {}
 func (UnimplementedVMServiceServer) testEmbeddedByValue()                   {}
 
@@ -274,6 +398,114 @@ func _VMService_List_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _VMService_InsertMedia_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InsertMediaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VMServiceServer).InsertMedia(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VMService_InsertMedia_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VMServiceServer).InsertMedia(ctx, req.(*InsertMediaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VMService_EjectMedia_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VMIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VMServiceServer).EjectMedia(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VMService_EjectMedia_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VMServiceServer).EjectMedia(ctx, req.(*VMIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VMService_AttachInterface_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttachInterfaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VMServiceServer).AttachInterface(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VMService_AttachInterface_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VMServiceServer).AttachInterface(ctx, req.(*AttachInterfaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VMService_DetachInterface_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DetachInterfaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VMServiceServer).DetachInterface(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VMService_DetachInterface_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VMServiceServer).DetachInterface(ctx, req.(*DetachInterfaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VMService_UpdateInterface_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateInterfaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VMServiceServer).UpdateInterface(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VMService_UpdateInterface_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VMServiceServer).UpdateInterface(ctx, req.(*UpdateInterfaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VMService_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(VMIDRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(VMServiceServer).Export(m, &grpc.GenericServerStream[VMIDRequest, VMArchiveChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VMService_ExportServer = grpc.ServerStreamingServer[VMArchiveChunk]
+
+func _VMService_Import_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(VMServiceServer).Import(&grpc.GenericServerStream[ImportVMRequest, VM]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VMService_ImportServer = grpc.ClientStreamingServer[ImportVMRequest, VM]
+
 // VMService_ServiceDesc is the grpc.ServiceDesc for VMService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -305,15 +537,50 @@ var VMService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "List",
 			Handler:    _VMService_List_Handler,
 		},
+		{
+			MethodName: "InsertMedia",
+			Handler:    _VMService_InsertMedia_Handler,
+		},
+		{
+			MethodName: "EjectMedia",
+			Handler:    _VMService_EjectMedia_Handler,
+		},
+		{
+			MethodName: "AttachInterface",
+			Handler:    _VMService_AttachInterface_Handler,
+		},
+		{
+			MethodName: "DetachInterface",
+			Handler:    _VMService_DetachInterface_Handler,
+		},
+		{
+			MethodName: "UpdateInterface",
+			Handler:    _VMService_UpdateInterface_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Export",
+			Handler:       _VMService_Export_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Import",
+			Handler:       _VMService_Import_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "deusvm.proto",
 }
 
 const (
-	ImageService_Create_FullMethodName = "/deusvm.v1.ImageService/Create"
-	ImageService_Delete_FullMethodName = "/deusvm.v1.ImageService/Delete"
-	ImageService_List_FullMethodName   = "/deusvm.v1.ImageService/List"
+	ImageService_Create_FullMethodName        = "/deusvm.v1.ImageService/Create"
+	ImageService_CreateStream_FullMethodName  = "/deusvm.v1.ImageService/CreateStream"
+	ImageService_Upload_FullMethodName        = "/deusvm.v1.ImageService/Upload"
+	ImageService_Tag_FullMethodName           = "/deusvm.v1.ImageService/Tag"
+	ImageService_CaptureFromVM_FullMethodName = "/deusvm.v1.ImageService/CaptureFromVM"
+	ImageService_Delete_FullMethodName        = "/deusvm.v1.ImageService/Delete"
+	ImageService_List_FullMethodName          = "/deusvm.v1.ImageService/List"
 )
 
 // ImageServiceClient is the client API for ImageService service.
@@ -321,6 +588,10 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ImageServiceClient interface {
 	Create(ctx context.Context, in *CreateImageRequest, opts ...grpc.CallOption) (*Image, error)
+	CreateStream(ctx context.Context, in *CreateImageRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ImageProgress], error)
+	Upload(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadImageRequest, Image], error)
+	Tag(ctx context.Context, in *TagImageRequest, opts ...grpc.CallOption) (*Image, error)
+	CaptureFromVM(ctx context.Context, in *CaptureImageRequest, opts ...grpc.CallOption) (*Image, error)
 	Delete(ctx context.Context, in *ImageNameRequest, opts ...grpc.CallOption) (*Empty, error)
 	List(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListImagesResponse, error)
 }
@@ -343,6 +614,58 @@ func (c *imageServiceClient) Create(ctx context.Context, in *CreateImageRequest,
 	return out, nil
 }
 
+func (c *imageServiceClient) CreateStream(ctx context.Context, in *CreateImageRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ImageProgress], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ImageService_ServiceDesc.Streams[0], ImageService_CreateStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CreateImageRequest, ImageProgress]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ImageService_CreateStreamClient = grpc.ServerStreamingClient[ImageProgress]
+
+func (c *imageServiceClient) Upload(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadImageRequest, Image], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ImageService_ServiceDesc.Streams[1], ImageService_Upload_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadImageRequest, Image]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ImageService_UploadClient = grpc.ClientStreamingClient[UploadImageRequest, Image]
+
+func (c *imageServiceClient) Tag(ctx context.Context, in *TagImageRequest, opts ...grpc.CallOption) (*Image, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Image)
+	err := c.cc.Invoke(ctx, ImageService_Tag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) CaptureFromVM(ctx context.Context, in *CaptureImageRequest, opts ...grpc.CallOption) (*Image, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Image)
+	err := c.cc.Invoke(ctx, ImageService_CaptureFromVM_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *imageServiceClient) Delete(ctx context.Context, in *ImageNameRequest, opts ...grpc.CallOption) (*Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Empty)
@@ -368,6 +691,10 @@ func (c *imageServiceClient) List(ctx context.Context, in *Empty, opts ...grpc.C
 // for forward compatibility.
 type ImageServiceServer interface {
 	Create(context.Context, *CreateImageRequest) (*Image, error)
+	CreateStream(*CreateImageRequest, grpc.ServerStreamingServer[ImageProgress]) error
+	Upload(grpc.ClientStreamingServer[UploadImageRequest, Image]) error
+	Tag(context.Context, *TagImageRequest) (*Image, error)
+	CaptureFromVM(context.Context, *CaptureImageRequest) (*Image, error)
 	Delete(context.Context, *ImageNameRequest) (*Empty, error)
 	List(context.Context, *Empty) (*ListImagesResponse, error)
 	mustEmbedUnimplementedImageServiceServer()
@@ -383,6 +710,18 @@ type UnimplementedImageServiceServer struct{}
 func (UnimplementedImageServiceServer) Create(context.Context, *CreateImageRequest) (*Image, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
 }
+func (UnimplementedImageServiceServer) CreateStream(*CreateImageRequest, grpc.ServerStreamingServer[ImageProgress]) error {
+	return status.Errorf(codes.Unimplemented, "method CreateStream not implemented")
+}
+func (UnimplementedImageServiceServer) Upload(grpc.ClientStreamingServer[UploadImageRequest, Image]) error {
+	return status.Errorf(codes.Unimplemented, "method Upload not implemented")
+}
+func (UnimplementedImageServiceServer) Tag(context.Context, *TagImageRequest) (*Image, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Tag not implemented")
+}
+func (UnimplementedImageServiceServer) CaptureFromVM(context.Context, *CaptureImageRequest) (*Image, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CaptureFromVM not implemented")
+}
 func (UnimplementedImageServiceServer) Delete(context.Context, *ImageNameRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
@@ -428,6 +767,60 @@ func _ImageService_Create_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ImageService_CreateStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CreateImageRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ImageServiceServer).CreateStream(m, &grpc.GenericServerStream[CreateImageRequest, ImageProgress]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ImageService_CreateStreamServer = grpc.ServerStreamingServer[ImageProgress]
+
+func _ImageService_Upload_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ImageServiceServer).Upload(&grpc.GenericServerStream[UploadImageRequest, Image]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ImageService_UploadServer = grpc.ClientStreamingServer[UploadImageRequest, Image]
+
+func _ImageService_Tag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TagImageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).Tag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_Tag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).Tag(ctx, req.(*TagImageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_CaptureFromVM_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CaptureImageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).CaptureFromVM(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_CaptureFromVM_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).CaptureFromVM(ctx, req.(*CaptureImageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ImageService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ImageNameRequest)
 	if err := dec(in); err != nil {
@@ -475,6 +868,14 @@ var ImageService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Create",
 			Handler:    _ImageService_Create_Handler,
 		},
+		{
+			MethodName: "Tag",
+			Handler:    _ImageService_Tag_Handler,
+		},
+		{
+			MethodName: "CaptureFromVM",
+			Handler:    _ImageService_CaptureFromVM_Handler,
+		},
 		{
 			MethodName: "Delete",
 			Handler:    _ImageService_Delete_Handler,
@@ -484,6 +885,2085 @@ var ImageService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _ImageService_List_Handler,
 		},
 	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CreateStream",
+			Handler:       _ImageService_CreateStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Upload",
+			Handler:       _ImageService_Upload_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "deusvm.proto",
+}
+
+const (
+	VolumeService_Create_FullMethodName = "/deusvm.v1.VolumeService/Create"
+	VolumeService_Get_FullMethodName    = "/deusvm.v1.VolumeService/Get"
+	VolumeService_List_FullMethodName   = "/deusvm.v1.VolumeService/List"
+	VolumeService_Delete_FullMethodName = "/deusvm.v1.VolumeService/Delete"
+	VolumeService_Attach_FullMethodName = "/deusvm.v1.VolumeService/Attach"
+	VolumeService_Detach_FullMethodName = "/deusvm.v1.VolumeService/Detach"
+	VolumeService_Resize_FullMethodName = "/deusvm.v1.VolumeService/Resize"
+	VolumeService_Clone_FullMethodName  = "/deusvm.v1.VolumeService/Clone"
+)
+
+// VolumeServiceClient is the client API for VolumeService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type VolumeServiceClient interface {
+	Create(ctx context.Context, in *CreateVolumeRequest, opts ...grpc.CallOption) (*Volume, error)
+	Get(ctx context.Context, in *VolumeNameRequest, opts ...grpc.CallOption) (*Volume, error)
+	List(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListVolumesResponse, error)
+	Delete(ctx context.Context, in *VolumeNameRequest, opts ...grpc.CallOption) (*Empty, error)
+	Attach(ctx context.Context, in *AttachVolumeRequest, opts ...grpc.CallOption) (*Volume, error)
+	Detach(ctx context.Context, in *VolumeNameRequest, opts ...grpc.CallOption) (*Volume, error)
+	Resize(ctx context.Context, in *ResizeVolumeRequest, opts ...grpc.CallOption) (*Volume, error)
+	Clone(ctx context.Context, in *CloneVolumeRequest, opts ...grpc.CallOption) (*Volume, error)
+}
+
+type volumeServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVolumeServiceClient(cc grpc.ClientConnInterface) VolumeServiceClient {
+	return &volumeServiceClient{cc}
+}
+
+func (c *volumeServiceClient) Create(ctx context.Context, in *CreateVolumeRequest, opts ...grpc.CallOption) (*Volume, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Volume)
+	err := c.cc.Invoke(ctx, VolumeService_Create_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeServiceClient) Get(ctx context.Context, in *VolumeNameRequest, opts ...grpc.CallOption) (*Volume, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Volume)
+	err := c.cc.Invoke(ctx, VolumeService_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeServiceClient) List(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListVolumesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListVolumesResponse)
+	err := c.cc.Invoke(ctx, VolumeService_List_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeServiceClient) Delete(ctx context.Context, in *VolumeNameRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, VolumeService_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeServiceClient) Attach(ctx context.Context, in *AttachVolumeRequest, opts ...grpc.CallOption) (*Volume, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Volume)
+	err := c.cc.Invoke(ctx, VolumeService_Attach_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeServiceClient) Detach(ctx context.Context, in *VolumeNameRequest, opts ...grpc.CallOption) (*Volume, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Volume)
+	err := c.cc.Invoke(ctx, VolumeService_Detach_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeServiceClient) Resize(ctx context.Context, in *ResizeVolumeRequest, opts ...grpc.CallOption) (*Volume, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Volume)
+	err := c.cc.Invoke(ctx, VolumeService_Resize_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeServiceClient) Clone(ctx context.Context, in *CloneVolumeRequest, opts ...grpc.CallOption) (*Volume, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Volume)
+	err := c.cc.Invoke(ctx, VolumeService_Clone_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// VolumeServiceServer is the server API for VolumeService service.
+// All implementations must embed UnimplementedVolumeServiceServer
+// for forward compatibility.
+type VolumeServiceServer interface {
+	Create(context.Context, *CreateVolumeRequest) (*Volume, error)
+	Get(context.Context, *VolumeNameRequest) (*Volume, error)
+	List(context.Context, *Empty) (*ListVolumesResponse, error)
+	Delete(context.Context, *VolumeNameRequest) (*Empty, error)
+	Attach(context.Context, *AttachVolumeRequest) (*Volume, error)
+	Detach(context.Context, *VolumeNameRequest) (*Volume, error)
+	Resize(context.Context, *ResizeVolumeRequest) (*Volume, error)
+	Clone(context.Context, *CloneVolumeRequest) (*Volume, error)
+	mustEmbedUnimplementedVolumeServiceServer()
+}
+
+// UnimplementedVolumeServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedVolumeServiceServer struct{}
+
+func (UnimplementedVolumeServiceServer) Create(context.Context, *CreateVolumeRequest) (*Volume, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedVolumeServiceServer) Get(context.Context, *VolumeNameRequest) (*Volume, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedVolumeServiceServer) List(context.Context, *Empty) (*ListVolumesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedVolumeServiceServer) Delete(context.Context, *VolumeNameRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedVolumeServiceServer) Attach(context.Context, *AttachVolumeRequest) (*Volume, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Attach not implemented")
+}
+func (UnimplementedVolumeServiceServer) Detach(context.Context, *VolumeNameRequest) (*Volume, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Detach not implemented")
+}
+func (UnimplementedVolumeServiceServer) Resize(context.Context, *ResizeVolumeRequest) (*Volume, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resize not implemented")
+}
+func (UnimplementedVolumeServiceServer) Clone(context.Context, *CloneVolumeRequest) (*Volume, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Clone not implemented")
+}
+func (UnimplementedVolumeServiceServer) mustEmbedUnimplementedVolumeServiceServer() {}
+func (UnimplementedVolumeServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeVolumeServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to VolumeServiceServer will
+// result in compilation errors.
+type UnsafeVolumeServiceServer interface {
+	mustEmbedUnimplementedVolumeServiceServer()
+}
+
+func RegisterVolumeServiceServer(s grpc.ServiceRegistrar, srv VolumeServiceServer) {
+	// If the following call pancis, it indicates UnimplementedVolumeServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&VolumeService_ServiceDesc, srv)
+}
+
+func _VolumeService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateVolumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VolumeService_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeServiceServer).Create(ctx, req.(*CreateVolumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VolumeNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VolumeService_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeServiceServer).Get(ctx, req.(*VolumeNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VolumeService_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeServiceServer).List(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VolumeNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VolumeService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeServiceServer).Delete(ctx, req.(*VolumeNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeService_Attach_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttachVolumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeServiceServer).Attach(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VolumeService_Attach_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeServiceServer).Attach(ctx, req.(*AttachVolumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeService_Detach_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VolumeNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeServiceServer).Detach(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VolumeService_Detach_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeServiceServer).Detach(ctx, req.(*VolumeNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeService_Resize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResizeVolumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeServiceServer).Resize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VolumeService_Resize_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeServiceServer).Resize(ctx, req.(*ResizeVolumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeService_Clone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CloneVolumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeServiceServer).Clone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VolumeService_Clone_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeServiceServer).Clone(ctx, req.(*CloneVolumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// VolumeService_ServiceDesc is the grpc.ServiceDesc for VolumeService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var VolumeService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "deusvm.v1.VolumeService",
+	HandlerType: (*VolumeServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _VolumeService_Create_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _VolumeService_Get_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _VolumeService_List_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _VolumeService_Delete_Handler,
+		},
+		{
+			MethodName: "Attach",
+			Handler:    _VolumeService_Attach_Handler,
+		},
+		{
+			MethodName: "Detach",
+			Handler:    _VolumeService_Detach_Handler,
+		},
+		{
+			MethodName: "Resize",
+			Handler:    _VolumeService_Resize_Handler,
+		},
+		{
+			MethodName: "Clone",
+			Handler:    _VolumeService_Clone_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "deusvm.proto",
+}
+
+const (
+	StorageService_Convert_FullMethodName        = "/deusvm.v1.StorageService/Convert"
+	StorageService_GetJob_FullMethodName         = "/deusvm.v1.StorageService/GetJob"
+	StorageService_ListJobs_FullMethodName       = "/deusvm.v1.StorageService/ListJobs"
+	StorageService_Usage_FullMethodName          = "/deusvm.v1.StorageService/Usage"
+	StorageService_GarbageCollect_FullMethodName = "/deusvm.v1.StorageService/GarbageCollect"
+)
+
+// StorageServiceClient is the client API for StorageService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// StorageService runs operations that span images and volumes.
+type StorageServiceClient interface {
+	Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*Job, error)
+	GetJob(ctx context.Context, in *JobIDRequest, opts ...grpc.CallOption) (*Job, error)
+	ListJobs(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListJobsResponse, error)
+	Usage(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*StorageUsage, error)
+	GarbageCollect(ctx context.Context, in *GCRequest, opts ...grpc.CallOption) (*GCReport, error)
+}
+
+type storageServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStorageServiceClient(cc grpc.ClientConnInterface) StorageServiceClient {
+	return &storageServiceClient{cc}
+}
+
+func (c *storageServiceClient) Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, StorageService_Convert_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) GetJob(ctx context.Context, in *JobIDRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, StorageService_GetJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) ListJobs(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListJobsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListJobsResponse)
+	err := c.cc.Invoke(ctx, StorageService_ListJobs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) Usage(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*StorageUsage, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StorageUsage)
+	err := c.cc.Invoke(ctx, StorageService_Usage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) GarbageCollect(ctx context.Context, in *GCRequest, opts ...grpc.CallOption) (*GCReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GCReport)
+	err := c.cc.Invoke(ctx, StorageService_GarbageCollect_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StorageServiceServer is the server API for StorageService service.
+// All implementations must embed UnimplementedStorageServiceServer
+// for forward compatibility.
+//
+// StorageService runs operations that span images and volumes.
+type StorageServiceServer interface {
+	Convert(context.Context, *ConvertRequest) (*Job, error)
+	GetJob(context.Context, *JobIDRequest) (*Job, error)
+	ListJobs(context.Context, *Empty) (*ListJobsResponse, error)
+	Usage(context.Context, *Empty) (*StorageUsage, error)
+	GarbageCollect(context.Context, *GCRequest) (*GCReport, error)
+	mustEmbedUnimplementedStorageServiceServer()
+}
+
+// UnimplementedStorageServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedStorageServiceServer struct{}
+
+func (UnimplementedStorageServiceServer) Convert(context.Context, *ConvertRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Convert not implemented")
+}
+func (UnimplementedStorageServiceServer) GetJob(context.Context, *JobIDRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedStorageServiceServer) ListJobs(context.Context, *Empty) (*ListJobsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListJobs not implemented")
+}
+func (UnimplementedStorageServiceServer) Usage(context.Context, *Empty) (*StorageUsage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Usage not implemented")
+}
+func (UnimplementedStorageServiceServer) GarbageCollect(context.Context, *GCRequest) (*GCReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GarbageCollect not implemented")
+}
+func (UnimplementedStorageServiceServer) mustEmbedUnimplementedStorageServiceServer() {}
+func (UnimplementedStorageServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeStorageServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StorageServiceServer will
+// result in compilation errors.
+type UnsafeStorageServiceServer interface {
+	mustEmbedUnimplementedStorageServiceServer()
+}
+
+func RegisterStorageServiceServer(s grpc.ServiceRegistrar, srv StorageServiceServer) {
+	// If the following call pancis, it indicates UnimplementedStorageServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&StorageService_ServiceDesc, srv)
+}
+
+func _StorageService_Convert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConvertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).Convert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_Convert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).Convert(ctx, req.(*ConvertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_GetJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).GetJob(ctx, req.(*JobIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_ListJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).ListJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_ListJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).ListJobs(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_Usage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).Usage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_Usage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).Usage(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_GarbageCollect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GCRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).GarbageCollect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_GarbageCollect_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).GarbageCollect(ctx, req.(*GCRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// StorageService_ServiceDesc is the grpc.ServiceDesc for StorageService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var StorageService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "deusvm.v1.StorageService",
+	HandlerType: (*StorageServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Convert",
+			Handler:    _StorageService_Convert_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _StorageService_GetJob_Handler,
+		},
+		{
+			MethodName: "ListJobs",
+			Handler:    _StorageService_ListJobs_Handler,
+		},
+		{
+			MethodName: "Usage",
+			Handler:    _StorageService_Usage_Handler,
+		},
+		{
+			MethodName: "GarbageCollect",
+			Handler:    _StorageService_GarbageCollect_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "deusvm.proto",
+}
+
+const (
+	BackupService_Create_FullMethodName  = "/deusvm.v1.BackupService/Create"
+	BackupService_List_FullMethodName    = "/deusvm.v1.BackupService/List"
+	BackupService_Get_FullMethodName     = "/deusvm.v1.BackupService/Get"
+	BackupService_Restore_FullMethodName = "/deusvm.v1.BackupService/Restore"
+	BackupService_Delete_FullMethodName  = "/deusvm.v1.BackupService/Delete"
+)
+
+// BackupServiceClient is the client API for BackupService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BackupService takes and restores VM backups. Create and Restore run as
+// jobs, followed with StorageService.GetJob.
+type BackupServiceClient interface {
+	Create(ctx context.Context, in *CreateBackupRequest, opts ...grpc.CallOption) (*Job, error)
+	List(ctx context.Context, in *ListBackupsRequest, opts ...grpc.CallOption) (*ListBackupsResponse, error)
+	Get(ctx context.Context, in *BackupIDRequest, opts ...grpc.CallOption) (*Backup, error)
+	Restore(ctx context.Context, in *RestoreBackupRequest, opts ...grpc.CallOption) (*Job, error)
+	Delete(ctx context.Context, in *BackupIDRequest, opts ...grpc.CallOption) (*Empty, error)
+}
+
+type backupServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBackupServiceClient(cc grpc.ClientConnInterface) BackupServiceClient {
+	return &backupServiceClient{cc}
+}
+
+func (c *backupServiceClient) Create(ctx context.Context, in *CreateBackupRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, BackupService_Create_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backupServiceClient) List(ctx context.Context, in *ListBackupsRequest, opts ...grpc.CallOption) (*ListBackupsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBackupsResponse)
+	err := c.cc.Invoke(ctx, BackupService_List_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backupServiceClient) Get(ctx context.Context, in *BackupIDRequest, opts ...grpc.CallOption) (*Backup, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Backup)
+	err := c.cc.Invoke(ctx, BackupService_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backupServiceClient) Restore(ctx context.Context, in *RestoreBackupRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, BackupService_Restore_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backupServiceClient) Delete(ctx context.Context, in *BackupIDRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, BackupService_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BackupServiceServer is the server API for BackupService service.
+// All implementations must embed UnimplementedBackupServiceServer
+// for forward compatibility.
+//
+// BackupService takes and restores VM backups. Create and Restore run as
+// jobs, followed with StorageService.GetJob.
+type BackupServiceServer interface {
+	Create(context.Context, *CreateBackupRequest) (*Job, error)
+	List(context.Context, *ListBackupsRequest) (*ListBackupsResponse, error)
+	Get(context.Context, *BackupIDRequest) (*Backup, error)
+	Restore(context.Context, *RestoreBackupRequest) (*Job, error)
+	Delete(context.Context, *BackupIDRequest) (*Empty, error)
+	mustEmbedUnimplementedBackupServiceServer()
+}
+
+// UnimplementedBackupServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBackupServiceServer struct{}
+
+func (UnimplementedBackupServiceServer) Create(context.Context, *CreateBackupRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedBackupServiceServer) List(context.Context, *ListBackupsRequest) (*ListBackupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedBackupServiceServer) Get(context.Context, *BackupIDRequest) (*Backup, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedBackupServiceServer) Restore(context.Context, *RestoreBackupRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Restore not implemented")
+}
+func (UnimplementedBackupServiceServer) Delete(context.Context, *BackupIDRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedBackupServiceServer) mustEmbedUnimplementedBackupServiceServer() {}
+func (UnimplementedBackupServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeBackupServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BackupServiceServer will
+// result in compilation errors.
+type UnsafeBackupServiceServer interface {
+	mustEmbedUnimplementedBackupServiceServer()
+}
+
+func RegisterBackupServiceServer(s grpc.ServiceRegistrar, srv BackupServiceServer) {
+	// If the following call pancis, it indicates UnimplementedBackupServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BackupService_ServiceDesc, srv)
+}
+
+func _BackupService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBackupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackupServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BackupService_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackupServiceServer).Create(ctx, req.(*CreateBackupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BackupService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBackupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackupServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BackupService_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackupServiceServer).List(ctx, req.(*ListBackupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BackupService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BackupIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackupServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BackupService_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackupServiceServer).Get(ctx, req.(*BackupIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BackupService_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreBackupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackupServiceServer).Restore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BackupService_Restore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackupServiceServer).Restore(ctx, req.(*RestoreBackupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BackupService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BackupIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackupServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BackupService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackupServiceServer).Delete(ctx, req.(*BackupIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BackupService_ServiceDesc is the grpc.ServiceDesc for BackupService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BackupService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "deusvm.v1.BackupService",
+	HandlerType: (*BackupServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _BackupService_Create_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _BackupService_List_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _BackupService_Get_Handler,
+		},
+		{
+			MethodName: "Restore",
+			Handler:    _BackupService_Restore_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _BackupService_Delete_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "deusvm.proto",
+}
+
+const (
+	NetworkService_Create_FullMethodName          = "/deusvm.v1.NetworkService/Create"
+	NetworkService_Get_FullMethodName             = "/deusvm.v1.NetworkService/Get"
+	NetworkService_List_FullMethodName            = "/deusvm.v1.NetworkService/List"
+	NetworkService_Delete_FullMethodName          = "/deusvm.v1.NetworkService/Delete"
+	NetworkService_ListAllocations_FullMethodName = "/deusvm.v1.NetworkService/ListAllocations"
+)
+
+// NetworkServiceClient is the client API for NetworkService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// NetworkService manages the libvirt networks VM NICs attach to.
+type NetworkServiceClient interface {
+	Create(ctx context.Context, in *CreateNetworkRequest, opts ...grpc.CallOption) (*Network, error)
+	Get(ctx context.Context, in *NetworkNameRequest, opts ...grpc.CallOption) (*Network, error)
+	List(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListNetworksResponse, error)
+	Delete(ctx context.Context, in *NetworkNameRequest, opts ...grpc.CallOption) (*Empty, error)
+	ListAllocations(ctx context.Context, in *ListAllocationsRequest, opts ...grpc.CallOption) (*ListAllocationsResponse, error)
+}
+
+type networkServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNetworkServiceClient(cc grpc.ClientConnInterface) NetworkServiceClient {
+	return &networkServiceClient{cc}
+}
+
+func (c *networkServiceClient) Create(ctx context.Context, in *CreateNetworkRequest, opts ...grpc.CallOption) (*Network, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Network)
+	err := c.cc.Invoke(ctx, NetworkService_Create_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *networkServiceClient) Get(ctx context.Context, in *NetworkNameRequest, opts ...grpc.CallOption) (*Network, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Network)
+	err := c.cc.Invoke(ctx, NetworkService_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *networkServiceClient) List(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListNetworksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListNetworksResponse)
+	err := c.cc.Invoke(ctx, NetworkService_List_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *networkServiceClient) Delete(ctx context.Context, in *NetworkNameRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, NetworkService_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *networkServiceClient) ListAllocations(ctx context.Context, in *ListAllocationsRequest, opts ...grpc.CallOption) (*ListAllocationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAllocationsResponse)
+	err := c.cc.Invoke(ctx, NetworkService_ListAllocations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// NetworkServiceServer is the server API for NetworkService service.
+// All implementations must embed UnimplementedNetworkServiceServer
+// for forward compatibility.
+//
+// NetworkService manages the libvirt networks VM NICs attach to.
+type NetworkServiceServer interface {
+	Create(context.Context, *CreateNetworkRequest) (*Network, error)
+	Get(context.Context, *NetworkNameRequest) (*Network, error)
+	List(context.Context, *Empty) (*ListNetworksResponse, error)
+	Delete(context.Context, *NetworkNameRequest) (*Empty, error)
+	ListAllocations(context.Context, *ListAllocationsRequest) (*ListAllocationsResponse, error)
+	mustEmbedUnimplementedNetworkServiceServer()
+}
+
+// UnimplementedNetworkServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedNetworkServiceServer struct{}
+
+func (UnimplementedNetworkServiceServer) Create(context.Context, *CreateNetworkRequest) (*Network, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedNetworkServiceServer) Get(context.Context, *NetworkNameRequest) (*Network, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedNetworkServiceServer) List(context.Context, *Empty) (*ListNetworksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedNetworkServiceServer) Delete(context.Context, *NetworkNameRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedNetworkServiceServer) ListAllocations(context.Context, *ListAllocationsRequest) (*ListAllocationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAllocations not implemented")
+}
+func (UnimplementedNetworkServiceServer) mustEmbedUnimplementedNetworkServiceServer() {}
+func (UnimplementedNetworkServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeNetworkServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NetworkServiceServer will
+// result in compilation errors.
+type UnsafeNetworkServiceServer interface {
+	mustEmbedUnimplementedNetworkServiceServer()
+}
+
+func RegisterNetworkServiceServer(s grpc.ServiceRegistrar, srv NetworkServiceServer) {
+	// If the following call pancis, it indicates UnimplementedNetworkServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&NetworkService_ServiceDesc, srv)
+}
+
+func _NetworkService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateNetworkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetworkServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NetworkService_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetworkServiceServer).Create(ctx, req.(*CreateNetworkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NetworkService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NetworkNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetworkServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NetworkService_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetworkServiceServer).Get(ctx, req.(*NetworkNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NetworkService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetworkServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NetworkService_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetworkServiceServer).List(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NetworkService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NetworkNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetworkServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NetworkService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetworkServiceServer).Delete(ctx, req.(*NetworkNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NetworkService_ListAllocations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAllocationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetworkServiceServer).ListAllocations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NetworkService_ListAllocations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetworkServiceServer).ListAllocations(ctx, req.(*ListAllocationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// NetworkService_ServiceDesc is the grpc.ServiceDesc for NetworkService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var NetworkService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "deusvm.v1.NetworkService",
+	HandlerType: (*NetworkServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _NetworkService_Create_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _NetworkService_Get_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _NetworkService_List_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _NetworkService_Delete_Handler,
+		},
+		{
+			MethodName: "ListAllocations",
+			Handler:    _NetworkService_ListAllocations_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "deusvm.proto",
+}
+
+const (
+	SecurityGroupService_Create_FullMethodName       = "/deusvm.v1.SecurityGroupService/Create"
+	SecurityGroupService_Get_FullMethodName          = "/deusvm.v1.SecurityGroupService/Get"
+	SecurityGroupService_List_FullMethodName         = "/deusvm.v1.SecurityGroupService/List"
+	SecurityGroupService_Update_FullMethodName       = "/deusvm.v1.SecurityGroupService/Update"
+	SecurityGroupService_Delete_FullMethodName       = "/deusvm.v1.SecurityGroupService/Delete"
+	SecurityGroupService_SetNICGroups_FullMethodName = "/deusvm.v1.SecurityGroupService/SetNICGroups"
+)
+
+// SecurityGroupServiceClient is the client API for SecurityGroupService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SecurityGroupService manages the rule sets enforced on VM NICs with
+// nftables.
+type SecurityGroupServiceClient interface {
+	Create(ctx context.Context, in *SecurityGroup, opts ...grpc.CallOption) (*SecurityGroup, error)
+	Get(ctx context.Context, in *SecurityGroupNameRequest, opts ...grpc.CallOption) (*SecurityGroup, error)
+	List(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListSecurityGroupsResponse, error)
+	Update(ctx context.Context, in *SecurityGroup, opts ...grpc.CallOption) (*SecurityGroup, error)
+	Delete(ctx context.Context, in *SecurityGroupNameRequest, opts ...grpc.CallOption) (*Empty, error)
+	SetNICGroups(ctx context.Context, in *SetNICSecurityGroupsRequest, opts ...grpc.CallOption) (*VM, error)
+}
+
+type securityGroupServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSecurityGroupServiceClient(cc grpc.ClientConnInterface) SecurityGroupServiceClient {
+	return &securityGroupServiceClient{cc}
+}
+
+func (c *securityGroupServiceClient) Create(ctx context.Context, in *SecurityGroup, opts ...grpc.CallOption) (*SecurityGroup, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SecurityGroup)
+	err := c.cc.Invoke(ctx, SecurityGroupService_Create_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *securityGroupServiceClient) Get(ctx context.Context, in *SecurityGroupNameRequest, opts ...grpc.CallOption) (*SecurityGroup, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SecurityGroup)
+	err := c.cc.Invoke(ctx, SecurityGroupService_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *securityGroupServiceClient) List(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListSecurityGroupsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSecurityGroupsResponse)
+	err := c.cc.Invoke(ctx, SecurityGroupService_List_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *securityGroupServiceClient) Update(ctx context.Context, in *SecurityGroup, opts ...grpc.CallOption) (*SecurityGroup, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SecurityGroup)
+	err := c.cc.Invoke(ctx, SecurityGroupService_Update_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *securityGroupServiceClient) Delete(ctx context.Context, in *SecurityGroupNameRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, SecurityGroupService_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *securityGroupServiceClient) SetNICGroups(ctx context.Context, in *SetNICSecurityGroupsRequest, opts ...grpc.CallOption) (*VM, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VM)
+	err := c.cc.Invoke(ctx, SecurityGroupService_SetNICGroups_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SecurityGroupServiceServer is the server API for SecurityGroupService service.
+// All implementations must embed UnimplementedSecurityGroupServiceServer
+// for forward compatibility.
+//
+// SecurityGroupService manages the rule sets enforced on VM NICs with
+// nftables.
+type SecurityGroupServiceServer interface {
+	Create(context.Context, *SecurityGroup) (*SecurityGroup, error)
+	Get(context.Context, *SecurityGroupNameRequest) (*SecurityGroup, error)
+	List(context.Context, *Empty) (*ListSecurityGroupsResponse, error)
+	Update(context.Context, *SecurityGroup) (*SecurityGroup, error)
+	Delete(context.Context, *SecurityGroupNameRequest) (*Empty, error)
+	SetNICGroups(context.Context, *SetNICSecurityGroupsRequest) (*VM, error)
+	mustEmbedUnimplementedSecurityGroupServiceServer()
+}
+
+// UnimplementedSecurityGroupServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSecurityGroupServiceServer struct{}
+
+func (UnimplementedSecurityGroupServiceServer) Create(context.Context, *SecurityGroup) (*SecurityGroup, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedSecurityGroupServiceServer) Get(context.Context, *SecurityGroupNameRequest) (*SecurityGroup, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedSecurityGroupServiceServer) List(context.Context, *Empty) (*ListSecurityGroupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedSecurityGroupServiceServer) Update(context.Context, *SecurityGroup) (*SecurityGroup, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedSecurityGroupServiceServer) Delete(context.Context, *SecurityGroupNameRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedSecurityGroupServiceServer) SetNICGroups(context.Context, *SetNICSecurityGroupsRequest) (*VM, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetNICGroups not implemented")
+}
+func (UnimplementedSecurityGroupServiceServer) mustEmbedUnimplementedSecurityGroupServiceServer() {}
+func (UnimplementedSecurityGroupServiceServer) testEmbeddedByValue()                              {}
+
+// UnsafeSecurityGroupServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SecurityGroupServiceServer will
+// result in compilation errors.
+type UnsafeSecurityGroupServiceServer interface {
+	mustEmbedUnimplementedSecurityGroupServiceServer()
+}
+
+func RegisterSecurityGroupServiceServer(s grpc.ServiceRegistrar, srv SecurityGroupServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSecurityGroupServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SecurityGroupService_ServiceDesc, srv)
+}
+
+func _SecurityGroupService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SecurityGroup)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecurityGroupServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SecurityGroupService_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecurityGroupServiceServer).Create(ctx, req.(*SecurityGroup))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SecurityGroupService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SecurityGroupNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecurityGroupServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SecurityGroupService_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecurityGroupServiceServer).Get(ctx, req.(*SecurityGroupNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SecurityGroupService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecurityGroupServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SecurityGroupService_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecurityGroupServiceServer).List(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SecurityGroupService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SecurityGroup)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecurityGroupServiceServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SecurityGroupService_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecurityGroupServiceServer).Update(ctx, req.(*SecurityGroup))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SecurityGroupService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SecurityGroupNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecurityGroupServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SecurityGroupService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecurityGroupServiceServer).Delete(ctx, req.(*SecurityGroupNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SecurityGroupService_SetNICGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetNICSecurityGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecurityGroupServiceServer).SetNICGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SecurityGroupService_SetNICGroups_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecurityGroupServiceServer).SetNICGroups(ctx, req.(*SetNICSecurityGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SecurityGroupService_ServiceDesc is the grpc.ServiceDesc for SecurityGroupService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SecurityGroupService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "deusvm.v1.SecurityGroupService",
+	HandlerType: (*SecurityGroupServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _SecurityGroupService_Create_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _SecurityGroupService_Get_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _SecurityGroupService_List_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _SecurityGroupService_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _SecurityGroupService_Delete_Handler,
+		},
+		{
+			MethodName: "SetNICGroups",
+			Handler:    _SecurityGroupService_SetNICGroups_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "deusvm.proto",
+}
+
+const (
+	PortForwardService_Create_FullMethodName = "/deusvm.v1.PortForwardService/Create"
+	PortForwardService_Get_FullMethodName    = "/deusvm.v1.PortForwardService/Get"
+	PortForwardService_List_FullMethodName   = "/deusvm.v1.PortForwardService/List"
+	PortForwardService_Delete_FullMethodName = "/deusvm.v1.PortForwardService/Delete"
+)
+
+// PortForwardServiceClient is the client API for PortForwardService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PortForwardService forwards host ports to VMs on NAT networks with DNAT
+// rules.
+type PortForwardServiceClient interface {
+	Create(ctx context.Context, in *PortForward, opts ...grpc.CallOption) (*PortForward, error)
+	Get(ctx context.Context, in *PortForwardNameRequest, opts ...grpc.CallOption) (*PortForward, error)
+	List(ctx context.Context, in *ListPortForwardsRequest, opts ...grpc.CallOption) (*ListPortForwardsResponse, error)
+	Delete(ctx context.Context, in *PortForwardNameRequest, opts ...grpc.CallOption) (*Empty, error)
+}
+
+type portForwardServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPortForwardServiceClient(cc grpc.ClientConnInterface) PortForwardServiceClient {
+	return &portForwardServiceClient{cc}
+}
+
+func (c *portForwardServiceClient) Create(ctx context.Context, in *PortForward, opts ...grpc.CallOption) (*PortForward, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PortForward)
+	err := c.cc.Invoke(ctx, PortForwardService_Create_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *portForwardServiceClient) Get(ctx context.Context, in *PortForwardNameRequest, opts ...grpc.CallOption) (*PortForward, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PortForward)
+	err := c.cc.Invoke(ctx, PortForwardService_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *portForwardServiceClient) List(ctx context.Context, in *ListPortForwardsRequest, opts ...grpc.CallOption) (*ListPortForwardsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPortForwardsResponse)
+	err := c.cc.Invoke(ctx, PortForwardService_List_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *portForwardServiceClient) Delete(ctx context.Context, in *PortForwardNameRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, PortForwardService_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PortForwardServiceServer is the server API for PortForwardService service.
+// All implementations must embed UnimplementedPortForwardServiceServer
+// for forward compatibility.
+//
+// PortForwardService forwards host ports to VMs on NAT networks with DNAT
+// rules.
+type PortForwardServiceServer interface {
+	Create(context.Context, *PortForward) (*PortForward, error)
+	Get(context.Context, *PortForwardNameRequest) (*PortForward, error)
+	List(context.Context, *ListPortForwardsRequest) (*ListPortForwardsResponse, error)
+	Delete(context.Context, *PortForwardNameRequest) (*Empty, error)
+	mustEmbedUnimplementedPortForwardServiceServer()
+}
+
+// UnimplementedPortForwardServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPortForwardServiceServer struct{}
+
+func (UnimplementedPortForwardServiceServer) Create(context.Context, *PortForward) (*PortForward, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedPortForwardServiceServer) Get(context.Context, *PortForwardNameRequest) (*PortForward, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedPortForwardServiceServer) List(context.Context, *ListPortForwardsRequest) (*ListPortForwardsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedPortForwardServiceServer) Delete(context.Context, *PortForwardNameRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedPortForwardServiceServer) mustEmbedUnimplementedPortForwardServiceServer() {}
+func (UnimplementedPortForwardServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafePortForwardServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PortForwardServiceServer will
+// result in compilation errors.
+type UnsafePortForwardServiceServer interface {
+	mustEmbedUnimplementedPortForwardServiceServer()
+}
+
+func RegisterPortForwardServiceServer(s grpc.ServiceRegistrar, srv PortForwardServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPortForwardServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PortForwardService_ServiceDesc, srv)
+}
+
+func _PortForwardService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PortForward)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PortForwardServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PortForwardService_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PortForwardServiceServer).Create(ctx, req.(*PortForward))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PortForwardService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PortForwardNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PortForwardServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PortForwardService_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PortForwardServiceServer).Get(ctx, req.(*PortForwardNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PortForwardService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPortForwardsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PortForwardServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PortForwardService_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PortForwardServiceServer).List(ctx, req.(*ListPortForwardsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PortForwardService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PortForwardNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PortForwardServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PortForwardService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PortForwardServiceServer).Delete(ctx, req.(*PortForwardNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PortForwardService_ServiceDesc is the grpc.ServiceDesc for PortForwardService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PortForwardService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "deusvm.v1.PortForwardService",
+	HandlerType: (*PortForwardServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _PortForwardService_Create_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _PortForwardService_Get_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _PortForwardService_List_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _PortForwardService_Delete_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "deusvm.proto",
+}
+
+const (
+	BootService_CreateTemplate_FullMethodName = "/deusvm.v1.BootService/CreateTemplate"
+	BootService_GetTemplate_FullMethodName    = "/deusvm.v1.BootService/GetTemplate"
+	BootService_ListTemplates_FullMethodName  = "/deusvm.v1.BootService/ListTemplates"
+	BootService_UpdateTemplate_FullMethodName = "/deusvm.v1.BootService/UpdateTemplate"
+	BootService_DeleteTemplate_FullMethodName = "/deusvm.v1.BootService/DeleteTemplate"
+	BootService_SetVMTemplate_FullMethodName  = "/deusvm.v1.BootService/SetVMTemplate"
+)
+
+// BootServiceClient is the client API for BootService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BootService manages the iPXE templates VMs booting from networks with PXE
+// are served.
+type BootServiceClient interface {
+	CreateTemplate(ctx context.Context, in *BootTemplate, opts ...grpc.CallOption) (*BootTemplate, error)
+	GetTemplate(ctx context.Context, in *BootTemplateNameRequest, opts ...grpc.CallOption) (*BootTemplate, error)
+	ListTemplates(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListBootTemplatesResponse, error)
+	UpdateTemplate(ctx context.Context, in *BootTemplate, opts ...grpc.CallOption) (*BootTemplate, error)
+	DeleteTemplate(ctx context.Context, in *BootTemplateNameRequest, opts ...grpc.CallOption) (*Empty, error)
+	SetVMTemplate(ctx context.Context, in *SetVMBootTemplateRequest, opts ...grpc.CallOption) (*VM, error)
+}
+
+type bootServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBootServiceClient(cc grpc.ClientConnInterface) BootServiceClient {
+	return &bootServiceClient{cc}
+}
+
+func (c *bootServiceClient) CreateTemplate(ctx context.Context, in *BootTemplate, opts ...grpc.CallOption) (*BootTemplate, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BootTemplate)
+	err := c.cc.Invoke(ctx, BootService_CreateTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bootServiceClient) GetTemplate(ctx context.Context, in *BootTemplateNameRequest, opts ...grpc.CallOption) (*BootTemplate, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BootTemplate)
+	err := c.cc.Invoke(ctx, BootService_GetTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bootServiceClient) ListTemplates(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListBootTemplatesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBootTemplatesResponse)
+	err := c.cc.Invoke(ctx, BootService_ListTemplates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bootServiceClient) UpdateTemplate(ctx context.Context, in *BootTemplate, opts ...grpc.CallOption) (*BootTemplate, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BootTemplate)
+	err := c.cc.Invoke(ctx, BootService_UpdateTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bootServiceClient) DeleteTemplate(ctx context.Context, in *BootTemplateNameRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, BootService_DeleteTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bootServiceClient) SetVMTemplate(ctx context.Context, in *SetVMBootTemplateRequest, opts ...grpc.CallOption) (*VM, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VM)
+	err := c.cc.Invoke(ctx, BootService_SetVMTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BootServiceServer is the server API for BootService service.
+// All implementations must embed UnimplementedBootServiceServer
+// for forward compatibility.
+//
+// BootService manages the iPXE templates VMs booting from networks with PXE
+// are served.
+type BootServiceServer interface {
+	CreateTemplate(context.Context, *BootTemplate) (*BootTemplate, error)
+	GetTemplate(context.Context, *BootTemplateNameRequest) (*BootTemplate, error)
+	ListTemplates(context.Context, *Empty) (*ListBootTemplatesResponse, error)
+	UpdateTemplate(context.Context, *BootTemplate) (*BootTemplate, error)
+	DeleteTemplate(context.Context, *BootTemplateNameRequest) (*Empty, error)
+	SetVMTemplate(context.Context, *SetVMBootTemplateRequest) (*VM, error)
+	mustEmbedUnimplementedBootServiceServer()
+}
+
+// UnimplementedBootServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBootServiceServer struct{}
+
+func (UnimplementedBootServiceServer) CreateTemplate(context.Context, *BootTemplate) (*BootTemplate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTemplate not implemented")
+}
+func (UnimplementedBootServiceServer) GetTemplate(context.Context, *BootTemplateNameRequest) (*BootTemplate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTemplate not implemented")
+}
+func (UnimplementedBootServiceServer) ListTemplates(context.Context, *Empty) (*ListBootTemplatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTemplates not implemented")
+}
+func (UnimplementedBootServiceServer) UpdateTemplate(context.Context, *BootTemplate) (*BootTemplate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateTemplate not implemented")
+}
+func (UnimplementedBootServiceServer) DeleteTemplate(context.Context, *BootTemplateNameRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteTemplate not implemented")
+}
+func (UnimplementedBootServiceServer) SetVMTemplate(context.Context, *SetVMBootTemplateRequest) (*VM, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetVMTemplate not implemented")
+}
+func (UnimplementedBootServiceServer) mustEmbedUnimplementedBootServiceServer() {}
+func (UnimplementedBootServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeBootServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BootServiceServer will
+// result in compilation errors.
+type UnsafeBootServiceServer interface {
+	mustEmbedUnimplementedBootServiceServer()
+}
+
+func RegisterBootServiceServer(s grpc.ServiceRegistrar, srv BootServiceServer) {
+	// If the following call pancis, it indicates UnimplementedBootServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BootService_ServiceDesc, srv)
+}
+
+func _BootService_CreateTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BootTemplate)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BootServiceServer).CreateTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BootService_CreateTemplate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BootServiceServer).CreateTemplate(ctx, req.(*BootTemplate))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BootService_GetTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BootTemplateNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BootServiceServer).GetTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BootService_GetTemplate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BootServiceServer).GetTemplate(ctx, req.(*BootTemplateNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BootService_ListTemplates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BootServiceServer).ListTemplates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BootService_ListTemplates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BootServiceServer).ListTemplates(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BootService_UpdateTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BootTemplate)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BootServiceServer).UpdateTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BootService_UpdateTemplate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BootServiceServer).UpdateTemplate(ctx, req.(*BootTemplate))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BootService_DeleteTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BootTemplateNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BootServiceServer).DeleteTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BootService_DeleteTemplate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BootServiceServer).DeleteTemplate(ctx, req.(*BootTemplateNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BootService_SetVMTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetVMBootTemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BootServiceServer).SetVMTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BootService_SetVMTemplate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BootServiceServer).SetVMTemplate(ctx, req.(*SetVMBootTemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BootService_ServiceDesc is the grpc.ServiceDesc for BootService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BootService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "deusvm.v1.BootService",
+	HandlerType: (*BootServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateTemplate",
+			Handler:    _BootService_CreateTemplate_Handler,
+		},
+		{
+			MethodName: "GetTemplate",
+			Handler:    _BootService_GetTemplate_Handler,
+		},
+		{
+			MethodName: "ListTemplates",
+			Handler:    _BootService_ListTemplates_Handler,
+		},
+		{
+			MethodName: "UpdateTemplate",
+			Handler:    _BootService_UpdateTemplate_Handler,
+		},
+		{
+			MethodName: "DeleteTemplate",
+			Handler:    _BootService_DeleteTemplate_Handler,
+		},
+		{
+			MethodName: "SetVMTemplate",
+			Handler:    _BootService_SetVMTemplate_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "deusvm.proto",
+}
+
+const (
+	HostService_ListNetworkInterfaces_FullMethodName = "/deusvm.v1.HostService/ListNetworkInterfaces"
+)
+
+// HostServiceClient is the client API for HostService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// HostService reports on the host VMs run on.
+type HostServiceClient interface {
+	ListNetworkInterfaces(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListNetworkInterfacesResponse, error)
+}
+
+type hostServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewHostServiceClient(cc grpc.ClientConnInterface) HostServiceClient {
+	return &hostServiceClient{cc}
+}
+
+func (c *hostServiceClient) ListNetworkInterfaces(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListNetworkInterfacesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListNetworkInterfacesResponse)
+	err := c.cc.Invoke(ctx, HostService_ListNetworkInterfaces_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HostServiceServer is the server API for HostService service.
+// All implementations must embed UnimplementedHostServiceServer
+// for forward compatibility.
+//
+// HostService reports on the host VMs run on.
+type HostServiceServer interface {
+	ListNetworkInterfaces(context.Context, *Empty) (*ListNetworkInterfacesResponse, error)
+	mustEmbedUnimplementedHostServiceServer()
+}
+
+// UnimplementedHostServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedHostServiceServer struct{}
+
+func (UnimplementedHostServiceServer) ListNetworkInterfaces(context.Context, *Empty) (*ListNetworkInterfacesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListNetworkInterfaces not implemented")
+}
+func (UnimplementedHostServiceServer) mustEmbedUnimplementedHostServiceServer() {}
+func (UnimplementedHostServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeHostServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to HostServiceServer will
+// result in compilation errors.
+type UnsafeHostServiceServer interface {
+	mustEmbedUnimplementedHostServiceServer()
+}
+
+func RegisterHostServiceServer(s grpc.ServiceRegistrar, srv HostServiceServer) {
+	// If the following call pancis, it indicates UnimplementedHostServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&HostService_ServiceDesc, srv)
+}
+
+func _HostService_ListNetworkInterfaces_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServiceServer).ListNetworkInterfaces(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostService_ListNetworkInterfaces_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServiceServer).ListNetworkInterfaces(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// HostService_ServiceDesc is the grpc.ServiceDesc for HostService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var HostService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "deusvm.v1.HostService",
+	HandlerType: (*HostServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListNetworkInterfaces",
+			Handler:    _HostService_ListNetworkInterfaces_Handler,
+		},
+	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "deusvm.proto",
 }
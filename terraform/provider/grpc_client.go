@@ -9,9 +9,11 @@ import (
 )
 
 type GRPCClients struct {
-	VM    deusvmproto.VMServiceClient
-	Image deusvmproto.ImageServiceClient
-	conn  *grpc.ClientConn
+	VM          deusvmproto.VMServiceClient
+	Image       deusvmproto.ImageServiceClient
+	Network     deusvmproto.NetworkServiceClient
+	PortForward deusvmproto.PortForwardServiceClient
+	conn        *grpc.ClientConn
 }
 
 func NewGRPCClients(ctx context.Context, endpoint string, useTLS bool) (*GRPCClients, error) {
@@ -26,9 +28,11 @@ func NewGRPCClients(ctx context.Context, endpoint string, useTLS bool) (*GRPCCli
 		return nil, err
 	}
 	return &GRPCClients{
-		VM:    deusvmproto.NewVMServiceClient(conn),
-		Image: deusvmproto.NewImageServiceClient(conn),
-		conn:  conn,
+		VM:          deusvmproto.NewVMServiceClient(conn),
+		Image:       deusvmproto.NewImageServiceClient(conn),
+		Network:     deusvmproto.NewNetworkServiceClient(conn),
+		PortForward: deusvmproto.NewPortForwardServiceClient(conn),
+		conn:        conn,
 	}, nil
 }
 
@@ -54,6 +54,8 @@ func (p *DeusProvider) Resources(ctx context.Context) []func() resource.Resource
 	return []func() resource.Resource{
 		func() resource.Resource { return NewImageResource() },
 		func() resource.Resource { return NewVMResource() },
+		func() resource.Resource { return NewNetworkResource() },
+		func() resource.Resource { return NewPortForwardResource() },
 	}
 }
 
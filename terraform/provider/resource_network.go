@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	deusvmproto "github.com/riccardotacconi/deusvm/pkg/proto/gen/github.com/riccardotacconi/deusvm/pkg/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type networkResource struct{ clients *GRPCClients }
+
+func NewNetworkResource() resource.Resource { return &networkResource{} }
+
+type networkModel struct {
+	ID     types.String `tfsdk:"id"`
+	Name   types.String `tfsdk:"name"`
+	Mode   types.String `tfsdk:"mode"`
+	Bridge types.String `tfsdk:"bridge"`
+	Uplink types.String `tfsdk:"uplink"`
+
+	IPv4CIDR      types.String `tfsdk:"ipv4_cidr"`
+	IPv4DHCPStart types.String `tfsdk:"ipv4_dhcp_start"`
+	IPv4DHCPEnd   types.String `tfsdk:"ipv4_dhcp_end"`
+	IPv6CIDR      types.String `tfsdk:"ipv6_cidr"`
+	IPv6DHCPStart types.String `tfsdk:"ipv6_dhcp_start"`
+	IPv6DHCPEnd   types.String `tfsdk:"ipv6_dhcp_end"`
+
+	ExternalDNS types.Bool `tfsdk:"external_dns"`
+	PXE         types.Bool `tfsdk:"pxe"`
+}
+
+func (r *networkResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = "deusvm_network"
+}
+
+func (r *networkResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	// networks are not changed in place
+	replace := []planmodifier.String{stringplanmodifier.RequiresReplace()}
+	optional := func() schema.StringAttribute {
+		return schema.StringAttribute{Optional: true, PlanModifiers: replace}
+	}
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id":              schema.StringAttribute{Computed: true},
+			"name":            schema.StringAttribute{Required: true, PlanModifiers: replace},
+			"mode":            schema.StringAttribute{Optional: true, Computed: true, PlanModifiers: replace},
+			"bridge":          schema.StringAttribute{Optional: true, Computed: true, PlanModifiers: replace},
+			"uplink":          optional(),
+			"ipv4_cidr":       optional(),
+			"ipv4_dhcp_start": optional(),
+			"ipv4_dhcp_end":   optional(),
+			"ipv6_cidr":       optional(),
+			"ipv6_dhcp_start": optional(),
+			"ipv6_dhcp_end":   optional(),
+			"external_dns": schema.BoolAttribute{
+				Optional:      true,
+				PlanModifiers: []planmodifier.Bool{boolplanmodifier.RequiresReplace()},
+			},
+			"pxe": schema.BoolAttribute{
+				Optional:      true,
+				PlanModifiers: []planmodifier.Bool{boolplanmodifier.RequiresReplace()},
+			},
+		},
+	}
+}
+
+func (r *networkResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(*GRPCClients)
+	if ok {
+		r.clients = c
+	}
+}
+
+func (r *networkResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data networkModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	n, err := r.clients.Network.Create(ctx, &deusvmproto.CreateNetworkRequest{
+		Name:   data.Name.ValueString(),
+		Mode:   data.Mode.ValueString(),
+		Bridge: data.Bridge.ValueString(),
+		Uplink: data.Uplink.ValueString(),
+		Ipv4:   subnetRequest(data.IPv4CIDR, data.IPv4DHCPStart, data.IPv4DHCPEnd),
+		Ipv6:   subnetRequest(data.IPv6CIDR, data.IPv6DHCPStart, data.IPv6DHCPEnd),
+
+		ExternalDns: data.ExternalDNS.ValueBool(),
+		Pxe:         data.PXE.ValueBool(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("create network", err.Error())
+		return
+	}
+	data.ID = types.StringValue(n.GetName())
+	data.Mode = types.StringValue(n.GetMode())
+	data.Bridge = types.StringValue(n.GetBridge())
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func subnetRequest(cidr, start, end types.String) *deusvmproto.Subnet {
+	if cidr.ValueString() == "" {
+		return nil
+	}
+	return &deusvmproto.Subnet{Cidr: cidr.ValueString(), DhcpStart: start.ValueString(), DhcpEnd: end.ValueString()}
+}
+
+func (r *networkResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data networkModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	n, err := r.clients.Network.Get(ctx, &deusvmproto.NetworkNameRequest{Name: data.ID.ValueString()})
+	if status.Code(err) == codes.NotFound {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		return
+	}
+	data.Name = types.StringValue(n.GetName())
+	data.Mode = types.StringValue(n.GetMode())
+	data.Bridge = types.StringValue(n.GetBridge())
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *networkResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data networkModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *networkResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data networkModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	_, err := r.clients.Network.Delete(ctx, &deusvmproto.NetworkNameRequest{Name: data.ID.ValueString()})
+	if err != nil && status.Code(err) != codes.NotFound {
+		resp.Diagnostics.AddError("delete network", err.Error())
+	}
+}
+
+func (r *networkResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), req.ID)...)
+}
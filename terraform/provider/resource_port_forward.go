@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	deusvmproto "github.com/riccardotacconi/deusvm/pkg/proto/gen/github.com/riccardotacconi/deusvm/pkg/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type portForwardResource struct{ clients *GRPCClients }
+
+func NewPortForwardResource() resource.Resource { return &portForwardResource{} }
+
+type portForwardModel struct {
+	ID       types.String `tfsdk:"id"`
+	Name     types.String `tfsdk:"name"`
+	HostIP   types.String `tfsdk:"host_ip"`
+	HostPort types.Int64  `tfsdk:"host_port"`
+	Protocol types.String `tfsdk:"protocol"`
+	VM       types.String `tfsdk:"vm"`
+	Network  types.String `tfsdk:"network"`
+	Port     types.Int64  `tfsdk:"port"`
+	Target   types.String `tfsdk:"target"`
+}
+
+func (r *portForwardResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = "deusvm_port_forward"
+}
+
+func (r *portForwardResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	// forwards are not changed in place
+	replace := []planmodifier.String{stringplanmodifier.RequiresReplace()}
+	replaceInt := []planmodifier.Int64{int64planmodifier.RequiresReplace()}
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id":        schema.StringAttribute{Computed: true},
+			"name":      schema.StringAttribute{Required: true, PlanModifiers: replace},
+			"host_ip":   schema.StringAttribute{Optional: true, PlanModifiers: replace},
+			"host_port": schema.Int64Attribute{Required: true, PlanModifiers: replaceInt},
+			"protocol":  schema.StringAttribute{Optional: true, Computed: true, PlanModifiers: replace},
+			"vm":        schema.StringAttribute{Required: true, PlanModifiers: replace},
+			"network":   schema.StringAttribute{Optional: true, Computed: true, PlanModifiers: replace},
+			"port":      schema.Int64Attribute{Required: true, PlanModifiers: replaceInt},
+			"target":    schema.StringAttribute{Computed: true},
+		},
+	}
+}
+
+func (r *portForwardResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	c, ok := req.ProviderData.(*GRPCClients)
+	if ok {
+		r.clients = c
+	}
+}
+
+func (r *portForwardResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data portForwardModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	f, err := r.clients.PortForward.Create(ctx, &deusvmproto.PortForward{
+		Name:     data.Name.ValueString(),
+		HostIp:   data.HostIP.ValueString(),
+		HostPort: int32(data.HostPort.ValueInt64()),
+		Protocol: data.Protocol.ValueString(),
+		Vm:       data.VM.ValueString(),
+		Network:  data.Network.ValueString(),
+		Port:     int32(data.Port.ValueInt64()),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("create port forward", err.Error())
+		return
+	}
+	data.ID = types.StringValue(f.GetName())
+	data.Protocol = types.StringValue(f.GetProtocol())
+	data.Network = types.StringValue(f.GetNetwork())
+	data.Target = types.StringValue(f.GetTarget())
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *portForwardResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data portForwardModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	f, err := r.clients.PortForward.Get(ctx, &deusvmproto.PortForwardNameRequest{Name: data.ID.ValueString()})
+	if status.Code(err) == codes.NotFound {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		return
+	}
+	data.Name = types.StringValue(f.GetName())
+	data.HostPort = types.Int64Value(int64(f.GetHostPort()))
+	data.Protocol = types.StringValue(f.GetProtocol())
+	data.Network = types.StringValue(f.GetNetwork())
+	data.Port = types.Int64Value(int64(f.GetPort()))
+	data.Target = types.StringValue(f.GetTarget())
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *portForwardResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data portForwardModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *portForwardResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data portForwardModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	_, err := r.clients.PortForward.Delete(ctx, &deusvmproto.PortForwardNameRequest{Name: data.ID.ValueString()})
+	if err != nil && status.Code(err) != codes.NotFound {
+		resp.Diagnostics.AddError("delete port forward", err.Error())
+	}
+}
+
+func (r *portForwardResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), req.ID)...)
+}
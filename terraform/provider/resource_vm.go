@@ -21,6 +21,12 @@ type vmModel struct {
 	CPU    types.Int64  `tfsdk:"cpu"`
 	Memory types.String `tfsdk:"memory"`
 	Disk   types.String `tfsdk:"disk"`
+	Pool   types.String `tfsdk:"pool"`
+	// ISO boots an installer with a blank disk instead of cloning Image.
+	ISO       types.String   `tfsdk:"iso"`
+	BootOrder []types.String `tfsdk:"boot_order"`
+	// Networks adds a NIC on each managed network, in order.
+	Networks []types.String `tfsdk:"networks"`
 }
 
 func (r *vmResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -32,10 +38,20 @@ func (r *vmResource) Schema(ctx context.Context, req resource.SchemaRequest, res
 		Attributes: map[string]schema.Attribute{
 			"id":     schema.StringAttribute{Computed: true},
 			"name":   schema.StringAttribute{Required: true},
-			"image":  schema.StringAttribute{Required: true},
+			"image":  schema.StringAttribute{Optional: true},
 			"cpu":    schema.Int64Attribute{Required: true},
 			"memory": schema.StringAttribute{Required: true},
 			"disk":   schema.StringAttribute{Required: true},
+			"pool":   schema.StringAttribute{Optional: true},
+			"iso":    schema.StringAttribute{Optional: true},
+			"boot_order": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"networks": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+			},
 		},
 	}
 }
@@ -56,9 +72,20 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	var bootOrder []string
+	for _, dev := range data.BootOrder {
+		bootOrder = append(bootOrder, dev.ValueString())
+	}
+	var nics []*deusvmproto.NIC
+	for _, n := range data.Networks {
+		nics = append(nics, &deusvmproto.NIC{Network: n.ValueString()})
+	}
 	vm, err := r.clients.VM.Create(ctx, &deusvmproto.CreateVMRequest{
 		Name: data.Name.ValueString(), Image: data.Image.ValueString(), Cpu: int32(data.CPU.ValueInt64()),
 		MemoryBytes: 0, DiskBytes: 0, // for simplicity; convert strings later
+		Pool: data.Pool.ValueString(),
+		Iso:  data.ISO.ValueString(), BootOrder: bootOrder,
+		Nics: nics,
 	})
 	if err != nil {
 		resp.Diagnostics.AddError("create vm", err.Error())